@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/katatrina/airbnb-clone/pkg/response"
@@ -13,6 +14,25 @@ import (
 // AuthUser contains user info from the token if authenticated.
 type AuthUser struct {
 	ID string
+
+	// SessionID is the server-side session the token belongs to, if any.
+	SessionID string
+
+	// EmailVerified is the verification state at the time the token was issued.
+	EmailVerified bool
+
+	// Roles are the user's roles at the time the token was issued.
+	Roles []Role
+
+	// Locale, Timezone and Currency are the user's display preferences at the
+	// time the token was issued, empty for older tokens.
+	Locale   string
+	Timezone string
+	Currency string
+
+	// TokenID and TokenExpiresAt identify the presented access token, so it can be revoked on logout.
+	TokenID        string
+	TokenExpiresAt time.Time
 }
 
 const AuthUserKey = "authUser"
@@ -38,7 +58,10 @@ func MustGetAuthUser(c *gin.Context) *AuthUser {
 }
 
 // AuthMiddleware authenticates the request.
-func AuthMiddleware(tokenMaker token.TokenMaker) gin.HandlerFunc {
+//
+// Besides verifying the token itself, it rejects tokens that were revoked through
+// the RevocationStore (logout, logout everywhere) before their natural expiry.
+func AuthMiddleware(tokenVerifier token.TokenVerifier, revocations token.RevocationStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -56,7 +79,7 @@ func AuthMiddleware(tokenMaker token.TokenMaker) gin.HandlerFunc {
 			return
 		}
 
-		claims, err := tokenMaker.VerifyToken(tokenString)
+		claims, err := tokenVerifier.VerifyToken(tokenString)
 		if err != nil {
 			switch {
 			case errors.Is(err, token.ErrTokenExpired):
@@ -74,7 +97,36 @@ func AuthMiddleware(tokenMaker token.TokenMaker) gin.HandlerFunc {
 			return
 		}
 
-		c.Set(AuthUserKey, &AuthUser{ID: claims.UserID})
+		revoked, err := revocations.IsRevoked(c.Request.Context(), claims)
+		if err != nil {
+			// Fail closed: without the store we can't tell a logged-out token from a valid one
+			log.Printf("[ERROR] failed to check token revocation: %v", err)
+			response.ServiceUnavailable(c, "Unable to verify authentication. Please try again later")
+			c.Abort()
+			return
+		}
+		if revoked {
+			response.Unauthorized(c, response.CodeTokenRevoked, "Token has been revoked")
+			c.Abort()
+			return
+		}
+
+		roles := make([]Role, len(claims.Roles))
+		for i, role := range claims.Roles {
+			roles[i] = Role(role)
+		}
+
+		c.Set(AuthUserKey, &AuthUser{
+			ID:             claims.UserID,
+			SessionID:      claims.SessionID,
+			EmailVerified:  claims.EmailVerified,
+			Roles:          roles,
+			Locale:         claims.Locale,
+			Timezone:       claims.Timezone,
+			Currency:       claims.Currency,
+			TokenID:        claims.ID,
+			TokenExpiresAt: claims.ExpiresAt,
+		})
 		c.Next()
 	}
 }
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+)
+
+// InternalAPIKeyHeader carries the shared key on service-to-service requests.
+const InternalAPIKeyHeader = "X-Internal-API-Key"
+
+// RequireInternalAPIKey protects endpoints meant only for other services
+// (under /internal). Callers must send apiKey in InternalAPIKeyHeader; an empty
+// apiKey rejects every request, so a missing config never opens the endpoints.
+func RequireInternalAPIKey(apiKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader(InternalAPIKeyHeader)
+		if apiKey == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(apiKey)) != 1 {
+			response.Unauthorized(c, response.CodeAuthenticationRequired,
+				"A valid internal API key is required")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
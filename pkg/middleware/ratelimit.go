@@ -0,0 +1,157 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+)
+
+// RateLimitStrategy selects how requests are counted.
+type RateLimitStrategy string
+
+const (
+	// TokenBucket allows bursts of up to Limit requests, then refills
+	// continuously at Limit per Window. Good for write endpoints where a short
+	// burst is normal but a sustained flood is not.
+	TokenBucket RateLimitStrategy = "token_bucket"
+
+	// SlidingWindow allows at most Limit requests in any Window-long period.
+	// It has no burst at window boundaries, unlike a fixed window.
+	SlidingWindow RateLimitStrategy = "sliding_window"
+)
+
+// RateLimitKeyFunc returns who a request is counted against.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// RateLimitByIP counts requests per client IP.
+func RateLimitByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimitByUserOrIP counts requests per authenticated user, falling back to
+// the client IP for anonymous requests. Put it after AuthMiddleware to get
+// per-user limits.
+func RateLimitByUserOrIP(c *gin.Context) string {
+	if authUser := GetAuthUser(c); authUser != nil {
+		return "user:" + authUser.ID
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitPolicy describes one limit, e.g. "10 bookings per hour per user".
+type RateLimitPolicy struct {
+	// Name namespaces the counters, so two policies never share them.
+	Name     string
+	Strategy RateLimitStrategy
+	Limit    int
+	Window   time.Duration
+	Key      RateLimitKeyFunc
+}
+
+// RateLimitResult is the outcome of counting one request.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+
+	// Reset is how long until the quota is replenished: fully for a token
+	// bucket, by one request for a sliding window.
+	Reset time.Duration
+
+	// RetryAfter is how long a rejected client should wait.
+	RetryAfter time.Duration
+}
+
+// RateLimitStore counts requests. key is already namespaced by the policy.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimit rejects requests over policy with 429 TOO_MANY_REQUESTS.
+//
+// Every response carries the RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset and RateLimit-Policy headers; rejected ones also carry
+// Retry-After. If the store fails, the request is let through: rate limiting
+// protects the service and must not take it down with Redis.
+func RateLimit(store RateLimitStore, policy RateLimitPolicy) gin.HandlerFunc {
+	if policy.Name == "" || policy.Limit <= 0 || policy.Window <= 0 || policy.Key == nil {
+		panic("RateLimit: policy needs a name, a positive limit and window, and a key function")
+	}
+	if policy.Strategy != TokenBucket && policy.Strategy != SlidingWindow {
+		panic(fmt.Sprintf("RateLimit: unknown strategy %q", policy.Strategy))
+	}
+
+	return func(c *gin.Context) {
+		key := policy.Name + ":" + policy.Key(c)
+
+		result, err := store.Take(c.Request.Context(), key, policy, time.Now())
+		if err != nil {
+			log.Printf("[WARN] rate limit store failed, allowing request: %v", err)
+			c.Next()
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", strconv.FormatInt(ceilSeconds(result.Reset), 10))
+		c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, ceilSeconds(policy.Window)))
+
+		if !result.Allowed {
+			response.TooManyRequests(c, "Too many requests. Please slow down", result.RetryAfter)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func ceilSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(max(d, 0).Seconds()))
+}
+
+// tokenBucketResult turns the bucket level after a request into a result.
+// Both stores share it so they report exactly the same numbers.
+func tokenBucketResult(policy RateLimitPolicy, allowed bool, tokens float64) RateLimitResult {
+	perToken := policy.Window / time.Duration(policy.Limit)
+
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(policy.Limit) - tokens) * float64(perToken)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(perToken))
+	}
+
+	return result
+}
+
+// refillTokens returns the bucket level at now, given its level at last.
+func refillTokens(policy RateLimitPolicy, tokens float64, last, now time.Time) float64 {
+	elapsed := max(now.Sub(last), 0)
+	refilled := tokens + float64(elapsed)/float64(policy.Window)*float64(policy.Limit)
+	return min(refilled, float64(policy.Limit))
+}
+
+// slidingWindowResult turns the request count in the current window into a result.
+// oldest is the time of the oldest request still counted.
+func slidingWindowResult(policy RateLimitPolicy, allowed bool, count int, oldest, now time.Time) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Limit:     policy.Limit,
+		Remaining: max(policy.Limit-count, 0),
+		Reset:     max(oldest.Add(policy.Window).Sub(now), 0),
+	}
+	if !allowed {
+		result.RetryAfter = result.Reset
+	}
+
+	return result
+}
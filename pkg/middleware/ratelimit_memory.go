@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRateLimitStore counts requests in process. Counters are not shared
+// between replicas, so it is meant for tests and single-instance setups.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*memoryBucket
+	windows   map[string]*memoryWindow
+	lastSweep time.Time
+}
+
+type memoryBucket struct {
+	tokens    float64
+	updatedAt time.Time
+	window    time.Duration
+}
+
+type memoryWindow struct {
+	requests []time.Time // oldest first
+	window   time.Duration
+}
+
+// memoryRateLimitSweepInterval is how often idle counters are dropped.
+const memoryRateLimitSweepInterval = time.Minute
+
+var _ RateLimitStore = (*MemoryRateLimitStore)(nil)
+
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*memoryBucket),
+		windows: make(map[string]*memoryWindow),
+	}
+}
+
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	if policy.Strategy == TokenBucket {
+		return s.takeToken(key, policy, now), nil
+	}
+	return s.takeSlot(key, policy, now), nil
+}
+
+func (s *MemoryRateLimitStore) takeToken(key string, policy RateLimitPolicy, now time.Time) RateLimitResult {
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(policy.Limit), updatedAt: now, window: policy.Window}
+		s.buckets[key] = bucket
+	}
+
+	bucket.tokens = refillTokens(policy, bucket.tokens, bucket.updatedAt, now)
+	bucket.updatedAt = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	return tokenBucketResult(policy, allowed, bucket.tokens)
+}
+
+func (s *MemoryRateLimitStore) takeSlot(key string, policy RateLimitPolicy, now time.Time) RateLimitResult {
+	w, ok := s.windows[key]
+	if !ok {
+		w = &memoryWindow{window: policy.Window}
+		s.windows[key] = w
+	}
+
+	w.requests = dropBefore(w.requests, now.Add(-policy.Window))
+
+	allowed := len(w.requests) < policy.Limit
+	if allowed {
+		w.requests = append(w.requests, now)
+	}
+
+	oldest := now
+	if len(w.requests) > 0 {
+		oldest = w.requests[0]
+	}
+
+	return slidingWindowResult(policy, allowed, len(w.requests), oldest, now)
+}
+
+// dropBefore removes the requests made at or before cutoff.
+func dropBefore(requests []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(requests) && !requests[i].After(cutoff) {
+		i++
+	}
+	return requests[i:]
+}
+
+// sweep drops counters idle for longer than their window; they would be
+// indistinguishable from new ones anyway.
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memoryRateLimitSweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.updatedAt) > bucket.window {
+			delete(s.buckets, key)
+		}
+	}
+	for key, w := range s.windows {
+		if len(dropBefore(w.requests, now.Add(-w.window))) == 0 {
+			delete(s.windows, key)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const rateLimitKeyPrefix = "ratelimit:"
+
+// tokenBucketScript refills and takes from a bucket atomically.
+// The level is returned as a string because Redis truncates Lua numbers to integers.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * capacity / window)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], window)
+
+return {allowed, tostring(tokens)}
+`)
+
+// slidingWindowScript keeps the timestamps of recent requests in a sorted set.
+var slidingWindowScript = redis.NewScript(`
+local window = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+
+local count = redis.call('ZCARD', KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+redis.call('PEXPIRE', KEYS[1], window)
+
+return {allowed, count, oldest[2] or tostring(now)}
+`)
+
+// RedisRateLimitStore shares counters between every replica of a service.
+// Each check is a single Lua script, so concurrent requests are counted exactly.
+//
+// Keys:
+//   - ratelimit:{policy}:{key} -> hash {tokens, ts} for a token bucket
+//   - ratelimit:{policy}:{key} -> sorted set of request times for a sliding window
+//
+// Times come from the calling service, so replicas should have synchronized clocks.
+type RedisRateLimitStore struct {
+	client *redis.Client
+}
+
+var _ RateLimitStore = (*RedisRateLimitStore)(nil)
+
+func NewRedisRateLimitStore(client *redis.Client) *RedisRateLimitStore {
+	return &RedisRateLimitStore{client: client}
+}
+
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	if policy.Strategy == TokenBucket {
+		return s.takeToken(ctx, key, policy, now)
+	}
+	return s.takeSlot(ctx, key, policy, now)
+}
+
+func (s *RedisRateLimitStore) takeToken(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	values, err := tokenBucketScript.Run(ctx, s.client,
+		[]string{rateLimitKeyPrefix + key},
+		policy.Limit, policy.Window.Milliseconds(), now.UnixMilli(),
+	).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if len(values) != 2 {
+		return RateLimitResult{}, errors.New("unexpected token bucket script result")
+	}
+	allowed, _ := values[0].(int64)
+	rawTokens, _ := values[1].(string)
+
+	tokens, err := strconv.ParseFloat(rawTokens, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("invalid token bucket level: %w", err)
+	}
+
+	return tokenBucketResult(policy, allowed == 1, tokens), nil
+}
+
+func (s *RedisRateLimitStore) takeSlot(ctx context.Context, key string, policy RateLimitPolicy, now time.Time) (RateLimitResult, error) {
+	member, err := newRequestMember(now)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	values, err := slidingWindowScript.Run(ctx, s.client,
+		[]string{rateLimitKeyPrefix + key},
+		policy.Window.Milliseconds(), policy.Limit, now.UnixMilli(), member,
+	).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	if len(values) != 3 {
+		return RateLimitResult{}, errors.New("unexpected sliding window script result")
+	}
+	allowed, _ := values[0].(int64)
+	count, _ := values[1].(int64)
+	rawOldest, _ := values[2].(string)
+
+	oldest, err := strconv.ParseInt(rawOldest, 10, 64)
+	if err != nil {
+		return RateLimitResult{}, fmt.Errorf("invalid sliding window timestamp: %w", err)
+	}
+
+	return slidingWindowResult(policy, allowed == 1, int(count), time.UnixMilli(oldest), now), nil
+}
+
+// newRequestMember makes a unique sorted set member, so two requests in the
+// same millisecond are both counted.
+func newRequestMember(now time.Time) (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+	return strconv.FormatInt(now.UnixMilli(), 10) + "-" + hex.EncodeToString(b), nil
+}
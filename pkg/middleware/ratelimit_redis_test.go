@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRedisRateLimitStore runs the store scenarios against a real Redis.
+// Set REDIS_TEST_ADDR (e.g. localhost:6379) to run it.
+func TestRedisRateLimitStore(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	testRateLimitStore(t, func(*testing.T) RateLimitStore { return NewRedisRateLimitStore(client) })
+}
+
+func TestRedisRateLimitStoreFailsOpen(t *testing.T) {
+	// Nothing listens on port 1, so every command fails
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+	store := NewRedisRateLimitStore(client)
+
+	for _, strategy := range []RateLimitStrategy{TokenBucket, SlidingWindow} {
+		_, err := store.Take(context.Background(), "test", RateLimitPolicy{Name: "test", Strategy: strategy, Limit: 1, Window: time.Minute}, time.Now())
+		require.Error(t, err, strategy)
+	}
+
+	router := newRateLimitRouter(store, RateLimitPolicy{
+		Name:     "test",
+		Strategy: SlidingWindow,
+		Limit:    1,
+		Window:   time.Minute,
+		Key:      RateLimitByIP,
+	})
+	for range 3 {
+		assert.Equal(t, http.StatusOK, doLimitedRequest(router, "203.0.113.7", "").Code)
+	}
+}
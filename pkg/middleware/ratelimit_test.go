@@ -0,0 +1,312 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// rateLimitStep is one request made at offset from the start of a test.
+type rateLimitStep struct {
+	at             time.Duration
+	wantAllowed    bool
+	wantRemaining  int
+	wantReset      time.Duration
+	wantRetryAfter time.Duration
+}
+
+// testRateLimitStore runs the same scenarios against any store, so the
+// in-memory and Redis stores are held to identical numbers.
+func testRateLimitStore(t *testing.T, newStore func(t *testing.T) RateLimitStore) {
+	// Keys are unique per run, so a shared Redis never carries counts over
+	runID := strconv.FormatInt(time.Now().UnixNano(), 36)
+
+	// 4 requests per 4 seconds: a token is refilled every second. Powers of
+	// two keep the refill arithmetic exact.
+	tokenBucket := RateLimitPolicy{Name: "test-bucket", Strategy: TokenBucket, Limit: 4, Window: 4 * time.Second}
+	slidingWindow := RateLimitPolicy{Name: "test-window", Strategy: SlidingWindow, Limit: 3, Window: time.Minute}
+
+	testCases := []struct {
+		name   string
+		policy RateLimitPolicy
+		steps  []rateLimitStep
+	}{
+		{
+			name:   "token bucket - burst up to the limit, then rejected",
+			policy: tokenBucket,
+			steps: []rateLimitStep{
+				{at: 0, wantAllowed: true, wantRemaining: 3, wantReset: time.Second},
+				{at: 0, wantAllowed: true, wantRemaining: 2, wantReset: 2 * time.Second},
+				{at: 0, wantAllowed: true, wantRemaining: 1, wantReset: 3 * time.Second},
+				{at: 0, wantAllowed: true, wantRemaining: 0, wantReset: 4 * time.Second},
+				{at: 0, wantAllowed: false, wantRemaining: 0, wantReset: 4 * time.Second, wantRetryAfter: time.Second},
+			},
+		},
+		{
+			name:   "token bucket - refills one token per second",
+			policy: tokenBucket,
+			steps: []rateLimitStep{
+				{at: 0, wantAllowed: true, wantRemaining: 3, wantReset: time.Second},
+				{at: 0, wantAllowed: true, wantRemaining: 2, wantReset: 2 * time.Second},
+				{at: 0, wantAllowed: true, wantRemaining: 1, wantReset: 3 * time.Second},
+				{at: 0, wantAllowed: true, wantRemaining: 0, wantReset: 4 * time.Second},
+				{at: 500 * time.Millisecond, wantAllowed: false, wantRemaining: 0, wantReset: 3500 * time.Millisecond, wantRetryAfter: 500 * time.Millisecond},
+				{at: time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 4 * time.Second},
+				{at: time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 4 * time.Second, wantRetryAfter: time.Second},
+				{at: 3 * time.Second, wantAllowed: true, wantRemaining: 1, wantReset: 3 * time.Second},
+			},
+		},
+		{
+			name:   "token bucket - an idle bucket refills up to the limit only",
+			policy: tokenBucket,
+			steps: []rateLimitStep{
+				{at: 0, wantAllowed: true, wantRemaining: 3, wantReset: time.Second},
+				{at: time.Hour, wantAllowed: true, wantRemaining: 3, wantReset: time.Second},
+			},
+		},
+		{
+			name:   "sliding window - rejected until the oldest request leaves the window",
+			policy: slidingWindow,
+			steps: []rateLimitStep{
+				{at: 0, wantAllowed: true, wantRemaining: 2, wantReset: time.Minute},
+				{at: 10 * time.Second, wantAllowed: true, wantRemaining: 1, wantReset: 50 * time.Second},
+				{at: 20 * time.Second, wantAllowed: true, wantRemaining: 0, wantReset: 40 * time.Second},
+				{at: 30 * time.Second, wantAllowed: false, wantRemaining: 0, wantReset: 30 * time.Second, wantRetryAfter: 30 * time.Second},
+				// The first request expires exactly one window later
+				{at: time.Minute, wantAllowed: true, wantRemaining: 0, wantReset: 10 * time.Second},
+				{at: time.Minute, wantAllowed: false, wantRemaining: 0, wantReset: 10 * time.Second, wantRetryAfter: 10 * time.Second},
+			},
+		},
+		{
+			name:   "sliding window - rejected requests are not counted",
+			policy: slidingWindow,
+			steps: []rateLimitStep{
+				{at: 0, wantAllowed: true, wantRemaining: 2, wantReset: time.Minute},
+				{at: 0, wantAllowed: true, wantRemaining: 1, wantReset: time.Minute},
+				{at: 0, wantAllowed: true, wantRemaining: 0, wantReset: time.Minute},
+				{at: 0, wantAllowed: false, wantRemaining: 0, wantReset: time.Minute, wantRetryAfter: time.Minute},
+				{at: 59 * time.Second, wantAllowed: false, wantRemaining: 0, wantReset: time.Second, wantRetryAfter: time.Second},
+				{at: time.Minute, wantAllowed: true, wantRemaining: 2, wantReset: time.Minute},
+			},
+		},
+		{
+			name:   "sliding window - the whole window expires",
+			policy: slidingWindow,
+			steps: []rateLimitStep{
+				{at: 0, wantAllowed: true, wantRemaining: 2, wantReset: time.Minute},
+				{at: 0, wantAllowed: true, wantRemaining: 1, wantReset: time.Minute},
+				{at: 2 * time.Minute, wantAllowed: true, wantRemaining: 2, wantReset: time.Minute},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := newStore(t)
+			ctx := context.Background()
+			start := time.Now().Truncate(time.Second)
+			key := tc.policy.Name + ":" + runID + ":" + t.Name()
+
+			for i, step := range tc.steps {
+				result, err := store.Take(ctx, key, tc.policy, start.Add(step.at))
+				require.NoError(t, err)
+
+				assert.Equal(t, step.wantAllowed, result.Allowed, "step %d: allowed", i)
+				assert.Equal(t, tc.policy.Limit, result.Limit, "step %d: limit", i)
+				assert.Equal(t, step.wantRemaining, result.Remaining, "step %d: remaining", i)
+				assert.InDelta(t, step.wantReset, result.Reset, float64(time.Millisecond), "step %d: reset", i)
+				assert.InDelta(t, step.wantRetryAfter, result.RetryAfter, float64(time.Millisecond), "step %d: retry after", i)
+			}
+		})
+	}
+
+	t.Run("keys are counted separately", func(t *testing.T) {
+		store := newStore(t)
+		ctx := context.Background()
+		now := time.Now()
+		policy := RateLimitPolicy{Name: "test-keys", Strategy: SlidingWindow, Limit: 1, Window: time.Minute}
+
+		result, err := store.Take(ctx, runID+":a", policy, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = store.Take(ctx, runID+":a", policy, now)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+
+		result, err = store.Take(ctx, runID+":b", policy, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	testRateLimitStore(t, func(*testing.T) RateLimitStore { return NewMemoryRateLimitStore() })
+}
+
+func TestMemoryRateLimitStoreSweepsIdleCounters(t *testing.T) {
+	store := NewMemoryRateLimitStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	bucket := RateLimitPolicy{Name: "test-bucket", Strategy: TokenBucket, Limit: 1, Window: time.Second}
+	window := RateLimitPolicy{Name: "test-window", Strategy: SlidingWindow, Limit: 1, Window: time.Second}
+
+	_, err := store.Take(ctx, "bucket", bucket, now)
+	require.NoError(t, err)
+	_, err = store.Take(ctx, "window", window, now)
+	require.NoError(t, err)
+
+	// The next call after the sweep interval drops both idle counters
+	_, err = store.Take(ctx, "other", window, now.Add(memoryRateLimitSweepInterval))
+	require.NoError(t, err)
+
+	assert.NotContains(t, store.buckets, "bucket")
+	assert.NotContains(t, store.windows, "window")
+	assert.Contains(t, store.windows, "other")
+}
+
+// failingRateLimitStore stands in for a store whose backend is down.
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, RateLimitPolicy, time.Time) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("connection refused")
+}
+
+// newRateLimitRouter serves GET /limited behind RateLimit, with the
+// X-Test-User header standing in for an authenticated user.
+func newRateLimitRouter(store RateLimitStore, policy RateLimitPolicy) *gin.Engine {
+	router := gin.New()
+	router.GET("/limited", func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set(AuthUserKey, &AuthUser{ID: userID})
+		}
+	}, RateLimit(store, policy), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func doLimitedRequest(router *gin.Engine, clientIP, userID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = clientIP + ":54321"
+	if userID != "" {
+		req.Header.Set("X-Test-User", userID)
+	}
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit(t *testing.T) {
+	policy := RateLimitPolicy{
+		Name:     "test",
+		Strategy: SlidingWindow,
+		Limit:    2,
+		Window:   time.Minute,
+		Key:      RateLimitByIP,
+	}
+
+	t.Run("headers on allowed requests", func(t *testing.T) {
+		router := newRateLimitRouter(NewMemoryRateLimitStore(), policy)
+
+		rec := doLimitedRequest(router, "203.0.113.7", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+		assert.Empty(t, rec.Header().Get("Retry-After"))
+
+		rec = doLimitedRequest(router, "203.0.113.7", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("429 with Retry-After once over the limit", func(t *testing.T) {
+		router := newRateLimitRouter(NewMemoryRateLimitStore(), policy)
+
+		for range policy.Limit {
+			require.Equal(t, http.StatusOK, doLimitedRequest(router, "203.0.113.7", "").Code)
+		}
+
+		rec := doLimitedRequest(router, "203.0.113.7", "")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get("Retry-After"))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+
+		var body response.Response
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.False(t, body.Success)
+		assert.Equal(t, response.CodeTooManyRequests, body.Code)
+		assert.NotEmpty(t, body.Message)
+
+		// Other clients are not affected
+		assert.Equal(t, http.StatusOK, doLimitedRequest(router, "198.51.100.1", "").Code)
+	})
+
+	t.Run("per user limits follow the user across addresses", func(t *testing.T) {
+		perUser := policy
+		perUser.Key = RateLimitByUserOrIP
+		router := newRateLimitRouter(NewMemoryRateLimitStore(), perUser)
+
+		require.Equal(t, http.StatusOK, doLimitedRequest(router, "203.0.113.7", "user-1").Code)
+		require.Equal(t, http.StatusOK, doLimitedRequest(router, "198.51.100.1", "user-1").Code)
+		assert.Equal(t, http.StatusTooManyRequests, doLimitedRequest(router, "192.0.2.1", "user-1").Code)
+
+		// Anonymous requests from the same address have their own quota
+		assert.Equal(t, http.StatusOK, doLimitedRequest(router, "203.0.113.7", "").Code)
+	})
+
+	t.Run("fails open when the store errors", func(t *testing.T) {
+		router := newRateLimitRouter(failingRateLimitStore{}, policy)
+
+		for range policy.Limit + 1 {
+			rec := doLimitedRequest(router, "203.0.113.7", "")
+			require.Equal(t, http.StatusOK, rec.Code)
+			assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+			assert.Empty(t, rec.Header().Get("Retry-After"))
+		}
+	})
+}
+
+func TestRateLimitRejectsInvalidPolicies(t *testing.T) {
+	valid := RateLimitPolicy{Name: "test", Strategy: TokenBucket, Limit: 1, Window: time.Second, Key: RateLimitByIP}
+
+	testCases := []struct {
+		name   string
+		modify func(*RateLimitPolicy)
+	}{
+		{name: "no name", modify: func(p *RateLimitPolicy) { p.Name = "" }},
+		{name: "zero limit", modify: func(p *RateLimitPolicy) { p.Limit = 0 }},
+		{name: "zero window", modify: func(p *RateLimitPolicy) { p.Window = 0 }},
+		{name: "no key function", modify: func(p *RateLimitPolicy) { p.Key = nil }},
+		{name: "unknown strategy", modify: func(p *RateLimitPolicy) { p.Strategy = "fixed_window" }},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy := valid
+			tc.modify(&policy)
+			assert.Panics(t, func() { RateLimit(NewMemoryRateLimitStore(), policy) })
+		})
+	}
+
+	assert.NotPanics(t, func() { RateLimit(NewMemoryRateLimitStore(), valid) })
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+)
+
+// Role is a coarse-grained group of users, carried in the "roles" token claim.
+type Role string
+
+const (
+	RoleGuest Role = "guest" // May book stays
+	RoleHost  Role = "host"  // May list places
+	RoleAdmin Role = "admin" // Moderation and support
+)
+
+// Permission is a single action that one or more roles are allowed to perform.
+// Routes should prefer RequirePermission over RequireRole, so who may do what
+// is decided here rather than in every router.
+type Permission string
+
+const (
+	PermissionModerateListings Permission = "listings:moderate"
+	PermissionManageBookings   Permission = "bookings:manage"
+	PermissionManageUsers      Permission = "users:manage"
+	PermissionVerifyHosts      Permission = "hosts:verify"
+)
+
+// rolePermissions maps each role to the permissions it grants.
+var rolePermissions = map[Role][]Permission{
+	RoleAdmin: {
+		PermissionModerateListings,
+		PermissionManageBookings,
+		PermissionManageUsers,
+		PermissionVerifyHosts,
+	},
+}
+
+// IsValidRole reports whether role is one of the known roles.
+func IsValidRole(role Role) bool {
+	switch role {
+	case RoleGuest, RoleHost, RoleAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// HasRole reports whether the user has at least one of the given roles.
+func (u *AuthUser) HasRole(roles ...Role) bool {
+	for _, role := range roles {
+		if slices.Contains(u.Roles, role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Can reports whether any of the user's roles grants the permission.
+// Handlers use it for checks that depend on the resource, e.g. letting an
+// admin see a booking they are not part of.
+func (u *AuthUser) Can(permission Permission) bool {
+	for _, role := range u.Roles {
+		if slices.Contains(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireRole rejects users that have none of the given roles.
+// It must run after AuthMiddleware.
+//
+// Roles come from the token, so a role change takes effect once the user's
+// access token is refreshed.
+func RequireRole(roles ...Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !MustGetAuthUser(c).HasRole(roles...) {
+			response.Forbidden(c, response.CodeForbidden,
+				"You do not have permission to perform this action")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission rejects users whose roles do not grant permission.
+// It must run after AuthMiddleware.
+func RequirePermission(permission Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !MustGetAuthUser(c).Can(permission) {
+			response.Forbidden(c, response.CodeForbidden,
+				"You do not have permission to perform this action")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+)
+
+// RequireVerifiedEmail rejects users whose token says they have not verified
+// their email yet. It must run after AuthMiddleware.
+//
+// The check relies on the "email_verified" claim, so a user who just verified
+// needs to refresh their access token before it takes effect.
+func RequireVerifiedEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !MustGetAuthUser(c).EmailVerified {
+			response.Forbidden(c, response.CodeEmailNotVerified,
+				"Please verify your email address before continuing")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -47,3 +47,19 @@ func ShouldBindJSON(c *gin.Context, obj interface{}) error {
 
 	return nil
 }
+
+// ShouldBindQuery binds query parameters to obj using its `form` tags,
+// normalizes it, then validates, like ShouldBindJSON does for a body.
+func ShouldBindQuery(c *gin.Context, obj interface{}) error {
+	if err := c.ShouldBindQuery(obj); err != nil {
+		return err
+	}
+
+	NormalizeStrings(obj)
+
+	if err := validate.Struct(obj); err != nil {
+		return err
+	}
+
+	return nil
+}
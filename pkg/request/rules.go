@@ -1,6 +1,7 @@
 package request
 
 import (
+	"regexp"
 	"strconv"
 
 	"github.com/go-playground/validator/v10"
@@ -9,8 +10,15 @@ import (
 func registerCustomRules() {
 	_ = validate.RegisterValidation("maxbytes", validateMaxBytes)
 	registerTranslation("maxbytes", "{0} is too long")
+
+	_ = validate.RegisterValidation("vnphone", validateVNPhone)
+	registerTranslation("vnphone", "{0} must be a Vietnamese mobile number in E.164 format, e.g. +84901234567")
 }
 
+// vnMobilePattern matches Vietnamese mobile numbers in E.164: +84 followed by
+// the 9 digits of the national number, which starts with 3, 5, 7, 8 or 9.
+var vnMobilePattern = regexp.MustCompile(`^\+84[35789][0-9]{8}$`)
+
 // validateMaxBytes checks byte length (not rune length)
 // Needed because bcrypt only uses first 72 bytes
 func validateMaxBytes(fl validator.FieldLevel) bool {
@@ -22,3 +30,9 @@ func validateMaxBytes(fl validator.FieldLevel) bool {
 	}
 	return len(field) <= limit
 }
+
+// validateVNPhone checks for a Vietnamese mobile number in E.164 format.
+// Landlines are rejected since they cannot receive SMS.
+func validateVNPhone(fl validator.FieldLevel) bool {
+	return vnMobilePattern.MatchString(fl.Field().String())
+}
@@ -75,7 +75,7 @@ func mapValidationTag(tag string) FieldErrorCode {
 	switch tag {
 	case "required":
 		return FieldCodeRequired
-	case "email":
+	case "email", "vnphone":
 		return FieldCodeInvalidFormat
 	case "min", "gte":
 		return FieldCodeMinValue
@@ -9,17 +9,26 @@ const (
 	CodeJSONFormatInvalid ErrorCode = "INVALID_JSON_FORMAT"
 	CodeReferenceInvalid  ErrorCode = "INVALID_REFERENCE" // Foreign key, relationship
 
+	CodeFileTooLarge        ErrorCode = "FILE_TOO_LARGE"
+	CodeFileTypeUnsupported ErrorCode = "UNSUPPORTED_FILE_TYPE"
+
 	CodeListingNotDraft              ErrorCode = "LISTING_NOT_DRAFT"
 	CodeListingNotActive             ErrorCode = "LISTING_NOT_ACTIVE"
 	CodeListingNotInactive           ErrorCode = "LISTING_NOT_INACTIVE"
 	CodeListingIncomplete            ErrorCode = "LISTING_INCOMPLETE"
 	CodeActiveListingCannotBeUpdated ErrorCode = "ACTIVE_LISTING_CANNOT_BE_UPDATED"
+	CodeCoordinatesOutsideProvince   ErrorCode = "COORDINATES_OUTSIDE_PROVINCE"
 	CodeBookingNotPending            ErrorCode = "BOOKING_NOT_PENDING"
+	CodeBookingNotCancellable        ErrorCode = "BOOKING_NOT_CANCELLABLE"
 
 	CodeAuthenticationRequired ErrorCode = "AUTHENTICATION_REQUIRED"
 	CodeCredentialsInvalid     ErrorCode = "INVALID_CREDENTIALS"
 	CodeTokenExpired           ErrorCode = "TOKEN_EXPIRED"
 	CodeTokenInvalid           ErrorCode = "TOKEN_INVALID"
+	CodeTokenRevoked           ErrorCode = "TOKEN_REVOKED"
+	CodeRefreshTokenReused     ErrorCode = "REFRESH_TOKEN_REUSED"
+
+	CodeForbidden ErrorCode = "FORBIDDEN"
 
 	CodeUserNotFound     ErrorCode = "USER_NOT_FOUND"
 	CodeListingNotFound  ErrorCode = "LISTING_NOT_FOUND"
@@ -27,10 +36,49 @@ const (
 	CodeDistrictNotFound ErrorCode = "DISTRICT_NOT_FOUND"
 	CodeWardNotFound     ErrorCode = "WARD_NOT_FOUND"
 	CodeBookingNotFound  ErrorCode = "BOOKING_NOT_FOUND"
+	CodeSessionNotFound  ErrorCode = "SESSION_NOT_FOUND"
+
+	CodeBlockedDatesNotFound ErrorCode = "BLOCKED_DATES_NOT_FOUND"
 
 	CodeEmailAlreadyExists ErrorCode = "EMAIL_ALREADY_EXISTS"
 	CodeDatesUnavailable   ErrorCode = "DATES_UNAVAILABLE"
 
+	CodeAccountHasUpcomingBookings ErrorCode = "ACCOUNT_HAS_UPCOMING_BOOKINGS"
+
+	CodeEmailNotVerified         ErrorCode = "EMAIL_NOT_VERIFIED"
+	CodeEmailAlreadyVerified     ErrorCode = "EMAIL_ALREADY_VERIFIED"
+	CodeVerificationTokenInvalid ErrorCode = "INVALID_VERIFICATION_TOKEN"
+	CodeResetTokenInvalid        ErrorCode = "INVALID_RESET_TOKEN"
+
+	CodeEmailUnchanged          ErrorCode = "EMAIL_UNCHANGED"
+	CodeEmailChangeTokenInvalid ErrorCode = "INVALID_EMAIL_CHANGE_TOKEN"
+
+	CodeTwoFactorAlreadyEnabled   ErrorCode = "TWO_FACTOR_ALREADY_ENABLED"
+	CodeTwoFactorNotEnabled       ErrorCode = "TWO_FACTOR_NOT_ENABLED"
+	CodeTwoFactorSetupNotStarted  ErrorCode = "TWO_FACTOR_SETUP_NOT_STARTED"
+	CodeTwoFactorCodeInvalid      ErrorCode = "INVALID_TWO_FACTOR_CODE"
+	CodeTwoFactorChallengeInvalid ErrorCode = "INVALID_TWO_FACTOR_CHALLENGE"
+
+	CodeIdentityProviderNotFound ErrorCode = "IDENTITY_PROVIDER_NOT_FOUND"
+	CodeOIDCStateInvalid         ErrorCode = "INVALID_OIDC_STATE"
+	CodeOIDCAuthenticationFailed ErrorCode = "OIDC_AUTHENTICATION_FAILED"
+	CodeOIDCEmailNotVerified     ErrorCode = "OIDC_EMAIL_NOT_VERIFIED"
+
+	CodeDataExportNotFound    ErrorCode = "DATA_EXPORT_NOT_FOUND"
+	CodeDataExportInProgress  ErrorCode = "DATA_EXPORT_IN_PROGRESS"
+	CodeDataExportLinkInvalid ErrorCode = "INVALID_DATA_EXPORT_LINK"
+
+	CodeHostVerificationNotFound        ErrorCode = "HOST_VERIFICATION_NOT_FOUND"
+	CodeHostVerificationPending         ErrorCode = "HOST_VERIFICATION_PENDING"
+	CodeHostVerificationAlreadyReviewed ErrorCode = "HOST_VERIFICATION_ALREADY_REVIEWED"
+	CodeHostAlreadyVerified             ErrorCode = "HOST_ALREADY_VERIFIED"
+	CodeHostNotVerified                 ErrorCode = "HOST_NOT_VERIFIED"
+
+	CodePhoneNumberMissing   ErrorCode = "PHONE_NUMBER_MISSING"
+	CodePhoneAlreadyVerified ErrorCode = "PHONE_ALREADY_VERIFIED"
+	CodePhoneCodeInvalid     ErrorCode = "INVALID_PHONE_CODE"
+	CodePhoneCodeExpired     ErrorCode = "PHONE_CODE_EXPIRED"
+
 	CodeTooManyRequests ErrorCode = "TOO_MANY_REQUESTS"
 
 	CodeRouteNotFound ErrorCode = "ROUTE_NOT_FOUND"
@@ -2,7 +2,10 @@ package response
 
 import (
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	validatorV10 "github.com/go-playground/validator/v10"
@@ -37,6 +40,10 @@ func Unauthorized(c *gin.Context, code ErrorCode, message string) {
 	c.JSON(http.StatusUnauthorized, New().Error(code, message).Build())
 }
 
+func Forbidden(c *gin.Context, code ErrorCode, message string) {
+	c.JSON(http.StatusForbidden, New().Error(code, message).Build())
+}
+
 func NotFound(c *gin.Context, code ErrorCode, message string) {
 	c.JSON(http.StatusNotFound, New().Error(code, message).Build())
 }
@@ -45,6 +52,18 @@ func Conflict(c *gin.Context, code ErrorCode, message string) {
 	c.JSON(http.StatusConflict, New().Error(code, message).Build())
 }
 
+// TooManyRequests responds with 429 and a Retry-After header telling the
+// client how many seconds to wait (rounded up, at least 1).
+func TooManyRequests(c *gin.Context, message string, retryAfter time.Duration) {
+	seconds := int64(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	c.Header("Retry-After", strconv.FormatInt(seconds, 10))
+	c.JSON(http.StatusTooManyRequests, New().Error(CodeTooManyRequests, message).Build())
+}
+
 func InternalServerError(c *gin.Context) {
 	c.JSON(http.StatusInternalServerError,
 		New().Error(CodeInternalServerError, "Internal server error. Please try again later").Build(),
@@ -77,3 +96,15 @@ func HandleJSONBindingError(c *gin.Context, err error) {
 	// TODO: Detail JSON parsing error
 	BadRequest(c, CodeJSONFormatInvalid, "Request body must be valid JSON")
 }
+
+// HandleQueryBindingError is HandleJSONBindingError for query parameters.
+func HandleQueryBindingError(c *gin.Context, err error) {
+	var validationErrors validatorV10.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		fieldErrors := request.TranslateValidationErrors(validationErrors)
+		BadRequestWithErrors(c, CodeValidationFailed, "Validation failed", fieldErrors)
+		return
+	}
+
+	BadRequest(c, CodeValidationFailed, "Query parameters are invalid")
+}
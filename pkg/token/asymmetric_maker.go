@@ -0,0 +1,107 @@
+package token
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// asymmetricMethods are the signing algorithms accepted from asymmetric keys.
+var asymmetricMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// AsymmetricMaker is a TokenMaker that signs JWTs with a private key
+// (RS256 or EdDSA) and stamps the key ID into the "kid" header.
+//
+// Unlike JWTMaker, other services never need the signing secret: they verify
+// tokens with the public keys published via JWKS(). Key rotation works by
+// promoting a new active key while keeping the previous ones around, so
+// tokens signed before the rotation stay valid until they expire.
+type AsymmetricMaker struct {
+	activeKey  *SigningKey
+	publicKeys map[string]crypto.PublicKey
+	keySet     JWKSet
+	expiry     time.Duration
+}
+
+var (
+	_ TokenMaker   = (*AsymmetricMaker)(nil)
+	_ JWKSProvider = (*AsymmetricMaker)(nil)
+)
+
+// NewAsymmetricMaker creates a maker signing with activeKey.
+// previousKeys are no longer used for signing but are still accepted and
+// published until every token they signed has expired.
+func NewAsymmetricMaker(expiry time.Duration, activeKey *SigningKey, previousKeys ...*SigningKey) (*AsymmetricMaker, error) {
+	if activeKey == nil {
+		return nil, errors.New("active signing key is required")
+	}
+	if expiry < MinTokenExpiry {
+		return nil, fmt.Errorf("token expiry must be at least %s", MinTokenExpiry)
+	}
+
+	m := &AsymmetricMaker{
+		activeKey:  activeKey,
+		publicKeys: make(map[string]crypto.PublicKey),
+		keySet:     JWKSet{Keys: []JWK{}},
+		expiry:     expiry,
+	}
+
+	for _, key := range append([]*SigningKey{activeKey}, previousKeys...) {
+		if _, exists := m.publicKeys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate signing key %s", key.ID)
+		}
+
+		jwk, err := newJWK(key.ID, key.PublicKey())
+		if err != nil {
+			return nil, err
+		}
+
+		m.publicKeys[key.ID] = key.PublicKey()
+		m.keySet.Keys = append(m.keySet.Keys, jwk)
+	}
+
+	return m, nil
+}
+
+// CreateToken generates a new JWT signed with the active key.
+// It carries the same claims as JWTMaker.CreateToken.
+func (m *AsymmetricMaker) CreateToken(arg CreateTokenParams) (string, time.Time, error) {
+	claims, expiresAt := newJWTClaims(arg, m.expiry)
+
+	token := jwt.NewWithClaims(m.activeKey.method, claims)
+	token.Header["kid"] = m.activeKey.ID
+
+	tokenStr, err := token.SignedString(m.activeKey.signer)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenStr, expiresAt, nil
+}
+
+// VerifyToken validates a token signed by the active or any previous key.
+func (m *AsymmetricMaker) VerifyToken(tokenString string) (*Claims, error) {
+	return parseToken(
+		tokenString,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			key, ok := m.publicKeys[kid]
+			if !ok {
+				return nil, fmt.Errorf("unknown signing key %q", kid)
+			}
+			return key, nil
+		},
+		asymmetricMethods,
+	)
+}
+
+// JWKS returns the public keys to publish at /.well-known/jwks.json.
+func (m *AsymmetricMaker) JWKS() JWKSet {
+	return m.keySet
+}
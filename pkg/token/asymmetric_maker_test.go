@@ -0,0 +1,246 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testTokenExpiry = 15 * time.Minute
+
+func newTestRSAKey(t *testing.T) *SigningKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, MinRSAKeySize)
+	require.NoError(t, err)
+
+	key, err := NewSigningKey(privateKey)
+	require.NoError(t, err)
+	return key
+}
+
+func newTestEd25519Key(t *testing.T) *SigningKey {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := NewSigningKey(privateKey)
+	require.NoError(t, err)
+	return key
+}
+
+// signRaw signs claims with any method and key, bypassing the maker, to
+// build tokens the maker would never produce.
+func signRaw(t *testing.T, method jwt.SigningMethod, kid string, key any) string {
+	t.Helper()
+
+	claims, _ := newJWTClaims(CreateTokenParams{UserID: "user-123"}, testTokenExpiry)
+	tok := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		tok.Header["kid"] = kid
+	}
+
+	tokenStr, err := tok.SignedString(key)
+	require.NoError(t, err)
+	return tokenStr
+}
+
+func TestAsymmetricMakerRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name    string
+		key     func(*testing.T) *SigningKey
+		wantAlg string
+	}{
+		{name: "RS256", key: newTestRSAKey, wantAlg: "RS256"},
+		{name: "EdDSA", key: newTestEd25519Key, wantAlg: "EdDSA"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key := tc.key(t)
+			assert.Equal(t, tc.wantAlg, key.Algorithm())
+
+			maker, err := NewAsymmetricMaker(testTokenExpiry, key)
+			require.NoError(t, err)
+
+			tokenStr, expiresAt, err := maker.CreateToken(CreateTokenParams{
+				UserID:        "user-123",
+				SessionID:     "session-1",
+				EmailVerified: true,
+				Roles:         []string{"guest", "host"},
+			})
+			require.NoError(t, err)
+			assert.WithinDuration(t, time.Now().Add(testTokenExpiry), expiresAt, time.Second)
+
+			parsed, _, err := jwt.NewParser().ParseUnverified(tokenStr, &jwtClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantAlg, parsed.Method.Alg())
+			assert.Equal(t, key.ID, parsed.Header["kid"])
+
+			claims, err := maker.VerifyToken(tokenStr)
+			require.NoError(t, err)
+			assert.Equal(t, "user-123", claims.UserID)
+			assert.Equal(t, "session-1", claims.SessionID)
+			assert.True(t, claims.EmailVerified)
+			assert.Equal(t, []string{"guest", "host"}, claims.Roles)
+
+			// The published key set verifies the token too
+			require.Len(t, maker.JWKS().Keys, 1)
+			publicKey, err := maker.JWKS().Keys[0].PublicKey()
+			require.NoError(t, err)
+			_, err = jwt.Parse(tokenStr, func(*jwt.Token) (any, error) { return publicKey, nil })
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestAsymmetricMakerKeyRotation(t *testing.T) {
+	oldKey := newTestRSAKey(t)
+	newKey := newTestEd25519Key(t)
+
+	oldMaker, err := NewAsymmetricMaker(testTokenExpiry, oldKey)
+	require.NoError(t, err)
+	oldToken, _, err := oldMaker.CreateToken(CreateTokenParams{UserID: "user-123"})
+	require.NoError(t, err)
+
+	// After rotation the old key only verifies, and is still published
+	rotated, err := NewAsymmetricMaker(testTokenExpiry, newKey, oldKey)
+	require.NoError(t, err)
+
+	claims, err := rotated.VerifyToken(oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.UserID)
+
+	newToken, _, err := rotated.CreateToken(CreateTokenParams{UserID: "user-123"})
+	require.NoError(t, err)
+	parsed, _, err := jwt.NewParser().ParseUnverified(newToken, &jwtClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, newKey.ID, parsed.Header["kid"])
+
+	var kids []string
+	for _, jwk := range rotated.JWKS().Keys {
+		kids = append(kids, jwk.KeyID)
+	}
+	assert.Equal(t, []string{newKey.ID, oldKey.ID}, kids)
+
+	// Once the old key is dropped its tokens are rejected
+	dropped, err := NewAsymmetricMaker(testTokenExpiry, newKey)
+	require.NoError(t, err)
+	_, err = dropped.VerifyToken(oldToken)
+	require.ErrorIs(t, err, ErrTokenInvalid)
+
+	_, err = NewAsymmetricMaker(testTokenExpiry, newKey, newKey)
+	require.Error(t, err, "duplicate keys are rejected")
+}
+
+func TestAsymmetricMakerRejectsForgedTokens(t *testing.T) {
+	rsaKey := newTestRSAKey(t)
+	edKey := newTestEd25519Key(t)
+
+	maker, err := NewAsymmetricMaker(testTokenExpiry, rsaKey, edKey)
+	require.NoError(t, err)
+
+	otherKey := newTestEd25519Key(t)
+
+	testCases := []struct {
+		name  string
+		token func(*testing.T) string
+	}{
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodNone, rsaKey.ID, jwt.UnsafeAllowNoneSignatureType)
+			},
+		},
+		{
+			name: "HS256 signed with the public RSA key",
+			token: func(t *testing.T) string {
+				publicKey := rsaKey.PublicKey().(*rsa.PublicKey)
+				return signRaw(t, jwt.SigningMethodHS256, rsaKey.ID, publicKey.N.Bytes())
+			},
+		},
+		{
+			name: "EdDSA token pointing at the RSA key",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodEdDSA, rsaKey.ID, otherKey.signer)
+			},
+		},
+		{
+			name: "signed by an unknown key with a known kid",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodEdDSA, edKey.ID, otherKey.signer)
+			},
+		},
+		{
+			name: "unknown kid",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodEdDSA, otherKey.ID, otherKey.signer)
+			},
+		},
+		{
+			name: "no kid",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodRS256, "", rsaKey.signer)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := maker.VerifyToken(tc.token(t))
+			require.ErrorIs(t, err, ErrTokenInvalid)
+		})
+	}
+}
+
+func TestNewSigningKeyRejectsWeakKeys(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+
+	_, err = NewSigningKey(weak)
+	require.Error(t, err)
+}
+
+func TestThumbprint(t *testing.T) {
+	// RFC 7638, section 3.1
+	n, err := jwkBigInt("0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw")
+	require.NoError(t, err)
+
+	kid, err := thumbprint(&rsa.PublicKey{N: n, E: 65537})
+	require.NoError(t, err)
+	assert.Equal(t, "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs", kid)
+}
+
+func TestJWKPublicKeyRoundTrip(t *testing.T) {
+	for _, key := range []*SigningKey{newTestRSAKey(t), newTestEd25519Key(t)} {
+		jwk, err := newJWK(key.ID, key.PublicKey())
+		require.NoError(t, err)
+
+		publicKey, err := jwk.PublicKey()
+		require.NoError(t, err)
+		assert.True(t, publicKey.(interface{ Equal(crypto.PublicKey) bool }).Equal(key.PublicKey()))
+	}
+
+	_, err := JWK{KeyType: "OKP", Curve: "X25519", X: "AA"}.PublicKey()
+	require.Error(t, err)
+	_, err = JWK{KeyType: "EC"}.PublicKey()
+	require.Error(t, err)
+}
+
+func jwkBigInt(s string) (*big.Int, error) {
+	jwk := JWK{KeyType: "RSA", N: s, E: "AQAB"}
+	publicKey, err := jwk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	return publicKey.(*rsa.PublicKey).N, nil
+}
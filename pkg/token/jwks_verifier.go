@@ -0,0 +1,203 @@
+package token
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// DefaultJWKSRefreshInterval is how long fetched keys are trusted before
+	// the key set is downloaded again.
+	DefaultJWKSRefreshInterval = 10 * time.Minute
+
+	// minJWKSRefetchInterval throttles refetches triggered by unknown key IDs,
+	// so tokens with garbage "kid" headers cannot hammer the issuer.
+	minJWKSRefetchInterval = 10 * time.Second
+
+	jwksFetchTimeout = 5 * time.Second
+)
+
+// ErrUnknownSigningKey is returned when no published key matches the token's "kid".
+var ErrUnknownSigningKey = errors.New("unknown signing key")
+
+type cachedJWK struct {
+	algorithm string
+	publicKey crypto.PublicKey
+}
+
+// JWKSCache downloads and caches a remote JSON Web Key Set.
+//
+// Keys are refreshed every refreshInterval, and immediately (throttled) when a
+// token references a key ID we have not seen yet - that is how a freshly
+// rotated key is picked up without restarting the service.
+type JWKSCache struct {
+	url             string
+	httpClient      *http.Client
+	refreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]cachedJWK
+	fetchedAt   time.Time
+	lastAttempt time.Time
+
+	// refreshMu serializes downloads so concurrent misses share one request.
+	refreshMu sync.Mutex
+}
+
+// NewJWKSCache creates a cache for the key set served at url.
+// A non-positive refreshInterval falls back to DefaultJWKSRefreshInterval.
+func NewJWKSCache(url string, refreshInterval time.Duration) *JWKSCache {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	return &JWKSCache{
+		url:             url,
+		httpClient:      &http.Client{Timeout: jwksFetchTimeout},
+		refreshInterval: refreshInterval,
+		keys:            make(map[string]cachedJWK),
+	}
+}
+
+// Refresh downloads the key set and replaces the cached keys.
+func (c *JWKSCache) Refresh(ctx context.Context) error {
+	c.mu.Lock()
+	c.lastAttempt = time.Now()
+	c.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create JWKS request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected JWKS response status: %d", resp.StatusCode)
+	}
+
+	var keySet JWKSet
+	if err := json.NewDecoder(resp.Body).Decode(&keySet); err != nil {
+		return fmt.Errorf("failed to decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]cachedJWK, len(keySet.Keys))
+	for _, jwk := range keySet.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			// Skip keys we do not understand instead of rejecting the whole set
+			log.Printf("[WARN] skipping JWKS key %q: %v", jwk.KeyID, err)
+			continue
+		}
+
+		keys[jwk.KeyID] = cachedJWK{algorithm: jwk.Algorithm, publicKey: publicKey}
+	}
+
+	c.mu.Lock()
+	c.keys = keys
+	c.fetchedAt = time.Now()
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Key returns the public key with the given ID, refreshing the set when it
+// is stale or does not contain kid. If a refresh fails, previously cached keys
+// keep being served so a short issuer outage does not log everyone out.
+func (c *JWKSCache) Key(ctx context.Context, kid string) (crypto.PublicKey, string, error) {
+	key, found, stale, canRefetch := c.lookup(kid)
+	if found && !stale {
+		return key.publicKey, key.algorithm, nil
+	}
+
+	if stale || canRefetch {
+		c.refreshMu.Lock()
+		// Another goroutine may have refreshed while we were waiting
+		key, found, stale, canRefetch = c.lookup(kid)
+		if stale || (!found && canRefetch) {
+			if err := c.Refresh(ctx); err != nil {
+				log.Printf("[WARN] failed to refresh JWKS from %s: %v", c.url, err)
+			}
+			key, found, _, _ = c.lookup(kid)
+		}
+		c.refreshMu.Unlock()
+	}
+
+	if !found {
+		return nil, "", ErrUnknownSigningKey
+	}
+
+	return key.publicKey, key.algorithm, nil
+}
+
+func (c *JWKSCache) lookup(kid string) (key cachedJWK, found, stale, canRefetch bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	now := time.Now()
+	key, found = c.keys[kid]
+	stale = now.Sub(c.fetchedAt) > c.refreshInterval && now.Sub(c.lastAttempt) > minJWKSRefetchInterval
+	canRefetch = now.Sub(c.lastAttempt) > minJWKSRefetchInterval
+
+	return key, found, stale, canRefetch
+}
+
+// JWKSVerifier is a TokenVerifier for services that consume tokens issued by
+// another service. It only needs the issuer's public keys, fetched from its
+// JWKS endpoint, and accepts tokens signed by any key currently published.
+type JWKSVerifier struct {
+	cache *JWKSCache
+}
+
+var _ TokenVerifier = (*JWKSVerifier)(nil)
+
+// NewJWKSVerifier creates a verifier backed by the given key cache.
+func NewJWKSVerifier(cache *JWKSCache) *JWKSVerifier {
+	return &JWKSVerifier{cache: cache}
+}
+
+// VerifyToken validates a token against the published keys.
+func (v *JWKSVerifier) VerifyToken(tokenString string) (*Claims, error) {
+	return parseToken(
+		tokenString,
+		func(token *jwt.Token) (any, error) {
+			kid, _ := token.Header["kid"].(string)
+			if kid == "" {
+				return nil, ErrUnknownSigningKey
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+			defer cancel()
+
+			key, algorithm, err := v.cache.Key(ctx, kid)
+			if err != nil {
+				return nil, err
+			}
+
+			// A key published for one algorithm must not verify another
+			if algorithm != "" && algorithm != token.Method.Alg() {
+				return nil, fmt.Errorf("signing key %q is not valid for %s", kid, token.Method.Alg())
+			}
+
+			return key, nil
+		},
+		asymmetricMethods,
+	)
+}
@@ -0,0 +1,235 @@
+package token
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeIssuer serves a key set that tests can swap, counting the downloads.
+type fakeIssuer struct {
+	mu      sync.Mutex
+	keySet  JWKSet
+	fetches atomic.Int32
+	server  *httptest.Server
+}
+
+func newFakeIssuer(t *testing.T, keySet JWKSet) *fakeIssuer {
+	t.Helper()
+
+	issuer := &fakeIssuer{keySet: keySet}
+	issuer.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		issuer.fetches.Add(1)
+
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(issuer.keySet)
+	}))
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (f *fakeIssuer) publish(keySet JWKSet) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.keySet = keySet
+}
+
+// allowRefetch pretends the last download was long enough ago for an unknown
+// kid to trigger another one.
+func allowRefetch(c *JWKSCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lastAttempt = time.Now().Add(-2 * minJWKSRefetchInterval)
+}
+
+func TestJWKSVerifierRoundTrip(t *testing.T) {
+	key := newTestEd25519Key(t)
+	maker, err := NewAsymmetricMaker(testTokenExpiry, key)
+	require.NoError(t, err)
+
+	issuer := newFakeIssuer(t, maker.JWKS())
+	verifier := NewJWKSVerifier(NewJWKSCache(issuer.server.URL, time.Hour))
+
+	tokenStr, _, err := maker.CreateToken(CreateTokenParams{UserID: "user-123"})
+	require.NoError(t, err)
+
+	claims, err := verifier.VerifyToken(tokenStr)
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.UserID)
+
+	// Cached keys are reused
+	_, err = verifier.VerifyToken(tokenStr)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, issuer.fetches.Load())
+}
+
+func TestJWKSCachePicksUpRotatedKey(t *testing.T) {
+	oldKey := newTestRSAKey(t)
+	newKey := newTestEd25519Key(t)
+
+	oldMaker, err := NewAsymmetricMaker(testTokenExpiry, oldKey)
+	require.NoError(t, err)
+	issuer := newFakeIssuer(t, oldMaker.JWKS())
+
+	cache := NewJWKSCache(issuer.server.URL, time.Hour)
+	require.NoError(t, cache.Refresh(context.Background()))
+	verifier := NewJWKSVerifier(cache)
+
+	rotated, err := NewAsymmetricMaker(testTokenExpiry, newKey, oldKey)
+	require.NoError(t, err)
+	issuer.publish(rotated.JWKS())
+
+	newToken, _, err := rotated.CreateToken(CreateTokenParams{UserID: "user-123"})
+	require.NoError(t, err)
+
+	// Right after a download, an unknown kid does not trigger another one
+	_, err = verifier.VerifyToken(newToken)
+	require.ErrorIs(t, err, ErrTokenInvalid)
+	assert.EqualValues(t, 1, issuer.fetches.Load())
+
+	// Once the throttle has passed it does, and the new key is found
+	allowRefetch(cache)
+	_, err = verifier.VerifyToken(newToken)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, issuer.fetches.Load())
+
+	// Tokens of the rotated-out key stay valid while it is published
+	oldToken, _, err := oldMaker.CreateToken(CreateTokenParams{UserID: "user-123"})
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(oldToken)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, issuer.fetches.Load())
+}
+
+func TestJWKSCacheThrottlesUnknownKids(t *testing.T) {
+	key := newTestEd25519Key(t)
+	maker, err := NewAsymmetricMaker(testTokenExpiry, key)
+	require.NoError(t, err)
+
+	issuer := newFakeIssuer(t, maker.JWKS())
+	cache := NewJWKSCache(issuer.server.URL, time.Hour)
+	allowRefetch(cache)
+
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _, err := cache.Key(context.Background(), "garbage-kid")
+			assert.ErrorIs(t, err, ErrUnknownSigningKey)
+		}()
+	}
+	wg.Wait()
+
+	// Concurrent misses share one download, later ones wait for the throttle
+	assert.EqualValues(t, 1, issuer.fetches.Load())
+
+	_, _, err = cache.Key(context.Background(), "another-garbage-kid")
+	require.ErrorIs(t, err, ErrUnknownSigningKey)
+	assert.EqualValues(t, 1, issuer.fetches.Load())
+}
+
+func TestJWKSCacheKeepsKeysWhenIssuerIsDown(t *testing.T) {
+	key := newTestEd25519Key(t)
+	maker, err := NewAsymmetricMaker(testTokenExpiry, key)
+	require.NoError(t, err)
+
+	issuer := newFakeIssuer(t, maker.JWKS())
+	cache := NewJWKSCache(issuer.server.URL, time.Hour)
+	require.NoError(t, cache.Refresh(context.Background()))
+
+	issuer.server.Close()
+	cache.mu.Lock()
+	cache.fetchedAt = time.Now().Add(-2 * time.Hour)
+	cache.lastAttempt = time.Now().Add(-2 * time.Hour)
+	cache.mu.Unlock()
+
+	_, _, err = cache.Key(context.Background(), key.ID)
+	require.NoError(t, err)
+}
+
+func TestJWKSVerifierRejectsForgedTokens(t *testing.T) {
+	rsaKey := newTestRSAKey(t)
+	maker, err := NewAsymmetricMaker(testTokenExpiry, rsaKey)
+	require.NoError(t, err)
+
+	issuer := newFakeIssuer(t, maker.JWKS())
+	verifier := NewJWKSVerifier(NewJWKSCache(issuer.server.URL, time.Hour))
+
+	edKey := newTestEd25519Key(t)
+
+	testCases := []struct {
+		name  string
+		token func(*testing.T) string
+	}{
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodNone, rsaKey.ID, jwt.UnsafeAllowNoneSignatureType)
+			},
+		},
+		{
+			name: "EdDSA token using the kid of an RS256 key",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodEdDSA, rsaKey.ID, edKey.signer)
+			},
+		},
+		{
+			name: "HS256 token using the kid of an RS256 key",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodHS256, rsaKey.ID, []byte("a-secret-of-at-least-thirty-two-bytes"))
+			},
+		},
+		{
+			name: "no kid",
+			token: func(t *testing.T) string {
+				return signRaw(t, jwt.SigningMethodRS256, "", rsaKey.signer)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := verifier.VerifyToken(tc.token(t))
+			require.ErrorIs(t, err, ErrTokenInvalid)
+		})
+	}
+}
+
+func TestJWKSCacheSkipsUnusableKeys(t *testing.T) {
+	key := newTestEd25519Key(t)
+	jwk, err := newJWK(key.ID, key.PublicKey())
+	require.NoError(t, err)
+
+	encryption := jwk
+	encryption.KeyID = "enc-key"
+	encryption.Use = "enc"
+
+	issuer := newFakeIssuer(t, JWKSet{Keys: []JWK{
+		jwk,
+		encryption,
+		{KeyType: "EC", KeyID: "ec-key"},
+	}})
+	cache := NewJWKSCache(issuer.server.URL, time.Hour)
+	require.NoError(t, cache.Refresh(context.Background()))
+
+	_, alg, err := cache.Key(context.Background(), key.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "EdDSA", alg)
+
+	for _, kid := range []string{"enc-key", "ec-key"} {
+		_, _, err = cache.Key(context.Background(), kid)
+		require.ErrorIs(t, err, ErrUnknownSigningKey)
+	}
+}
@@ -32,6 +32,26 @@ type JWTMaker struct {
 
 var _ TokenMaker = (*JWTMaker)(nil)
 
+// jwtClaims extends the registered claims with our private claims.
+type jwtClaims struct {
+	jwt.RegisteredClaims
+
+	// SessionID is a private claim binding the token to a server-side session.
+	SessionID string `json:"sid,omitempty"`
+
+	// EmailVerified mirrors the OpenID Connect claim of the same name.
+	EmailVerified bool `json:"email_verified"`
+
+	// Roles is a private claim listing the user's roles.
+	Roles []string `json:"roles,omitempty"`
+
+	// Locale and Zoneinfo are the OpenID Connect claims of the same name;
+	// Currency is a private claim.
+	Locale   string `json:"locale,omitempty"`
+	Zoneinfo string `json:"zoneinfo,omitempty"`
+	Currency string `json:"currency,omitempty"`
+}
+
 // NewJWTMaker creates a new JWTMaker with the given secret key and expiry duration.
 //
 // Parameters:
@@ -58,7 +78,7 @@ func NewJWTMaker(secretKey []byte, expiry time.Duration) (*JWTMaker, error) {
 	}, nil
 }
 
-// CreateToken generates a new JWT for the given user ID.
+// CreateToken generates a new JWT for the given user.
 //
 // The token contains standard JWT claims (RFC 7519):
 //   - sub (Subject): The user ID - who this token represents
@@ -67,17 +87,35 @@ func NewJWTMaker(secretKey []byte, expiry time.Duration) (*JWTMaker, error) {
 //   - nbf (Not Before): Token is not valid before this time (set to now)
 //   - jti (JWT ID): Unique identifier for this token (for revocation lists)
 //
+// Plus the private "sid" claim when the token belongs to a session,
+// "email_verified" and "roles".
+//
 // The token is signed using HS256 (HMAC-SHA256) algorithm.
-func (m *JWTMaker) CreateToken(userID string) (string, time.Time, error) {
+func (m *JWTMaker) CreateToken(arg CreateTokenParams) (string, time.Time, error) {
+	claims, expiresAt := newJWTClaims(arg, m.expiry)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+
+	tokenStr, err := token.SignedString(m.secretKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return tokenStr, expiresAt, nil
+}
+
+// newJWTClaims builds the claims of a token issued now and valid for expiry.
+// It is shared by every TokenMaker implementation.
+func newJWTClaims(arg CreateTokenParams, expiry time.Duration) (jwtClaims, time.Time) {
 	now := time.Now()
-	expiresAt := now.Add(m.expiry)
+	expiresAt := now.Add(expiry)
 
 	// RegisteredClaims is from jwt/v5 and follows RFC 7519 standard claim names.
 	// Using standard claims makes your tokens interoperable with other systems.
-	claims := jwt.RegisteredClaims{
+	registered := jwt.RegisteredClaims{
 		// Subject identifies the principal (user) this token represents.
 		// This is the most important claim - it's how you know who is authenticated.
-		Subject: userID,
+		Subject: arg.UserID,
 
 		// ExpiresAt is when this token becomes invalid.
 		// After this time, VerifyToken will return ErrTokenExpired.
@@ -101,14 +139,17 @@ func (m *JWTMaker) CreateToken(userID string) (string, time.Time, error) {
 		ID: uuid.NewString(),
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	tokenStr, err := token.SignedString(m.secretKey)
-	if err != nil {
-		return "", time.Time{}, err
+	claims := jwtClaims{
+		RegisteredClaims: registered,
+		SessionID:        arg.SessionID,
+		EmailVerified:    arg.EmailVerified,
+		Roles:            arg.Roles,
+		Locale:           arg.Locale,
+		Zoneinfo:         arg.Timezone,
+		Currency:         arg.Currency,
 	}
 
-	return tokenStr, expiresAt, nil
+	return claims, expiresAt
 }
 
 // VerifyToken parses a JWT string and validates it.
@@ -121,11 +162,8 @@ func (m *JWTMaker) CreateToken(userID string) (string, time.Time, error) {
 //
 // Returns Claims if the token is valid, or an error if validation fails.
 func (m *JWTMaker) VerifyToken(tokenString string) (*Claims, error) {
-	// ParseWithClaims parses the token and validates the signature.
-	// The callback function provides the key for signature verification.
-	token, err := jwt.ParseWithClaims(
+	return parseToken(
 		tokenString,
-		&jwt.RegisteredClaims{},
 		func(token *jwt.Token) (any, error) {
 			// This function is called during parsing to get the verification key.
 			// You could also check token.Method here to ensure the expected algorithm.
@@ -134,7 +172,20 @@ func (m *JWTMaker) VerifyToken(tokenString string) (*Claims, error) {
 		// WithValidMethods explicitly specifies which signing algorithms are allowed.
 		// This prevents "algorithm confusion" attacks where an attacker changes
 		// the algorithm in the header to bypass signature verification.
-		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		[]string{jwt.SigningMethodHS256.Alg()},
+	)
+}
+
+// parseToken validates tokenString with the key returned by keyFunc and converts
+// its claims. It is shared by every TokenVerifier implementation.
+func parseToken(tokenString string, keyFunc jwt.Keyfunc, validMethods []string) (*Claims, error) {
+	// ParseWithClaims parses the token and validates the signature.
+	// The callback function provides the key for signature verification.
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&jwtClaims{},
+		keyFunc,
+		jwt.WithValidMethods(validMethods),
 	)
 
 	// Handle parsing/validation errors
@@ -144,13 +195,13 @@ func (m *JWTMaker) VerifyToken(tokenString string) (*Claims, error) {
 		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrTokenExpired
 		}
-		// For all other errors (invalid signature, malformed, etc.)
+		// For all other errors (invalid signature, malformed, unknown key, etc.)
 		return nil, ErrTokenInvalid
 	}
 
 	// Extract the claims from the parsed token.
 	// We already know the token is valid at this point.
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
+	claims, ok := token.Claims.(*jwtClaims)
 	if !ok {
 		// This shouldn't happen if ParseWithClaims succeeded, but be defensive.
 		return nil, ErrTokenInvalid
@@ -159,8 +210,15 @@ func (m *JWTMaker) VerifyToken(tokenString string) (*Claims, error) {
 	// Convert JWT claims to our application's Claims struct.
 	// This decouples our application from the JWT library's types.
 	return &Claims{
-		UserID:    claims.Subject,
-		IssuedAt:  claims.IssuedAt.Time,
-		ExpiresAt: claims.ExpiresAt.Time,
+		ID:            claims.ID,
+		UserID:        claims.Subject,
+		SessionID:     claims.SessionID,
+		EmailVerified: claims.EmailVerified,
+		Roles:         claims.Roles,
+		Locale:        claims.Locale,
+		Timezone:      claims.Zoneinfo,
+		Currency:      claims.Currency,
+		IssuedAt:      claims.IssuedAt.Time,
+		ExpiresAt:     claims.ExpiresAt.Time,
 	}, nil
 }
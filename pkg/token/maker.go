@@ -2,22 +2,74 @@ package token
 
 import "time"
 
+// TokenVerifier validates tokens. Services that only consume tokens
+// (e.g. verifying against the issuer's JWKS) need nothing more.
+type TokenVerifier interface {
+	VerifyToken(tokenString string) (*Claims, error)
+}
+
 type TokenMaker interface {
-	CreateToken(userID string) (string, time.Time, error)
+	TokenVerifier
 
-	VerifyToken(tokenString string) (*Claims, error)
+	CreateToken(arg CreateTokenParams) (string, time.Time, error)
+}
+
+// CreateTokenParams contains the data to embed into a new token.
+type CreateTokenParams struct {
+	// UserID becomes the "sub" (subject) claim.
+	UserID string
+
+	// SessionID links the access token to the server-side session
+	// (refresh token family) it was issued for. Optional.
+	SessionID string
+
+	// EmailVerified becomes the "email_verified" claim, so other services can
+	// gate actions on it without calling the user service.
+	EmailVerified bool
+
+	// Roles becomes the private "roles" claim (e.g. "guest", "host", "admin"),
+	// so other services can authorize requests without calling the user service.
+	Roles []string
+
+	// Locale, Timezone and Currency are the user's display preferences. They
+	// become the OpenID Connect "locale" and "zoneinfo" claims and the private
+	// "currency" claim, so other services can localize responses.
+	Locale   string
+	Timezone string
+	Currency string
 }
 
 // Claims contains the payload data extracted from a valid token.
 //
-// We keep this struct minimal and focused. If you need more claims
-// (like roles, permissions, etc.), add them here and update the
-// TokenMaker implementations accordingly.
+// We keep this struct minimal and focused. If you need more claims,
+// add them here and update the TokenMaker implementations accordingly.
 type Claims struct {
+	// ID is the unique identifier of this token.
+	// This is extracted from the "jti" (JWT ID) claim in JWT.
+	ID string
+
 	// UserID is the unique identifier of the authenticated user.
 	// This is extracted from the "sub" (subject) claim in JWT.
 	UserID string
 
+	// SessionID is the server-side session this token was issued for.
+	// Empty for tokens that are not bound to a session.
+	SessionID string
+
+	// EmailVerified reports whether the user had verified their email
+	// when the token was issued.
+	EmailVerified bool
+
+	// Roles are the roles the user had when the token was issued.
+	Roles []string
+
+	// Locale, Timezone and Currency are the user's display preferences when
+	// the token was issued. They are empty in tokens issued before preferences
+	// existed, so callers need a fallback.
+	Locale   string
+	Timezone string
+	Currency string
+
 	// IssuedAt is when the token was created.
 	// Useful for implementing token refresh logic.
 	IssuedAt time.Time
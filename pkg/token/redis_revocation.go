@@ -0,0 +1,107 @@
+package token
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	revokedTokenKeyPrefix   = "auth:revoked:token:"
+	revokedSessionKeyPrefix = "auth:revoked:session:"
+	revokedUserKeyPrefix    = "auth:revoked:user:"
+)
+
+// RedisRevocationStore is a RevocationStore shared by all services through Redis.
+//
+// Keys:
+//   - auth:revoked:token:{jti} -> "1", expires with the token
+//   - auth:revoked:session:{sid} -> cutoff as unix seconds, expires after retention
+//   - auth:revoked:user:{userID} -> cutoff as unix seconds, expires after retention
+type RedisRevocationStore struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+var _ RevocationStore = (*RedisRevocationStore)(nil)
+
+// NewRedisRevocationStore creates a RedisRevocationStore.
+// retention is how long per-user cutoffs are kept; it should be at least the access token expiry.
+func NewRedisRevocationStore(client *redis.Client, retention time.Duration) *RedisRevocationStore {
+	return &RedisRevocationStore{
+		client:    client,
+		retention: retention,
+	}
+}
+
+func (s *RedisRevocationStore) RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil // Already expired, nothing to revoke
+	}
+
+	return s.client.Set(ctx, revokedTokenKeyPrefix+tokenID, "1", ttl).Err()
+}
+
+func (s *RedisRevocationStore) RevokeSessionTokens(ctx context.Context, sessionID string, issuedBefore time.Time) error {
+	return s.client.Set(ctx, revokedSessionKeyPrefix+sessionID, issuedBefore.Unix(), s.retention).Err()
+}
+
+func (s *RedisRevocationStore) RevokeUserTokens(ctx context.Context, userID string, issuedBefore time.Time) error {
+	return s.client.Set(ctx, revokedUserKeyPrefix+userID, issuedBefore.Unix(), s.retention).Err()
+}
+
+// IsRevoked checks the token, session and user cutoffs in a single round trip.
+func (s *RedisRevocationStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	values, err := s.client.MGet(ctx,
+		revokedTokenKeyPrefix+claims.ID,
+		revokedUserKeyPrefix+claims.UserID,
+		revokedSessionKeyPrefix+claims.SessionID,
+	).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if values[0] != nil {
+		return true, nil
+	}
+
+	cutoffs := values[1:]
+	if claims.SessionID == "" {
+		cutoffs = values[1:2]
+	}
+
+	for _, value := range cutoffs {
+		if value == nil {
+			continue
+		}
+
+		cutoff, err := parseCutoff(value)
+		if err != nil {
+			return false, err
+		}
+
+		if issuedBeforeCutoff(claims.IssuedAt, cutoff) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func parseCutoff(value any) (time.Time, error) {
+	raw, ok := value.(string)
+	if !ok {
+		return time.Time{}, errors.New("unexpected revocation cutoff value type")
+	}
+
+	cutoff, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(cutoff, 0), nil
+}
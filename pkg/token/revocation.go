@@ -0,0 +1,157 @@
+package token
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RevocationStore keeps track of access tokens that must be rejected before they expire.
+//
+// JWTs are stateless: once issued, a token stays valid until "exp". To support
+// logout we need a small amount of shared state that every service consults:
+//   - Revoked token IDs ("jti"), for logging out a single device
+//   - A per-session cutoff, for logging out other devices
+//   - A per-user "tokens valid after" cutoff, for logging out everywhere
+//
+// Entries only need to live as long as the tokens they reject, so stores can
+// expire them automatically.
+type RevocationStore interface {
+	// RevokeToken rejects a single token until its expiry.
+	RevokeToken(ctx context.Context, tokenID string, expiresAt time.Time) error
+
+	// RevokeSessionTokens rejects every token of the session issued before issuedBefore.
+	RevokeSessionTokens(ctx context.Context, sessionID string, issuedBefore time.Time) error
+
+	// RevokeUserTokens rejects every token of the user issued before issuedBefore.
+	RevokeUserTokens(ctx context.Context, userID string, issuedBefore time.Time) error
+
+	// IsRevoked reports whether the token described by claims has been revoked.
+	IsRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
+
+// issuedBeforeCutoff reports whether a token issued at issuedAt is covered by cutoff.
+//
+// "iat" only has second precision, so the cutoff is truncated to the second
+// too, and a token issued in the same second as the cutoff stays valid. That
+// is the token a user gets right after changing their password or logging out
+// their other devices, which must not be rejected.
+func issuedBeforeCutoff(issuedAt, cutoff time.Time) bool {
+	return issuedAt.Before(cutoff.Truncate(time.Second))
+}
+
+// MemoryRevocationStore is an in-process RevocationStore.
+//
+// It is only suitable for tests and single-instance development setups:
+// revocations are not shared between services or replicas. Expired entries
+// are dropped on the next revocation, so memory stays bounded by what was
+// revoked within the last retention period.
+type MemoryRevocationStore struct {
+	mu        sync.RWMutex
+	retention time.Duration
+	tokens    map[string]time.Time // jti -> expires at
+	sessions  map[string]memoryCutoff
+	users     map[string]memoryCutoff
+	now       func() time.Time
+}
+
+type memoryCutoff struct {
+	issuedBefore time.Time
+	expiresAt    time.Time
+}
+
+var _ RevocationStore = (*MemoryRevocationStore)(nil)
+
+// NewMemoryRevocationStore creates a MemoryRevocationStore.
+// retention is how long per-user cutoffs are kept; it should be at least the access token expiry.
+func NewMemoryRevocationStore(retention time.Duration) *MemoryRevocationStore {
+	return &MemoryRevocationStore{
+		retention: retention,
+		tokens:    make(map[string]time.Time),
+		sessions:  make(map[string]memoryCutoff),
+		users:     make(map[string]memoryCutoff),
+		now:       time.Now,
+	}
+}
+
+// prune drops the entries that no longer reject any token. The caller must
+// hold the write lock.
+func (s *MemoryRevocationStore) prune(now time.Time) {
+	for id, expiresAt := range s.tokens {
+		if !now.Before(expiresAt) {
+			delete(s.tokens, id)
+		}
+	}
+	for id, cutoff := range s.sessions {
+		if !now.Before(cutoff.expiresAt) {
+			delete(s.sessions, id)
+		}
+	}
+	for id, cutoff := range s.users {
+		if !now.Before(cutoff.expiresAt) {
+			delete(s.users, id)
+		}
+	}
+}
+
+func (s *MemoryRevocationStore) RevokeToken(_ context.Context, tokenID string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	if !now.Before(expiresAt) {
+		return nil // Already expired, nothing to revoke
+	}
+
+	s.tokens[tokenID] = expiresAt
+	return nil
+}
+
+func (s *MemoryRevocationStore) RevokeSessionTokens(_ context.Context, sessionID string, issuedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	s.sessions[sessionID] = memoryCutoff{
+		issuedBefore: issuedBefore,
+		expiresAt:    now.Add(s.retention),
+	}
+	return nil
+}
+
+func (s *MemoryRevocationStore) RevokeUserTokens(_ context.Context, userID string, issuedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+	s.users[userID] = memoryCutoff{
+		issuedBefore: issuedBefore,
+		expiresAt:    now.Add(s.retention),
+	}
+	return nil
+}
+
+func (s *MemoryRevocationStore) IsRevoked(_ context.Context, claims *Claims) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := s.now()
+
+	if expiresAt, ok := s.tokens[claims.ID]; ok && now.Before(expiresAt) {
+		return true, nil
+	}
+
+	if cutoff, ok := s.sessions[claims.SessionID]; ok && claims.SessionID != "" && now.Before(cutoff.expiresAt) &&
+		issuedBeforeCutoff(claims.IssuedAt, cutoff.issuedBefore) {
+		return true, nil
+	}
+
+	if cutoff, ok := s.users[claims.UserID]; ok && now.Before(cutoff.expiresAt) {
+		return issuedBeforeCutoff(claims.IssuedAt, cutoff.issuedBefore), nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,107 @@
+package token
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRevocationStoreSessionCutoff(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryRevocationStore(time.Hour)
+
+	issuedAt := time.Now().Add(-time.Minute)
+	require.NoError(t, store.RevokeSessionTokens(ctx, "session-2", time.Now()))
+
+	testCases := []struct {
+		name   string
+		claims Claims
+		want   bool
+	}{
+		{
+			name:   "token of the revoked session",
+			claims: Claims{ID: "jti-2", UserID: "user-123", SessionID: "session-2", IssuedAt: issuedAt},
+			want:   true,
+		},
+		{
+			name:   "token of another session of the same user",
+			claims: Claims{ID: "jti-1", UserID: "user-123", SessionID: "session-1", IssuedAt: issuedAt},
+			want:   false,
+		},
+		{
+			name:   "token without a session",
+			claims: Claims{ID: "jti-3", UserID: "user-123", IssuedAt: issuedAt},
+			want:   false,
+		},
+		{
+			name:   "token of the revoked session issued after the cutoff",
+			claims: Claims{ID: "jti-4", UserID: "user-123", SessionID: "session-2", IssuedAt: time.Now().Add(2 * time.Second)},
+			want:   false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			revoked, err := store.IsRevoked(ctx, &tc.claims)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, revoked)
+		})
+	}
+}
+
+func TestIssuedBeforeCutoff(t *testing.T) {
+	cutoff := time.Date(2026, 10, 17, 9, 30, 15, 700_000_000, time.UTC)
+
+	testCases := []struct {
+		name     string
+		issuedAt time.Time
+		want     bool
+	}{
+		{name: "a second before", issuedAt: cutoff.Add(-time.Second).Truncate(time.Second), want: true},
+		{name: "same second, before the cutoff", issuedAt: cutoff.Truncate(time.Second), want: false},
+		{name: "next second", issuedAt: cutoff.Add(time.Second).Truncate(time.Second), want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, issuedBeforeCutoff(tc.issuedAt, cutoff))
+		})
+	}
+}
+
+func TestMemoryRevocationStorePrunesExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	store := NewMemoryRevocationStore(15 * time.Minute)
+	store.now = func() time.Time { return now }
+
+	require.NoError(t, store.RevokeToken(ctx, "jti-1", now.Add(5*time.Minute)))
+	require.NoError(t, store.RevokeToken(ctx, "jti-expired", now.Add(-time.Second)))
+	require.NoError(t, store.RevokeSessionTokens(ctx, "session-1", now))
+	require.NoError(t, store.RevokeUserTokens(ctx, "user-1", now))
+	assert.Len(t, store.tokens, 1, "an expired token is not stored")
+	assert.Len(t, store.sessions, 1)
+	assert.Len(t, store.users, 1)
+
+	revoked, err := store.IsRevoked(ctx, &Claims{ID: "jti-1", UserID: "user-2", IssuedAt: now})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// After the token expired, the next revocation drops it
+	now = now.Add(10 * time.Minute)
+	require.NoError(t, store.RevokeToken(ctx, "jti-2", now.Add(5*time.Minute)))
+	assert.NotContains(t, store.tokens, "jti-1")
+	assert.Contains(t, store.sessions, "session-1")
+	assert.Contains(t, store.users, "user-1")
+
+	// And after the retention period, the cutoffs too
+	now = now.Add(10 * time.Minute)
+	require.NoError(t, store.RevokeToken(ctx, "jti-3", now.Add(5*time.Minute)))
+	assert.Empty(t, store.sessions)
+	assert.Empty(t, store.users)
+	assert.Len(t, store.tokens, 1)
+	assert.Contains(t, store.tokens, "jti-3")
+}
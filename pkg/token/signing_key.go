@@ -0,0 +1,98 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MinRSAKeySize is the smallest RSA modulus (in bits) we accept for signing.
+const MinRSAKeySize = 2048
+
+// SigningKey is an asymmetric private key together with its key ID and the
+// JWT algorithm it signs with (RS256 for RSA, EdDSA for Ed25519).
+type SigningKey struct {
+	ID     string
+	method jwt.SigningMethod
+	signer crypto.Signer
+}
+
+// NewSigningKey wraps an RSA or Ed25519 private key.
+// The key ID is the RFC 7638 thumbprint of the public key.
+func NewSigningKey(privateKey crypto.Signer) (*SigningKey, error) {
+	var method jwt.SigningMethod
+	switch key := privateKey.(type) {
+	case *rsa.PrivateKey:
+		if key.N.BitLen() < MinRSAKeySize {
+			return nil, fmt.Errorf("RSA key must be at least %d bits", MinRSAKeySize)
+		}
+		method = jwt.SigningMethodRS256
+	case ed25519.PrivateKey:
+		method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+	}
+
+	kid, err := thumbprint(privateKey.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &SigningKey{
+		ID:     kid,
+		method: method,
+		signer: privateKey,
+	}, nil
+}
+
+// LoadSigningKey reads a PEM encoded private key from path.
+// Supported formats are PKCS#8 ("PRIVATE KEY", RSA or Ed25519) and
+// PKCS#1 ("RSA PRIVATE KEY"), which is what `openssl genpkey` produces.
+func LoadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM block found in %s", path)
+	}
+
+	var privateKey any
+	switch block.Type {
+	case "PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q in %s", block.Type, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing key %s: %w", path, err)
+	}
+
+	signer, ok := privateKey.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("signing key is not a private key")
+	}
+
+	return NewSigningKey(signer)
+}
+
+// Algorithm returns the JWT "alg" this key signs with.
+func (k *SigningKey) Algorithm() string {
+	return k.method.Alg()
+}
+
+// PublicKey returns the public half of the key.
+func (k *SigningKey) PublicKey() crypto.PublicKey {
+	return k.signer.Public()
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/katatrina/airbnb-clone/services/booking/internal/handler"
 	"github.com/katatrina/airbnb-clone/services/booking/internal/repository"
 	"github.com/katatrina/airbnb-clone/services/booking/internal/service"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -38,16 +39,57 @@ func main() {
 	}
 	log.Println("Connected to database successfully")
 
-	tokenMaker, err := token.NewJWTMaker([]byte(cfg.JWTSecret), cfg.JWTExpiry)
+	redisOpts, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
-		log.Fatalf("Failed to create token maker: %v", err)
+		log.Fatalf("Failed to parse Redis URL: %v", err)
 	}
+	redisClient := redis.NewClient(redisOpts)
+	defer redisClient.Close()
 
-	listingClient := client.NewListingClient(cfg.ListingServiceURL)
+	if err = redisClient.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to ping Redis: %v", err)
+	}
+	log.Println("Connected to Redis successfully")
+
+	var tokenVerifier token.TokenVerifier
+	if cfg.JWKSURL != "" {
+		jwksCache := token.NewJWKSCache(cfg.JWKSURL, cfg.JWKSRefreshInterval)
+		if err = jwksCache.Refresh(ctx); err != nil {
+			// The user service may not be up yet; keys are fetched again on first use
+			log.Printf("[WARN] Failed to prefetch JWKS: %v", err)
+		}
+		tokenVerifier = token.NewJWKSVerifier(jwksCache)
+	} else {
+		tokenVerifier, err = token.NewJWTMaker([]byte(cfg.JWTSecret), cfg.JWTExpiry)
+		if err != nil {
+			log.Fatalf("Failed to create token maker: %v", err)
+		}
+	}
+
+	// Shared with the other services, so a logout is honored everywhere
+	revocationStore := token.NewRedisRevocationStore(redisClient, cfg.JWTExpiry)
+	authMiddleware := middleware.AuthMiddleware(tokenVerifier, revocationStore)
+
+	listingClient := client.NewListingClient(cfg.ListingServiceURL, cfg.InternalAPIKey)
 	bookingRepo := repository.NewBookingRepository(db)
 	bookingService := service.NewBookingService(bookingRepo, listingClient)
 	bookingHandler := handler.NewBookingHandler(bookingService)
 
+	requireVerifiedEmail := func(c *gin.Context) { c.Next() }
+	if cfg.RequireVerifiedEmail {
+		requireVerifiedEmail = middleware.RequireVerifiedEmail()
+	}
+
+	// A guest rarely needs more than a handful of booking requests; this stops
+	// scripts from flooding hosts with pending bookings
+	bookingRateLimit := middleware.RateLimit(middleware.NewRedisRateLimitStore(redisClient), middleware.RateLimitPolicy{
+		Name:     "bookings:create",
+		Strategy: middleware.TokenBucket,
+		Limit:    10,
+		Window:   time.Hour,
+		Key:      middleware.RateLimitByUserOrIP,
+	})
+
 	router := gin.Default()
 
 	router.NoRoute(func(c *gin.Context) {
@@ -59,9 +101,9 @@ func main() {
 	v1 := router.Group("/api/v1")
 	{
 		protected := v1.Group("")
-		protected.Use(middleware.AuthMiddleware(tokenMaker))
+		protected.Use(authMiddleware)
 		{
-			protected.POST("/me/bookings", bookingHandler.CreateBooking)
+			protected.POST("/me/bookings", middleware.RequireRole(middleware.RoleGuest), requireVerifiedEmail, bookingRateLimit, bookingHandler.CreateBooking)
 
 			protected.GET("/me/bookings", bookingHandler.ListGuestBookings)
 			protected.GET("/me/bookings/:id", bookingHandler.GetBooking)
@@ -71,6 +113,20 @@ func main() {
 			protected.POST("/me/hosting/bookings/:id/confirm", bookingHandler.ConfirmBooking)
 			protected.POST("/me/hosting/bookings/:id/reject", bookingHandler.RejectBooking)
 		}
+
+		support := v1.Group("/admin/bookings")
+		support.Use(authMiddleware, middleware.RequirePermission(middleware.PermissionManageBookings))
+		{
+			support.GET("/:id", bookingHandler.GetBookingForSupport)
+			support.POST("/:id/cancel", bookingHandler.CancelBookingForSupport)
+		}
+	}
+
+	internal := router.Group("/internal/v1")
+	internal.Use(middleware.RequireInternalAPIKey(cfg.InternalAPIKey))
+	{
+		internal.GET("/users/:id/upcoming-bookings", bookingHandler.GetUpcomingBookings)
+		internal.GET("/users/:id/bookings", bookingHandler.GetUserBookings)
 	}
 
 	addr := fmt.Sprintf(":%s", cfg.ServerPort)
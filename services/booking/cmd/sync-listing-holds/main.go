@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/katatrina/airbnb-clone/services/booking/config"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/client"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/repository"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/service"
+)
+
+// sync-listing-holds copies the nights of current bookings to the listing
+// service. Run it once after deploying listing migration 000008, and again
+// whenever the API logged a failed release.
+func main() {
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to create database pool: %v", err)
+	}
+	defer db.Close()
+
+	if err = db.Ping(ctx); err != nil {
+		log.Fatalf("Failed to ping database: %v", err)
+	}
+
+	listingClient := client.NewListingClient(cfg.ListingServiceURL, cfg.InternalAPIKey)
+	bookingService := service.NewBookingService(repository.NewBookingRepository(db), listingClient)
+
+	result, err := bookingService.SyncListingHolds(ctx)
+	if err != nil {
+		log.Fatalf("Failed to sync listing holds: %v", err)
+	}
+
+	log.Printf("Held %d bookings, released %d, failed %d", result.Held, result.Released, result.Failed)
+	if result.Failed > 0 {
+		log.Fatalf("Some bookings could not be synced, run again once the listing service is reachable")
+	}
+}
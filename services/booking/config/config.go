@@ -10,9 +10,21 @@ import (
 type Config struct {
 	ServerPort        string        `mapstructure:"SERVER_PORT"`
 	DatabaseURL       string        `mapstructure:"DATABASE_URL"`
+	RedisURL          string        `mapstructure:"REDIS_URL"`
 	ListingServiceURL string        `mapstructure:"LISTING_SERVICE_URL"`
 	JWTSecret         string        `mapstructure:"JWT_SECRET"`
 	JWTExpiry         time.Duration `mapstructure:"JWT_EXPIRY"`
+
+	// JWKSURL makes the service verify tokens with the user service's public
+	// keys instead of the shared JWT_SECRET.
+	JWKSURL             string        `mapstructure:"JWKS_URL"`
+	JWKSRefreshInterval time.Duration `mapstructure:"JWKS_REFRESH_INTERVAL"`
+
+	// RequireVerifiedEmail blocks creating bookings until the user has verified their email.
+	RequireVerifiedEmail bool `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+
+	// InternalAPIKey authenticates calls from other services to /internal endpoints.
+	InternalAPIKey string `mapstructure:"INTERNAL_API_KEY"`
 }
 
 // Validate checks that all required configuration is present.
@@ -23,11 +35,17 @@ func (c *Config) Validate() error {
 	if c.DatabaseURL == "" {
 		return errors.New("DATABASE_URL is required")
 	}
+	if c.RedisURL == "" {
+		return errors.New("REDIS_URL is required")
+	}
 	if c.ListingServiceURL == "" {
 		return errors.New("LISTING_SERVICE_URL is required")
 	}
-	if c.JWTSecret == "" {
-		return errors.New("JWT_SECRET is required")
+	if c.JWTSecret == "" && c.JWKSURL == "" {
+		return errors.New("JWT_SECRET or JWKS_URL is required")
+	}
+	if c.InternalAPIKey == "" {
+		return errors.New("INTERNAL_API_KEY is required")
 	}
 
 	return nil
@@ -1,24 +1,30 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
 	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
 	"github.com/katatrina/airbnb-clone/services/booking/internal/service"
 )
 
+const dateLayout = "2006-01-02"
+
 type ListingClient struct {
-	baseURL    string
-	httpClient *http.Client
+	baseURL        string
+	internalAPIKey string
+	httpClient     *http.Client
 }
 
-func NewListingClient(baseURL string) *ListingClient {
+func NewListingClient(baseURL, internalAPIKey string) *ListingClient {
 	return &ListingClient{
-		baseURL: baseURL,
+		baseURL:        baseURL,
+		internalAPIKey: internalAPIKey,
 		httpClient: &http.Client{
 			Timeout: 5 * time.Second,
 		},
@@ -39,6 +45,12 @@ type listingAPIData struct {
 	PricePerNight int64  `json:"pricePerNight"`
 	Currency      string `json:"currency"`
 	Status        string `json:"status"`
+
+	PropertyType *string `json:"propertyType"`
+	MaxGuests    *int32  `json:"maxGuests"`
+	Bedrooms     *int32  `json:"bedrooms"`
+	Beds         *int32  `json:"beds"`
+	Bathrooms    *int32  `json:"bathrooms"`
 }
 
 func (c *ListingClient) GetActiveListingByID(
@@ -81,5 +93,79 @@ func (c *ListingClient) GetActiveListingByID(
 		PricePerNight: apiResp.Data.PricePerNight,
 		Currency:      apiResp.Data.Currency,
 		Status:        apiResp.Data.Status,
+		PropertyType:  apiResp.Data.PropertyType,
+		MaxGuests:     apiResp.Data.MaxGuests,
+		Bedrooms:      apiResp.Data.Bedrooms,
+		Beds:          apiResp.Data.Beds,
+		Bathrooms:     apiResp.Data.Bathrooms,
 	}, nil
 }
+
+type holdDatesAPIRequest struct {
+	CheckIn  string `json:"checkIn"`
+	CheckOut string `json:"checkOut"`
+}
+
+// HoldDates asks the listing service to take the nights of a booking. Calling
+// it again for the same booking and stay succeeds, so it is safe to retry.
+func (c *ListingClient) HoldDates(
+	ctx context.Context,
+	listingID, bookingID string,
+	checkIn, checkOut time.Time,
+) error {
+	body, err := json.Marshal(holdDatesAPIRequest{
+		CheckIn:  checkIn.Format(dateLayout),
+		CheckOut: checkOut.Format(dateLayout),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode hold request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/internal/v1/listings/%s/holds/%s", c.baseURL, listingID, bookingID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return model.ErrListingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return model.ErrListingNotFound
+	case http.StatusConflict:
+		return model.ErrDatesUnavailable
+	default:
+		return model.ErrListingServiceUnavailable
+	}
+}
+
+// ReleaseDates asks the listing service to free the nights of a booking.
+// Releasing dates that are not held succeeds.
+func (c *ListingClient) ReleaseDates(ctx context.Context, listingID, bookingID string) error {
+	url := fmt.Sprintf("%s/internal/v1/listings/%s/holds/%s", c.baseURL, listingID, bookingID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return model.ErrListingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return model.ErrListingServiceUnavailable
+	}
+
+	return nil
+}
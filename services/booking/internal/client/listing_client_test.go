@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testListingID = "0190a0b0-0000-7000-8000-0000000000b1"
+	testBookingID = "0190a0b0-0000-7000-8000-0000000000c1"
+)
+
+func TestHoldDates(t *testing.T) {
+	checkIn := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+	checkOut := time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name    string
+		status  int
+		wantErr error
+	}{
+		{name: "held", status: http.StatusOK},
+		{name: "listing not active", status: http.StatusNotFound, wantErr: model.ErrListingNotFound},
+		{name: "dates taken", status: http.StatusConflict, wantErr: model.ErrDatesUnavailable},
+		{name: "listing service failing", status: http.StatusInternalServerError, wantErr: model.ErrListingServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPut, r.Method)
+				assert.Equal(t, "/internal/v1/listings/"+testListingID+"/holds/"+testBookingID, r.URL.Path)
+				assert.Equal(t, "internal-key", r.Header.Get(middleware.InternalAPIKeyHeader))
+
+				var body holdDatesAPIRequest
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, holdDatesAPIRequest{CheckIn: "2026-12-24", CheckOut: "2026-12-27"}, body)
+
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			err := NewListingClient(server.URL, "internal-key").
+				HoldDates(context.Background(), testListingID, testBookingID, checkIn, checkOut)
+
+			if tc.wantErr != nil {
+				require.ErrorIs(t, err, tc.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReleaseDates(t *testing.T) {
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/internal/v1/listings/"+testListingID+"/holds/"+testBookingID, r.URL.Path)
+		assert.Equal(t, "internal-key", r.Header.Get(middleware.InternalAPIKeyHeader))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	c := NewListingClient(server.URL, "internal-key")
+	require.NoError(t, c.ReleaseDates(context.Background(), testListingID, testBookingID))
+
+	status = http.StatusInternalServerError
+	require.ErrorIs(t, c.ReleaseDates(context.Background(), testListingID, testBookingID), model.ErrListingServiceUnavailable)
+}
+
+func TestHoldDatesListingServiceDown(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	err := NewListingClient(server.URL, "internal-key").
+		HoldDates(context.Background(), testListingID, testBookingID, time.Now(), time.Now().AddDate(0, 0, 1))
+	require.ErrorIs(t, err, model.ErrListingServiceUnavailable)
+}
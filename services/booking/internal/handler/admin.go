@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+)
+
+func (h *BookingHandler) GetBookingForSupport(c *gin.Context) {
+	bookingID := c.Param("id")
+
+	if _, err := uuid.Parse(bookingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed,
+			"Invalid booking ID format")
+		return
+	}
+
+	booking, err := h.bookingService.GetBookingForSupport(
+		c.Request.Context(), bookingID)
+	if err != nil {
+		if errors.Is(err, model.ErrBookingNotFound) {
+			response.NotFound(c, response.CodeBookingNotFound,
+				"Booking not found")
+			return
+		}
+		log.Printf("[ERROR] failed to get booking for support: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, NewBookingResponse(booking), "")
+}
+
+func (h *BookingHandler) CancelBookingForSupport(c *gin.Context) {
+	bookingID := c.Param("id")
+
+	if _, err := uuid.Parse(bookingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed,
+			"Invalid booking ID format")
+		return
+	}
+
+	booking, err := h.bookingService.CancelBookingForSupport(
+		c.Request.Context(), bookingID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrBookingNotFound):
+			response.NotFound(c, response.CodeBookingNotFound,
+				"Booking not found")
+		case errors.Is(err, model.ErrBookingNotCancellable):
+			response.BadRequest(c, response.CodeBookingNotCancellable,
+				"Only pending or confirmed bookings can be cancelled")
+		default:
+			log.Printf("[ERROR] failed to cancel booking for support: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewBookingResponse(booking),
+		"Booking cancelled successfully")
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testAdminID = "0190a0b0-0000-7000-8000-0000000000a9"
+
+var (
+	testAdmin      = &middleware.AuthUser{ID: testAdminID, Roles: []middleware.Role{middleware.RoleGuest, middleware.RoleAdmin}}
+	testGuestHost  = &middleware.AuthUser{ID: testGuestID, Roles: []middleware.Role{middleware.RoleGuest, middleware.RoleHost}}
+	requireSupport = middleware.RequirePermission(middleware.PermissionManageBookings)
+)
+
+func TestGetBookingForSupport(t *testing.T) {
+	const route = "/admin/bookings/:id"
+	booking := &model.Booking{ID: testBookingID, ListingID: testListingID, GuestID: testGuestID, HostID: testHostID, Status: model.BookingStatusConfirmed}
+
+	testCases := []struct {
+		name       string
+		user       *middleware.AuthUser
+		bookingID  string
+		setupMocks func(m *handlerMocks)
+		wantStatus int
+		wantCode   response.ErrorCode
+	}{
+		{
+			name:      "admin sees a booking they are not part of",
+			user:      testAdmin,
+			bookingID: testBookingID,
+			setupMocks: func(m *handlerMocks) {
+				m.bookingRepo.On("FindByID", mock.Anything, testBookingID).Return(booking, nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCode:   response.CodeSuccess,
+		},
+		{
+			name:       "not an admin",
+			user:       testGuestHost,
+			bookingID:  testBookingID,
+			setupMocks: func(m *handlerMocks) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   response.CodeForbidden,
+		},
+		{
+			name:      "booking not found",
+			user:      testAdmin,
+			bookingID: testBookingID,
+			setupMocks: func(m *handlerMocks) {
+				m.bookingRepo.On("FindByID", mock.Anything, testBookingID).Return(nil, model.ErrBookingNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   response.CodeBookingNotFound,
+		},
+		{
+			name:       "invalid booking ID",
+			user:       testAdmin,
+			bookingID:  "not-a-uuid",
+			setupMocks: func(m *handlerMocks) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   response.CodeValidationFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, h := newMocksAndHandler()
+			tc.setupMocks(m)
+
+			rec, resp := serveAs(t, http.MethodGet, route, "/admin/bookings/"+tc.bookingID, nil, tc.user, requireSupport, h.GetBookingForSupport)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestCancelBookingForSupport(t *testing.T) {
+	const route = "/admin/bookings/:id/cancel"
+	path := "/admin/bookings/" + testBookingID + "/cancel"
+	bookingWithStatus := func(status model.BookingStatus) *model.Booking {
+		return &model.Booking{ID: testBookingID, ListingID: testListingID, GuestID: testGuestID, HostID: testHostID, Status: status}
+	}
+
+	testCases := []struct {
+		name       string
+		user       *middleware.AuthUser
+		setupMocks func(m *handlerMocks)
+		wantStatus int
+		wantCode   response.ErrorCode
+	}{
+		{
+			name: "admin cancels a confirmed booking and its dates are released",
+			user: testAdmin,
+			setupMocks: func(m *handlerMocks) {
+				m.bookingRepo.On("FindByID", mock.Anything, testBookingID).Return(bookingWithStatus(model.BookingStatusConfirmed), nil)
+				m.bookingRepo.On("UpdateStatus", mock.Anything, testBookingID, model.BookingStatusCancelled).
+					Return(bookingWithStatus(model.BookingStatusCancelled), nil)
+				m.listingClient.On("ReleaseDates", mock.Anything, testListingID, testBookingID).Return(nil)
+			},
+			wantStatus: http.StatusOK,
+			wantCode:   response.CodeSuccess,
+		},
+		{
+			name:       "the guest of the booking is not an admin",
+			user:       testGuestHost,
+			setupMocks: func(m *handlerMocks) {},
+			wantStatus: http.StatusForbidden,
+			wantCode:   response.CodeForbidden,
+		},
+		{
+			name: "booking already ended",
+			user: testAdmin,
+			setupMocks: func(m *handlerMocks) {
+				m.bookingRepo.On("FindByID", mock.Anything, testBookingID).Return(bookingWithStatus(model.BookingStatusCompleted), nil)
+			},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   response.CodeBookingNotCancellable,
+		},
+		{
+			name: "booking not found",
+			user: testAdmin,
+			setupMocks: func(m *handlerMocks) {
+				m.bookingRepo.On("FindByID", mock.Anything, testBookingID).Return(nil, model.ErrBookingNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   response.CodeBookingNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, h := newMocksAndHandler()
+			tc.setupMocks(m)
+
+			rec, resp := serveAs(t, http.MethodPost, route, path, nil, tc.user, requireSupport, h.CancelBookingForSupport)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantCode, resp.Code)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestCreateBookingRequiresTheGuestRole(t *testing.T) {
+	m, h := newMocksAndHandler()
+	hostOnly := &middleware.AuthUser{ID: testHostID, Roles: []middleware.Role{middleware.RoleHost}}
+
+	rec, resp := serveAs(t, http.MethodPost, "/me/bookings", "/me/bookings", CreateBookingRequest{ListingID: testListingID},
+		hostOnly, middleware.RequireRole(middleware.RoleGuest), h.CreateBooking)
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Equal(t, response.CodeForbidden, resp.Code)
+	m.assertExpectations(t)
+}
@@ -0,0 +1,127 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateBooking(t *testing.T) {
+	checkIn := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 7)
+	checkOut := checkIn.AddDate(0, 0, 3)
+	listing := &service.ListingInfo{ID: testListingID, HostID: testHostID, PricePerNight: 500_000, Currency: "VND"}
+	validBody := CreateBookingRequest{
+		ListingID:    testListingID,
+		CheckInDate:  checkIn.Format(dateLayout),
+		CheckOutDate: checkOut.Format(dateLayout),
+	}
+
+	testCases := []struct {
+		name       string
+		body       CreateBookingRequest
+		setupMocks func(m *handlerMocks)
+		wantStatus int
+		wantCode   response.ErrorCode
+	}{
+		{
+			name: "created",
+			body: validBody,
+			setupMocks: func(m *handlerMocks) {
+				m.listingClient.On("GetActiveListingByID", mock.Anything, testListingID).Return(listing, nil)
+				m.listingClient.On("HoldDates", mock.Anything, testListingID, mock.AnythingOfType("string"), checkIn, checkOut).Return(nil)
+				m.bookingRepo.On("Create", mock.Anything, mock.AnythingOfType("model.Booking")).Return(&model.Booking{
+					ID: testBookingID, CheckInDate: checkIn, CheckOutDate: checkOut, Status: model.BookingStatusPending,
+				}, nil)
+			},
+			wantStatus: http.StatusCreated,
+			wantCode:   response.CodeSuccess,
+		},
+		{
+			name: "dates held by another booking",
+			body: validBody,
+			setupMocks: func(m *handlerMocks) {
+				m.listingClient.On("GetActiveListingByID", mock.Anything, testListingID).Return(listing, nil)
+				m.listingClient.On("HoldDates", mock.Anything, testListingID, mock.AnythingOfType("string"), checkIn, checkOut).
+					Return(model.ErrDatesUnavailable)
+			},
+			wantStatus: http.StatusConflict,
+			wantCode:   response.CodeDatesUnavailable,
+		},
+		{
+			name: "listing not active",
+			body: validBody,
+			setupMocks: func(m *handlerMocks) {
+				m.listingClient.On("GetActiveListingByID", mock.Anything, testListingID).Return(nil, model.ErrListingNotFound)
+			},
+			wantStatus: http.StatusNotFound,
+			wantCode:   response.CodeListingNotFound,
+		},
+		{
+			name: "listing service down",
+			body: validBody,
+			setupMocks: func(m *handlerMocks) {
+				m.listingClient.On("GetActiveListingByID", mock.Anything, testListingID).Return(listing, nil)
+				m.listingClient.On("HoldDates", mock.Anything, testListingID, mock.AnythingOfType("string"), checkIn, checkOut).
+					Return(model.ErrListingServiceUnavailable)
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantCode:   response.CodeServiceUnavailable,
+		},
+		{
+			name: "malformed check-in",
+			body: CreateBookingRequest{
+				ListingID:    testListingID,
+				CheckInDate:  "24/12/2026",
+				CheckOutDate: checkOut.Format(dateLayout),
+			},
+			setupMocks: func(m *handlerMocks) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   response.CodeValidationFailed,
+		},
+		{
+			name: "invalid listing ID",
+			body: CreateBookingRequest{
+				ListingID:    "abc",
+				CheckInDate:  checkIn.Format(dateLayout),
+				CheckOutDate: checkOut.Format(dateLayout),
+			},
+			setupMocks: func(m *handlerMocks) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   response.CodeValidationFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, h := newMocksAndHandler()
+			tc.setupMocks(m)
+
+			rec, body := serve(t, http.MethodPost, "/me/bookings", "/me/bookings", tc.body, testGuestID, h.CreateBooking)
+
+			assert.Equal(t, tc.wantStatus, rec.Code)
+			assert.Equal(t, tc.wantCode, body.Code)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestCancelBookingReleasesTheDates(t *testing.T) {
+	m, h := newMocksAndHandler()
+	pending := &model.Booking{ID: testBookingID, ListingID: testListingID, GuestID: testGuestID, HostID: testHostID, Status: model.BookingStatusPending}
+	cancelled := *pending
+	cancelled.Status = model.BookingStatusCancelled
+	m.bookingRepo.On("FindByID", mock.Anything, testBookingID).Return(pending, nil)
+	m.bookingRepo.On("UpdateStatus", mock.Anything, testBookingID, model.BookingStatusCancelled).Return(&cancelled, nil)
+	m.listingClient.On("ReleaseDates", mock.Anything, testListingID, testBookingID).Return(nil)
+
+	rec, _ := serve(t, http.MethodPost, "/me/bookings/:id/cancel", "/me/bookings/"+testBookingID+"/cancel", nil, testGuestID, h.CancelBooking)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	m.assertExpectations(t)
+}
@@ -36,6 +36,16 @@ func NewBookingResponse(b *model.Booking) *BookingResponse {
 	}
 }
 
+type UpcomingBookingsResponse struct {
+	AsGuest int64 `json:"asGuest"`
+	AsHost  int64 `json:"asHost"`
+}
+
+type UserBookingsResponse struct {
+	AsGuest []BookingResponse `json:"asGuest"`
+	AsHost  []BookingResponse `json:"asHost"`
+}
+
 func NewBookingsResponse(bookings []model.Booking) []BookingResponse {
 	resp := make([]BookingResponse, len(bookings))
 	for i := range bookings {
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGuestID   = "0190a0b0-0000-7000-8000-0000000000a1"
+	testHostID    = "0190a0b0-0000-7000-8000-0000000000a2"
+	testListingID = "0190a0b0-0000-7000-8000-0000000000b1"
+	testBookingID = "0190a0b0-0000-7000-8000-0000000000c1"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+type handlerMocks struct {
+	bookingRepo   *MockBookingRepository
+	listingClient *MockListingClient
+}
+
+func newMocksAndHandler() (*handlerMocks, *BookingHandler) {
+	m := &handlerMocks{
+		bookingRepo:   new(MockBookingRepository),
+		listingClient: new(MockListingClient),
+	}
+
+	return m, NewBookingHandler(service.NewBookingService(m.bookingRepo, m.listingClient))
+}
+
+func (m *handlerMocks) assertExpectations(t *testing.T) {
+	t.Helper()
+
+	m.bookingRepo.AssertExpectations(t)
+	m.listingClient.AssertExpectations(t)
+}
+
+// serve runs one request through handle, signed in as userID when it is set.
+func serve(t *testing.T, method, route, path string, body any, userID string, handle gin.HandlerFunc) (*httptest.ResponseRecorder, response.Response) {
+	t.Helper()
+
+	var user *middleware.AuthUser
+	if userID != "" {
+		user = &middleware.AuthUser{ID: userID}
+	}
+	return serveAs(t, method, route, path, body, user, handle)
+}
+
+// serveAs runs one request through handlers, signed in as user when it is set.
+// Pass the route's middleware first to test who may call it.
+func serveAs(t *testing.T, method, route, path string, body any, user *middleware.AuthUser, handlers ...gin.HandlerFunc) (*httptest.ResponseRecorder, response.Response) {
+	t.Helper()
+
+	router := gin.New()
+	router.Handle(method, route, append([]gin.HandlerFunc{func(c *gin.Context) {
+		if user != nil {
+			c.Set(middleware.AuthUserKey, user)
+		}
+	}}, handlers...)...)
+
+	var reqBody bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&reqBody).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &reqBody)
+	req.Header.Set("Content-Type", "application/json")
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp response.Response
+	if rec.Body.Len() > 0 {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	}
+	return rec, resp
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+)
+
+// GetUpcomingBookings tells another service whether a user still has confirmed
+// stays ahead, e.g. before the user service deletes the account.
+func (h *BookingHandler) GetUpcomingBookings(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	counts, err := h.bookingService.CountUpcomingBookings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[ERROR] failed to count upcoming bookings: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, UpcomingBookingsResponse{
+		AsGuest: counts.AsGuest,
+		AsHost:  counts.AsHost,
+	}, "Upcoming bookings counted successfully")
+}
+
+// GetUserBookings returns every booking of a user, as a guest and as a host.
+// The user service puts them into the user's personal data export.
+func (h *BookingHandler) GetUserBookings(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	asGuest, err := h.bookingService.ListGuestBookings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[ERROR] failed to list guest bookings: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	asHost, err := h.bookingService.ListHostBookings(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[ERROR] failed to list host bookings: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, UserBookingsResponse{
+		AsGuest: NewBookingsResponse(asGuest),
+		AsHost:  NewBookingsResponse(asHost),
+	}, "")
+}
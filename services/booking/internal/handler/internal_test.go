@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeData decodes the data of resp into v.
+func decodeData(t *testing.T, resp response.Response, v any) {
+	t.Helper()
+
+	raw, err := json.Marshal(resp.Data)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(raw, v))
+}
+
+func TestGetUpcomingBookings(t *testing.T) {
+	const route = "/internal/users/:id/upcoming-bookings"
+
+	t.Run("counts", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+		m.bookingRepo.On("CountUpcomingConfirmed", mock.Anything, testGuestID).
+			Return(&model.UpcomingBookingCounts{AsGuest: 2, AsHost: 1}, nil)
+
+		rec, resp := serve(t, http.MethodGet, route, "/internal/users/"+testGuestID+"/upcoming-bookings", nil, "", h.GetUpcomingBookings)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var counts UpcomingBookingsResponse
+		decodeData(t, resp, &counts)
+		assert.Equal(t, UpcomingBookingsResponse{AsGuest: 2, AsHost: 1}, counts)
+		m.assertExpectations(t)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+
+		rec, resp := serve(t, http.MethodGet, route, "/internal/users/not-a-uuid/upcoming-bookings", nil, "", h.GetUpcomingBookings)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, response.CodeValidationFailed, resp.Code)
+		m.assertExpectations(t)
+	})
+
+	t.Run("database down", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+		m.bookingRepo.On("CountUpcomingConfirmed", mock.Anything, testGuestID).
+			Return(nil, errors.New("connection refused"))
+
+		rec, _ := serve(t, http.MethodGet, route, "/internal/users/"+testGuestID+"/upcoming-bookings", nil, "", h.GetUpcomingBookings)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		m.assertExpectations(t)
+	})
+}
+
+func TestGetUserBookings(t *testing.T) {
+	const route = "/internal/users/:id/bookings"
+	path := "/internal/users/" + testGuestID + "/bookings"
+	asGuest := []model.Booking{{ID: testBookingID, ListingID: testListingID, GuestID: testGuestID, HostID: testHostID, Status: model.BookingStatusCompleted}}
+	asHost := []model.Booking{{ID: "0190a0b0-0000-7000-8000-0000000000c2", ListingID: "0190a0b0-0000-7000-8000-0000000000b2", GuestID: testHostID, HostID: testGuestID, Status: model.BookingStatusCancelled}}
+
+	t.Run("bookings as guest and as host", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+		m.bookingRepo.On("ListByGuestID", mock.Anything, testGuestID).Return(asGuest, nil)
+		m.bookingRepo.On("ListByHostID", mock.Anything, testGuestID).Return(asHost, nil)
+
+		rec, resp := serve(t, http.MethodGet, route, path, nil, "", h.GetUserBookings)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var bookings UserBookingsResponse
+		decodeData(t, resp, &bookings)
+		require.Len(t, bookings.AsGuest, 1)
+		require.Len(t, bookings.AsHost, 1)
+		assert.Equal(t, testBookingID, bookings.AsGuest[0].ID)
+		assert.Equal(t, "0190a0b0-0000-7000-8000-0000000000c2", bookings.AsHost[0].ID)
+		m.assertExpectations(t)
+	})
+
+	t.Run("no bookings are empty lists", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+		m.bookingRepo.On("ListByGuestID", mock.Anything, testGuestID).Return([]model.Booking{}, nil)
+		m.bookingRepo.On("ListByHostID", mock.Anything, testGuestID).Return([]model.Booking{}, nil)
+
+		rec, _ := serve(t, http.MethodGet, route, path, nil, "", h.GetUserBookings)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"asGuest": [], "asHost": []}`, string(dataJSON(t, rec.Body.Bytes())))
+		m.assertExpectations(t)
+	})
+
+	t.Run("invalid user ID", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+
+		rec, _ := serve(t, http.MethodGet, route, "/internal/users/not-a-uuid/bookings", nil, "", h.GetUserBookings)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		m.assertExpectations(t)
+	})
+
+	t.Run("database down", func(t *testing.T) {
+		m, h := newMocksAndHandler()
+		m.bookingRepo.On("ListByGuestID", mock.Anything, testGuestID).Return(asGuest, nil)
+		m.bookingRepo.On("ListByHostID", mock.Anything, testGuestID).Return([]model.Booking(nil), errors.New("connection refused"))
+
+		rec, _ := serve(t, http.MethodGet, route, path, nil, "", h.GetUserBookings)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		m.assertExpectations(t)
+	})
+}
+
+// dataJSON returns the raw "data" field of a response body.
+func dataJSON(t *testing.T, body []byte) json.RawMessage {
+	t.Helper()
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(body, &envelope))
+	return envelope.Data
+}
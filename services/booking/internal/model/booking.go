@@ -28,3 +28,19 @@ type Booking struct {
 	UpdatedAt     time.Time     `db:"updated_at"`
 	DeletedAt     *time.Time    `db:"deleted_at"`
 }
+
+// Vietnam is the time zone stay dates are counted in. It has no daylight
+// saving time, so a fixed offset avoids depending on the tz database.
+var Vietnam = time.FixedZone("ICT", 7*60*60)
+
+// Today returns the current date in Vietnam. See DateOf.
+func Today() time.Time {
+	return DateOf(time.Now())
+}
+
+// DateOf returns the calendar date of t in Vietnam, at midnight UTC like the
+// YYYY-MM-DD dates parsed from requests, so the two can be compared.
+func DateOf(t time.Time) time.Time {
+	year, month, day := t.In(Vietnam).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
@@ -8,3 +8,18 @@ type CreateBookingParams struct {
 	CheckInDate  time.Time
 	CheckOutDate time.Time
 }
+
+// UpcomingBookingCounts is how many confirmed, not yet finished stays a user
+// takes part in, as a guest and as a host.
+type UpcomingBookingCounts struct {
+	AsGuest int64
+	AsHost  int64
+}
+
+// HoldSyncResult counts the bookings whose nights a sync held or released in
+// the listing service, and those it could not reach it for.
+type HoldSyncResult struct {
+	Held     int
+	Released int
+	Failed   int
+}
@@ -14,6 +14,8 @@ var (
 	ErrInvalidDateRange  = errors.New("check-out date must be after check-in date")
 	ErrCheckInPast       = errors.New("check-in date cannot be in the past")
 
+	ErrBookingNotCancellable = errors.New("only pending or confirmed bookings can be cancelled")
+
 	ErrListingNotFound           = errors.New("listing not found or not active")
 	ErrListingServiceUnavailable = errors.New("listing service is unavailable")
 )
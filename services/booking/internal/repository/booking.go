@@ -155,3 +155,53 @@ func (r *BookingRepository) ListByHostID(
 
 	return bookings, nil
 }
+
+// CountUpcomingConfirmed counts confirmed bookings of the user, as guest or as
+// host, whose stay has not ended yet (including stays in progress).
+func (r *BookingRepository) CountUpcomingConfirmed(
+	ctx context.Context,
+	userID string,
+) (*model.UpcomingBookingCounts, error) {
+	query := `
+        SELECT
+            COUNT(*) FILTER (WHERE guest_id = $1),
+            COUNT(*) FILTER (WHERE host_id = $1)
+        FROM bookings
+        WHERE (guest_id = $1 OR host_id = $1)
+          AND status = $2
+          AND check_out_date > $3
+          AND deleted_at IS NULL
+    `
+
+	var counts model.UpcomingBookingCounts
+	err := r.db.QueryRow(ctx, query, userID, model.BookingStatusConfirmed, model.Today()).
+		Scan(&counts.AsGuest, &counts.AsHost)
+	if err != nil {
+		return nil, err
+	}
+
+	return &counts, nil
+}
+
+// ListNotEnded returns the bookings, in any status, whose stay has not ended
+// yet, oldest first.
+func (r *BookingRepository) ListNotEnded(ctx context.Context) ([]model.Booking, error) {
+	query := `
+        SELECT
+            id, listing_id, guest_id, host_id,
+            check_in_date, check_out_date, total_nights,
+            price_per_night, total_price, currency,
+            status, created_at, updated_at, deleted_at
+        FROM bookings
+        WHERE check_out_date > $1 AND deleted_at IS NULL
+        ORDER BY created_at
+    `
+
+	rows, _ := r.db.Query(ctx, query, model.Today())
+	bookings, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Booking])
+	if err != nil {
+		return nil, err
+	}
+
+	return bookings, nil
+}
@@ -0,0 +1,184 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB connects to a database migrated up to the latest version. Set
+// BOOKING_TEST_DATABASE_URL to run the tests that need it.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("BOOKING_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("BOOKING_TEST_DATABASE_URL is not set")
+	}
+
+	db, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	require.NoError(t, db.Ping(context.Background()))
+
+	return db
+}
+
+// createTestBooking saves a booking of guestID at hostID's listing, starting
+// checkIn for nights nights. It is deleted at the end of the test.
+func createTestBooking(
+	t *testing.T,
+	repo *BookingRepository,
+	listingID, guestID, hostID string,
+	checkIn time.Time,
+	nights int,
+	status model.BookingStatus,
+) *model.Booking {
+	t.Helper()
+
+	now := time.Now()
+	booking, err := repo.Create(context.Background(), model.Booking{
+		ID:            uuid.NewString(),
+		ListingID:     listingID,
+		GuestID:       guestID,
+		HostID:        hostID,
+		CheckInDate:   checkIn,
+		CheckOutDate:  checkIn.AddDate(0, 0, nights),
+		TotalNights:   nights,
+		PricePerNight: 500_000,
+		TotalPrice:    500_000 * int64(nights),
+		Currency:      "VND",
+		Status:        status,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = repo.db.Exec(context.Background(), `DELETE FROM bookings WHERE id = $1`, booking.ID)
+	})
+
+	return booking
+}
+
+func bookingIDs(bookings []model.Booking) []string {
+	ids := make([]string, len(bookings))
+	for i := range bookings {
+		ids[i] = bookings[i].ID
+	}
+	return ids
+}
+
+func TestListNotEnded(t *testing.T) {
+	db := testDB(t)
+	repo := NewBookingRepository(db)
+	ctx := context.Background()
+	listingID, guestID, hostID := uuid.NewString(), uuid.NewString(), uuid.NewString()
+	d := model.Today()
+
+	ended := createTestBooking(t, repo, listingID, guestID, hostID, d.AddDate(0, 0, -5), 5, model.BookingStatusConfirmed)
+	inProgress := createTestBooking(t, repo, listingID, guestID, hostID, d.AddDate(0, 0, -1), 2, model.BookingStatusConfirmed)
+	cancelled := createTestBooking(t, repo, listingID, guestID, hostID, d.AddDate(0, 0, 7), 2, model.BookingStatusCancelled)
+	pending := createTestBooking(t, repo, listingID, guestID, hostID, d.AddDate(0, 0, 7), 2, model.BookingStatusPending)
+
+	bookings, err := repo.ListNotEnded(ctx)
+	require.NoError(t, err)
+
+	ids := bookingIDs(bookings)
+	assert.NotContains(t, ids, ended.ID)
+	assert.Contains(t, ids, inProgress.ID)
+	assert.Contains(t, ids, cancelled.ID, "cancelled bookings are listed so their dates get released")
+	assert.Contains(t, ids, pending.ID)
+}
+
+func TestCreateRejectsOverlappingBookings(t *testing.T) {
+	db := testDB(t)
+	repo := NewBookingRepository(db)
+	listingID, hostID := uuid.NewString(), uuid.NewString()
+	checkIn := model.Today().AddDate(0, 0, 30)
+
+	createTestBooking(t, repo, listingID, uuid.NewString(), hostID, checkIn, 3, model.BookingStatusPending)
+
+	_, err := repo.Create(context.Background(), model.Booking{
+		ID:            uuid.NewString(),
+		ListingID:     listingID,
+		GuestID:       uuid.NewString(),
+		HostID:        hostID,
+		CheckInDate:   checkIn.AddDate(0, 0, 2),
+		CheckOutDate:  checkIn.AddDate(0, 0, 4),
+		TotalNights:   2,
+		PricePerNight: 500_000,
+		TotalPrice:    1_000_000,
+		Currency:      "VND",
+		Status:        model.BookingStatusPending,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	})
+	require.ErrorIs(t, err, model.ErrDatesUnavailable)
+
+	// The check-out day is free for the next guest
+	createTestBooking(t, repo, listingID, uuid.NewString(), hostID, checkIn.AddDate(0, 0, 3), 2, model.BookingStatusPending)
+}
+
+func TestCountUpcomingConfirmed(t *testing.T) {
+	db := testDB(t)
+	repo := NewBookingRepository(db)
+	ctx := context.Background()
+	userID, otherID := uuid.NewString(), uuid.NewString()
+	d := model.Today()
+
+	// Counted: confirmed stays that have not ended, as guest or host
+	createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, 10), 2, model.BookingStatusConfirmed)
+	createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, -1), 3, model.BookingStatusConfirmed)
+	createTestBooking(t, repo, uuid.NewString(), otherID, userID, d.AddDate(0, 0, 20), 2, model.BookingStatusConfirmed)
+
+	// Not counted
+	createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, -3), 3, model.BookingStatusConfirmed)
+	createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, 30), 2, model.BookingStatusPending)
+	createTestBooking(t, repo, uuid.NewString(), otherID, userID, d.AddDate(0, 0, 40), 2, model.BookingStatusCancelled)
+	deleted := createTestBooking(t, repo, uuid.NewString(), otherID, userID, d.AddDate(0, 0, 50), 2, model.BookingStatusConfirmed)
+	_, err := db.Exec(ctx, `UPDATE bookings SET deleted_at = NOW() WHERE id = $1`, deleted.ID)
+	require.NoError(t, err)
+
+	counts, err := repo.CountUpcomingConfirmed(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, model.UpcomingBookingCounts{AsGuest: 2, AsHost: 1}, *counts)
+
+	counts, err = repo.CountUpcomingConfirmed(ctx, uuid.NewString())
+	require.NoError(t, err)
+	assert.Equal(t, model.UpcomingBookingCounts{}, *counts)
+}
+
+func TestListByGuestIDAndHostID(t *testing.T) {
+	db := testDB(t)
+	repo := NewBookingRepository(db)
+	ctx := context.Background()
+	userID, otherID := uuid.NewString(), uuid.NewString()
+	d := model.Today()
+
+	firstStay := createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, -30), 2, model.BookingStatusCompleted)
+	secondStay := createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, 10), 2, model.BookingStatusCancelled)
+	hosted := createTestBooking(t, repo, uuid.NewString(), otherID, userID, d.AddDate(0, 0, 5), 2, model.BookingStatusPending)
+	deleted := createTestBooking(t, repo, uuid.NewString(), userID, otherID, d.AddDate(0, 0, 20), 2, model.BookingStatusConfirmed)
+	_, err := db.Exec(ctx, `UPDATE bookings SET deleted_at = NOW() WHERE id = $1`, deleted.ID)
+	require.NoError(t, err)
+
+	asGuest, err := repo.ListByGuestID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{secondStay.ID, firstStay.ID}, bookingIDs(asGuest), "newest first, in any status, without deleted ones")
+
+	asHost, err := repo.ListByHostID(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{hosted.ID}, bookingIDs(asHost))
+
+	none, err := repo.ListByGuestID(ctx, uuid.NewString())
+	require.NoError(t, err)
+	assert.NotNil(t, none, "an empty list, not null, in the export")
+	assert.Empty(t, none)
+}
@@ -37,3 +37,11 @@ func (s *BookingService) ListHostBookings(
 ) ([]model.Booking, error) {
 	return s.bookingRepo.ListByHostID(ctx, hostID)
 }
+
+// CountUpcomingBookings is used by the user service before deleting an account.
+func (s *BookingService) CountUpcomingBookings(
+	ctx context.Context,
+	userID string,
+) (*model.UpcomingBookingCounts, error) {
+	return s.bookingRepo.CountUpcomingConfirmed(ctx, userID)
+}
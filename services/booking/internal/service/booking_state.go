@@ -43,7 +43,14 @@ func (s *BookingService) RejectBooking(
 		return nil, model.ErrBookingNotPending
 	}
 
-	return s.bookingRepo.UpdateStatus(ctx, bookingID, model.BookingStatusRejected)
+	updated, err := s.bookingRepo.UpdateStatus(ctx, bookingID, model.BookingStatusRejected)
+	if err != nil {
+		return nil, err
+	}
+
+	s.releaseDates(ctx, updated)
+
+	return updated, nil
 }
 
 func (s *BookingService) CancelBooking(
@@ -63,5 +70,12 @@ func (s *BookingService) CancelBooking(
 		return nil, model.ErrBookingNotPending
 	}
 
-	return s.bookingRepo.UpdateStatus(ctx, bookingID, model.BookingStatusCancelled)
+	updated, err := s.bookingRepo.UpdateStatus(ctx, bookingID, model.BookingStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	s.releaseDates(ctx, updated)
+
+	return updated, nil
 }
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfirmBookingKeepsTheDatesHeld(t *testing.T) {
+	ctx := context.Background()
+	confirmed := testBooking()
+	confirmed.Status = model.BookingStatusConfirmed
+
+	m, service := newMocksAndService()
+	m.bookingRepo.On("FindByID", ctx, testBookingID).Return(testBooking(), nil)
+	m.bookingRepo.On("UpdateStatus", ctx, testBookingID, model.BookingStatusConfirmed).Return(confirmed, nil)
+
+	booking, err := service.ConfirmBooking(ctx, testBookingID, testHostID)
+	require.NoError(t, err)
+	assert.Equal(t, model.BookingStatusConfirmed, booking.Status)
+	m.listingClient.AssertNotCalled(t, "ReleaseDates", mock.Anything, mock.Anything, mock.Anything)
+	m.assertExpectations(t)
+}
+
+// TestEndingBookingReleasesTheDates checks that every way a booking stops
+// needing its nights gives them back to the listing calendar.
+func TestEndingBookingReleasesTheDates(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name       string
+		status     model.BookingStatus
+		releaseErr error
+		end        func(s *BookingService) (*model.Booking, error)
+	}{
+		{
+			name:   "host rejects",
+			status: model.BookingStatusRejected,
+			end: func(s *BookingService) (*model.Booking, error) {
+				return s.RejectBooking(ctx, testBookingID, testHostID)
+			},
+		},
+		{
+			name:   "guest cancels",
+			status: model.BookingStatusCancelled,
+			end: func(s *BookingService) (*model.Booking, error) {
+				return s.CancelBooking(ctx, testBookingID, testGuestID)
+			},
+		},
+		{
+			name:   "support cancels",
+			status: model.BookingStatusCancelled,
+			end: func(s *BookingService) (*model.Booking, error) {
+				return s.CancelBookingForSupport(ctx, testBookingID)
+			},
+		},
+		{
+			name:       "release failure does not undo the cancellation",
+			status:     model.BookingStatusCancelled,
+			releaseErr: model.ErrListingServiceUnavailable,
+			end: func(s *BookingService) (*model.Booking, error) {
+				return s.CancelBooking(ctx, testBookingID, testGuestID)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ended := testBooking()
+			ended.Status = tc.status
+
+			m, service := newMocksAndService()
+			m.bookingRepo.On("FindByID", ctx, testBookingID).Return(testBooking(), nil)
+			m.bookingRepo.On("UpdateStatus", ctx, testBookingID, tc.status).Return(ended, nil)
+			m.listingClient.On("ReleaseDates", mock.Anything, testListingID, testBookingID).Return(tc.releaseErr)
+
+			booking, err := tc.end(service)
+			require.NoError(t, err)
+			assert.Equal(t, tc.status, booking.Status)
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestRejectBookingOfAnotherHostKeepsTheDates(t *testing.T) {
+	ctx := context.Background()
+
+	m, service := newMocksAndService()
+	m.bookingRepo.On("FindByID", ctx, testBookingID).Return(testBooking(), nil)
+
+	_, err := service.RejectBooking(ctx, testBookingID, testGuestID)
+	require.ErrorIs(t, err, model.ErrNotBookingHost)
+	m.bookingRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+	m.listingClient.AssertNotCalled(t, "ReleaseDates", mock.Anything, mock.Anything, mock.Anything)
+}
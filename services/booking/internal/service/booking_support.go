@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+)
+
+// The methods below back the admin support endpoints. They skip the guest/host
+// checks; the router only lets users with the bookings:manage permission in.
+
+func (s *BookingService) GetBookingForSupport(
+	ctx context.Context,
+	bookingID string,
+) (*model.Booking, error) {
+	return s.bookingRepo.FindByID(ctx, bookingID)
+}
+
+// CancelBookingForSupport cancels a pending or confirmed booking on behalf of
+// the guest or host, e.g. when a host can no longer receive guests.
+func (s *BookingService) CancelBookingForSupport(
+	ctx context.Context,
+	bookingID string,
+) (*model.Booking, error) {
+	booking, err := s.bookingRepo.FindByID(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != model.BookingStatusPending &&
+		booking.Status != model.BookingStatusConfirmed {
+		return nil, model.ErrBookingNotCancellable
+	}
+
+	updated, err := s.bookingRepo.UpdateStatus(ctx, bookingID, model.BookingStatusCancelled)
+	if err != nil {
+		return nil, err
+	}
+
+	s.releaseDates(ctx, updated)
+
+	return updated, nil
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+)
+
+// SyncListingHolds makes the listing service hold the nights of every pending
+// or confirmed booking that has not ended, and free those of the others. It
+// fills the listing calendar for bookings made before holds existed, and
+// catches up on releases that failed. Every call is idempotent, so it is safe
+// to run at any time.
+func (s *BookingService) SyncListingHolds(ctx context.Context) (*model.HoldSyncResult, error) {
+	bookings, err := s.bookingRepo.ListNotEnded(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &model.HoldSyncResult{}
+	for i := range bookings {
+		b := &bookings[i]
+
+		if b.Status == model.BookingStatusPending || b.Status == model.BookingStatusConfirmed {
+			err = s.listingClient.HoldDates(ctx, b.ListingID, b.ID, b.CheckInDate, b.CheckOutDate)
+			if err != nil {
+				log.Printf("[ERROR] failed to hold dates of booking %s: %v", b.ID, err)
+				result.Failed++
+				continue
+			}
+			result.Held++
+			continue
+		}
+
+		if err = s.listingClient.ReleaseDates(ctx, b.ListingID, b.ID); err != nil {
+			log.Printf("[ERROR] failed to release dates of booking %s: %v", b.ID, err)
+			result.Failed++
+			continue
+		}
+		result.Released++
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSyncListingHolds(t *testing.T) {
+	ctx := context.Background()
+
+	pending := *testBooking()
+	confirmed := *testBooking()
+	confirmed.ID = "0190a0b0-0000-7000-8000-0000000000c2"
+	confirmed.Status = model.BookingStatusConfirmed
+	cancelled := *testBooking()
+	cancelled.ID = "0190a0b0-0000-7000-8000-0000000000c3"
+	cancelled.Status = model.BookingStatusCancelled
+	unreachable := *testBooking()
+	unreachable.ID = "0190a0b0-0000-7000-8000-0000000000c4"
+	unreachable.ListingID = "0190a0b0-0000-7000-8000-0000000000b2"
+
+	m, service := newMocksAndService()
+	m.bookingRepo.On("ListNotEnded", ctx).Return([]model.Booking{pending, confirmed, cancelled, unreachable}, nil)
+	for _, b := range []model.Booking{pending, confirmed} {
+		m.listingClient.On("HoldDates", ctx, b.ListingID, b.ID, b.CheckInDate, b.CheckOutDate).Return(nil)
+	}
+	m.listingClient.On("ReleaseDates", ctx, cancelled.ListingID, cancelled.ID).Return(nil)
+	m.listingClient.On("HoldDates", ctx, unreachable.ListingID, unreachable.ID, unreachable.CheckInDate, unreachable.CheckOutDate).
+		Return(model.ErrListingNotFound)
+
+	result, err := service.SyncListingHolds(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &model.HoldSyncResult{Held: 2, Released: 1, Failed: 1}, result)
+	m.assertExpectations(t)
+}
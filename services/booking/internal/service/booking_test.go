@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateBooking(t *testing.T) {
+	ctx := context.Background()
+	today := model.Today()
+	checkIn := today.AddDate(0, 0, 7)
+	checkOut := checkIn.AddDate(0, 0, 3)
+	listing := &ListingInfo{ID: testListingID, HostID: testHostID, PricePerNight: 500_000, Currency: "VND", Status: "active"}
+	dbErr := errors.New("connection reset")
+
+	isNewBooking := mock.MatchedBy(func(b model.Booking) bool {
+		return b.ID != "" && b.ListingID == testListingID && b.GuestID == testGuestID &&
+			b.HostID == testHostID && b.TotalNights == 3 && b.TotalPrice == 1_500_000 &&
+			b.Status == model.BookingStatusPending
+	})
+
+	testCases := []struct {
+		name        string
+		guestID     string
+		checkIn     time.Time
+		checkOut    time.Time
+		setupMocks  func(m *serviceMocks)
+		expectedErr error
+	}{
+		{
+			name:     "success holds the dates before saving",
+			guestID:  testGuestID,
+			checkIn:  checkIn,
+			checkOut: checkOut,
+			setupMocks: func(m *serviceMocks) {
+				m.listingClient.On("GetActiveListingByID", ctx, testListingID).Return(listing, nil)
+				held := m.listingClient.On("HoldDates", ctx, testListingID, mock.AnythingOfType("string"), checkIn, checkOut).Return(nil)
+				m.bookingRepo.On("Create", ctx, isNewBooking).Return(testBooking(), nil).NotBefore(held)
+			},
+		},
+		{
+			name:        "check-out not after check-in",
+			guestID:     testGuestID,
+			checkIn:     checkIn,
+			checkOut:    checkIn,
+			setupMocks:  func(m *serviceMocks) {},
+			expectedErr: model.ErrInvalidDateRange,
+		},
+		{
+			name:     "check-in today",
+			guestID:  testGuestID,
+			checkIn:  today,
+			checkOut: today.AddDate(0, 0, 1),
+			setupMocks: func(m *serviceMocks) {
+				m.listingClient.On("GetActiveListingByID", ctx, testListingID).Return(listing, nil)
+				m.listingClient.On("HoldDates", ctx, testListingID, mock.AnythingOfType("string"), today, today.AddDate(0, 0, 1)).Return(nil)
+				m.bookingRepo.On("Create", ctx, mock.AnythingOfType("model.Booking")).Return(testBooking(), nil)
+			},
+		},
+		{
+			name:        "check-in in the past",
+			guestID:     testGuestID,
+			checkIn:     today.AddDate(0, 0, -1),
+			checkOut:    checkOut,
+			setupMocks:  func(m *serviceMocks) {},
+			expectedErr: model.ErrCheckInPast,
+		},
+		{
+			name:     "host books their own listing",
+			guestID:  testHostID,
+			checkIn:  checkIn,
+			checkOut: checkOut,
+			setupMocks: func(m *serviceMocks) {
+				m.listingClient.On("GetActiveListingByID", ctx, testListingID).Return(listing, nil)
+			},
+			expectedErr: model.ErrSelfBooking,
+		},
+		{
+			name:     "dates held by another booking",
+			guestID:  testGuestID,
+			checkIn:  checkIn,
+			checkOut: checkOut,
+			setupMocks: func(m *serviceMocks) {
+				m.listingClient.On("GetActiveListingByID", ctx, testListingID).Return(listing, nil)
+				m.listingClient.On("HoldDates", ctx, testListingID, mock.AnythingOfType("string"), checkIn, checkOut).
+					Return(model.ErrDatesUnavailable)
+			},
+			expectedErr: model.ErrDatesUnavailable,
+		},
+		{
+			name:     "listing service down",
+			guestID:  testGuestID,
+			checkIn:  checkIn,
+			checkOut: checkOut,
+			setupMocks: func(m *serviceMocks) {
+				m.listingClient.On("GetActiveListingByID", ctx, testListingID).Return(nil, model.ErrListingServiceUnavailable)
+			},
+			expectedErr: model.ErrListingServiceUnavailable,
+		},
+		{
+			name:     "failed insert releases the dates",
+			guestID:  testGuestID,
+			checkIn:  checkIn,
+			checkOut: checkOut,
+			setupMocks: func(m *serviceMocks) {
+				m.listingClient.On("GetActiveListingByID", ctx, testListingID).Return(listing, nil)
+				m.listingClient.On("HoldDates", ctx, testListingID, mock.AnythingOfType("string"), checkIn, checkOut).Return(nil)
+				m.bookingRepo.On("Create", ctx, isNewBooking).Return(nil, dbErr)
+				m.listingClient.On("ReleaseDates", mock.Anything, testListingID, mock.AnythingOfType("string")).Return(nil)
+			},
+			expectedErr: dbErr,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMocks(m)
+
+			booking, err := service.CreateBooking(ctx, model.CreateBookingParams{
+				ListingID:    testListingID,
+				GuestID:      tc.guestID,
+				CheckInDate:  tc.checkIn,
+				CheckOutDate: tc.checkOut,
+			})
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, booking)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, booking)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestCreateBookingReleasesTheHeldBooking(t *testing.T) {
+	ctx := context.Background()
+	booking := testBooking()
+
+	m, service := newMocksAndService()
+	m.listingClient.On("GetActiveListingByID", ctx, testListingID).
+		Return(&ListingInfo{ID: testListingID, HostID: testHostID, PricePerNight: 500_000, Currency: "VND"}, nil)
+
+	var heldID string
+	m.listingClient.On("HoldDates", ctx, testListingID, mock.AnythingOfType("string"), booking.CheckInDate, booking.CheckOutDate).
+		Run(func(args mock.Arguments) { heldID = args.String(2) }).
+		Return(nil)
+	m.bookingRepo.On("Create", ctx, mock.Anything).Return(nil, model.ErrDatesUnavailable)
+	m.listingClient.On("ReleaseDates", mock.Anything, testListingID, mock.AnythingOfType("string")).Return(nil)
+
+	_, err := service.CreateBooking(ctx, model.CreateBookingParams{
+		ListingID:    testListingID,
+		GuestID:      testGuestID,
+		CheckInDate:  booking.CheckInDate,
+		CheckOutDate: booking.CheckOutDate,
+	})
+	require.ErrorIs(t, err, model.ErrDatesUnavailable)
+
+	m.listingClient.AssertCalled(t, "ReleaseDates", mock.Anything, testListingID, heldID)
+	created := m.bookingRepo.Calls[0].Arguments.Get(1).(model.Booking)
+	assert.Equal(t, heldID, created.ID)
+}
@@ -0,0 +1,106 @@
+// mock_test.go
+// =============================================================================
+// File này chứa các MOCK OBJECTS thay thế dependencies thật của BookingService:
+// database (BookingRepository) và Listing Service được gọi qua HTTP.
+//
+// Cách dùng giống bên user service:
+//   - On(methodName, args...).Return(values...) để setup expectation
+//   - AssertExpectations(t) để verify các method đã được gọi đúng
+// =============================================================================
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockBookingRepository là bản giả của BookingRepository.
+type MockBookingRepository struct {
+	mock.Mock
+}
+
+// bookingOrError trả về booking đã setup, hoặc nil nếu test muốn trả về error.
+func bookingOrError(args mock.Arguments) (*model.Booking, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepository) Create(ctx context.Context, booking model.Booking) (*model.Booking, error) {
+	return bookingOrError(m.Called(ctx, booking))
+}
+
+func (m *MockBookingRepository) FindByID(ctx context.Context, id string) (*model.Booking, error) {
+	return bookingOrError(m.Called(ctx, id))
+}
+
+func (m *MockBookingRepository) UpdateStatus(ctx context.Context, id string, status model.BookingStatus) (*model.Booking, error) {
+	return bookingOrError(m.Called(ctx, id, status))
+}
+
+func (m *MockBookingRepository) ListByGuestID(ctx context.Context, guestID string) ([]model.Booking, error) {
+	args := m.Called(ctx, guestID)
+
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepository) ListByHostID(ctx context.Context, hostID string) ([]model.Booking, error) {
+	args := m.Called(ctx, hostID)
+
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+func (m *MockBookingRepository) CountUpcomingConfirmed(ctx context.Context, userID string) (*model.UpcomingBookingCounts, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UpcomingBookingCounts), args.Error(1)
+}
+
+func (m *MockBookingRepository) ListNotEnded(ctx context.Context) ([]model.Booking, error) {
+	args := m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.Booking), args.Error(1)
+}
+
+// MockListingClient giả lập HTTP client gọi sang Listing Service.
+type MockListingClient struct {
+	mock.Mock
+}
+
+func (m *MockListingClient) GetActiveListingByID(ctx context.Context, id string) (*ListingInfo, error) {
+	args := m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*ListingInfo), args.Error(1)
+}
+
+// HoldDates giả lập việc giữ các đêm của booking bên Listing Service.
+func (m *MockListingClient) HoldDates(ctx context.Context, listingID, bookingID string, checkIn, checkOut time.Time) error {
+	args := m.Called(ctx, listingID, bookingID, checkIn, checkOut)
+
+	return args.Error(0)
+}
+
+// ReleaseDates giả lập việc trả lại các đêm của booking cho Listing Service.
+func (m *MockListingClient) ReleaseDates(ctx context.Context, listingID, bookingID string) error {
+	args := m.Called(ctx, listingID, bookingID)
+
+	return args.Error(0)
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
 )
@@ -12,10 +13,24 @@ type ListingInfo struct {
 	PricePerNight int64
 	Currency      string
 	Status        string
+
+	// Capacity and rooms are nil for listings published before the listing
+	// service recorded them.
+	PropertyType *string
+	MaxGuests    *int32
+	Bedrooms     *int32
+	Beds         *int32
+	Bathrooms    *int32
 }
 
 type ListingClient interface {
 	GetActiveListingByID(ctx context.Context, id string) (*ListingInfo, error)
+
+	// HoldDates takes the nights of a booking in the listing service, which
+	// searches and host calendars read. It fails with ErrDatesUnavailable when
+	// another booking already holds one of them.
+	HoldDates(ctx context.Context, listingID, bookingID string, checkIn, checkOut time.Time) error
+	ReleaseDates(ctx context.Context, listingID, bookingID string) error
 }
 
 type BookingRepository interface {
@@ -24,6 +39,8 @@ type BookingRepository interface {
 	UpdateStatus(ctx context.Context, id string, status model.BookingStatus) (*model.Booking, error)
 	ListByGuestID(ctx context.Context, guestID string) ([]model.Booking, error)
 	ListByHostID(ctx context.Context, hostID string) ([]model.Booking, error)
+	CountUpcomingConfirmed(ctx context.Context, userID string) (*model.UpcomingBookingCounts, error)
+	ListNotEnded(ctx context.Context) ([]model.Booking, error)
 }
 
 type BookingService struct {
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/booking/internal/model"
+)
+
+const (
+	testGuestID   = "0190a0b0-0000-7000-8000-0000000000a1"
+	testHostID    = "0190a0b0-0000-7000-8000-0000000000a2"
+	testListingID = "0190a0b0-0000-7000-8000-0000000000b1"
+	testBookingID = "0190a0b0-0000-7000-8000-0000000000c1"
+)
+
+type serviceMocks struct {
+	bookingRepo   *MockBookingRepository
+	listingClient *MockListingClient
+}
+
+func newMocksAndService() (*serviceMocks, *BookingService) {
+	m := &serviceMocks{
+		bookingRepo:   new(MockBookingRepository),
+		listingClient: new(MockListingClient),
+	}
+	service := NewBookingService(m.bookingRepo, m.listingClient)
+
+	return m, service
+}
+
+func (m *serviceMocks) assertExpectations(t *testing.T) {
+	t.Helper()
+
+	m.bookingRepo.AssertExpectations(t)
+	m.listingClient.AssertExpectations(t)
+}
+
+// testBooking returns a pending booking of testGuestID at testHostID's listing,
+// a week from now for three nights.
+func testBooking() *model.Booking {
+	checkIn := model.Today().AddDate(0, 0, 7)
+	return &model.Booking{
+		ID:            testBookingID,
+		ListingID:     testListingID,
+		GuestID:       testGuestID,
+		HostID:        testHostID,
+		CheckInDate:   checkIn,
+		CheckOutDate:  checkIn.AddDate(0, 0, 3),
+		TotalNights:   3,
+		PricePerNight: 500_000,
+		TotalPrice:    1_500_000,
+		Currency:      "VND",
+		Status:        model.BookingStatusPending,
+	}
+}
@@ -12,9 +12,11 @@ import (
 	"github.com/katatrina/airbnb-clone/pkg/response"
 	"github.com/katatrina/airbnb-clone/pkg/token"
 	"github.com/katatrina/airbnb-clone/services/listing/config"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/client"
 	"github.com/katatrina/airbnb-clone/services/listing/internal/handler"
 	"github.com/katatrina/airbnb-clone/services/listing/internal/repository"
 	"github.com/katatrina/airbnb-clone/services/listing/internal/service"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -37,16 +39,62 @@ func main() {
 	}
 	log.Println("Connected to database successfully")
 
-	tokenMaker, err := token.NewJWTMaker([]byte(cfg.JWTSecret), cfg.JWTExpiry)
+	redisOpts, err := redis.ParseURL(cfg.RedisURL)
 	if err != nil {
-		log.Fatalf("Failed to create token maker: %v", err)
+		log.Fatalf("Failed to parse Redis URL: %v", err)
 	}
+	redisClient := redis.NewClient(redisOpts)
+	defer redisClient.Close()
+
+	if err = redisClient.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to ping Redis: %v", err)
+	}
+	log.Println("Connected to Redis successfully")
+
+	var tokenVerifier token.TokenVerifier
+	if cfg.JWKSURL != "" {
+		jwksCache := token.NewJWKSCache(cfg.JWKSURL, cfg.JWKSRefreshInterval)
+		if err = jwksCache.Refresh(ctx); err != nil {
+			// The user service may not be up yet; keys are fetched again on first use
+			log.Printf("[WARN] Failed to prefetch JWKS: %v", err)
+		}
+		tokenVerifier = token.NewJWKSVerifier(jwksCache)
+	} else {
+		tokenVerifier, err = token.NewJWTMaker([]byte(cfg.JWTSecret), cfg.JWTExpiry)
+		if err != nil {
+			log.Fatalf("Failed to create token maker: %v", err)
+		}
+	}
+
+	// Shared with the other services, so a logout is honored everywhere
+	revocationStore := token.NewRedisRevocationStore(redisClient, cfg.JWTExpiry)
+	authMiddleware := middleware.AuthMiddleware(tokenVerifier, revocationStore)
 
 	listingRepo := repository.NewListingRepository(db)
 	locationRepo := repository.NewLocationRepository(db)
-	listingService := service.NewListingService(listingRepo, locationRepo, tokenMaker)
+	availabilityRepo := repository.NewAvailabilityRepository(db)
+	userClient := client.NewUserClient(cfg.UserServiceURL, cfg.InternalAPIKey)
+	listingService := service.NewListingService(listingRepo, locationRepo, availabilityRepo, tokenVerifier, userClient, cfg.RequireVerifiedHost)
 	listingHandler := handler.NewListingHandler(listingService)
 
+	// Unverified hosts can still prepare drafts, they just cannot go live
+	requireVerifiedEmail := func(c *gin.Context) { c.Next() }
+	if cfg.RequireVerifiedEmail {
+		requireVerifiedEmail = middleware.RequireVerifiedEmail()
+	}
+
+	// Creating and (re)publishing listings is reserved for hosts
+	requireHost := middleware.RequireRole(middleware.RoleHost)
+
+	// Search is public and the most expensive query we serve
+	searchRateLimit := middleware.RateLimit(middleware.NewRedisRateLimitStore(redisClient), middleware.RateLimitPolicy{
+		Name:     "listings:search",
+		Strategy: middleware.SlidingWindow,
+		Limit:    120,
+		Window:   time.Minute,
+		Key:      middleware.RateLimitByIP,
+	})
+
 	router := gin.Default()
 
 	router.NoRoute(func(c *gin.Context) {
@@ -59,26 +107,50 @@ func main() {
 	{
 		public := v1.Group("")
 		{
-			public.GET("/listings", listingHandler.ListActiveListings)
+			public.GET("/listings", searchRateLimit, listingHandler.ListActiveListings)
+			public.GET("/listings/map", searchRateLimit, listingHandler.ListMapListings)
 			public.GET("/listings/:id", listingHandler.GetActiveListing)
+			public.GET("/hosts/:id/listings", listingHandler.ListActiveListingsByHost)
 			public.GET("/provinces", listingHandler.ListProvinces)
 			public.GET("/provinces/:code/districts", listingHandler.ListDistrictsByProvince)
 			public.GET("/districts/:code/wards", listingHandler.ListWardsByDistrict)
 		}
 
 		hostListings := v1.Group("/me/listings")
-		hostListings.Use(middleware.AuthMiddleware(tokenMaker))
+		hostListings.Use(authMiddleware)
 		{
-			hostListings.POST("", listingHandler.CreateListing)
+			hostListings.POST("", requireHost, listingHandler.CreateListing)
 			hostListings.GET("", listingHandler.ListHostListings)
 			hostListings.GET("/:id", listingHandler.GetHostListing)
 			hostListings.PATCH("/:id/basic-info", listingHandler.UpdateListingBasicInfo)
 			hostListings.PATCH("/:id/address", listingHandler.UpdateListingAddress)
+			hostListings.PATCH("/:id/details", listingHandler.UpdateListingDetails)
 			hostListings.DELETE("/:id", listingHandler.DeleteListing)
-			hostListings.POST("/:id/publish", listingHandler.PublishListing)
+			hostListings.POST("/:id/publish", requireHost, requireVerifiedEmail, listingHandler.PublishListing)
 			hostListings.POST("/:id/deactivate", listingHandler.DeactivateListing)
-			hostListings.POST("/:id/reactivate", listingHandler.ReactivateListing)
+			hostListings.POST("/:id/reactivate", requireHost, requireVerifiedEmail, listingHandler.ReactivateListing)
+			hostListings.GET("/:id/blocked-dates", listingHandler.ListBlockedDates)
+			hostListings.POST("/:id/blocked-dates", listingHandler.BlockDates)
+			hostListings.DELETE("/:id/blocked-dates/:blockedId", listingHandler.UnblockDates)
 		}
+
+		moderation := v1.Group("/admin/listings")
+		moderation.Use(authMiddleware, middleware.RequirePermission(middleware.PermissionModerateListings))
+		{
+			moderation.GET("", listingHandler.ListListingsForModeration)
+			moderation.GET("/:id", listingHandler.GetListingForModeration)
+			moderation.POST("/:id/suspend", listingHandler.SuspendListing)
+			moderation.DELETE("/:id", listingHandler.RemoveListing)
+		}
+	}
+
+	internal := router.Group("/internal/v1")
+	internal.Use(middleware.RequireInternalAPIKey(cfg.InternalAPIKey))
+	{
+		internal.GET("/hosts/:id/listings", listingHandler.GetHostListings)
+		internal.POST("/hosts/:id/listings/deactivate", listingHandler.DeactivateHostListings)
+		internal.PUT("/listings/:id/holds/:bookingId", listingHandler.HoldBookingDates)
+		internal.DELETE("/listings/:id/holds/:bookingId", listingHandler.ReleaseBookingDates)
 	}
 
 	addr := fmt.Sprintf(":%s", cfg.ServerPort)
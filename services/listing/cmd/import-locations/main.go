@@ -42,6 +42,15 @@ type Province struct {
 	Districts    []District `json:"districts"`
 }
 
+// ProvinceBounds is the bounding box of a province in the bounds JSON file
+type ProvinceBounds struct {
+	Code         int32   `json:"code"`
+	MinLatitude  float64 `json:"min_latitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig(".env")
@@ -82,6 +91,18 @@ func main() {
 		log.Fatalf("Failed to import locations: %v", err)
 	}
 
+	// Province bounding boxes let listing coordinates be checked against
+	// their province instead of only against Vietnam
+	boundsFilePath := "cmd/import-locations/vn-province-bounds.json"
+	bounds, err := readProvinceBounds(boundsFilePath)
+	if err != nil {
+		log.Fatalf("Failed to read province bounds: %v", err)
+	}
+
+	if err := importProvinceBounds(ctx, dbPool, bounds); err != nil {
+		log.Fatalf("Failed to import province bounds: %v", err)
+	}
+
 	log.Println("Import completed successfully!")
 }
 
@@ -151,3 +172,43 @@ func importLocations(ctx context.Context, pool *pgxpool.Pool, provinces []Provin
 	log.Printf("Total imported: %d provinces, %d districts, %d wards", provinceCount, districtCount, wardCount)
 	return nil
 }
+
+func readProvinceBounds(path string) ([]ProvinceBounds, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file %s: %w", path, err)
+	}
+
+	var bounds []ProvinceBounds
+	if err := json.Unmarshal(data, &bounds); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON file %s: %w", path, err)
+	}
+
+	return bounds, nil
+}
+
+func importProvinceBounds(ctx context.Context, pool *pgxpool.Pool, bounds []ProvinceBounds) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, b := range bounds {
+		_, err := tx.Exec(ctx,
+			`UPDATE provinces
+			 SET min_latitude = $2, max_latitude = $3, min_longitude = $4, max_longitude = $5
+			 WHERE code = $1`,
+			b.Code, b.MinLatitude, b.MaxLatitude, b.MinLongitude, b.MaxLongitude)
+		if err != nil {
+			return fmt.Errorf("failed to update bounds of province %d: %w", b.Code, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	log.Printf("Imported bounding boxes of %d provinces", len(bounds))
+	return nil
+}
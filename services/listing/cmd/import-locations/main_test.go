@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvinceBoundsFile(t *testing.T) {
+	data, err := os.ReadFile("vn-provinces.json")
+	require.NoError(t, err)
+	var provinces []Province
+	require.NoError(t, json.Unmarshal(data, &provinces))
+
+	bounds, err := readProvinceBounds("vn-province-bounds.json")
+	require.NoError(t, err)
+
+	boxes := make(map[int32]model.BoundingBox, len(bounds))
+	for _, b := range bounds {
+		require.NotContains(t, boxes, b.Code, "province %d has two bounding boxes", b.Code)
+		boxes[b.Code] = model.BoundingBox{
+			MinLatitude:  b.MinLatitude,
+			MinLongitude: b.MinLongitude,
+			MaxLatitude:  b.MaxLatitude,
+			MaxLongitude: b.MaxLongitude,
+		}
+
+		assert.Less(t, b.MinLatitude, b.MaxLatitude, "province %d", b.Code)
+		assert.Less(t, b.MinLongitude, b.MaxLongitude, "province %d", b.Code)
+		assert.True(t, model.VietnamBounds.Contains(model.GeoPoint{Latitude: b.MinLatitude, Longitude: b.MinLongitude}), "province %d", b.Code)
+		assert.True(t, model.VietnamBounds.Contains(model.GeoPoint{Latitude: b.MaxLatitude, Longitude: b.MaxLongitude}), "province %d", b.Code)
+	}
+
+	require.Len(t, boxes, len(provinces), "every province needs a bounding box")
+	for _, p := range provinces {
+		assert.Contains(t, boxes, p.Code, "%s has no bounding box", p.Name)
+	}
+
+	// Well-known places must fall in their own province and not in a distant one
+	places := []struct {
+		name      string
+		point     model.GeoPoint
+		province  int32
+		elsewhere int32
+	}{
+		{"Hoàn Kiếm Lake, Hà Nội", model.GeoPoint{Latitude: 21.0285, Longitude: 105.8542}, 1, 79},
+		{"Sa Pa", model.GeoPoint{Latitude: 22.3364, Longitude: 103.8438}, 10, 1},
+		{"Hạ Long", model.GeoPoint{Latitude: 20.9599, Longitude: 107.0425}, 22, 1},
+		{"Cát Bà", model.GeoPoint{Latitude: 20.7270, Longitude: 107.0480}, 31, 34},
+		{"Huế citadel", model.GeoPoint{Latitude: 16.4698, Longitude: 107.5786}, 46, 48},
+		{"Mỹ Khê beach, Đà Nẵng", model.GeoPoint{Latitude: 16.0544, Longitude: 108.2470}, 48, 51},
+		{"Hội An", model.GeoPoint{Latitude: 15.8801, Longitude: 108.3380}, 49, 1},
+		{"Quy Nhơn", model.GeoPoint{Latitude: 13.7820, Longitude: 109.2190}, 52, 79},
+		{"Nha Trang", model.GeoPoint{Latitude: 12.2388, Longitude: 109.1967}, 56, 68},
+		{"Đà Lạt", model.GeoPoint{Latitude: 11.9404, Longitude: 108.4583}, 68, 56},
+		{"Mũi Né", model.GeoPoint{Latitude: 10.9333, Longitude: 108.2833}, 60, 79},
+		{"Bến Thành market, Hồ Chí Minh", model.GeoPoint{Latitude: 10.7725, Longitude: 106.6980}, 79, 1},
+		{"Vũng Tàu", model.GeoPoint{Latitude: 10.3460, Longitude: 107.0843}, 77, 1},
+		{"Côn Đảo", model.GeoPoint{Latitude: 8.6833, Longitude: 106.6000}, 77, 79},
+		{"Ninh Kiều wharf, Cần Thơ", model.GeoPoint{Latitude: 10.0340, Longitude: 105.7880}, 92, 1},
+		{"Phú Quốc", model.GeoPoint{Latitude: 10.2899, Longitude: 103.9840}, 91, 89},
+	}
+
+	for _, place := range places {
+		assert.True(t, boxes[place.province].Contains(place.point), "%s should be in province %d", place.name, place.province)
+		assert.False(t, boxes[place.elsewhere].Contains(place.point), "%s should not be in province %d", place.name, place.elsewhere)
+	}
+}
@@ -10,8 +10,25 @@ import (
 type Config struct {
 	ServerPort  string        `mapstructure:"SERVER_PORT"`
 	DatabaseURL string        `mapstructure:"DATABASE_URL"`
+	RedisURL    string        `mapstructure:"REDIS_URL"`
 	JWTSecret   string        `mapstructure:"JWT_SECRET"`
 	JWTExpiry   time.Duration `mapstructure:"JWT_EXPIRY"`
+
+	// JWKSURL makes the service verify tokens with the user service's public
+	// keys instead of the shared JWT_SECRET.
+	JWKSURL             string        `mapstructure:"JWKS_URL"`
+	JWKSRefreshInterval time.Duration `mapstructure:"JWKS_REFRESH_INTERVAL"`
+
+	// RequireVerifiedEmail blocks publishing listings until the user has verified their email.
+	RequireVerifiedEmail bool `mapstructure:"REQUIRE_VERIFIED_EMAIL"`
+
+	// RequireVerifiedHost blocks publishing listings until the host has passed
+	// identity verification, which is checked with the user service.
+	RequireVerifiedHost bool   `mapstructure:"REQUIRE_VERIFIED_HOST"`
+	UserServiceURL      string `mapstructure:"USER_SERVICE_URL"`
+
+	// InternalAPIKey authenticates calls from other services to /internal endpoints.
+	InternalAPIKey string `mapstructure:"INTERNAL_API_KEY"`
 }
 
 // Validate checks that all required configuration is present.
@@ -22,8 +39,17 @@ func (c *Config) Validate() error {
 	if c.DatabaseURL == "" {
 		return errors.New("DATABASE_URL is required")
 	}
-	if c.JWTSecret == "" {
-		return errors.New("JWT_SECRET is required")
+	if c.RedisURL == "" {
+		return errors.New("REDIS_URL is required")
+	}
+	if c.JWTSecret == "" && c.JWKSURL == "" {
+		return errors.New("JWT_SECRET or JWKS_URL is required")
+	}
+	if c.InternalAPIKey == "" {
+		return errors.New("INTERNAL_API_KEY is required")
+	}
+	if c.RequireVerifiedHost && c.UserServiceURL == "" {
+		return errors.New("USER_SERVICE_URL is required when REQUIRE_VERIFIED_HOST is set")
 	}
 
 	return nil
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+type UserClient struct {
+	baseURL        string
+	internalAPIKey string
+	httpClient     *http.Client
+}
+
+func NewUserClient(baseURL, internalAPIKey string) *UserClient {
+	return &UserClient{
+		baseURL:        baseURL,
+		internalAPIKey: internalAPIKey,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+type hostVerificationAPIResponse struct {
+	Success bool `json:"success"`
+	Data    *struct {
+		UserID string `json:"userId"`
+		Status string `json:"status"`
+	} `json:"data"`
+}
+
+// IsHostVerified reports whether the host passed identity verification. An
+// unknown host is not verified.
+func (c *UserClient) IsHostVerified(ctx context.Context, hostID string) (bool, error) {
+	url := fmt.Sprintf("%s/internal/v1/users/%s/host-verification", c.baseURL, hostID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return false, model.ErrUserServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return false, model.ErrUserServiceUnavailable
+	}
+
+	var apiResp hostVerificationAPIResponse
+	if err = json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return false, fmt.Errorf("failed to decode host verification response: %w", err)
+	}
+
+	return apiResp.Data != nil && apiResp.Data.Status == "verified", nil
+}
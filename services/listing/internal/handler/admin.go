@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// ListListingsForModeration lists listings of any host by status (?status=, default active).
+func (h *ListingHandler) ListListingsForModeration(c *gin.Context) {
+	status := model.ListingStatus(c.DefaultQuery("status", string(model.ListingStatusActive)))
+	switch status {
+	case model.ListingStatusDraft, model.ListingStatusActive, model.ListingStatusInactive:
+	default:
+		response.BadRequest(c, response.CodeValidationFailed,
+			"status must be one of: draft, active, inactive")
+		return
+	}
+
+	paginationParams := request.ParsePaginationParams(c)
+
+	listings, total, err := h.listingService.ListListingsByStatus(
+		c.Request.Context(),
+		status,
+		paginationParams.Limit(),
+		paginationParams.Offset(),
+	)
+	if err != nil {
+		log.Printf("[ERROR] failed to list listings for moderation: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OKWithPagination(c, NewListingsResponse(listings), "", paginationParams.Page, paginationParams.PageSize, total)
+}
+
+func (h *ListingHandler) GetListingForModeration(c *gin.Context) {
+	listingID := c.Param("id")
+
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	listing, err := h.listingService.GetListingByID(c.Request.Context(), listingID)
+	if err != nil {
+		if errors.Is(err, model.ErrListingNotFound) {
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+			return
+		}
+
+		log.Printf("[ERROR] failed to get listing for moderation: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, NewListingResponse(listing), "")
+}
+
+func (h *ListingHandler) SuspendListing(c *gin.Context) {
+	listingID := c.Param("id")
+
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	listing, err := h.listingService.SuspendListing(c.Request.Context(), listingID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrListingNotFound):
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+		case errors.Is(err, model.ErrListingNotActive):
+			response.BadRequest(c, response.CodeListingNotActive, "Only active listings can be suspended")
+		default:
+			log.Printf("[ERROR] failed to suspend listing: %v", err)
+			response.InternalServerError(c)
+		}
+
+		return
+	}
+
+	response.OK(c, NewListingResponse(listing), "Listing suspended successfully")
+}
+
+func (h *ListingHandler) RemoveListing(c *gin.Context) {
+	listingID := c.Param("id")
+
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	err := h.listingService.RemoveListing(c.Request.Context(), listingID)
+	if err != nil {
+		if errors.Is(err, model.ErrListingNotFound) {
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+			return
+		}
+
+		log.Printf("[ERROR] failed to remove listing: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
@@ -0,0 +1,118 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// ListBlockedDates returns the dates a host has closed their listing for that
+// have not passed yet.
+func (h *ListingHandler) ListBlockedDates(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+	listingID := c.Param("id")
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	blocked, err := h.listingService.ListBlockedDates(c.Request.Context(), listingID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrListingNotFound), errors.Is(err, model.ErrListingOwnerMismatch):
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+		default:
+			log.Printf("[ERROR] failed to list blocked dates: %v", err)
+			response.InternalServerError(c)
+		}
+
+		return
+	}
+
+	response.OK(c, NewBlockedDatesListResponse(blocked), "")
+}
+
+// BlockDates closes a listing for some dates, whatever its status, so it
+// cannot be found or booked for a stay overlapping them. Booked dates cannot
+// be blocked.
+func (h *ListingHandler) BlockDates(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+	listingID := c.Param("id")
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	var req BlockDatesRequest
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	// Already validated as YYYY-MM-DD
+	startDate, _ := time.Parse(dateLayout, req.StartDate)
+	endDate, _ := time.Parse(dateLayout, req.EndDate)
+
+	blocked, err := h.listingService.BlockDates(c.Request.Context(), listingID, userID, startDate, endDate)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidDateRange):
+			response.BadRequest(c, response.CodeValidationFailed, "End date must be after start date")
+		case errors.Is(err, model.ErrBlockedDatesTooLong):
+			response.BadRequest(c, response.CodeValidationFailed, "Dates can be blocked for at most 365 nights at once")
+		case errors.Is(err, model.ErrStartDatePast):
+			response.BadRequest(c, response.CodeValidationFailed, "Start date cannot be in the past")
+		case errors.Is(err, model.ErrListingNotFound), errors.Is(err, model.ErrListingOwnerMismatch):
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+		case errors.Is(err, model.ErrDatesUnavailable):
+			response.Conflict(c, response.CodeDatesUnavailable, "Some of these dates are booked or already blocked")
+		default:
+			log.Printf("[ERROR] failed to block dates: %v", err)
+			response.InternalServerError(c)
+		}
+
+		return
+	}
+
+	response.Created(c, NewBlockedDatesResponse(blocked), "Dates blocked successfully")
+}
+
+// UnblockDates opens the dates of a block again.
+func (h *ListingHandler) UnblockDates(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+	listingID := c.Param("id")
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	blockedID := c.Param("blockedId")
+	if _, err := uuid.Parse(blockedID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid blocked dates ID format")
+		return
+	}
+
+	err := h.listingService.UnblockDates(c.Request.Context(), listingID, userID, blockedID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrListingNotFound), errors.Is(err, model.ErrListingOwnerMismatch):
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+		case errors.Is(err, model.ErrBlockedDatesNotFound):
+			response.NotFound(c, response.CodeBlockedDatesNotFound, "Blocked dates not found")
+		default:
+			log.Printf("[ERROR] failed to unblock dates: %v", err)
+			response.InternalServerError(c)
+		}
+
+		return
+	}
+
+	response.NoContent(c)
+}
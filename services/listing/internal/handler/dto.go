@@ -10,6 +10,10 @@ type CreateListingRequest struct {
 	DistrictCode  int32  `json:"districtCode" validate:"required"`
 	WardCode      int32  `json:"wardCode" validate:"required"`
 	AddressDetail string `json:"addressDetail" validate:"required,min=10,max=500" normalize:"trim,singlespace"`
+
+	// Optional map position; both or neither, inside the province.
+	Latitude  *float64 `json:"latitude" validate:"required_with=Longitude,omitnil,latitude"`
+	Longitude *float64 `json:"longitude" validate:"required_with=Latitude,omitnil,longitude"`
 }
 
 type UpdateListingBasicInfoRequest struct {
@@ -18,29 +22,95 @@ type UpdateListingBasicInfoRequest struct {
 	PricePerNight *int64  `json:"pricePerNight" validate:"omitnil,gte=1"`
 }
 
+type UpdateListingDetailsRequest struct {
+	PropertyType *string `json:"propertyType" validate:"omitnil,oneof=apartment house homestay villa room"`
+	MaxGuests    *int32  `json:"maxGuests" validate:"omitnil,gte=1,lte=50"`
+	Bedrooms     *int32  `json:"bedrooms" validate:"omitnil,gte=0,lte=50"`
+	Beds         *int32  `json:"beds" validate:"omitnil,gte=1,lte=50"`
+	Bathrooms    *int32  `json:"bathrooms" validate:"omitnil,gte=0,lte=50"`
+}
+
 type UpdateListingAddressRequest struct {
-	ProvinceCode  *int32  `json:"provinceCode" validate:"required_with=DistrictCode WardCode"`
-	DistrictCode  *int32  `json:"districtCode" validate:"required_with=ProvinceCode WardCode"`
-	WardCode      *int32  `json:"wardCode" validate:"required_with=ProvinceCode DistrictCode"`
-	AddressDetail *string `json:"addressDetail" validate:"omitnil,min=10,max=500" normalize:"trim,singlespace"`
+	ProvinceCode  *int32   `json:"provinceCode" validate:"required_with=DistrictCode WardCode"`
+	DistrictCode  *int32   `json:"districtCode" validate:"required_with=ProvinceCode WardCode"`
+	WardCode      *int32   `json:"wardCode" validate:"required_with=ProvinceCode DistrictCode"`
+	AddressDetail *string  `json:"addressDetail" validate:"omitnil,min=10,max=500" normalize:"trim,singlespace"`
+	Latitude      *float64 `json:"latitude" validate:"required_with=Longitude,omitnil,latitude"`
+	Longitude     *float64 `json:"longitude" validate:"required_with=Latitude,omitnil,longitude"`
+}
+
+// SearchListingsRequest holds the query parameters of the public listing search.
+type SearchListingsRequest struct {
+	ProvinceCode *int32 `form:"provinceCode" validate:"omitnil,gte=1"`
+	DistrictCode *int32 `form:"districtCode" validate:"omitnil,gte=1"`
+	WardCode     *int32 `form:"wardCode" validate:"omitnil,gte=1"`
+	MinPrice     *int64 `form:"minPrice" validate:"omitnil,gte=0"`
+	MaxPrice     *int64 `form:"maxPrice" validate:"omitnil,gte=0"`
+
+	// Near is "lat,lng"; RadiusKm defaults to 10 and needs Near.
+	Near     string   `form:"near" normalize:"trim"`
+	RadiusKm *float64 `form:"radiusKm" validate:"omitnil,gt=0,lte=100"`
+
+	// CheckIn and CheckOut are YYYY-MM-DD and leave out listings booked for
+	// any night in between.
+	CheckIn  string `form:"checkIn" validate:"required_with=CheckOut,omitempty,datetime=2006-01-02"`
+	CheckOut string `form:"checkOut" validate:"required_with=CheckIn,omitempty,datetime=2006-01-02"`
+
+	// Guests keeps listings that fit at least that many people.
+	Guests *int32 `form:"guests" validate:"omitnil,gte=1,lte=50"`
+
+	// Full-text query, e.g. "da lat" or "villa -pool"; diacritics are optional.
+	Keyword string `form:"q" validate:"omitempty,max=100" normalize:"trim,singlespace"`
+
+	// Defaults to relevance when searching by keyword, else distance when
+	// searching near a point, else newest.
+	Sort string `form:"sort" validate:"omitempty,oneof=relevance distance newest price_asc price_desc"`
+}
+
+// MapListingsRequest holds the query parameters of the map search.
+type MapListingsRequest struct {
+	// BBox is the visible area as "minLat,minLng,maxLat,maxLng".
+	BBox     string `form:"bbox" validate:"required" normalize:"trim"`
+	MinPrice *int64 `form:"minPrice" validate:"omitnil,gte=0"`
+	MaxPrice *int64 `form:"maxPrice" validate:"omitnil,gte=0"`
+}
+
+// BlockDatesRequest closes a listing from StartDate up to, not including,
+// EndDate, both YYYY-MM-DD.
+type BlockDatesRequest struct {
+	StartDate string `json:"startDate" validate:"required,datetime=2006-01-02"`
+	EndDate   string `json:"endDate" validate:"required,datetime=2006-01-02"`
+}
+
+// HoldDatesRequest is the stay the booking service holds for a booking.
+type HoldDatesRequest struct {
+	CheckIn  string `json:"checkIn" validate:"required,datetime=2006-01-02"`
+	CheckOut string `json:"checkOut" validate:"required,datetime=2006-01-02"`
 }
 
 type ListingResponse struct {
-	ID            string `json:"id"`
-	Title         string `json:"title"`
-	Description   string `json:"description"`
-	PricePerNight int64  `json:"pricePerNight"`
-	Currency      string `json:"currency"`
-	ProvinceCode  int32  `json:"provinceCode"`
-	ProvinceName  string `json:"provinceName"`
-	DistrictCode  int32  `json:"districtCode"`
-	DistrictName  string `json:"districtName"`
-	WardCode      int32  `json:"wardCode"`
-	WardName      string `json:"wardName"`
-	AddressDetail string `json:"addressDetail"`
-	Status        string `json:"status"`
-	CreatedAt     int64  `json:"createdAt"`
-	UpdatedAt     int64  `json:"updatedAt"`
+	ID            string   `json:"id"`
+	Title         string   `json:"title"`
+	Description   string   `json:"description"`
+	PricePerNight int64    `json:"pricePerNight"`
+	Currency      string   `json:"currency"`
+	ProvinceCode  int32    `json:"provinceCode"`
+	ProvinceName  string   `json:"provinceName"`
+	DistrictCode  int32    `json:"districtCode"`
+	DistrictName  string   `json:"districtName"`
+	WardCode      int32    `json:"wardCode"`
+	WardName      string   `json:"wardName"`
+	AddressDetail string   `json:"addressDetail"`
+	Latitude      *float64 `json:"latitude"`
+	Longitude     *float64 `json:"longitude"`
+	PropertyType  *string  `json:"propertyType"`
+	MaxGuests     *int32   `json:"maxGuests"`
+	Bedrooms      *int32   `json:"bedrooms"`
+	Beds          *int32   `json:"beds"`
+	Bathrooms     *int32   `json:"bathrooms"`
+	Status        string   `json:"status"`
+	CreatedAt     int64    `json:"createdAt"`
+	UpdatedAt     int64    `json:"updatedAt"`
 }
 
 type ProvinceResponse struct {
@@ -74,34 +144,135 @@ func NewListingResponse(listing *model.Listing) *ListingResponse {
 		WardCode:      listing.WardCode,
 		WardName:      listing.WardName,
 		AddressDetail: listing.AddressDetail,
+		Latitude:      listing.Latitude,
+		Longitude:     listing.Longitude,
+		PropertyType:  (*string)(listing.PropertyType),
+		MaxGuests:     listing.MaxGuests,
+		Bedrooms:      listing.Bedrooms,
+		Beds:          listing.Beds,
+		Bathrooms:     listing.Bathrooms,
 		Status:        string(listing.Status),
 		CreatedAt:     listing.CreatedAt.Unix(),
 		UpdatedAt:     listing.UpdatedAt.Unix(),
 	}
 }
 
-func NewListingsResponse(listings []model.Listing) []ListingResponse {
-	resp := make([]ListingResponse, len(listings))
-	for i := range listings {
-		l := &listings[i]
+type BookingHoldResponse struct {
+	ID        string `json:"id"`
+	ListingID string `json:"listingId"`
+	BookingID string `json:"bookingId"`
+	CheckIn   string `json:"checkIn"`
+	CheckOut  string `json:"checkOut"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+func NewBookingHoldResponse(hold *model.BookingHold) *BookingHoldResponse {
+	return &BookingHoldResponse{
+		ID:        hold.ID,
+		ListingID: hold.ListingID,
+		BookingID: hold.BookingID,
+		CheckIn:   hold.StartDate.Format(dateLayout),
+		CheckOut:  hold.EndDate.Format(dateLayout),
+		CreatedAt: hold.CreatedAt.Unix(),
+	}
+}
+
+// ListingSearchResultResponse is a listing found by the public search. The
+// highlights are HTML with the matched words wrapped in <mark>, only present
+// when searching by keyword.
+type ListingSearchResultResponse struct {
+	ListingResponse
+
+	TitleHighlight *string  `json:"titleHighlight,omitempty"`
+	Snippet        *string  `json:"snippet,omitempty"`
+	DistanceKm     *float64 `json:"distanceKm,omitempty"`
+}
+
+// ListingMapPinResponse is the little a map needs to draw a listing.
+type ListingMapPinResponse struct {
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	PricePerNight int64   `json:"pricePerNight"`
+	Currency      string  `json:"currency"`
+	Latitude      float64 `json:"latitude"`
+	Longitude     float64 `json:"longitude"`
+}
+
+// ListingMapResponse holds the pins of a map search. Total counts every
+// listing in the area; when it exceeds the number of pins, the client should
+// zoom in.
+type ListingMapResponse struct {
+	Pins  []ListingMapPinResponse `json:"pins"`
+	Total int64                   `json:"total"`
+}
 
-		resp[i] = ListingResponse{
+func NewListingMapResponse(results []model.ListingSearchResult, total int64) ListingMapResponse {
+	pins := make([]ListingMapPinResponse, 0, len(results))
+	for i := range results {
+		l := &results[i].Listing
+		if l.Latitude == nil || l.Longitude == nil {
+			continue
+		}
+
+		pins = append(pins, ListingMapPinResponse{
 			ID:            l.ID,
 			Title:         l.Title,
-			Description:   l.Description,
 			PricePerNight: l.PricePerNight,
 			Currency:      string(l.Currency),
-			ProvinceCode:  l.ProvinceCode,
-			ProvinceName:  l.ProvinceName,
-			DistrictCode:  l.DistrictCode,
-			DistrictName:  l.DistrictName,
-			WardCode:      l.WardCode,
-			WardName:      l.WardName,
-			AddressDetail: l.AddressDetail,
-			Status:        string(l.Status),
-			CreatedAt:     l.CreatedAt.Unix(),
-			UpdatedAt:     l.UpdatedAt.Unix(),
+			Latitude:      *l.Latitude,
+			Longitude:     *l.Longitude,
+		})
+	}
+	return ListingMapResponse{Pins: pins, Total: total}
+}
+
+func NewListingSearchResultsResponse(results []model.ListingSearchResult) []ListingSearchResultResponse {
+	resp := make([]ListingSearchResultResponse, len(results))
+	for i := range results {
+		r := &results[i]
+
+		resp[i] = ListingSearchResultResponse{
+			ListingResponse: *NewListingResponse(&r.Listing),
+			TitleHighlight:  r.TitleHighlight,
+			Snippet:         r.Snippet,
+			DistanceKm:      r.DistanceKm,
 		}
 	}
 	return resp
 }
+
+func NewListingsResponse(listings []model.Listing) []ListingResponse {
+	resp := make([]ListingResponse, len(listings))
+	for i := range listings {
+		resp[i] = *NewListingResponse(&listings[i])
+	}
+	return resp
+}
+
+type DeactivateHostListingsResponse struct {
+	Deactivated int64 `json:"deactivated"`
+}
+
+type BlockedDatesResponse struct {
+	ID        string `json:"id"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+func NewBlockedDatesResponse(b *model.BlockedDates) *BlockedDatesResponse {
+	return &BlockedDatesResponse{
+		ID:        b.ID,
+		StartDate: b.StartDate.Format(dateLayout),
+		EndDate:   b.EndDate.Format(dateLayout),
+		CreatedAt: b.CreatedAt.Unix(),
+	}
+}
+
+func NewBlockedDatesListResponse(blocked []model.BlockedDates) []BlockedDatesResponse {
+	resp := make([]BlockedDatesResponse, len(blocked))
+	for i := range blocked {
+		resp[i] = *NewBlockedDatesResponse(&blocked[i])
+	}
+	return resp
+}
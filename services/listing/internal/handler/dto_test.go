@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewListingsResponseMatchesNewListingResponse(t *testing.T) {
+	room := model.PropertyTypeRoom
+	maxGuests, bedrooms, beds, bathrooms := int32(2), int32(1), int32(1), int32(0)
+	now := time.Now()
+
+	listings := []model.Listing{
+		{
+			ID:            "0190a0b0-0000-7000-8000-000000000001",
+			Title:         "Phòng nhỏ phố cổ Hội An",
+			PricePerNight: 450_000,
+			Currency:      model.ListingCurrencyVND,
+			PropertyType:  &room,
+			MaxGuests:     &maxGuests,
+			Bedrooms:      &bedrooms,
+			Beds:          &beds,
+			Bathrooms:     &bathrooms,
+			Status:        model.ListingStatusDraft,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		},
+		{ID: "0190a0b0-0000-7000-8000-000000000002", Status: model.ListingStatusDraft},
+	}
+
+	resp := NewListingsResponse(listings)
+
+	assert.Len(t, resp, len(listings))
+	for i := range listings {
+		assert.Equal(t, *NewListingResponse(&listings[i]), resp[i])
+	}
+	assert.Equal(t, "room", *resp[0].PropertyType)
+	assert.EqualValues(t, 2, *resp[0].MaxGuests)
+	assert.EqualValues(t, 0, *resp[0].Bathrooms)
+}
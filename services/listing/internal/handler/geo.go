@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+const (
+	defaultRadiusKm = 10
+
+	// maxMapPins caps a map search; a crowded area needs zooming in anyway.
+	maxMapPins = 500
+)
+
+// newGeoPoint returns the point of a request's coordinates, nil when they
+// were not sent. Validation guarantees both or neither are set.
+func newGeoPoint(latitude, longitude *float64) *model.GeoPoint {
+	if latitude == nil || longitude == nil {
+		return nil
+	}
+
+	return &model.GeoPoint{Latitude: *latitude, Longitude: *longitude}
+}
+
+// parseGeoPoint parses "lat,lng".
+func parseGeoPoint(s string) (model.GeoPoint, error) {
+	values, err := parseCoordinates(s, 2)
+	if err != nil {
+		return model.GeoPoint{}, err
+	}
+
+	point := model.GeoPoint{Latitude: values[0], Longitude: values[1]}
+	if !validLatitude(point.Latitude) || !validLongitude(point.Longitude) {
+		return model.GeoPoint{}, errors.New("coordinates out of range")
+	}
+
+	return point, nil
+}
+
+// parseBoundingBox parses "minLat,minLng,maxLat,maxLng".
+func parseBoundingBox(s string) (model.BoundingBox, error) {
+	values, err := parseCoordinates(s, 4)
+	if err != nil {
+		return model.BoundingBox{}, err
+	}
+
+	box := model.BoundingBox{
+		MinLatitude:  values[0],
+		MinLongitude: values[1],
+		MaxLatitude:  values[2],
+		MaxLongitude: values[3],
+	}
+	if !validLatitude(box.MinLatitude) || !validLatitude(box.MaxLatitude) ||
+		!validLongitude(box.MinLongitude) || !validLongitude(box.MaxLongitude) {
+		return model.BoundingBox{}, errors.New("coordinates out of range")
+	}
+	if box.MinLatitude > box.MaxLatitude || box.MinLongitude > box.MaxLongitude {
+		return model.BoundingBox{}, errors.New("minimum is greater than maximum")
+	}
+
+	return box, nil
+}
+
+func parseCoordinates(s string, n int) ([]float64, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != n {
+		return nil, errors.New("wrong number of coordinates")
+	}
+
+	values := make([]float64, n)
+	for i, part := range parts {
+		v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+func validLatitude(v float64) bool {
+	return v >= -90 && v <= 90
+}
+
+func validLongitude(v float64) bool {
+	return v >= -180 && v <= 180
+}
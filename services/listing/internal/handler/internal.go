@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// DeactivateHostListings is called by the user service when a host deletes
+// their account, so none of their listings can be booked anymore.
+func (h *ListingHandler) DeactivateHostListings(c *gin.Context) {
+	hostID := c.Param("id")
+	if _, err := uuid.Parse(hostID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid host ID format")
+		return
+	}
+
+	deactivated, err := h.listingService.DeactivateHostListings(c.Request.Context(), hostID)
+	if err != nil {
+		log.Printf("[ERROR] failed to deactivate host listings: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, DeactivateHostListingsResponse{Deactivated: deactivated},
+		"Host listings deactivated successfully")
+}
+
+// GetHostListings returns every listing of a host, whatever its status.
+// The user service puts them into the host's personal data export.
+func (h *ListingHandler) GetHostListings(c *gin.Context) {
+	hostID := c.Param("id")
+	if _, err := uuid.Parse(hostID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid host ID format")
+		return
+	}
+
+	listings, err := h.listingService.ListHostListings(c.Request.Context(), hostID)
+	if err != nil {
+		log.Printf("[ERROR] failed to list host listings: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, NewListingsResponse(listings), "")
+}
+
+// HoldBookingDates is called by the booking service before it saves a
+// booking. Holds never overlap, so a 409 means the dates were just taken.
+func (h *ListingHandler) HoldBookingDates(c *gin.Context) {
+	listingID, bookingID, ok := bookingHoldParams(c)
+	if !ok {
+		return
+	}
+
+	var req HoldDatesRequest
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	stay := model.StayDates{}
+	stay.CheckIn, _ = time.Parse(dateLayout, req.CheckIn)
+	stay.CheckOut, _ = time.Parse(dateLayout, req.CheckOut)
+
+	hold, err := h.listingService.HoldBookingDates(c.Request.Context(), listingID, bookingID, stay)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrInvalidDateRange):
+			response.BadRequest(c, response.CodeValidationFailed, "checkOut must be after checkIn")
+		case errors.Is(err, model.ErrListingNotFound):
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+		case errors.Is(err, model.ErrDatesUnavailable),
+			errors.Is(err, model.ErrBookingHoldMismatch):
+			response.Conflict(c, response.CodeDatesUnavailable, "Selected dates are not available")
+		default:
+			log.Printf("[ERROR] failed to hold booking dates: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewBookingHoldResponse(hold), "")
+}
+
+// ReleaseBookingDates is called by the booking service when a booking is
+// rejected, cancelled or could not be saved, to make its nights bookable again.
+func (h *ListingHandler) ReleaseBookingDates(c *gin.Context) {
+	listingID, bookingID, ok := bookingHoldParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.listingService.ReleaseBookingDates(c.Request.Context(), listingID, bookingID); err != nil {
+		log.Printf("[ERROR] failed to release booking dates: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func bookingHoldParams(c *gin.Context) (listingID, bookingID string, ok bool) {
+	listingID = c.Param("id")
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return "", "", false
+	}
+
+	bookingID = c.Param("bookingId")
+	if _, err := uuid.Parse(bookingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid booking ID format")
+		return "", "", false
+	}
+
+	return listingID, bookingID, true
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHoldBookingDatesRejectsInvalidRequests(t *testing.T) {
+	const (
+		listingID = "0190a0b0-0000-7000-8000-0000000000b1"
+		bookingID = "0190a0b0-0000-7000-8000-0000000000d1"
+	)
+
+	testCases := []struct {
+		name      string
+		listingID string
+		bookingID string
+		body      string
+	}{
+		{name: "invalid listing ID", listingID: "abc", bookingID: bookingID, body: `{"checkIn":"2026-12-24","checkOut":"2026-12-27"}`},
+		{name: "invalid booking ID", listingID: listingID, bookingID: "abc", body: `{"checkIn":"2026-12-24","checkOut":"2026-12-27"}`},
+		{name: "missing check-out", listingID: listingID, bookingID: bookingID, body: `{"checkIn":"2026-12-24"}`},
+		{name: "malformed check-in", listingID: listingID, bookingID: bookingID, body: `{"checkIn":"24/12/2026","checkOut":"2026-12-27"}`},
+		{name: "check-out before check-in", listingID: listingID, bookingID: bookingID, body: `{"checkIn":"2026-12-27","checkOut":"2026-12-24"}`},
+	}
+
+	// Every request is rejected before the service touches a repository
+	h := NewListingHandler(service.NewListingService(nil, nil, nil, nil, nil, false))
+	router := gin.New()
+	router.PUT("/listings/:id/holds/:bookingId", h.HoldBookingDates)
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/listings/"+tc.listingID+"/holds/"+tc.bookingID, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			var body response.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, response.CodeValidationFailed, body.Code)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"errors"
 	"log"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -12,13 +13,63 @@ import (
 	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
 )
 
+const dateLayout = "2006-01-02"
+
 func (h *ListingHandler) ListActiveListings(c *gin.Context) {
 	paginationParams := request.ParsePaginationParams(c)
 
-	// TODO: Add filtering and searching
+	var req SearchListingsRequest
+	if err := request.ShouldBindQuery(c, &req); err != nil {
+		response.HandleQueryBindingError(c, err)
+		return
+	}
+
+	if !checkPriceRange(c, req.MinPrice, req.MaxPrice) {
+		return
+	}
+
+	filter := model.ListingFilter{
+		ProvinceCode: req.ProvinceCode,
+		DistrictCode: req.DistrictCode,
+		WardCode:     req.WardCode,
+		MinPrice:     req.MinPrice,
+		MaxPrice:     req.MaxPrice,
+		Guests:       req.Guests,
+		Keyword:      req.Keyword,
+		Sort:         model.ListingSort(req.Sort),
+	}
+
+	if req.Near != "" {
+		near, err := parseGeoPoint(req.Near)
+		if err != nil {
+			respondQueryFieldError(c, "near", req.Near, request.FieldCodeInvalidFormat,
+				"near must be a latitude and longitude, e.g. 11.9404,108.4583")
+			return
+		}
+		filter.Near = &near
+		filter.RadiusKm = defaultRadiusKm
+		if req.RadiusKm != nil {
+			filter.RadiusKm = *req.RadiusKm
+		}
+	} else if req.RadiusKm != nil {
+		respondQueryFieldError(c, "near", nil, request.FieldCodeRequired, "near is required when radiusKm is present")
+		return
+	} else if filter.Sort == model.ListingSortDistance {
+		respondQueryFieldError(c, "near", nil, request.FieldCodeRequired, "near is required when sorting by distance")
+		return
+	}
+
+	if req.CheckIn != "" {
+		stay, ok := parseStayDates(c, req.CheckIn, req.CheckOut)
+		if !ok {
+			return
+		}
+		filter.Stay = stay
+	}
 
 	listings, total, err := h.listingService.ListActiveListings(
 		c.Request.Context(),
+		filter,
 		paginationParams.Limit(),
 		paginationParams.Offset(),
 	)
@@ -28,6 +79,111 @@ func (h *ListingHandler) ListActiveListings(c *gin.Context) {
 		return
 	}
 
+	response.OKWithPagination(c, NewListingSearchResultsResponse(listings), "", paginationParams.Page, paginationParams.PageSize, total)
+}
+
+// ListMapListings returns pins for the active listings inside the visible
+// area of a map, newest first.
+func (h *ListingHandler) ListMapListings(c *gin.Context) {
+	var req MapListingsRequest
+	if err := request.ShouldBindQuery(c, &req); err != nil {
+		response.HandleQueryBindingError(c, err)
+		return
+	}
+
+	if !checkPriceRange(c, req.MinPrice, req.MaxPrice) {
+		return
+	}
+
+	bbox, err := parseBoundingBox(req.BBox)
+	if err != nil {
+		respondQueryFieldError(c, "bbox", req.BBox, request.FieldCodeInvalidFormat,
+			"bbox must be minLat,minLng,maxLat,maxLng, e.g. 11.90,108.40,11.98,108.50")
+		return
+	}
+
+	listings, total, err := h.listingService.ListActiveListings(
+		c.Request.Context(),
+		model.ListingFilter{
+			BBox:     &bbox,
+			MinPrice: req.MinPrice,
+			MaxPrice: req.MaxPrice,
+		},
+		maxMapPins,
+		0,
+	)
+	if err != nil {
+		log.Printf("[ERROR] failed to list listings on map: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, NewListingMapResponse(listings, total), "")
+}
+
+// checkPriceRange rejects a minPrice above maxPrice, which the validator
+// cannot express when either may be missing.
+func checkPriceRange(c *gin.Context, minPrice, maxPrice *int64) bool {
+	if minPrice != nil && maxPrice != nil && *minPrice > *maxPrice {
+		respondQueryFieldError(c, "maxPrice", *maxPrice, request.FieldCodeMinValue, "maxPrice must be at least minPrice")
+		return false
+	}
+
+	return true
+}
+
+// parseStayDates parses the dates of a search, already validated as
+// YYYY-MM-DD, and rejects stays that are empty or start in the past.
+func parseStayDates(c *gin.Context, checkIn, checkOut string) (*model.StayDates, bool) {
+	stay := &model.StayDates{}
+	stay.CheckIn, _ = time.Parse(dateLayout, checkIn)
+	stay.CheckOut, _ = time.Parse(dateLayout, checkOut)
+
+	if stay.CheckIn.Before(model.Today()) {
+		respondQueryFieldError(c, "checkIn", checkIn, request.FieldCodeMinValue, "checkIn cannot be in the past")
+		return nil, false
+	}
+	if !stay.CheckOut.After(stay.CheckIn) {
+		respondQueryFieldError(c, "checkOut", checkOut, request.FieldCodeMinValue, "checkOut must be after checkIn")
+		return nil, false
+	}
+
+	return stay, true
+}
+
+// respondQueryFieldError reports a query parameter that failed a check made
+// outside the validator, in the same shape as a validation error.
+func respondQueryFieldError(c *gin.Context, field string, value any, code request.FieldErrorCode, message string) {
+	response.BadRequestWithErrors(c, response.CodeValidationFailed, "Validation failed", []request.FieldError{{
+		Field:   field,
+		Value:   value,
+		Code:    code,
+		Message: message,
+	}})
+}
+
+func (h *ListingHandler) ListActiveListingsByHost(c *gin.Context) {
+	hostID := c.Param("id")
+
+	if _, err := uuid.Parse(hostID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid host ID format")
+		return
+	}
+
+	paginationParams := request.ParsePaginationParams(c)
+
+	listings, total, err := h.listingService.ListActiveListingsByHost(
+		c.Request.Context(),
+		hostID,
+		paginationParams.Limit(),
+		paginationParams.Offset(),
+	)
+	if err != nil {
+		log.Printf("[ERROR] failed to list active listings of host: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
 	response.OKWithPagination(c, NewListingsResponse(listings), "", paginationParams.Page, paginationParams.PageSize, total)
 }
 
@@ -73,6 +229,7 @@ func (h *ListingHandler) CreateListing(c *gin.Context) {
 		DistrictCode:  req.DistrictCode,
 		WardCode:      req.WardCode,
 		AddressDetail: req.AddressDetail,
+		Location:      newGeoPoint(req.Latitude, req.Longitude),
 	})
 	if err != nil {
 		switch {
@@ -86,6 +243,8 @@ func (h *ListingHandler) CreateListing(c *gin.Context) {
 			response.BadRequest(c, response.CodeReferenceInvalid, "District does not belong to province")
 		case errors.Is(err, model.ErrWardDistrictMismatch):
 			response.BadRequest(c, response.CodeReferenceInvalid, "Ward does not belong to district")
+		case errors.Is(err, model.ErrCoordinatesOutsideProvince):
+			response.BadRequest(c, response.CodeCoordinatesOutsideProvince, "Coordinates are outside the selected province")
 		default:
 			log.Printf("[ERROR] failed to create listing: %v", err)
 			response.InternalServerError(c)
@@ -132,6 +291,46 @@ func (h *ListingHandler) UpdateListingBasicInfo(c *gin.Context) {
 	response.OK(c, NewListingResponse(listing), "Listing basic info updated successfully")
 }
 
+// UpdateListingDetails sets the property type, capacity and rooms of a
+// listing. Fields left out keep their value.
+func (h *ListingHandler) UpdateListingDetails(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+	listingID := c.Param("id")
+	if _, err := uuid.Parse(listingID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid listing ID format")
+		return
+	}
+
+	var req UpdateListingDetailsRequest
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	listing, err := h.listingService.UpdateListingDetails(c.Request.Context(), listingID, userID, model.UpdateListingDetailsParams{
+		PropertyType: (*model.PropertyType)(req.PropertyType),
+		MaxGuests:    req.MaxGuests,
+		Bedrooms:     req.Bedrooms,
+		Beds:         req.Beds,
+		Bathrooms:    req.Bathrooms,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrListingNotFound), errors.Is(err, model.ErrListingOwnerMismatch):
+			response.NotFound(c, response.CodeListingNotFound, "Listing not found")
+		case errors.Is(err, model.ErrActiveListingCannotBeUpdated):
+			response.BadRequest(c, response.CodeActiveListingCannotBeUpdated, "Active listing cannot be updated")
+		default:
+			log.Printf("[ERROR] failed to update listing details: %v", err)
+			response.InternalServerError(c)
+		}
+
+		return
+	}
+
+	response.OK(c, NewListingResponse(listing), "Listing details updated successfully")
+}
+
 func (h *ListingHandler) UpdateListingAddress(c *gin.Context) {
 	userID := middleware.MustGetAuthUser(c).ID
 	listingID := c.Param("id")
@@ -153,6 +352,7 @@ func (h *ListingHandler) UpdateListingAddress(c *gin.Context) {
 		DistrictCode:  req.DistrictCode,
 		WardCode:      req.WardCode,
 		AddressDetail: req.AddressDetail,
+		Location:      newGeoPoint(req.Latitude, req.Longitude),
 	})
 	if err != nil {
 		switch {
@@ -170,6 +370,8 @@ func (h *ListingHandler) UpdateListingAddress(c *gin.Context) {
 			response.BadRequest(c, response.CodeReferenceInvalid, "District does not belong to province")
 		case errors.Is(err, model.ErrWardDistrictMismatch):
 			response.BadRequest(c, response.CodeReferenceInvalid, "Ward does not belong to district")
+		case errors.Is(err, model.ErrCoordinatesOutsideProvince):
+			response.BadRequest(c, response.CodeCoordinatesOutsideProvince, "Coordinates are outside the selected province")
 		default:
 			log.Printf("[ERROR] failed to update listing address: %v", err)
 			response.InternalServerError(c)
@@ -199,6 +401,10 @@ func (h *ListingHandler) PublishListing(c *gin.Context) {
 			response.BadRequest(c, response.CodeListingNotDraft, "Listing must be in draft status to publish")
 		case errors.As(err, &incompleteErr):
 			response.BadRequest(c, response.CodeListingIncomplete, incompleteErr.Error())
+		case errors.Is(err, model.ErrHostNotVerified):
+			response.Forbidden(c, response.CodeHostNotVerified, "Verify your identity before publishing listings")
+		case errors.Is(err, model.ErrUserServiceUnavailable):
+			response.ServiceUnavailable(c, "Unable to check your host verification right now, please try again later")
 		default:
 			log.Printf("[ERROR] failed to publish host listing: %v", err)
 			response.InternalServerError(c)
@@ -256,6 +462,10 @@ func (h *ListingHandler) ReactivateListing(c *gin.Context) {
 			response.BadRequest(c, response.CodeListingNotInactive, "Listing must be in inactive status to reactivate")
 		case errors.As(err, &incompleteErr):
 			response.BadRequest(c, response.CodeListingIncomplete, incompleteErr.Error())
+		case errors.Is(err, model.ErrHostNotVerified):
+			response.Forbidden(c, response.CodeHostNotVerified, "Verify your identity before publishing listings")
+		case errors.Is(err, model.ErrUserServiceUnavailable):
+			response.ServiceUnavailable(c, "Unable to check your host verification right now, please try again later")
 		default:
 			log.Printf("[ERROR] failed to reactivate host listing: %v", err)
 			response.InternalServerError(c)
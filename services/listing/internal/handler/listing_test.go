@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// searchListings runs a search against a handler without a service, so only
+// requests rejected before reaching the service can be made.
+func searchListings(t *testing.T, query string) (*httptest.ResponseRecorder, response.Response) {
+	t.Helper()
+
+	router := gin.New()
+	router.GET("/listings", NewListingHandler(nil).ListActiveListings)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/listings?"+query, nil))
+
+	var body response.Response
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	return rec, body
+}
+
+func TestListActiveListingsRejectsInvalidSearches(t *testing.T) {
+	testCases := []struct {
+		name      string
+		query     string
+		wantField string
+		wantCode  request.FieldErrorCode
+	}{
+		{name: "sort by distance without near", query: "sort=distance", wantField: "near", wantCode: request.FieldCodeRequired},
+		{name: "radius without near", query: "radiusKm=5", wantField: "near", wantCode: request.FieldCodeRequired},
+		{name: "malformed near", query: "near=somewhere", wantField: "near", wantCode: request.FieldCodeInvalidFormat},
+		{name: "min price above max price", query: "minPrice=500000&maxPrice=100000", wantField: "maxPrice", wantCode: request.FieldCodeMinValue},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec, body := searchListings(t, tc.query)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Equal(t, response.CodeValidationFailed, body.Code)
+			require.Len(t, body.Errors, 1)
+			assert.Equal(t, tc.wantField, body.Errors[0].Field)
+			assert.Equal(t, tc.wantCode, body.Errors[0].Code)
+		})
+	}
+}
+
+func TestParseStayDates(t *testing.T) {
+	today := model.Today()
+	day := func(offset int) string { return today.AddDate(0, 0, offset).Format(dateLayout) }
+
+	testCases := []struct {
+		name      string
+		checkIn   string
+		checkOut  string
+		wantField string
+	}{
+		{name: "tonight", checkIn: day(0), checkOut: day(1)},
+		{name: "a week later", checkIn: day(7), checkOut: day(10)},
+		{name: "check-in in the past", checkIn: day(-1), checkOut: day(2), wantField: "checkIn"},
+		{name: "check-out on check-in", checkIn: day(3), checkOut: day(3), wantField: "checkOut"},
+		{name: "check-out before check-in", checkIn: day(3), checkOut: day(2), wantField: "checkOut"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+
+			stay, ok := parseStayDates(c, tc.checkIn, tc.checkOut)
+
+			if tc.wantField == "" {
+				require.True(t, ok)
+				assert.Equal(t, tc.checkIn, stay.CheckIn.Format(dateLayout))
+				assert.Equal(t, tc.checkOut, stay.CheckOut.Format(dateLayout))
+				return
+			}
+
+			require.False(t, ok)
+			assert.Nil(t, stay)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+
+			var body response.Response
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			require.Len(t, body.Errors, 1)
+			assert.Equal(t, tc.wantField, body.Errors[0].Field)
+			assert.Equal(t, request.FieldCodeMinValue, body.Errors[0].Code)
+		})
+	}
+}
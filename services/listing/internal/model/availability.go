@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// MaxBlockedNights is the longest a host can block a listing at once. A
+// listing closed for longer should be deactivated instead.
+const MaxBlockedNights = 365
+
+// BookingHold keeps the nights of a pending or confirmed booking, from
+// StartDate up to, not including, EndDate, from being booked again.
+type BookingHold struct {
+	ID        string    `db:"id"`
+	ListingID string    `db:"listing_id"`
+	BookingID string    `db:"booking_id"`
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// BlockedDates closes a listing from StartDate up to, not including, EndDate,
+// at the host's request. Like a booking hold, it never overlaps another.
+type BlockedDates struct {
+	ID        string    `db:"id"`
+	ListingID string    `db:"listing_id"`
+	StartDate time.Time `db:"start_date"`
+	EndDate   time.Time `db:"end_date"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 type CreateListingParams struct {
 	HostID        string
 	Title         string
@@ -9,6 +11,9 @@ type CreateListingParams struct {
 	DistrictCode  int32
 	WardCode      int32
 	AddressDetail string
+
+	// Location is optional and has to lie in the province.
+	Location *GeoPoint
 }
 
 type UpdateListingBasicInfoParams struct {
@@ -17,6 +22,14 @@ type UpdateListingBasicInfoParams struct {
 	PricePerNight *int64
 }
 
+type UpdateListingDetailsParams struct {
+	PropertyType *PropertyType
+	MaxGuests    *int32
+	Bedrooms     *int32
+	Beds         *int32
+	Bathrooms    *int32
+}
+
 type UpdateListingAddressParams struct {
 	ListingID     string
 	HostID        string
@@ -27,4 +40,77 @@ type UpdateListingAddressParams struct {
 	WardCode      *int32
 	WardName      *string
 	AddressDetail *string
+
+	// Location replaces the coordinates when set.
+	Location *GeoPoint
+}
+
+// ListingSort is the order of search results.
+type ListingSort string
+
+const (
+	// ListingSortRelevance ranks keyword matches, best first. It is the default
+	// when searching by keyword and falls back to newest without one.
+	ListingSortRelevance ListingSort = "relevance"
+
+	// ListingSortDistance puts the closest listings first. It is the default
+	// when searching near a point without a keyword.
+	ListingSortDistance ListingSort = "distance"
+
+	ListingSortNewest    ListingSort = "newest"
+	ListingSortPriceAsc  ListingSort = "price_asc"
+	ListingSortPriceDesc ListingSort = "price_desc"
+)
+
+// ListingFilter narrows a listing search. Nil and empty fields match every
+// listing; the rest are combined with AND.
+type ListingFilter struct {
+	Status       ListingStatus
+	ProvinceCode *int32
+	DistrictCode *int32
+	WardCode     *int32
+
+	// MinPrice and MaxPrice bound the price per night, inclusive.
+	MinPrice *int64
+	MaxPrice *int64
+
+	// Near and RadiusKm keep listings within RadiusKm of a point; BBox keeps
+	// those inside an area. Listings without coordinates never match either.
+	Near     *GeoPoint
+	RadiusKm float64
+	BBox     *BoundingBox
+
+	// Stay keeps listings with no unavailable night during the stay.
+	Stay *StayDates
+
+	// Guests keeps listings that fit at least that many people.
+	Guests *int32
+
+	// Keyword is a full-text query over the title, location names and
+	// description, insensitive to Vietnamese diacritics.
+	Keyword string
+	Sort    ListingSort
+}
+
+// StayDates is a stay from the check-in date up to, not including, the
+// check-out date.
+type StayDates struct {
+	CheckIn  time.Time
+	CheckOut time.Time
+}
+
+// Vietnam is the time zone stay dates are counted in. It has no daylight
+// saving time, so a fixed offset avoids depending on the tz database.
+var Vietnam = time.FixedZone("ICT", 7*60*60)
+
+// Today returns the current date in Vietnam. See DateOf.
+func Today() time.Time {
+	return DateOf(time.Now())
+}
+
+// DateOf returns the calendar date of t in Vietnam, at midnight UTC like the
+// YYYY-MM-DD dates parsed from requests, so the two can be compared.
+func DateOf(t time.Time) time.Time {
+	year, month, day := t.In(Vietnam).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
 }
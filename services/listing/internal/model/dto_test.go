@@ -0,0 +1,46 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateOf(t *testing.T) {
+	testCases := []struct {
+		name string
+		t    time.Time
+		want time.Time
+	}{
+		{
+			name: "morning in Vietnam is still the previous day in UTC",
+			t:    time.Date(2026, 12, 23, 18, 30, 0, 0, time.UTC),
+			want: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "last minute of the day in Vietnam",
+			t:    time.Date(2026, 12, 24, 16, 59, 0, 0, time.UTC),
+			want: time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "midnight in Vietnam",
+			t:    time.Date(2026, 12, 24, 17, 0, 0, 0, time.UTC),
+			want: time.Date(2026, 12, 25, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "time in another zone",
+			t:    time.Date(2026, 12, 31, 20, 0, 0, 0, time.FixedZone("EST", -5*60*60)),
+			want: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := DateOf(tc.t)
+
+			assert.True(t, tc.want.Equal(got), "got %v", got)
+			assert.Equal(t, time.UTC, got.Location())
+		})
+	}
+}
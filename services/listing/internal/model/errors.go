@@ -17,11 +17,26 @@ var (
 	ErrActiveListingCannotBeUpdated = errors.New("active listing cannot be updated")
 	ErrListingHasActiveBookings     = errors.New("listing has active bookings")
 
+	ErrHostNotVerified        = errors.New("host identity is not verified")
+	ErrUserServiceUnavailable = errors.New("user service is unavailable")
+
+	ErrInvalidDateRange    = errors.New("end date must be after start date")
+	ErrDatesUnavailable    = errors.New("selected dates are not available")
+	ErrBookingHoldNotFound = errors.New("booking hold not found")
+	ErrBookingHoldExists   = errors.New("booking already holds dates")
+	ErrBookingHoldMismatch = errors.New("booking already holds other dates")
+
+	ErrStartDatePast        = errors.New("start date cannot be in the past")
+	ErrBlockedDatesTooLong  = errors.New("blocked dates cannot span more than a year")
+	ErrBlockedDatesNotFound = errors.New("blocked dates not found")
+
 	ErrProvinceCodeNotFound     = errors.New("province code not found")
 	ErrDistrictCodeNotFound     = errors.New("district code not found")
 	ErrWardCodeNotFound         = errors.New("ward code not found")
 	ErrDistrictProvinceMismatch = errors.New("district does not belong to the selected province")
 	ErrWardDistrictMismatch     = errors.New("ward does not belong to the selected district")
+
+	ErrCoordinatesOutsideProvince = errors.New("coordinates are outside the selected province")
 )
 
 type IncompleteListingError struct {
@@ -0,0 +1,29 @@
+package model
+
+// GeoPoint is a WGS 84 coordinate in degrees.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// BoundingBox is an area between two latitudes and two longitudes. Vietnam is
+// far from the antimeridian, so MinLongitude is always west of MaxLongitude.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// VietnamBounds covers the mainland and the offshore islands.
+var VietnamBounds = BoundingBox{
+	MinLatitude:  7.0,
+	MinLongitude: 102.0,
+	MaxLatitude:  23.5,
+	MaxLongitude: 118.0,
+}
+
+func (b BoundingBox) Contains(p GeoPoint) bool {
+	return p.Latitude >= b.MinLatitude && p.Latitude <= b.MaxLatitude &&
+		p.Longitude >= b.MinLongitude && p.Longitude <= b.MaxLongitude
+}
@@ -9,6 +9,7 @@ import (
 type (
 	ListingStatus   string
 	ListingCurrency string
+	PropertyType    string
 )
 
 const (
@@ -17,6 +18,12 @@ const (
 	ListingStatusInactive ListingStatus = "inactive"
 
 	ListingCurrencyVND ListingCurrency = "VND"
+
+	PropertyTypeApartment PropertyType = "apartment"
+	PropertyTypeHouse     PropertyType = "house"
+	PropertyTypeHomestay  PropertyType = "homestay"
+	PropertyTypeVilla     PropertyType = "villa"
+	PropertyTypeRoom      PropertyType = "room"
 )
 
 type Listing struct {
@@ -33,12 +40,32 @@ type Listing struct {
 	WardCode      int32           `db:"ward_code"`
 	WardName      string          `db:"ward_name"`
 	AddressDetail string          `db:"address_detail"`
+	Latitude      *float64        `db:"latitude"`
+	Longitude     *float64        `db:"longitude"`
+	PropertyType  *PropertyType   `db:"property_type"`
+	MaxGuests     *int32          `db:"max_guests"`
+	Bedrooms      *int32          `db:"bedrooms"`
+	Beds          *int32          `db:"beds"`
+	Bathrooms     *int32          `db:"bathrooms"`
 	Status        ListingStatus   `db:"status"`
 	CreatedAt     time.Time       `db:"created_at"`
 	UpdatedAt     time.Time       `db:"updated_at"`
 	DeletedAt     *time.Time      `db:"deleted_at"`
 }
 
+// ListingSearchResult is a listing found by a search. The highlights are only
+// set when searching by keyword: the title and matching fragments of the
+// description, HTML-escaped, with the matched words wrapped in <mark>.
+type ListingSearchResult struct {
+	Listing
+
+	TitleHighlight *string `db:"title_highlight"`
+	Snippet        *string `db:"snippet"`
+
+	// DistanceKm is the distance from the point searched near, if any.
+	DistanceKm *float64 `db:"distance_km"`
+}
+
 func (l *Listing) ValidateForPublish() error {
 	var missing []string
 
@@ -62,6 +89,26 @@ func (l *Listing) ValidateForPublish() error {
 		missing = append(missing, "addressDetail")
 	}
 
+	if l.PropertyType == nil {
+		missing = append(missing, "propertyType")
+	}
+
+	if l.MaxGuests == nil {
+		missing = append(missing, "maxGuests")
+	}
+
+	if l.Bedrooms == nil {
+		missing = append(missing, "bedrooms")
+	}
+
+	if l.Beds == nil {
+		missing = append(missing, "beds")
+	}
+
+	if l.Bathrooms == nil {
+		missing = append(missing, "bathrooms")
+	}
+
 	if len(missing) > 0 {
 		return &IncompleteListingError{MissingFields: missing}
 	}
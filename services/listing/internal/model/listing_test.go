@@ -0,0 +1,82 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// publishableListing returns a listing with every field publishing needs.
+func publishableListing() *Listing {
+	propertyType := PropertyTypeHomestay
+	maxGuests, bedrooms, beds, bathrooms := int32(4), int32(2), int32(3), int32(1)
+
+	return &Listing{
+		Title:         "Homestay gần hồ Xuân Hương",
+		Description:   strings.Repeat("Phòng sáng, yên tĩnh, đi bộ ra chợ đêm. ", 2),
+		PricePerNight: 800_000,
+		ProvinceCode:  68,
+		DistrictCode:  672,
+		WardCode:      24781,
+		AddressDetail: "12 Đường Trần Hưng Đạo",
+		PropertyType:  &propertyType,
+		MaxGuests:     &maxGuests,
+		Bedrooms:      &bedrooms,
+		Beds:          &beds,
+		Bathrooms:     &bathrooms,
+	}
+}
+
+func TestValidateForPublish(t *testing.T) {
+	testCases := []struct {
+		name        string
+		modify      func(l *Listing)
+		wantMissing []string
+	}{
+		{name: "complete", modify: func(l *Listing) {}},
+		{name: "no property type", modify: func(l *Listing) { l.PropertyType = nil }, wantMissing: []string{"propertyType"}},
+		{name: "no max guests", modify: func(l *Listing) { l.MaxGuests = nil }, wantMissing: []string{"maxGuests"}},
+		{name: "no bedrooms", modify: func(l *Listing) { l.Bedrooms = nil }, wantMissing: []string{"bedrooms"}},
+		{name: "no beds", modify: func(l *Listing) { l.Beds = nil }, wantMissing: []string{"beds"}},
+		{name: "no bathrooms", modify: func(l *Listing) { l.Bathrooms = nil }, wantMissing: []string{"bathrooms"}},
+		{
+			name: "zero bedrooms and bathrooms are set",
+			modify: func(l *Listing) {
+				zero := int32(0)
+				l.Bedrooms, l.Bathrooms = &zero, &zero
+			},
+		},
+		{
+			name: "listing from before details existed",
+			modify: func(l *Listing) {
+				l.PropertyType, l.MaxGuests, l.Bedrooms, l.Beds, l.Bathrooms = nil, nil, nil, nil, nil
+			},
+			wantMissing: []string{"propertyType", "maxGuests", "bedrooms", "beds", "bathrooms"},
+		},
+		{
+			name:        "short title with no details",
+			modify:      func(l *Listing) { l.Title = "Homestay"; l.Beds = nil },
+			wantMissing: []string{"title", "beds"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			listing := publishableListing()
+			tc.modify(listing)
+
+			err := listing.ValidateForPublish()
+
+			if tc.wantMissing == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			var incomplete *IncompleteListingError
+			require.ErrorAs(t, err, &incomplete)
+			assert.Equal(t, tc.wantMissing, incomplete.MissingFields)
+		})
+	}
+}
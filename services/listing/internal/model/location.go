@@ -8,6 +8,27 @@ type Province struct {
 	Code      int32     `db:"code"`
 	FullName  string    `db:"full_name"`
 	CreatedAt time.Time `db:"created_at"`
+
+	// Bounding box of the province, nil until imported.
+	MinLatitude  *float64 `db:"min_latitude"`
+	MaxLatitude  *float64 `db:"max_latitude"`
+	MinLongitude *float64 `db:"min_longitude"`
+	MaxLongitude *float64 `db:"max_longitude"`
+}
+
+// Contains reports whether the point lies in the province's bounding box. A
+// province without a known bounding box only has to be in Vietnam.
+func (p *Province) Contains(point GeoPoint) bool {
+	if p.MinLatitude == nil || p.MaxLatitude == nil || p.MinLongitude == nil || p.MaxLongitude == nil {
+		return VietnamBounds.Contains(point)
+	}
+
+	return BoundingBox{
+		MinLatitude:  *p.MinLatitude,
+		MinLongitude: *p.MinLongitude,
+		MaxLatitude:  *p.MaxLatitude,
+		MaxLongitude: *p.MaxLongitude,
+	}.Contains(point)
 }
 
 type District struct {
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProvinceContains(t *testing.T) {
+	lat := func(v float64) *float64 { return &v }
+
+	// Roughly Lâm Đồng
+	lamDong := &Province{
+		Code:         68,
+		FullName:     "Tỉnh Lâm Đồng",
+		MinLatitude:  lat(11.15),
+		MaxLatitude:  lat(12.42),
+		MinLongitude: lat(107.22),
+		MaxLongitude: lat(108.77),
+	}
+	unknownBounds := &Province{Code: 68, FullName: "Tỉnh Lâm Đồng"}
+
+	testCases := []struct {
+		name     string
+		province *Province
+		point    GeoPoint
+		want     bool
+	}{
+		{name: "inside the province", province: lamDong, point: GeoPoint{Latitude: 11.9404, Longitude: 108.4583}, want: true},
+		{name: "on the edge of the box", province: lamDong, point: GeoPoint{Latitude: 11.15, Longitude: 108.77}, want: true},
+		{name: "elsewhere in Vietnam", province: lamDong, point: GeoPoint{Latitude: 10.7725, Longitude: 106.6980}, want: false},
+		{name: "just north of the box", province: lamDong, point: GeoPoint{Latitude: 12.43, Longitude: 108.0}, want: false},
+		{name: "outside Vietnam", province: lamDong, point: GeoPoint{Latitude: 13.7563, Longitude: 100.5018}, want: false},
+		{name: "without bounds, anywhere in Vietnam", province: unknownBounds, point: GeoPoint{Latitude: 21.0285, Longitude: 105.8542}, want: true},
+		{name: "without bounds, outside Vietnam", province: unknownBounds, point: GeoPoint{Latitude: 13.7563, Longitude: 100.5018}, want: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.province.Contains(tc.point))
+		})
+	}
+}
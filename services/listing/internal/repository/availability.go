@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// CreateBookingHold saves the nights of a booking. It returns
+// ErrDatesUnavailable when another hold already covers one of them, and
+// ErrBookingHoldExists when the booking holds dates already.
+func (r *AvailabilityRepository) CreateBookingHold(ctx context.Context, hold model.BookingHold) (*model.BookingHold, error) {
+	query := `
+		INSERT INTO listing_unavailable_dates (
+			id, listing_id, booking_id, start_date, end_date, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+		ON CONFLICT (booking_id) DO NOTHING
+		RETURNING id, listing_id, booking_id, start_date, end_date, created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query,
+		hold.ID,
+		hold.ListingID,
+		hold.BookingID,
+		hold.StartDate,
+		hold.EndDate,
+		hold.CreatedAt,
+	)
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.BookingHold])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrBookingHoldExists
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23P01" &&
+				pgErr.ConstraintName == "no_overlapping_unavailable_dates" {
+				return nil, model.ErrDatesUnavailable
+			}
+		}
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *AvailabilityRepository) FindBookingHold(ctx context.Context, bookingID string) (*model.BookingHold, error) {
+	query := `
+		SELECT id, listing_id, booking_id, start_date, end_date, created_at
+		FROM listing_unavailable_dates
+		WHERE booking_id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, bookingID)
+	hold, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.BookingHold])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrBookingHoldNotFound
+		}
+		return nil, err
+	}
+
+	return &hold, nil
+}
+
+// DeleteBookingHold frees the nights of a booking. Deleting a hold that is
+// already gone is not an error, so the booking service can retry.
+func (r *AvailabilityRepository) DeleteBookingHold(ctx context.Context, listingID, bookingID string) error {
+	query := `
+		DELETE FROM listing_unavailable_dates
+		WHERE listing_id = $1 AND booking_id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, listingID, bookingID)
+	return err
+}
+
+// CreateBlockedDates closes a listing for some nights. It returns
+// ErrDatesUnavailable when a booking or another block already covers one of
+// them.
+func (r *AvailabilityRepository) CreateBlockedDates(ctx context.Context, blocked model.BlockedDates) (*model.BlockedDates, error) {
+	query := `
+		INSERT INTO listing_unavailable_dates (
+			id, listing_id, start_date, end_date, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5
+		)
+		RETURNING id, listing_id, start_date, end_date, created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query,
+		blocked.ID,
+		blocked.ListingID,
+		blocked.StartDate,
+		blocked.EndDate,
+		blocked.CreatedAt,
+	)
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.BlockedDates])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23P01" &&
+				pgErr.ConstraintName == "no_overlapping_unavailable_dates" {
+				return nil, model.ErrDatesUnavailable
+			}
+		}
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// ListBlockedDates returns the blocks of a listing ending after from,
+// earliest first.
+func (r *AvailabilityRepository) ListBlockedDates(ctx context.Context, listingID string, from time.Time) ([]model.BlockedDates, error) {
+	query := `
+		SELECT id, listing_id, start_date, end_date, created_at
+		FROM listing_unavailable_dates
+		WHERE listing_id = $1 AND booking_id IS NULL AND end_date > $2
+		ORDER BY start_date
+	`
+
+	rows, _ := r.db.Query(ctx, query, listingID, from)
+	blocked, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.BlockedDates])
+	if err != nil {
+		return nil, err
+	}
+
+	return blocked, nil
+}
+
+// DeleteBlockedDates opens the nights of a block again. Booking holds cannot
+// be deleted this way.
+func (r *AvailabilityRepository) DeleteBlockedDates(ctx context.Context, listingID, id string) error {
+	query := `
+		DELETE FROM listing_unavailable_dates
+		WHERE id = $1 AND listing_id = $2 AND booking_id IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id, listingID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrBlockedDatesNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDB connects to a database migrated up to the latest version. Set
+// LISTING_TEST_DATABASE_URL to run the tests that need it.
+func testDB(t *testing.T) *pgxpool.Pool {
+	t.Helper()
+
+	url := os.Getenv("LISTING_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("LISTING_TEST_DATABASE_URL is not set")
+	}
+
+	db, err := pgxpool.New(context.Background(), url)
+	require.NoError(t, err)
+	t.Cleanup(db.Close)
+	require.NoError(t, db.Ping(context.Background()))
+
+	return db
+}
+
+// createTestListing inserts an active listing, deleted with its holds at the
+// end of the test.
+func createTestListing(t *testing.T, db *pgxpool.Pool) string {
+	t.Helper()
+
+	id := uuid.NewString()
+	_, err := db.Exec(context.Background(), `
+		INSERT INTO listings (
+			id, host_id, title, price_per_night,
+			province_code, province_name, district_code, district_name,
+			ward_code, ward_name, address_detail, status
+		) VALUES ($1, $2, 'Test listing for holds', 500000, 1, 'P', 1, 'D', 1, 'W', 'Somewhere in town', 'active')
+	`, id, uuid.NewString())
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(context.Background(), `DELETE FROM listings WHERE id = $1`, id)
+	})
+
+	return id
+}
+
+func newTestHold(listingID string, start time.Time, nights int) model.BookingHold {
+	return model.BookingHold{
+		ID:        uuid.NewString(),
+		ListingID: listingID,
+		BookingID: uuid.NewString(),
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, nights),
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestBookingHolds(t *testing.T) {
+	db := testDB(t)
+	repo := NewAvailabilityRepository(db)
+	ctx := context.Background()
+	listingID := createTestListing(t, db)
+	christmas := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+
+	hold := newTestHold(listingID, christmas, 3)
+	created, err := repo.CreateBookingHold(ctx, hold)
+	require.NoError(t, err)
+	assert.Equal(t, hold.BookingID, created.BookingID)
+	assert.True(t, created.StartDate.Equal(hold.StartDate))
+
+	// A second hold of the same booking is reported, not stored
+	retry := hold
+	retry.ID = uuid.NewString()
+	_, err = repo.CreateBookingHold(ctx, retry)
+	require.ErrorIs(t, err, model.ErrBookingHoldExists)
+
+	found, err := repo.FindBookingHold(ctx, hold.BookingID)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+
+	// Another booking cannot take any of the held nights
+	_, err = repo.CreateBookingHold(ctx, newTestHold(listingID, christmas.AddDate(0, 0, 2), 2))
+	require.ErrorIs(t, err, model.ErrDatesUnavailable)
+
+	// The check-out day is free for the next guest
+	_, err = repo.CreateBookingHold(ctx, newTestHold(listingID, hold.EndDate, 2))
+	require.NoError(t, err)
+
+	require.NoError(t, repo.DeleteBookingHold(ctx, listingID, hold.BookingID))
+	require.NoError(t, repo.DeleteBookingHold(ctx, listingID, hold.BookingID))
+	_, err = repo.FindBookingHold(ctx, hold.BookingID)
+	require.ErrorIs(t, err, model.ErrBookingHoldNotFound)
+
+	// Once released, the nights can be held again
+	_, err = repo.CreateBookingHold(ctx, newTestHold(listingID, christmas, 3))
+	require.NoError(t, err)
+}
+
+func TestSearchLeavesOutHeldListings(t *testing.T) {
+	db := testDB(t)
+	listingRepo := NewListingRepository(db)
+	availabilityRepo := NewAvailabilityRepository(db)
+	ctx := context.Background()
+	listingID := createTestListing(t, db)
+	christmas := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+
+	_, err := availabilityRepo.CreateBookingHold(ctx, newTestHold(listingID, christmas, 3))
+	require.NoError(t, err)
+
+	isFound := func(checkIn time.Time, nights int) bool {
+		t.Helper()
+
+		results, err := listingRepo.ListByFilter(ctx, model.ListingFilter{
+			Status: model.ListingStatusActive,
+			Stay:   &model.StayDates{CheckIn: checkIn, CheckOut: checkIn.AddDate(0, 0, nights)},
+		}, 1000, 0)
+		require.NoError(t, err)
+
+		for _, r := range results {
+			if r.ID == listingID {
+				return true
+			}
+		}
+		return false
+	}
+
+	assert.False(t, isFound(christmas.AddDate(0, 0, 1), 1), "stay inside the hold")
+	assert.False(t, isFound(christmas.AddDate(0, 0, -2), 3), "stay ending inside the hold")
+	assert.True(t, isFound(christmas.AddDate(0, 0, -2), 2), "stay ending on the first held night")
+	assert.True(t, isFound(christmas.AddDate(0, 0, 3), 2), "stay starting on check-out day")
+}
+
+func TestBlockedDatesShareTheCalendarWithBookings(t *testing.T) {
+	db := testDB(t)
+	repo := NewAvailabilityRepository(db)
+	ctx := context.Background()
+	listingID := createTestListing(t, db)
+	christmas := time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC)
+
+	hold := newTestHold(listingID, christmas, 3)
+	_, err := repo.CreateBookingHold(ctx, hold)
+	require.NoError(t, err)
+
+	newBlock := func(start time.Time, nights int) model.BlockedDates {
+		return model.BlockedDates{
+			ID:        uuid.NewString(),
+			ListingID: listingID,
+			StartDate: start,
+			EndDate:   start.AddDate(0, 0, nights),
+			CreatedAt: time.Now(),
+		}
+	}
+
+	// A booked night cannot be blocked
+	_, err = repo.CreateBlockedDates(ctx, newBlock(christmas.AddDate(0, 0, 1), 5))
+	require.ErrorIs(t, err, model.ErrDatesUnavailable)
+
+	block, err := repo.CreateBlockedDates(ctx, newBlock(hold.EndDate, 5))
+	require.NoError(t, err)
+
+	// And a blocked night cannot be booked
+	_, err = repo.CreateBookingHold(ctx, newTestHold(listingID, hold.EndDate.AddDate(0, 0, 2), 1))
+	require.ErrorIs(t, err, model.ErrDatesUnavailable)
+
+	blocked, err := repo.ListBlockedDates(ctx, listingID, christmas)
+	require.NoError(t, err)
+	require.Len(t, blocked, 1, "booking holds are not listed as blocks")
+	assert.Equal(t, block.ID, blocked[0].ID)
+
+	// A booking hold cannot be unblocked by the host
+	holdRow, err := repo.FindBookingHold(ctx, hold.BookingID)
+	require.NoError(t, err)
+	require.ErrorIs(t, repo.DeleteBlockedDates(ctx, listingID, holdRow.ID), model.ErrBlockedDatesNotFound)
+
+	require.NoError(t, repo.DeleteBlockedDates(ctx, listingID, block.ID))
+	require.ErrorIs(t, repo.DeleteBlockedDates(ctx, listingID, block.ID), model.ErrBlockedDatesNotFound)
+}
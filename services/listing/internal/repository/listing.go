@@ -12,24 +12,18 @@ import (
 )
 
 func (r *ListingRepository) Create(ctx context.Context, listing model.Listing) (*model.Listing, error) {
-	query := `
+	query := fmt.Sprintf(`
 		INSERT INTO listings (
-			id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
+			%[1]s
 		) VALUES (
 			$1, $2, $3, $4, $5, $6,
 			$7, $8, $9, $10,
-			$11, $12, $13,
-			$14, $15, $16, $17
+			$11, $12, $13, $14, $15,
+			$16, $17, $18, $19, $20,
+			$21, $22, $23, $24
 		)
-		RETURNING
-			id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
-	`
+		RETURNING %[1]s
+	`, listingColumns)
 
 	rows, _ := r.db.Query(ctx, query,
 		listing.ID,
@@ -45,6 +39,13 @@ func (r *ListingRepository) Create(ctx context.Context, listing model.Listing) (
 		listing.WardCode,
 		listing.WardName,
 		listing.AddressDetail,
+		listing.Latitude,
+		listing.Longitude,
+		listing.PropertyType,
+		listing.MaxGuests,
+		listing.Bedrooms,
+		listing.Beds,
+		listing.Bathrooms,
 		listing.Status,
 		listing.CreatedAt,
 		listing.UpdatedAt,
@@ -59,15 +60,11 @@ func (r *ListingRepository) Create(ctx context.Context, listing model.Listing) (
 }
 
 func (r *ListingRepository) FindByID(ctx context.Context, id string) (*model.Listing, error) {
-	query := `
-		SELECT
-			id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM listings
 		WHERE id = $1 AND deleted_at IS NULL
-	`
+	`, listingColumns)
 
 	rows, _ := r.db.Query(ctx, query, id)
 	listing, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Listing])
@@ -87,17 +84,13 @@ func (r *ListingRepository) ListByStatus(
 	limit,
 	offset int,
 ) ([]model.Listing, error) {
-	query := `
-		SELECT
-			id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM listings
 		WHERE status = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
 		LIMIT $2 OFFSET $3
-	`
+	`, listingColumns)
 	rows, _ := r.db.Query(ctx, query, status, limit, offset)
 	listings, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Listing])
 	if err != nil {
@@ -126,17 +119,55 @@ func (r *ListingRepository) CountByStatus(
 	return count, nil
 }
 
+// ListByFilter returns one page of the listings matching filter, in the order
+// it asks for.
+func (r *ListingRepository) ListByFilter(ctx context.Context, filter model.ListingFilter, limit, offset int) ([]model.ListingSearchResult, error) {
+	q := newListingQuery(filter)
+
+	query := fmt.Sprintf(`
+		SELECT %s,
+			%s
+		FROM listings
+		%s
+		%s
+		LIMIT %s OFFSET %s
+	`, listingColumns, q.searchColumns(), q.whereClause(), q.orderBy(filter.Sort), q.arg(limit), q.arg(offset))
+
+	rows, _ := r.db.Query(ctx, query, q.args...)
+	listings, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.ListingSearchResult])
+	if err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+// CountByFilter counts every listing matching filter, for pagination.
+func (r *ListingRepository) CountByFilter(ctx context.Context, filter model.ListingFilter) (int64, error) {
+	q := newListingQuery(filter)
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM listings
+		%s
+	`, q.whereClause())
+
+	var count int64
+	err := r.db.QueryRow(ctx, query, q.args...).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func (r *ListingRepository) UpdateStatus(ctx context.Context, id string, status model.ListingStatus) (*model.Listing, error) {
-	query := `
+	query := fmt.Sprintf(`
 		UPDATE listings
 		SET status = $1, updated_at = NOW()
 		WHERE id = $2 AND deleted_at IS NULL
-		RETURNING
-			id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
-	`
+		RETURNING %s
+	`, listingColumns)
 
 	rows, _ := r.db.Query(ctx, query, status, id)
 	listing, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Listing])
@@ -187,11 +218,72 @@ func (r *ListingRepository) UpdateBasicInfo(ctx context.Context, id string, para
 		UPDATE listings
 		SET %s
 		WHERE id = $%d AND deleted_at IS NULL
-		RETURNING id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
-	`, strings.Join(setClauses, ", "), paramIndex)
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), paramIndex, listingColumns)
+
+	rows, _ := r.db.Query(ctx, query, args...)
+	listing, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Listing])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrListingNotFound
+		}
+		return nil, err
+	}
+
+	return &listing, nil
+}
+
+func (r *ListingRepository) UpdateDetails(ctx context.Context, id string, params model.UpdateListingDetailsParams) (*model.Listing, error) {
+	var setClauses []string
+	var args []interface{}
+	paramIndex := 1
+
+	if params.PropertyType != nil {
+		setClauses = append(setClauses, fmt.Sprintf("property_type = $%d", paramIndex))
+		args = append(args, *params.PropertyType)
+		paramIndex++
+	}
+
+	if params.MaxGuests != nil {
+		setClauses = append(setClauses, fmt.Sprintf("max_guests = $%d", paramIndex))
+		args = append(args, *params.MaxGuests)
+		paramIndex++
+	}
+
+	if params.Bedrooms != nil {
+		setClauses = append(setClauses, fmt.Sprintf("bedrooms = $%d", paramIndex))
+		args = append(args, *params.Bedrooms)
+		paramIndex++
+	}
+
+	if params.Beds != nil {
+		setClauses = append(setClauses, fmt.Sprintf("beds = $%d", paramIndex))
+		args = append(args, *params.Beds)
+		paramIndex++
+	}
+
+	if params.Bathrooms != nil {
+		setClauses = append(setClauses, fmt.Sprintf("bathrooms = $%d", paramIndex))
+		args = append(args, *params.Bathrooms)
+		paramIndex++
+	}
+
+	if len(setClauses) == 0 {
+		return nil, nil
+	}
+
+	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", paramIndex))
+	args = append(args, time.Now())
+	paramIndex++
+
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE listings
+		SET %s
+		WHERE id = $%d AND deleted_at IS NULL
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), paramIndex, listingColumns)
 
 	rows, _ := r.db.Query(ctx, query, args...)
 	listing, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Listing])
@@ -252,6 +344,12 @@ func (r *ListingRepository) UpdateAddress(ctx context.Context, params model.Upda
 		paramIndex++
 	}
 
+	if params.Location != nil {
+		setClauses = append(setClauses, fmt.Sprintf("latitude = $%d, longitude = $%d", paramIndex, paramIndex+1))
+		args = append(args, params.Location.Latitude, params.Location.Longitude)
+		paramIndex += 2
+	}
+
 	if len(setClauses) == 0 {
 		return nil, nil
 	}
@@ -266,11 +364,8 @@ func (r *ListingRepository) UpdateAddress(ctx context.Context, params model.Upda
 		UPDATE listings
 		SET %s
 		WHERE id = $%d AND deleted_at IS NULL
-		RETURNING id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
-	`, strings.Join(setClauses, ", "), paramIndex)
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), paramIndex, listingColumns)
 
 	rows, _ := r.db.Query(ctx, query, args...)
 	listing, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Listing])
@@ -285,15 +380,12 @@ func (r *ListingRepository) UpdateAddress(ctx context.Context, params model.Upda
 }
 
 func (r *ListingRepository) ListByHostID(ctx context.Context, hostID string) ([]model.Listing, error) {
-	query := `
-		SELECT id, host_id, title, description, price_per_night, currency,
-			province_code, province_name, district_code, district_name,
-			ward_code, ward_name, address_detail,
-			status, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM listings
 		WHERE host_id = $1 AND deleted_at IS NULL
 		ORDER BY created_at DESC
-	`
+	`, listingColumns)
 
 	rows, _ := r.db.Query(ctx, query, hostID)
 	listings, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Listing])
@@ -304,6 +396,67 @@ func (r *ListingRepository) ListByHostID(ctx context.Context, hostID string) ([]
 	return listings, nil
 }
 
+func (r *ListingRepository) ListByHostIDAndStatus(
+	ctx context.Context,
+	hostID string,
+	status model.ListingStatus,
+	limit,
+	offset int,
+) ([]model.Listing, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM listings
+		WHERE host_id = $1 AND status = $2 AND deleted_at IS NULL
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4
+	`, listingColumns)
+
+	rows, _ := r.db.Query(ctx, query, hostID, status, limit, offset)
+	listings, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Listing])
+	if err != nil {
+		return nil, err
+	}
+
+	return listings, nil
+}
+
+func (r *ListingRepository) CountByHostIDAndStatus(
+	ctx context.Context,
+	hostID string,
+	status model.ListingStatus,
+) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM listings
+		WHERE host_id = $1 AND status = $2 AND deleted_at IS NULL
+	`
+
+	var count int64
+	err := r.db.QueryRow(ctx, query, hostID, status).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeactivateAllByHostID moves every active listing of the host to inactive and
+// returns how many were changed. Drafts are left alone; they are not bookable.
+func (r *ListingRepository) DeactivateAllByHostID(ctx context.Context, hostID string) (int64, error) {
+	query := `
+		UPDATE listings
+		SET status = $1, updated_at = NOW()
+		WHERE host_id = $2 AND status = $3 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, model.ListingStatusInactive, hostID, model.ListingStatusActive)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected(), nil
+}
+
 func (r *ListingRepository) Delete(ctx context.Context, listingID string) error {
 	query := `
 		UPDATE listings
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// listingColumns lists the columns scanned into model.Listing.
+const listingColumns = `id, host_id, title, description, price_per_night, currency,
+			province_code, province_name, district_code, district_name,
+			ward_code, ward_name, address_detail, latitude, longitude,
+			property_type, max_guests, bedrooms, beds, bathrooms,
+			status, created_at, updated_at, deleted_at`
+
+// listingQuery builds the WHERE clause of a listing search one condition at a
+// time, numbering the placeholders as it goes, so the list and count queries
+// always apply the same filters.
+type listingQuery struct {
+	conditions []string
+	args       []any
+
+	// tsquery is the parsed keyword, empty when not searching by keyword.
+	tsquery string
+
+	// origin is the point searched near, empty when not searching near one.
+	origin string
+}
+
+func newListingQuery(filter model.ListingFilter) *listingQuery {
+	q := &listingQuery{conditions: []string{"deleted_at IS NULL"}}
+
+	if filter.Status != "" {
+		q.where("status = ?", filter.Status)
+	}
+	if filter.ProvinceCode != nil {
+		q.where("province_code = ?", *filter.ProvinceCode)
+	}
+	if filter.DistrictCode != nil {
+		q.where("district_code = ?", *filter.DistrictCode)
+	}
+	if filter.WardCode != nil {
+		q.where("ward_code = ?", *filter.WardCode)
+	}
+	if filter.MinPrice != nil {
+		q.where("price_per_night >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		q.where("price_per_night <= ?", *filter.MaxPrice)
+	}
+	if filter.BBox != nil {
+		q.where("latitude BETWEEN ? AND ?", filter.BBox.MinLatitude, filter.BBox.MaxLatitude)
+		q.where("longitude BETWEEN ? AND ?", filter.BBox.MinLongitude, filter.BBox.MaxLongitude)
+	}
+	if filter.Near != nil {
+		q.origin = fmt.Sprintf("ll_to_earth(%s, %s)", q.arg(filter.Near.Latitude), q.arg(filter.Near.Longitude))
+		radius := q.arg(filter.RadiusKm * 1000)
+		// The cube around the origin uses the index, the exact distance then
+		// trims its corners
+		q.conditions = append(q.conditions,
+			"latitude IS NOT NULL",
+			fmt.Sprintf("earth_box(%s, %s) @> ll_to_earth(latitude, longitude)", q.origin, radius),
+			fmt.Sprintf("earth_distance(%s, ll_to_earth(latitude, longitude)) <= %s", q.origin, radius),
+		)
+	}
+	if filter.Guests != nil {
+		q.where("max_guests >= ?", *filter.Guests)
+	}
+	if filter.Stay != nil {
+		// Written like the no_overlapping_unavailable_dates constraint so its
+		// index answers it
+		q.where("NOT EXISTS (SELECT 1 FROM listing_unavailable_dates u WHERE u.listing_id = listings.id AND daterange(u.start_date, u.end_date) && daterange(?, ?))",
+			filter.Stay.CheckIn, filter.Stay.CheckOut)
+	}
+	if filter.Keyword != "" {
+		// websearch_to_tsquery never fails on user input, unlike to_tsquery
+		q.tsquery = fmt.Sprintf("websearch_to_tsquery('vietnamese', %s)", q.arg(filter.Keyword))
+		q.conditions = append(q.conditions, "search_vector @@ "+q.tsquery)
+	}
+
+	return q
+}
+
+// where adds a condition. Every "?" in cond is replaced by the placeholder of
+// the matching arg.
+func (q *listingQuery) where(cond string, args ...any) {
+	for _, arg := range args {
+		cond = strings.Replace(cond, "?", q.arg(arg), 1)
+	}
+	q.conditions = append(q.conditions, cond)
+}
+
+// arg appends a query argument and returns its placeholder.
+func (q *listingQuery) arg(v any) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *listingQuery) whereClause() string {
+	return "WHERE " + strings.Join(q.conditions, " AND ")
+}
+
+// searchColumns selects the fields ListingSearchResult adds to a listing. The
+// highlighted text is escaped before <mark> tags are added, so clients can
+// render it as HTML.
+func (q *listingQuery) searchColumns() string {
+	highlights := "NULL::text AS title_highlight, NULL::text AS snippet"
+	if q.tsquery != "" {
+		highlights = fmt.Sprintf(`ts_headline('vietnamese', %s, %s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight,
+			ts_headline('vietnamese', %s, %s, 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet`,
+			escapeHTML("title"), q.tsquery, escapeHTML("description"), q.tsquery)
+	}
+
+	distance := "NULL::float8 AS distance_km"
+	if q.origin != "" {
+		distance = q.distance() + " / 1000 AS distance_km"
+	}
+
+	return highlights + ", " + distance
+}
+
+// distance is the SQL expression of the distance from the origin, in meters.
+func (q *listingQuery) distance() string {
+	return fmt.Sprintf("earth_distance(%s, ll_to_earth(latitude, longitude))", q.origin)
+}
+
+// orderBy returns the ORDER BY clause of a sort. Without a sort, keyword
+// searches are ranked by relevance and searches near a point by distance. id
+// breaks ties so pages never overlap when listings share a rank, price or
+// creation time.
+func (q *listingQuery) orderBy(sort model.ListingSort) string {
+	if q.tsquery != "" && (sort == "" || sort == model.ListingSortRelevance) {
+		return fmt.Sprintf("ORDER BY ts_rank_cd(search_vector, %s) DESC, created_at DESC, id", q.tsquery)
+	}
+	if q.origin != "" && (sort == "" || sort == model.ListingSortDistance) {
+		return fmt.Sprintf("ORDER BY %s, created_at DESC, id", q.distance())
+	}
+
+	switch sort {
+	case model.ListingSortPriceAsc:
+		return "ORDER BY price_per_night ASC, created_at DESC, id"
+	case model.ListingSortPriceDesc:
+		return "ORDER BY price_per_night DESC, created_at DESC, id"
+	default:
+		return "ORDER BY created_at DESC, id"
+	}
+}
+
+// escapeHTML is the SQL expression escaping column for HTML text.
+func escapeHTML(column string) string {
+	return fmt.Sprintf("replace(replace(replace(%s, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')", column)
+}
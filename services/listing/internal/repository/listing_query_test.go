@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewListingQuery(t *testing.T) {
+	provinceCode := int32(79)
+	minPrice := int64(500_000)
+	maxPrice := int64(2_000_000)
+	guests := int32(4)
+	hanoi := &model.GeoPoint{Latitude: 21.0285, Longitude: 105.8542}
+	stay := &model.StayDates{
+		CheckIn:  time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC),
+		CheckOut: time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		name     string
+		filter   model.ListingFilter
+		wantSQL  string
+		wantArgs []any
+	}{
+		{
+			name:    "no filter",
+			filter:  model.ListingFilter{},
+			wantSQL: "WHERE deleted_at IS NULL",
+		},
+		{
+			name: "status, location and price",
+			filter: model.ListingFilter{
+				Status:       model.ListingStatusActive,
+				ProvinceCode: &provinceCode,
+				MinPrice:     &minPrice,
+				MaxPrice:     &maxPrice,
+			},
+			wantSQL: "WHERE deleted_at IS NULL AND status = $1 AND province_code = $2" +
+				" AND price_per_night >= $3 AND price_per_night <= $4",
+			wantArgs: []any{model.ListingStatusActive, provinceCode, minPrice, maxPrice},
+		},
+		{
+			name: "bounding box",
+			filter: model.ListingFilter{
+				BBox: &model.BoundingBox{MinLatitude: 10.7, MinLongitude: 106.6, MaxLatitude: 10.8, MaxLongitude: 106.7},
+			},
+			wantSQL:  "WHERE deleted_at IS NULL AND latitude BETWEEN $1 AND $2 AND longitude BETWEEN $3 AND $4",
+			wantArgs: []any{10.7, 10.8, 106.6, 106.7},
+		},
+		{
+			name:   "near a point",
+			filter: model.ListingFilter{Near: hanoi, RadiusKm: 5},
+			wantSQL: "WHERE deleted_at IS NULL AND latitude IS NOT NULL" +
+				" AND earth_box(ll_to_earth($1, $2), $3) @> ll_to_earth(latitude, longitude)" +
+				" AND earth_distance(ll_to_earth($1, $2), ll_to_earth(latitude, longitude)) <= $3",
+			wantArgs: []any{21.0285, 105.8542, 5000.0},
+		},
+		{
+			name:     "guests",
+			filter:   model.ListingFilter{Guests: &guests},
+			wantSQL:  "WHERE deleted_at IS NULL AND max_guests >= $1",
+			wantArgs: []any{guests},
+		},
+		{
+			name:   "stay",
+			filter: model.ListingFilter{Stay: stay},
+			wantSQL: "WHERE deleted_at IS NULL AND NOT EXISTS (SELECT 1 FROM listing_unavailable_dates u" +
+				" WHERE u.listing_id = listings.id AND daterange(u.start_date, u.end_date) && daterange($1, $2))",
+			wantArgs: []any{stay.CheckIn, stay.CheckOut},
+		},
+		{
+			name:     "keyword",
+			filter:   model.ListingFilter{Keyword: "nhà gần biển"},
+			wantSQL:  "WHERE deleted_at IS NULL AND search_vector @@ websearch_to_tsquery('vietnamese', $1)",
+			wantArgs: []any{"nhà gần biển"},
+		},
+		{
+			name: "every filter numbers its placeholders in order",
+			filter: model.ListingFilter{
+				Status:   model.ListingStatusActive,
+				MinPrice: &minPrice,
+				Near:     hanoi,
+				RadiusKm: 10,
+				Guests:   &guests,
+				Stay:     stay,
+				Keyword:  "homestay",
+			},
+			wantSQL: "WHERE deleted_at IS NULL AND status = $1 AND price_per_night >= $2" +
+				" AND latitude IS NOT NULL" +
+				" AND earth_box(ll_to_earth($3, $4), $5) @> ll_to_earth(latitude, longitude)" +
+				" AND earth_distance(ll_to_earth($3, $4), ll_to_earth(latitude, longitude)) <= $5" +
+				" AND max_guests >= $6 AND NOT EXISTS (SELECT 1 FROM listing_unavailable_dates u" +
+				" WHERE u.listing_id = listings.id AND daterange(u.start_date, u.end_date) && daterange($7, $8))" +
+				" AND search_vector @@ websearch_to_tsquery('vietnamese', $9)",
+			wantArgs: []any{model.ListingStatusActive, minPrice, 21.0285, 105.8542, 10000.0, guests, stay.CheckIn, stay.CheckOut, "homestay"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newListingQuery(tc.filter)
+
+			assert.Equal(t, tc.wantSQL, q.whereClause())
+			assert.Equal(t, tc.wantArgs, q.args)
+		})
+	}
+}
+
+func TestListingQueryPagingArgsFollowFilters(t *testing.T) {
+	guests := int32(2)
+	q := newListingQuery(model.ListingFilter{Guests: &guests, Keyword: "biển"})
+
+	// ListByFilter adds LIMIT and OFFSET after the filters
+	assert.Equal(t, "$3", q.arg(20))
+	assert.Equal(t, "$4", q.arg(40))
+	assert.Equal(t, []any{guests, "biển", 20, 40}, q.args)
+}
+
+func TestListingQuerySearchColumns(t *testing.T) {
+	t.Run("no keyword or origin", func(t *testing.T) {
+		q := newListingQuery(model.ListingFilter{})
+
+		assert.Equal(t, "NULL::text AS title_highlight, NULL::text AS snippet, NULL::float8 AS distance_km", q.searchColumns())
+	})
+
+	t.Run("keyword highlights escaped text", func(t *testing.T) {
+		q := newListingQuery(model.ListingFilter{Keyword: "biển"})
+		columns := q.searchColumns()
+
+		// Escaping happens before ts_headline adds the <mark> tags, so
+		// markup in a title is shown as text while the tags still work
+		escapedTitle := "replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+		escapedDescription := "replace(replace(replace(description, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')"
+		assert.Contains(t, columns, "ts_headline('vietnamese', "+escapedTitle+", websearch_to_tsquery('vietnamese', $1), 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true') AS title_highlight")
+		assert.Contains(t, columns, "ts_headline('vietnamese', "+escapedDescription+", websearch_to_tsquery('vietnamese', $1), 'StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet")
+		assert.NotContains(t, columns, "ts_headline('vietnamese', title,")
+		assert.Contains(t, columns, "NULL::float8 AS distance_km")
+	})
+
+	t.Run("origin adds the distance in kilometers", func(t *testing.T) {
+		q := newListingQuery(model.ListingFilter{Near: &model.GeoPoint{Latitude: 16.0544, Longitude: 108.2022}, RadiusKm: 3})
+
+		assert.Contains(t, q.searchColumns(), "earth_distance(ll_to_earth($1, $2), ll_to_earth(latitude, longitude)) / 1000 AS distance_km")
+	})
+}
+
+func TestEscapeHTML(t *testing.T) {
+	// & goes first, otherwise the entities of < and > would be escaped again
+	assert.Equal(t, "replace(replace(replace(title, '&', '&amp;'), '<', '&lt;'), '>', '&gt;')", escapeHTML("title"))
+}
+
+func TestListingQueryOrderBy(t *testing.T) {
+	near := &model.GeoPoint{Latitude: 10.7769, Longitude: 106.7009}
+	const rank = "ORDER BY ts_rank_cd(search_vector, websearch_to_tsquery('vietnamese', $1)) DESC, created_at DESC, id"
+
+	testCases := []struct {
+		name   string
+		filter model.ListingFilter
+		sort   model.ListingSort
+		want   string
+	}{
+		{name: "newest by default", want: "ORDER BY created_at DESC, id"},
+		{name: "price ascending", sort: model.ListingSortPriceAsc, want: "ORDER BY price_per_night ASC, created_at DESC, id"},
+		{name: "price descending", sort: model.ListingSortPriceDesc, want: "ORDER BY price_per_night DESC, created_at DESC, id"},
+		{name: "keyword ranks by relevance", filter: model.ListingFilter{Keyword: "villa"}, want: rank},
+		{name: "keyword with explicit relevance", filter: model.ListingFilter{Keyword: "villa"}, sort: model.ListingSortRelevance, want: rank},
+		{name: "keyword sorted by price", filter: model.ListingFilter{Keyword: "villa"}, sort: model.ListingSortPriceAsc, want: "ORDER BY price_per_night ASC, created_at DESC, id"},
+		{
+			name:   "near a point sorts by distance",
+			filter: model.ListingFilter{Near: near, RadiusKm: 5},
+			want:   "ORDER BY earth_distance(ll_to_earth($1, $2), ll_to_earth(latitude, longitude)), created_at DESC, id",
+		},
+		{
+			name:   "keyword near a point ranks by relevance first",
+			filter: model.ListingFilter{Near: near, RadiusKm: 5, Keyword: "villa"},
+			want:   "ORDER BY ts_rank_cd(search_vector, websearch_to_tsquery('vietnamese', $4)) DESC, created_at DESC, id",
+		},
+		{
+			name:   "keyword near a point sorted by distance",
+			filter: model.ListingFilter{Near: near, RadiusKm: 5, Keyword: "villa"},
+			sort:   model.ListingSortDistance,
+			want:   "ORDER BY earth_distance(ll_to_earth($1, $2), ll_to_earth(latitude, longitude)), created_at DESC, id",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			q := newListingQuery(tc.filter)
+
+			assert.Equal(t, tc.want, q.orderBy(tc.sort))
+		})
+	}
+}
@@ -10,7 +10,8 @@ import (
 
 func (r *LocationRepository) FindProvinceByCode(ctx context.Context, code int32) (*model.Province, error) {
 	query := `
-		SELECT code, full_name, created_at
+		SELECT code, full_name, created_at,
+			min_latitude, max_latitude, min_longitude, max_longitude
 		FROM provinces
 		WHERE code = $1
 	`
@@ -67,7 +68,8 @@ func (r *LocationRepository) FindWardByCode(ctx context.Context, code int32) (*m
 
 func (r *LocationRepository) ListProvinces(ctx context.Context) ([]model.Province, error) {
 	query := `
-		SELECT code, full_name, created_at
+		SELECT code, full_name, created_at,
+			min_latitude, max_latitude, min_longitude, max_longitude
 		FROM provinces
 		ORDER BY full_name
 	`
@@ -23,3 +23,13 @@ func NewLocationRepository(db *pgxpool.Pool) *LocationRepository {
 		db: db,
 	}
 }
+
+type AvailabilityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAvailabilityRepository(db *pgxpool.Pool) *AvailabilityRepository {
+	return &AvailabilityRepository{
+		db: db,
+	}
+}
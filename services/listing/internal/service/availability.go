@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// HoldBookingDates takes the nights of a stay for a booking, before the
+// booking service saves it. Only active listings can be held. Holding the same
+// stay again for the same booking returns the existing hold, so the booking
+// service can retry a call that timed out.
+func (s *ListingService) HoldBookingDates(ctx context.Context, listingID, bookingID string, stay model.StayDates) (*model.BookingHold, error) {
+	if !stay.CheckOut.After(stay.CheckIn) {
+		return nil, model.ErrInvalidDateRange
+	}
+
+	listing, err := s.listingRepo.FindByID(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if listing.Status != model.ListingStatusActive {
+		return nil, model.ErrListingNotFound
+	}
+
+	holdID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating hold ID: %w", err)
+	}
+
+	hold, err := s.availabilityRepo.CreateBookingHold(ctx, model.BookingHold{
+		ID:        holdID.String(),
+		ListingID: listingID,
+		BookingID: bookingID,
+		StartDate: stay.CheckIn,
+		EndDate:   stay.CheckOut,
+		CreatedAt: time.Now(),
+	})
+	if errors.Is(err, model.ErrBookingHoldExists) {
+		return s.findRetriedBookingHold(ctx, listingID, bookingID, stay)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return hold, nil
+}
+
+// findRetriedBookingHold returns the hold a booking already has, as long as it
+// covers the same stay on the same listing.
+func (s *ListingService) findRetriedBookingHold(ctx context.Context, listingID, bookingID string, stay model.StayDates) (*model.BookingHold, error) {
+	hold, err := s.availabilityRepo.FindBookingHold(ctx, bookingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if hold.ListingID != listingID ||
+		!hold.StartDate.Equal(stay.CheckIn) ||
+		!hold.EndDate.Equal(stay.CheckOut) {
+		return nil, model.ErrBookingHoldMismatch
+	}
+
+	return hold, nil
+}
+
+// ReleaseBookingDates frees the nights of a booking that was rejected,
+// cancelled or never saved.
+func (s *ListingService) ReleaseBookingDates(ctx context.Context, listingID, bookingID string) error {
+	return s.availabilityRepo.DeleteBookingHold(ctx, listingID, bookingID)
+}
+
+// ListBlockedDates returns the blocks of a host's listing that have not ended
+// yet, earliest first.
+func (s *ListingService) ListBlockedDates(ctx context.Context, listingID, hostID string) ([]model.BlockedDates, error) {
+	if _, err := s.GetHostListingByID(ctx, listingID, hostID); err != nil {
+		return nil, err
+	}
+
+	return s.availabilityRepo.ListBlockedDates(ctx, listingID, model.Today())
+}
+
+// BlockDates closes a host's listing from startDate up to, not including,
+// endDate. It fails with ErrDatesUnavailable when a pending or confirmed
+// booking holds one of the nights; the host has to reject or cancel it first.
+func (s *ListingService) BlockDates(ctx context.Context, listingID, hostID string, startDate, endDate time.Time) (*model.BlockedDates, error) {
+	if !endDate.After(startDate) {
+		return nil, model.ErrInvalidDateRange
+	}
+
+	if endDate.After(startDate.AddDate(0, 0, model.MaxBlockedNights)) {
+		return nil, model.ErrBlockedDatesTooLong
+	}
+
+	if startDate.Before(model.Today()) {
+		return nil, model.ErrStartDatePast
+	}
+
+	if _, err := s.GetHostListingByID(ctx, listingID, hostID); err != nil {
+		return nil, err
+	}
+
+	blockedID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating blocked dates ID: %w", err)
+	}
+
+	return s.availabilityRepo.CreateBlockedDates(ctx, model.BlockedDates{
+		ID:        blockedID.String(),
+		ListingID: listingID,
+		StartDate: startDate,
+		EndDate:   endDate,
+		CreatedAt: time.Now(),
+	})
+}
+
+// UnblockDates opens the nights of a block again.
+func (s *ListingService) UnblockDates(ctx context.Context, listingID, hostID, blockedID string) error {
+	if _, err := s.GetHostListingByID(ctx, listingID, hostID); err != nil {
+		return err
+	}
+
+	return s.availabilityRepo.DeleteBlockedDates(ctx, listingID, blockedID)
+}
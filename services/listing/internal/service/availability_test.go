@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testBookingID = "0190a0b0-0000-7000-8000-0000000000d1"
+
+func TestHoldBookingDates(t *testing.T) {
+	ctx := context.Background()
+	stay := model.StayDates{
+		CheckIn:  time.Date(2026, 12, 24, 0, 0, 0, 0, time.UTC),
+		CheckOut: time.Date(2026, 12, 27, 0, 0, 0, 0, time.UTC),
+	}
+	active := &model.Listing{ID: testListingID, HostID: testHostID, Status: model.ListingStatusActive}
+	existing := &model.BookingHold{
+		ID:        "0190a0b0-0000-7000-8000-0000000000c1",
+		ListingID: testListingID,
+		BookingID: testBookingID,
+		StartDate: stay.CheckIn,
+		EndDate:   stay.CheckOut,
+	}
+	isNewHold := mock.MatchedBy(func(h model.BookingHold) bool {
+		return h.ID != "" && h.ListingID == testListingID && h.BookingID == testBookingID &&
+			h.StartDate.Equal(stay.CheckIn) && h.EndDate.Equal(stay.CheckOut)
+	})
+
+	testCases := []struct {
+		name        string
+		stay        model.StayDates
+		setupMocks  func(m *serviceMocks)
+		expectedErr error
+	}{
+		{
+			name: "success",
+			stay: stay,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(active, nil)
+				m.availabilityRepo.On("CreateBookingHold", ctx, isNewHold).Return(existing, nil)
+			},
+		},
+		{
+			name:        "check-out not after check-in",
+			stay:        model.StayDates{CheckIn: stay.CheckIn, CheckOut: stay.CheckIn},
+			setupMocks:  func(m *serviceMocks) {},
+			expectedErr: model.ErrInvalidDateRange,
+		},
+		{
+			name: "listing not active",
+			stay: stay,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).
+					Return(&model.Listing{ID: testListingID, Status: model.ListingStatusInactive}, nil)
+			},
+			expectedErr: model.ErrListingNotFound,
+		},
+		{
+			name: "nights already held",
+			stay: stay,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(active, nil)
+				m.availabilityRepo.On("CreateBookingHold", ctx, isNewHold).Return(nil, model.ErrDatesUnavailable)
+			},
+			expectedErr: model.ErrDatesUnavailable,
+		},
+		{
+			name: "retry returns the existing hold",
+			stay: stay,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(active, nil)
+				m.availabilityRepo.On("CreateBookingHold", ctx, isNewHold).Return(nil, model.ErrBookingHoldExists)
+				m.availabilityRepo.On("FindBookingHold", ctx, testBookingID).Return(existing, nil)
+			},
+		},
+		{
+			name: "booking already holds other dates",
+			stay: model.StayDates{CheckIn: stay.CheckIn, CheckOut: stay.CheckOut.AddDate(0, 0, 1)},
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(active, nil)
+				m.availabilityRepo.On("CreateBookingHold", ctx, mock.Anything).Return(nil, model.ErrBookingHoldExists)
+				m.availabilityRepo.On("FindBookingHold", ctx, testBookingID).Return(existing, nil)
+			},
+			expectedErr: model.ErrBookingHoldMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMocks(m)
+
+			hold, err := service.HoldBookingDates(ctx, testListingID, testBookingID, tc.stay)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, hold)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, existing, hold)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestReleaseBookingDates(t *testing.T) {
+	ctx := context.Background()
+	dbErr := errors.New("connection refused")
+
+	m, service := newMocksAndService()
+	m.availabilityRepo.On("DeleteBookingHold", ctx, testListingID, testBookingID).Return(nil).Once()
+	m.availabilityRepo.On("DeleteBookingHold", ctx, testListingID, testBookingID).Return(dbErr).Once()
+
+	require.NoError(t, service.ReleaseBookingDates(ctx, testListingID, testBookingID))
+	require.ErrorIs(t, service.ReleaseBookingDates(ctx, testListingID, testBookingID), dbErr)
+	m.assertExpectations(t)
+}
+
+func TestBlockDates(t *testing.T) {
+	ctx := context.Background()
+	today := model.Today()
+	listing := &model.Listing{ID: testListingID, HostID: testHostID, Status: model.ListingStatusActive}
+	isBlock := func(start, end time.Time) any {
+		return mock.MatchedBy(func(b model.BlockedDates) bool {
+			return b.ID != "" && b.ListingID == testListingID && b.StartDate.Equal(start) && b.EndDate.Equal(end)
+		})
+	}
+
+	testCases := []struct {
+		name        string
+		hostID      string
+		startDate   time.Time
+		endDate     time.Time
+		setupMocks  func(m *serviceMocks)
+		expectedErr error
+	}{
+		{
+			name:      "success",
+			hostID:    testHostID,
+			startDate: today,
+			endDate:   today.AddDate(0, 0, 3),
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(listing, nil)
+				m.availabilityRepo.On("CreateBlockedDates", ctx, isBlock(today, today.AddDate(0, 0, 3))).
+					Return(&model.BlockedDates{ID: "0190a0b0-0000-7000-8000-0000000000c1"}, nil)
+			},
+		},
+		{
+			name:      "a full year",
+			hostID:    testHostID,
+			startDate: today,
+			endDate:   today.AddDate(0, 0, model.MaxBlockedNights),
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(listing, nil)
+				m.availabilityRepo.On("CreateBlockedDates", ctx, isBlock(today, today.AddDate(0, 0, model.MaxBlockedNights))).
+					Return(&model.BlockedDates{ID: "0190a0b0-0000-7000-8000-0000000000c1"}, nil)
+			},
+		},
+		{
+			name:        "end date not after start date",
+			hostID:      testHostID,
+			startDate:   today.AddDate(0, 0, 2),
+			endDate:     today.AddDate(0, 0, 2),
+			setupMocks:  func(m *serviceMocks) {},
+			expectedErr: model.ErrInvalidDateRange,
+		},
+		{
+			name:        "longer than a year",
+			hostID:      testHostID,
+			startDate:   today,
+			endDate:     today.AddDate(0, 0, model.MaxBlockedNights+1),
+			setupMocks:  func(m *serviceMocks) {},
+			expectedErr: model.ErrBlockedDatesTooLong,
+		},
+		{
+			name:        "start date in the past",
+			hostID:      testHostID,
+			startDate:   today.AddDate(0, 0, -1),
+			endDate:     today.AddDate(0, 0, 1),
+			setupMocks:  func(m *serviceMocks) {},
+			expectedErr: model.ErrStartDatePast,
+		},
+		{
+			name:      "listing of another host",
+			hostID:    "0190a0b0-0000-7000-8000-0000000000a2",
+			startDate: today,
+			endDate:   today.AddDate(0, 0, 1),
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(listing, nil)
+			},
+			expectedErr: model.ErrListingOwnerMismatch,
+		},
+		{
+			name:      "dates already booked",
+			hostID:    testHostID,
+			startDate: today,
+			endDate:   today.AddDate(0, 0, 3),
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(listing, nil)
+				m.availabilityRepo.On("CreateBlockedDates", ctx, isBlock(today, today.AddDate(0, 0, 3))).
+					Return(nil, model.ErrDatesUnavailable)
+			},
+			expectedErr: model.ErrDatesUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMocks(m)
+
+			blocked, err := service.BlockDates(ctx, testListingID, tc.hostID, tc.startDate, tc.endDate)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, blocked)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, blocked)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
+
+func TestUnblockDatesChecksOwner(t *testing.T) {
+	ctx := context.Background()
+	blockedID := "0190a0b0-0000-7000-8000-0000000000c1"
+
+	m, service := newMocksAndService()
+	m.listingRepo.On("FindByID", ctx, testListingID).Return(&model.Listing{ID: testListingID, HostID: testHostID}, nil)
+
+	err := service.UnblockDates(ctx, testListingID, "0190a0b0-0000-7000-8000-0000000000a2", blockedID)
+	require.ErrorIs(t, err, model.ErrListingOwnerMismatch)
+	m.availabilityRepo.AssertNotCalled(t, "DeleteBlockedDates", mock.Anything, mock.Anything, mock.Anything)
+
+	m.availabilityRepo.On("DeleteBlockedDates", ctx, testListingID, blockedID).Return(nil)
+	require.NoError(t, service.UnblockDates(ctx, testListingID, testHostID, blockedID))
+	m.assertExpectations(t)
+}
@@ -33,6 +33,14 @@ func (s *ListingService) CreateListing(ctx context.Context, arg model.CreateList
 		return nil, model.ErrWardDistrictMismatch
 	}
 
+	var latitude, longitude *float64
+	if arg.Location != nil {
+		if !province.Contains(*arg.Location) {
+			return nil, model.ErrCoordinatesOutsideProvince
+		}
+		latitude, longitude = &arg.Location.Latitude, &arg.Location.Longitude
+	}
+
 	listingID, err := uuid.NewV7()
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error occur when generating listing ID: %w", err)
@@ -53,6 +61,8 @@ func (s *ListingService) CreateListing(ctx context.Context, arg model.CreateList
 		WardCode:      arg.WardCode,
 		WardName:      ward.FullName,
 		AddressDetail: arg.AddressDetail,
+		Latitude:      latitude,
+		Longitude:     longitude,
 		Status:        model.ListingStatusDraft,
 		CreatedAt:     now,
 		UpdatedAt:     now,
@@ -78,13 +88,18 @@ func (s *ListingService) GetActiveListingByID(ctx context.Context, id string) (*
 	return listing, nil
 }
 
-func (s *ListingService) ListActiveListings(ctx context.Context, limit, offset int) ([]model.Listing, int64, error) {
-	listings, err := s.listingRepo.ListByStatus(ctx, model.ListingStatusActive, limit, offset)
+// ListActiveListings searches the listings guests can book. Whatever status
+// the filter asks for, only active listings are returned, and with a stay only
+// those with no night of it held by a pending or confirmed booking.
+func (s *ListingService) ListActiveListings(ctx context.Context, filter model.ListingFilter, limit, offset int) ([]model.ListingSearchResult, int64, error) {
+	filter.Status = model.ListingStatusActive
+
+	listings, err := s.listingRepo.ListByFilter(ctx, filter, limit, offset)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	total, err := s.listingRepo.CountByStatus(ctx, model.ListingStatusActive)
+	total, err := s.listingRepo.CountByFilter(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -114,6 +129,34 @@ func (s *ListingService) UpdateListingBasicInfo(ctx context.Context, listingID,
 	return updatedListing, nil
 }
 
+// UpdateListingDetails sets the property type and how many people and rooms
+// the listing has. An empty update returns the listing unchanged.
+func (s *ListingService) UpdateListingDetails(ctx context.Context, listingID, hostID string, arg model.UpdateListingDetailsParams) (*model.Listing, error) {
+	listing, err := s.listingRepo.FindByID(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if listing.HostID != hostID {
+		return nil, model.ErrListingOwnerMismatch
+	}
+
+	if listing.Status == model.ListingStatusActive {
+		return nil, model.ErrActiveListingCannotBeUpdated
+	}
+
+	updatedListing, err := s.listingRepo.UpdateDetails(ctx, listingID, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	if updatedListing == nil {
+		return listing, nil
+	}
+
+	return updatedListing, nil
+}
+
 func (s *ListingService) UpdateListingAddress(ctx context.Context, arg model.UpdateListingAddressParams) (*model.Listing, error) {
 	listing, err := s.listingRepo.FindByID(ctx, arg.ListingID)
 	if err != nil {
@@ -157,6 +200,10 @@ func (s *ListingService) UpdateListingAddress(ctx context.Context, arg model.Upd
 		arg.WardName = &ward.FullName
 	}
 
+	if err = s.checkListingLocation(ctx, listing, arg); err != nil {
+		return nil, err
+	}
+
 	updatedListing, err := s.listingRepo.UpdateAddress(ctx, arg)
 	if err != nil {
 		return nil, err
@@ -165,10 +212,53 @@ func (s *ListingService) UpdateListingAddress(ctx context.Context, arg model.Upd
 	return updatedListing, nil
 }
 
+// checkListingLocation makes sure the coordinates the listing ends up with lie
+// in the province it ends up in, whichever of the two the update changes.
+func (s *ListingService) checkListingLocation(ctx context.Context, listing *model.Listing, arg model.UpdateListingAddressParams) error {
+	location := arg.Location
+	if location == nil {
+		if listing.Latitude == nil || listing.Longitude == nil || arg.ProvinceCode == nil {
+			return nil
+		}
+		location = &model.GeoPoint{Latitude: *listing.Latitude, Longitude: *listing.Longitude}
+	}
+
+	provinceCode := listing.ProvinceCode
+	if arg.ProvinceCode != nil {
+		provinceCode = *arg.ProvinceCode
+	}
+
+	province, err := s.locationRepo.FindProvinceByCode(ctx, provinceCode)
+	if err != nil {
+		return err
+	}
+
+	if !province.Contains(*location) {
+		return model.ErrCoordinatesOutsideProvince
+	}
+
+	return nil
+}
+
 func (s *ListingService) ListHostListings(ctx context.Context, hostID string) ([]model.Listing, error) {
 	return s.listingRepo.ListByHostID(ctx, hostID)
 }
 
+// ListActiveListingsByHost returns the public listings of a host, e.g. for their profile page.
+func (s *ListingService) ListActiveListingsByHost(ctx context.Context, hostID string, limit, offset int) ([]model.Listing, int64, error) {
+	listings, err := s.listingRepo.ListByHostIDAndStatus(ctx, hostID, model.ListingStatusActive, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.listingRepo.CountByHostIDAndStatus(ctx, hostID, model.ListingStatusActive)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return listings, total, nil
+}
+
 func (s *ListingService) GetHostListingByID(ctx context.Context, listingID, hostID string) (*model.Listing, error) {
 	listing, err := s.listingRepo.FindByID(ctx, listingID)
 	if err != nil {
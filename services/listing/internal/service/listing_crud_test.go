@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateListingDetails(t *testing.T) {
+	ctx := context.Background()
+	villa := model.PropertyTypeVilla
+	maxGuests, beds := int32(8), int32(5)
+	arg := model.UpdateListingDetailsParams{PropertyType: &villa, MaxGuests: &maxGuests, Beds: &beds}
+
+	draft := &model.Listing{ID: testListingID, HostID: testHostID, Status: model.ListingStatusDraft}
+	updated := &model.Listing{ID: testListingID, HostID: testHostID, Status: model.ListingStatusDraft,
+		PropertyType: &villa, MaxGuests: &maxGuests, Beds: &beds}
+
+	testCases := []struct {
+		name        string
+		hostID      string
+		arg         model.UpdateListingDetailsParams
+		setupMocks  func(m *serviceMocks)
+		want        *model.Listing
+		expectedErr error
+	}{
+		{
+			name:   "success",
+			hostID: testHostID,
+			arg:    arg,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(draft, nil)
+				m.listingRepo.On("UpdateDetails", ctx, testListingID, arg).Return(updated, nil)
+			},
+			want: updated,
+		},
+		{
+			name:   "empty update returns the listing unchanged",
+			hostID: testHostID,
+			arg:    model.UpdateListingDetailsParams{},
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(draft, nil)
+				m.listingRepo.On("UpdateDetails", ctx, testListingID, model.UpdateListingDetailsParams{}).Return(nil, nil)
+			},
+			want: draft,
+		},
+		{
+			name:   "listing not found",
+			hostID: testHostID,
+			arg:    arg,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(nil, model.ErrListingNotFound)
+			},
+			expectedErr: model.ErrListingNotFound,
+		},
+		{
+			name:   "listing of another host",
+			hostID: "0190a0b0-0000-7000-8000-0000000000a2",
+			arg:    arg,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).Return(draft, nil)
+			},
+			expectedErr: model.ErrListingOwnerMismatch,
+		},
+		{
+			name:   "active listing",
+			hostID: testHostID,
+			arg:    arg,
+			setupMocks: func(m *serviceMocks) {
+				m.listingRepo.On("FindByID", ctx, testListingID).
+					Return(&model.Listing{ID: testListingID, HostID: testHostID, Status: model.ListingStatusActive}, nil)
+			},
+			expectedErr: model.ErrActiveListingCannotBeUpdated,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMocks(m)
+
+			listing, err := service.UpdateListingDetails(ctx, testListingID, tc.hostID, tc.arg)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, listing)
+				m.listingRepo.AssertNotCalled(t, "UpdateDetails", mock.Anything, mock.Anything, mock.Anything)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.want, listing)
+			}
+			m.assertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+)
+
+// The methods below back the admin moderation endpoints. Unlike their host
+// counterparts they do not check ownership; the router only lets users with
+// the listings:moderate permission reach them.
+
+func (s *ListingService) ListListingsByStatus(ctx context.Context, status model.ListingStatus, limit, offset int) ([]model.Listing, int64, error) {
+	listings, err := s.listingRepo.ListByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.listingRepo.CountByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return listings, total, nil
+}
+
+func (s *ListingService) GetListingByID(ctx context.Context, listingID string) (*model.Listing, error) {
+	return s.listingRepo.FindByID(ctx, listingID)
+}
+
+// SuspendListing takes an active listing off the market, e.g. after a report.
+// The host can reactivate it once the problem is fixed.
+func (s *ListingService) SuspendListing(ctx context.Context, listingID string) (*model.Listing, error) {
+	listing, err := s.listingRepo.FindByID(ctx, listingID)
+	if err != nil {
+		return nil, err
+	}
+
+	if listing.Status != model.ListingStatusActive {
+		return nil, model.ErrListingNotActive
+	}
+
+	return s.listingRepo.UpdateStatus(ctx, listingID, model.ListingStatusInactive)
+}
+
+// RemoveListing soft-deletes a listing regardless of who owns it.
+func (s *ListingService) RemoveListing(ctx context.Context, listingID string) error {
+	return s.listingRepo.Delete(ctx, listingID)
+}
@@ -24,6 +24,10 @@ func (s *ListingService) PublishListing(ctx context.Context, listingID, hostID s
 		return nil, err
 	}
 
+	if err = s.checkHostVerified(ctx, hostID); err != nil {
+		return nil, err
+	}
+
 	updatedListing, err := s.listingRepo.UpdateStatus(ctx, listingID, model.ListingStatusActive)
 	if err != nil {
 		return nil, err
@@ -54,6 +58,12 @@ func (s *ListingService) DeactivateListingByID(ctx context.Context, listingID, h
 	return updatedListing, nil
 }
 
+// DeactivateHostListings takes every active listing of a host off the market.
+// The user service calls it when the host deletes their account.
+func (s *ListingService) DeactivateHostListings(ctx context.Context, hostID string) (int64, error) {
+	return s.listingRepo.DeactivateAllByHostID(ctx, hostID)
+}
+
 func (s *ListingService) ReactivateListingByID(ctx context.Context, listingID, hostID string) (*model.Listing, error) {
 	listing, err := s.listingRepo.FindByID(ctx, listingID)
 	if err != nil {
@@ -72,6 +82,10 @@ func (s *ListingService) ReactivateListingByID(ctx context.Context, listingID, h
 		return nil, err
 	}
 
+	if err = s.checkHostVerified(ctx, hostID); err != nil {
+		return nil, err
+	}
+
 	updatedListing, err := s.listingRepo.UpdateStatus(ctx, listingID, model.ListingStatusActive)
 	if err != nil {
 		return nil, err
@@ -79,3 +93,22 @@ func (s *ListingService) ReactivateListingByID(ctx context.Context, listingID, h
 
 	return updatedListing, nil
 }
+
+// checkHostVerified asks the user service whether the host passed identity
+// verification, when that is required to go live. The answer is not cached:
+// a host whose verification is revoked must not publish again.
+func (s *ListingService) checkHostVerified(ctx context.Context, hostID string) error {
+	if !s.requireVerifiedHost {
+		return nil
+	}
+
+	verified, err := s.userClient.IsHostVerified(ctx, hostID)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return model.ErrHostNotVerified
+	}
+
+	return nil
+}
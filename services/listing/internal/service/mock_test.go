@@ -0,0 +1,236 @@
+// mock_test.go
+// =============================================================================
+// File này chứa các MOCK OBJECTS thay thế dependencies thật của ListingService:
+// database (ListingRepository, LocationRepository, AvailabilityRepository) và
+// User Service được gọi qua HTTP.
+//
+// Cách dùng giống bên user service:
+//   - On(methodName, args...).Return(values...) để setup expectation
+//   - AssertExpectations(t) để verify các method đã được gọi đúng
+// =============================================================================
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
+	"github.com/stretchr/testify/mock"
+)
+
+// MockListingRepository là bản giả của ListingRepository.
+type MockListingRepository struct {
+	mock.Mock
+}
+
+// listingOrError trả về listing đã setup, hoặc nil nếu test muốn trả về error.
+func listingOrError(args mock.Arguments) (*model.Listing, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) Create(ctx context.Context, listing model.Listing) (*model.Listing, error) {
+	return listingOrError(m.Called(ctx, listing))
+}
+
+func (m *MockListingRepository) FindByID(ctx context.Context, id string) (*model.Listing, error) {
+	return listingOrError(m.Called(ctx, id))
+}
+
+func (m *MockListingRepository) Delete(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+func (m *MockListingRepository) ListByStatus(ctx context.Context, status model.ListingStatus, limit, offset int) ([]model.Listing, error) {
+	args := m.Called(ctx, status, limit, offset)
+
+	return args.Get(0).([]model.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) ListByHostID(ctx context.Context, hostID string) ([]model.Listing, error) {
+	args := m.Called(ctx, hostID)
+
+	return args.Get(0).([]model.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) CountByStatus(ctx context.Context, status model.ListingStatus) (int64, error) {
+	args := m.Called(ctx, status)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockListingRepository) ListByHostIDAndStatus(ctx context.Context, hostID string, status model.ListingStatus, limit, offset int) ([]model.Listing, error) {
+	args := m.Called(ctx, hostID, status, limit, offset)
+
+	return args.Get(0).([]model.Listing), args.Error(1)
+}
+
+func (m *MockListingRepository) CountByHostIDAndStatus(ctx context.Context, hostID string, status model.ListingStatus) (int64, error) {
+	args := m.Called(ctx, hostID, status)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// ListByFilter giả lập việc tìm kiếm listing. Test có thể match filter bằng
+// mock.MatchedBy để kiểm tra service đã build filter đúng chưa.
+func (m *MockListingRepository) ListByFilter(ctx context.Context, filter model.ListingFilter, limit, offset int) ([]model.ListingSearchResult, error) {
+	args := m.Called(ctx, filter, limit, offset)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.ListingSearchResult), args.Error(1)
+}
+
+func (m *MockListingRepository) CountByFilter(ctx context.Context, filter model.ListingFilter) (int64, error) {
+	args := m.Called(ctx, filter)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockListingRepository) UpdateStatus(ctx context.Context, id string, status model.ListingStatus) (*model.Listing, error) {
+	return listingOrError(m.Called(ctx, id, status))
+}
+
+func (m *MockListingRepository) DeactivateAllByHostID(ctx context.Context, hostID string) (int64, error) {
+	args := m.Called(ctx, hostID)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockListingRepository) UpdateBasicInfo(ctx context.Context, id string, arg model.UpdateListingBasicInfoParams) (*model.Listing, error) {
+	return listingOrError(m.Called(ctx, id, arg))
+}
+
+func (m *MockListingRepository) UpdateDetails(ctx context.Context, id string, arg model.UpdateListingDetailsParams) (*model.Listing, error) {
+	return listingOrError(m.Called(ctx, id, arg))
+}
+
+func (m *MockListingRepository) UpdateAddress(ctx context.Context, arg model.UpdateListingAddressParams) (*model.Listing, error) {
+	return listingOrError(m.Called(ctx, arg))
+}
+
+// MockLocationRepository là bản giả của LocationRepository.
+type MockLocationRepository struct {
+	mock.Mock
+}
+
+func (m *MockLocationRepository) FindProvinceByCode(ctx context.Context, code int32) (*model.Province, error) {
+	args := m.Called(ctx, code)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Province), args.Error(1)
+}
+
+func (m *MockLocationRepository) FindDistrictByCode(ctx context.Context, code int32) (*model.District, error) {
+	args := m.Called(ctx, code)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.District), args.Error(1)
+}
+
+func (m *MockLocationRepository) FindWardByCode(ctx context.Context, code int32) (*model.Ward, error) {
+	args := m.Called(ctx, code)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Ward), args.Error(1)
+}
+
+func (m *MockLocationRepository) ListProvinces(ctx context.Context) ([]model.Province, error) {
+	args := m.Called(ctx)
+
+	return args.Get(0).([]model.Province), args.Error(1)
+}
+
+func (m *MockLocationRepository) ListDistrictsByProvinceCode(ctx context.Context, provinceCode int32) ([]model.District, error) {
+	args := m.Called(ctx, provinceCode)
+
+	return args.Get(0).([]model.District), args.Error(1)
+}
+
+func (m *MockLocationRepository) ListWardsByDistrictCode(ctx context.Context, districtCode int32) ([]model.Ward, error) {
+	args := m.Called(ctx, districtCode)
+
+	return args.Get(0).([]model.Ward), args.Error(1)
+}
+
+// MockAvailabilityRepository là bản giả của AvailabilityRepository.
+type MockAvailabilityRepository struct {
+	mock.Mock
+}
+
+func (m *MockAvailabilityRepository) CreateBookingHold(ctx context.Context, hold model.BookingHold) (*model.BookingHold, error) {
+	args := m.Called(ctx, hold)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.BookingHold), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) FindBookingHold(ctx context.Context, bookingID string) (*model.BookingHold, error) {
+	args := m.Called(ctx, bookingID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.BookingHold), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) DeleteBookingHold(ctx context.Context, listingID, bookingID string) error {
+	args := m.Called(ctx, listingID, bookingID)
+
+	return args.Error(0)
+}
+
+func (m *MockAvailabilityRepository) CreateBlockedDates(ctx context.Context, blocked model.BlockedDates) (*model.BlockedDates, error) {
+	args := m.Called(ctx, blocked)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.BlockedDates), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) ListBlockedDates(ctx context.Context, listingID string, from time.Time) ([]model.BlockedDates, error) {
+	args := m.Called(ctx, listingID, from)
+
+	return args.Get(0).([]model.BlockedDates), args.Error(1)
+}
+
+func (m *MockAvailabilityRepository) DeleteBlockedDates(ctx context.Context, listingID, id string) error {
+	args := m.Called(ctx, listingID, id)
+
+	return args.Error(0)
+}
+
+// MockUserClient giả lập HTTP client gọi sang User Service.
+type MockUserClient struct {
+	mock.Mock
+}
+
+// IsHostVerified giả lập việc hỏi User Service host đã xác minh danh tính chưa.
+func (m *MockUserClient) IsHostVerified(ctx context.Context, hostID string) (bool, error) {
+	args := m.Called(ctx, hostID)
+
+	return args.Bool(0), args.Error(1)
+}
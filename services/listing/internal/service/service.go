@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"time"
 
 	"github.com/katatrina/airbnb-clone/pkg/token"
 	"github.com/katatrina/airbnb-clone/services/listing/internal/model"
@@ -15,9 +16,15 @@ type ListingRepository interface {
 	ListByStatus(ctx context.Context, status model.ListingStatus, limit, offset int) ([]model.Listing, error)
 	ListByHostID(ctx context.Context, hostID string) ([]model.Listing, error)
 	CountByStatus(ctx context.Context, status model.ListingStatus) (int64, error)
+	ListByHostIDAndStatus(ctx context.Context, hostID string, status model.ListingStatus, limit, offset int) ([]model.Listing, error)
+	CountByHostIDAndStatus(ctx context.Context, hostID string, status model.ListingStatus) (int64, error)
+	ListByFilter(ctx context.Context, filter model.ListingFilter, limit, offset int) ([]model.ListingSearchResult, error)
+	CountByFilter(ctx context.Context, filter model.ListingFilter) (int64, error)
 
 	UpdateStatus(ctx context.Context, id string, status model.ListingStatus) (*model.Listing, error)
+	DeactivateAllByHostID(ctx context.Context, hostID string) (int64, error)
 	UpdateBasicInfo(ctx context.Context, id string, arg model.UpdateListingBasicInfoParams) (*model.Listing, error)
+	UpdateDetails(ctx context.Context, id string, arg model.UpdateListingDetailsParams) (*model.Listing, error)
 	UpdateAddress(ctx context.Context, arg model.UpdateListingAddressParams) (*model.Listing, error)
 }
 
@@ -31,20 +38,48 @@ type LocationRepository interface {
 	ListWardsByDistrictCode(ctx context.Context, districtCode int32) ([]model.Ward, error)
 }
 
+// AvailabilityRepository stores the nights listings cannot be booked. Holds
+// never overlap, so the store itself settles two bookings racing for a night.
+type AvailabilityRepository interface {
+	CreateBookingHold(ctx context.Context, hold model.BookingHold) (*model.BookingHold, error)
+	FindBookingHold(ctx context.Context, bookingID string) (*model.BookingHold, error)
+	DeleteBookingHold(ctx context.Context, listingID, bookingID string) error
+
+	CreateBlockedDates(ctx context.Context, blocked model.BlockedDates) (*model.BlockedDates, error)
+	ListBlockedDates(ctx context.Context, listingID string, from time.Time) ([]model.BlockedDates, error)
+	DeleteBlockedDates(ctx context.Context, listingID, id string) error
+}
+
+type UserClient interface {
+	IsHostVerified(ctx context.Context, hostID string) (bool, error)
+}
+
 type ListingService struct {
-	listingRepo  ListingRepository
-	locationRepo LocationRepository
-	tokenMaker   token.TokenMaker
+	listingRepo      ListingRepository
+	locationRepo     LocationRepository
+	availabilityRepo AvailabilityRepository
+	tokenVerifier    token.TokenVerifier
+	userClient       UserClient
+
+	// requireVerifiedHost keeps listings of hosts who have not passed identity
+	// verification from going live.
+	requireVerifiedHost bool
 }
 
 func NewListingService(
 	listingRepo ListingRepository,
 	locationRepo LocationRepository,
-	tokenMaker token.TokenMaker,
+	availabilityRepo AvailabilityRepository,
+	tokenVerifier token.TokenVerifier,
+	userClient UserClient,
+	requireVerifiedHost bool,
 ) *ListingService {
 	return &ListingService{
 		listingRepo,
 		locationRepo,
-		tokenMaker,
+		availabilityRepo,
+		tokenVerifier,
+		userClient,
+		requireVerifiedHost,
 	}
 }
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+)
+
+const (
+	testHostID    = "0190a0b0-0000-7000-8000-0000000000a1"
+	testListingID = "0190a0b0-0000-7000-8000-0000000000b1"
+)
+
+type serviceMocks struct {
+	listingRepo      *MockListingRepository
+	locationRepo     *MockLocationRepository
+	availabilityRepo *MockAvailabilityRepository
+	userClient       *MockUserClient
+}
+
+func newMocksAndService() (*serviceMocks, *ListingService) {
+	m := &serviceMocks{
+		listingRepo:      new(MockListingRepository),
+		locationRepo:     new(MockLocationRepository),
+		availabilityRepo: new(MockAvailabilityRepository),
+		userClient:       new(MockUserClient),
+	}
+	service := NewListingService(m.listingRepo, m.locationRepo, m.availabilityRepo, nil, m.userClient, false)
+
+	return m, service
+}
+
+func (m *serviceMocks) assertExpectations(t *testing.T) {
+	t.Helper()
+
+	m.listingRepo.AssertExpectations(t)
+	m.locationRepo.AssertExpectations(t)
+	m.availabilityRepo.AssertExpectations(t)
+	m.userClient.AssertExpectations(t)
+}
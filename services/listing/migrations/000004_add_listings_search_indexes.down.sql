@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX idx_listings_status_price;
+DROP INDEX idx_listings_status_location;
+
+COMMIT;
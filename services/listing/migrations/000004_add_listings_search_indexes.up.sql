@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Public search narrows by location from province down to ward
+CREATE INDEX idx_listings_status_location
+    ON listings (status, province_code, district_code, ward_code, created_at)
+    WHERE deleted_at IS NULL;
+
+-- Price range filters and sorting by price
+CREATE INDEX idx_listings_status_price
+    ON listings (status, price_per_night, created_at)
+    WHERE deleted_at IS NULL;
+
+COMMIT;
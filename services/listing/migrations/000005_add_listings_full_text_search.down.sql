@@ -0,0 +1,12 @@
+BEGIN;
+
+DROP INDEX idx_listings_search_vector;
+
+ALTER TABLE listings
+    DROP COLUMN search_vector;
+
+DROP TEXT SEARCH CONFIGURATION vietnamese;
+
+-- unaccent is left installed; other objects may depend on it.
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS unaccent;
+
+-- Postgres ships no Vietnamese stemmer. Words are only lowercased and stripped
+-- of diacritics, so "da lat" finds "Đà Lạt" and the other way around.
+CREATE TEXT SEARCH CONFIGURATION vietnamese (COPY = simple);
+ALTER TEXT SEARCH CONFIGURATION vietnamese
+    ALTER MAPPING FOR asciiword, asciihword, hword_asciipart, word, hword, hword_part
+    WITH unaccent, simple;
+
+-- Kept up to date by Postgres on every write. The title weighs most, then the
+-- location names, then the description.
+ALTER TABLE listings
+    ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('vietnamese', title), 'A') ||
+        setweight(to_tsvector('vietnamese', ward_name || ' ' || district_name || ' ' || province_name), 'B') ||
+        setweight(to_tsvector('vietnamese', description), 'C')
+    ) STORED;
+
+CREATE INDEX idx_listings_search_vector
+    ON listings USING GIN (search_vector)
+    WHERE deleted_at IS NULL;
+
+COMMIT;
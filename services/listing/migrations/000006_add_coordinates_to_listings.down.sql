@@ -0,0 +1,19 @@
+BEGIN;
+
+ALTER TABLE provinces
+    DROP COLUMN max_longitude,
+    DROP COLUMN min_longitude,
+    DROP COLUMN max_latitude,
+    DROP COLUMN min_latitude;
+
+DROP INDEX idx_listings_coordinates;
+DROP INDEX idx_listings_earth;
+
+ALTER TABLE listings
+    DROP CONSTRAINT check_coordinates,
+    DROP COLUMN longitude,
+    DROP COLUMN latitude;
+
+-- cube and earthdistance are left installed; other objects may depend on them.
+
+COMMIT;
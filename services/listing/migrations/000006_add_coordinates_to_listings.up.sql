@@ -0,0 +1,36 @@
+BEGIN;
+
+-- earthdistance (on top of cube) is a lighter fallback for PostGIS: great
+-- circle distances on a spherical earth, good enough to the meter for search
+CREATE EXTENSION IF NOT EXISTS cube;
+CREATE EXTENSION IF NOT EXISTS earthdistance;
+
+-- Where the listing is on the map. Optional, but always set as a pair.
+ALTER TABLE listings
+    ADD COLUMN latitude  DOUBLE PRECISION,
+    ADD COLUMN longitude DOUBLE PRECISION,
+    ADD CONSTRAINT check_coordinates CHECK (
+        (latitude IS NULL) = (longitude IS NULL)
+        AND latitude BETWEEN -90 AND 90
+        AND longitude BETWEEN -180 AND 180
+    );
+
+-- Radius search: earth_box() around a point is answered by this index
+CREATE INDEX idx_listings_earth
+    ON listings USING GIST (ll_to_earth(latitude, longitude))
+    WHERE deleted_at IS NULL AND latitude IS NOT NULL;
+
+-- Map viewport (bounding box) search
+CREATE INDEX idx_listings_coordinates
+    ON listings (latitude, longitude)
+    WHERE deleted_at IS NULL AND latitude IS NOT NULL;
+
+-- Bounding box of each province, loaded by cmd/import-locations, to check
+-- that a listing's coordinates lie in its province
+ALTER TABLE provinces
+    ADD COLUMN min_latitude  DOUBLE PRECISION,
+    ADD COLUMN max_latitude  DOUBLE PRECISION,
+    ADD COLUMN min_longitude DOUBLE PRECISION,
+    ADD COLUMN max_longitude DOUBLE PRECISION;
+
+COMMIT;
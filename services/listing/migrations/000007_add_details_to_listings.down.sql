@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE listings
+    DROP CONSTRAINT check_capacity,
+    DROP CONSTRAINT check_property_type,
+    DROP COLUMN bathrooms,
+    DROP COLUMN beds,
+    DROP COLUMN bedrooms,
+    DROP COLUMN max_guests,
+    DROP COLUMN property_type;
+
+COMMIT;
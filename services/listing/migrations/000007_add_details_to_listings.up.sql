@@ -0,0 +1,18 @@
+BEGIN;
+
+-- What kind of place it is and how many people it fits. Nullable so existing
+-- listings stay valid; a listing needs them all before it can be published.
+ALTER TABLE listings
+    ADD COLUMN property_type TEXT,
+    ADD COLUMN max_guests    INTEGER,
+    ADD COLUMN bedrooms      INTEGER,
+    ADD COLUMN beds          INTEGER,
+    ADD COLUMN bathrooms     INTEGER,
+    ADD CONSTRAINT check_property_type CHECK (
+        property_type IN ('apartment', 'house', 'homestay', 'villa', 'room')
+    ),
+    ADD CONSTRAINT check_capacity CHECK (
+        max_guests >= 1 AND bedrooms >= 0 AND beds >= 1 AND bathrooms >= 0
+    );
+
+COMMIT;
@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Required for the exclusion constraint with UUID + daterange
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+-- Nights a listing cannot be booked, from start_date up to, not including,
+-- end_date. The booking service holds the dates of every pending or confirmed
+-- booking here before saving it, so searches with dates can leave booked
+-- listings out in the same query.
+CREATE TABLE listing_unavailable_dates
+(
+    id         UUID PRIMARY KEY,
+    listing_id UUID        NOT NULL REFERENCES listings (id) ON DELETE CASCADE,
+    booking_id UUID        NOT NULL UNIQUE,
+    start_date DATE        NOT NULL,
+    end_date   DATE        NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT check_unavailable_dates CHECK (end_date > start_date)
+);
+
+-- Two holds never share a night; the index also serves the search
+ALTER TABLE listing_unavailable_dates
+    ADD CONSTRAINT no_overlapping_unavailable_dates EXCLUDE USING gist (
+            listing_id WITH =,
+            daterange(start_date, end_date) WITH &&
+        );
+
+COMMIT;
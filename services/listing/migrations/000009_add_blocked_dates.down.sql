@@ -0,0 +1,8 @@
+BEGIN;
+
+DELETE FROM listing_unavailable_dates WHERE booking_id IS NULL;
+
+ALTER TABLE listing_unavailable_dates
+    ALTER COLUMN booking_id SET NOT NULL;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Rows without a booking are dates the host blocked. They share the
+-- no_overlapping_unavailable_dates constraint with booking holds, so a host
+-- cannot block a booked night and a guest cannot book a blocked one.
+ALTER TABLE listing_unavailable_dates
+    ALTER COLUMN booking_id DROP NOT NULL;
+
+COMMIT;
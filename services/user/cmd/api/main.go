@@ -12,9 +12,18 @@ import (
 	"github.com/katatrina/airbnb-clone/pkg/response"
 	"github.com/katatrina/airbnb-clone/pkg/token"
 	"github.com/katatrina/airbnb-clone/services/user/config"
+	"github.com/katatrina/airbnb-clone/services/user/internal/client"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
 	"github.com/katatrina/airbnb-clone/services/user/internal/handler"
+	"github.com/katatrina/airbnb-clone/services/user/internal/loginguard"
+	"github.com/katatrina/airbnb-clone/services/user/internal/oidc"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordpolicy"
 	"github.com/katatrina/airbnb-clone/services/user/internal/repository"
 	"github.com/katatrina/airbnb-clone/services/user/internal/service"
+	"github.com/katatrina/airbnb-clone/services/user/internal/sms"
+	"github.com/katatrina/airbnb-clone/services/user/internal/storage"
+	"github.com/redis/go-redis/v9"
 )
 
 func main() {
@@ -37,15 +46,194 @@ func main() {
 	}
 	log.Println("Connected to database successfully")
 
-	tokenMaker, err := token.NewJWTMaker([]byte(cfg.JWTSecret), cfg.JWTExpiry)
+	redisOpts, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		log.Fatalf("Failed to parse Redis URL: %v", err)
+	}
+	redisClient := redis.NewClient(redisOpts)
+	defer redisClient.Close()
+
+	if err = redisClient.Ping(ctx).Err(); err != nil {
+		log.Fatalf("Failed to ping Redis: %v", err)
+	}
+	log.Println("Connected to Redis successfully")
+
+	var tokenMaker token.TokenMaker
+	if len(cfg.JWTPrivateKeyFiles) > 0 {
+		// The first key signs new tokens, the rest are kept for verification only
+		signingKeys := make([]*token.SigningKey, 0, len(cfg.JWTPrivateKeyFiles))
+		for _, path := range cfg.JWTPrivateKeyFiles {
+			signingKey, err := token.LoadSigningKey(path)
+			if err != nil {
+				log.Fatalf("Failed to load JWT signing key: %v", err)
+			}
+			signingKeys = append(signingKeys, signingKey)
+		}
+		tokenMaker, err = token.NewAsymmetricMaker(cfg.JWTExpiry, signingKeys[0], signingKeys[1:]...)
+	} else {
+		tokenMaker, err = token.NewJWTMaker([]byte(cfg.JWTSecret), cfg.JWTExpiry)
+	}
 	if err != nil {
 		log.Fatalf("Failed to create token maker: %v", err)
 	}
 
+	// Shared with the other services, so a logout is honored everywhere
+	revocationStore := token.NewRedisRevocationStore(redisClient, cfg.JWTExpiry)
+	authMiddleware := middleware.AuthMiddleware(tokenMaker, revocationStore)
+
 	userRepo := repository.NewUserRepository(db)
-	userService := service.NewUserService(userRepo, tokenMaker)
+	sessionRepo := repository.NewSessionRepository(db)
+	userTokenRepo := repository.NewUserTokenRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	identityRepo := repository.NewIdentityRepository(db)
+	loginEventRepo := repository.NewLoginEventRepository(db)
+	emailChangeRepo := repository.NewEmailChangeRepository(db)
+	dataExportRepo := repository.NewDataExportRepository(db)
+	hostVerificationRepo := repository.NewHostVerificationRepository(db)
+
+	var emailSender service.EmailSender
+	switch {
+	case cfg.SMTPHost != "":
+		emailSender = email.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.EmailFrom)
+	case cfg.EmailOutboxDir != "":
+		emailSender, err = email.NewFileSender(cfg.EmailOutboxDir)
+		if err != nil {
+			log.Fatalf("Failed to create email outbox: %v", err)
+		}
+	default:
+		emailSender = email.LogSender{}
+	}
+
+	// Config validation guarantees the provider is "console"
+	var smsSender service.SMSSender = sms.ConsoleSender{}
+
+	// Config validation guarantees the driver is "local"
+	avatarStore, err := storage.NewLocalDiskStore(cfg.StorageLocalDir, cfg.StoragePublicURL)
+	if err != nil {
+		log.Fatalf("Failed to create blob store: %v", err)
+	}
+
+	// Never served over HTTP: archives are only handed out via download links
+	exportStore, err := storage.NewLocalDiskStore(cfg.DataExportDir, "")
+	if err != nil {
+		log.Fatalf("Failed to create data export store: %v", err)
+	}
+
+	// ID documents are only shown to admins through the review endpoints
+	documentStore, err := storage.NewLocalDiskStore(cfg.HostVerificationDir, "")
+	if err != nil {
+		log.Fatalf("Failed to create host verification store: %v", err)
+	}
+
+	listingClient := client.NewListingClient(cfg.ListingServiceURL, cfg.InternalAPIKey)
+	bookingClient := client.NewBookingClient(cfg.BookingServiceURL, cfg.InternalAPIKey)
+
+	// Counters live in Redis so every replica sees them. If Redis fails at
+	// runtime, each replica keeps counting in memory instead.
+	loginGuard := loginguard.NewGuard(
+		loginguard.NewFallbackStore(loginguard.NewRedisStore(redisClient), loginguard.NewMemoryStore()),
+		loginguard.Policy{
+			FreeAttempts:    3,
+			BaseDelay:       time.Second,
+			MaxDelay:        30 * time.Second,
+			MaxAttempts:     cfg.LoginMaxAttemptsPerEmail,
+			LockoutDuration: cfg.LoginLockoutDuration,
+			Window:          cfg.LoginAttemptWindow,
+		},
+		// Looser per IP: many users can share an address behind a NAT
+		loginguard.Policy{
+			FreeAttempts:    20,
+			BaseDelay:       time.Second,
+			MaxDelay:        30 * time.Second,
+			MaxAttempts:     cfg.LoginMaxAttemptsPerIP,
+			LockoutDuration: cfg.LoginLockoutDuration,
+			Window:          cfg.LoginAttemptWindow,
+		},
+	)
+
+	var breachedPasswords passwordpolicy.BreachedSet
+	if cfg.PasswordBreachedHashesDir != "" {
+		breachedPasswords, err = passwordpolicy.NewHashRangeDir(cfg.PasswordBreachedHashesDir)
+		if err != nil {
+			log.Fatalf("Failed to load breached passwords: %v", err)
+		}
+	}
+	passwordPolicy := passwordpolicy.NewChecker(passwordpolicy.Config{
+		MinLength: cfg.PasswordMinLength,
+		MinScore:  cfg.PasswordMinScore,
+	}, breachedPasswords)
+
+	passwordHasher := passwordhash.NewHasher(passwordhash.Config{
+		Algorithm:  cfg.PasswordHashAlgorithm,
+		BcryptCost: cfg.BcryptCost,
+		Argon2: passwordhash.Argon2Params{
+			Memory:      cfg.Argon2MemoryKiB,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+		},
+	})
+
+	oidcProviders := make(map[string]service.OIDCProvider, len(cfg.OIDCProviders))
+	for name, providerCfg := range cfg.OIDCProviderConfigs {
+		oidcProviders[name] = oidc.NewProvider(oidc.Config{
+			Name:         name,
+			IssuerURL:    providerCfg.IssuerURL,
+			ClientID:     providerCfg.ClientID,
+			ClientSecret: providerCfg.ClientSecret,
+			RedirectURL:  providerCfg.RedirectURL,
+			Scopes:       []string{"email", "profile"},
+		})
+	}
+
+	userService := service.NewUserService(service.Deps{
+		UserRepo:        userRepo,
+		SessionRepo:     sessionRepo,
+		UserTokenRepo:   userTokenRepo,
+		TwoFactorRepo:   twoFactorRepo,
+		IdentityRepo:    identityRepo,
+		LoginEventRepo:  loginEventRepo,
+		EmailChangeRepo: emailChangeRepo,
+		DataExportRepo:  dataExportRepo,
+		HostVerifyRepo:  hostVerificationRepo,
+		TokenMaker:      tokenMaker,
+		Revocations:     revocationStore,
+		EmailSender:     emailSender,
+		SMSSender:       smsSender,
+		AvatarStore:     avatarStore,
+		ExportStore:     exportStore,
+		DocumentStore:   documentStore,
+		ListingClient:   listingClient,
+		BookingClient:   bookingClient,
+		LoginGuard:      loginGuard,
+		PasswordPolicy:  passwordPolicy,
+		PasswordHasher:  passwordHasher,
+		OIDCProviders:   oidcProviders,
+	}, service.Config{
+		RefreshTokenExpiry:         cfg.RefreshTokenExpiry,
+		EmailVerificationExpiry:    cfg.EmailVerificationExpiry,
+		PasswordResetExpiry:        cfg.PasswordResetExpiry,
+		AccountDeletionGracePeriod: cfg.AccountDeletionGracePeriod,
+		AppBaseURL:                 cfg.AppBaseURL,
+		TOTPIssuer:                 cfg.TOTPIssuer,
+		DataExportExpiry:           cfg.DataExportExpiry,
+	})
+
+	// Scrub personal data of accounts whose deletion grace period has passed
+	go userService.RunAccountAnonymizer(context.Background(), time.Hour)
+	// Build requested data exports and clean up expired ones
+	go userService.RunDataExportWorker(context.Background(), 30*time.Second)
 	userHandler := handler.NewUserHandler(userService)
 
+	// A 2FA code has only a million values; besides the per-challenge and
+	// per-account limits, cap how fast one client can try them at all
+	twoFactorRateLimit := middleware.RateLimit(middleware.NewRedisRateLimitStore(redisClient), middleware.RateLimitPolicy{
+		Name:     "auth:login-2fa",
+		Strategy: middleware.SlidingWindow,
+		Limit:    10,
+		Window:   time.Minute,
+		Key:      middleware.RateLimitByIP,
+	})
+
 	router := gin.Default()
 
 	router.NoRoute(func(c *gin.Context) {
@@ -53,6 +241,8 @@ func main() {
 	})
 
 	router.GET("/health", userHandler.Health)
+	router.GET("/.well-known/jwks.json", userHandler.JWKS)
+	router.Static("/uploads", cfg.StorageLocalDir)
 
 	v1 := router.Group("/api/v1")
 	{
@@ -60,13 +250,80 @@ func main() {
 		{
 			auth.POST("/register", userHandler.Register)
 			auth.POST("/login", userHandler.Login)
+			auth.POST("/login/2fa", twoFactorRateLimit, userHandler.VerifyTwoFactorLogin)
+			auth.POST("/oidc/:provider/start", userHandler.StartOIDCLogin)
+			auth.POST("/oidc/:provider/callback", userHandler.CompleteOIDCLogin)
+			auth.POST("/refresh", userHandler.RefreshToken)
+			auth.POST("/logout", authMiddleware, userHandler.Logout)
+			auth.POST("/logout-all", authMiddleware, userHandler.LogoutAll)
+			auth.POST("/verify-email", userHandler.VerifyEmail)
+			auth.POST("/verify-email/resend", authMiddleware, userHandler.ResendVerificationEmail)
+			auth.POST("/forgot-password", userHandler.ForgotPassword)
+			auth.POST("/reset-password", userHandler.ResetPassword)
+			auth.POST("/email-change/confirm", userHandler.ConfirmEmailChange)
+			auth.POST("/email-change/cancel", userHandler.CancelEmailChange)
 		}
 
+		v1.GET("/users/:id", userHandler.GetPublicProfile)
+		v1.GET("/data-export/download", userHandler.DownloadDataExport)
+
 		protected := v1.Group("/me")
-		protected.Use(middleware.AuthMiddleware(tokenMaker))
+		protected.Use(authMiddleware)
 		{
+			protected.DELETE("", userHandler.DeleteAccount)
 			protected.GET("/profile", userHandler.GetProfile)
+			protected.PATCH("/profile", userHandler.UpdateProfile)
+			protected.PUT("/profile/avatar", userHandler.UploadAvatar)
+			protected.DELETE("/profile/avatar", userHandler.DeleteAvatar)
+			protected.POST("/password", userHandler.ChangePassword)
+			protected.POST("/email", userHandler.RequestEmailChange)
+			protected.POST("/phone/send-code", userHandler.SendPhoneVerificationCode)
+			protected.POST("/phone/verify", userHandler.VerifyPhone)
+			protected.GET("/preferences", userHandler.GetPreferences)
+			protected.PUT("/preferences", userHandler.UpdatePreferences)
+
+			protected.GET("/2fa", userHandler.GetTwoFactorStatus)
+			protected.DELETE("/2fa", userHandler.DisableTwoFactor)
+			protected.POST("/2fa/totp/setup", userHandler.SetupTOTP)
+			protected.POST("/2fa/totp/enable", userHandler.EnableTOTP)
+			protected.POST("/2fa/recovery-codes", userHandler.RegenerateRecoveryCodes)
+
+			protected.GET("/sessions", userHandler.ListSessions)
+			protected.DELETE("/sessions", userHandler.RevokeOtherSessions)
+			protected.DELETE("/sessions/:id", userHandler.RevokeSession)
+
+			protected.GET("/security/events", userHandler.ListLoginEvents)
+
+			protected.POST("/data-export", userHandler.RequestDataExport)
+			protected.GET("/data-export", userHandler.GetDataExport)
+
+			protected.POST("/host-verification", userHandler.SubmitHostVerification)
+			protected.GET("/host-verification", userHandler.GetHostVerification)
+		}
+
+		admin := v1.Group("/admin")
+		admin.Use(authMiddleware, middleware.RequirePermission(middleware.PermissionManageUsers))
+		{
+			admin.GET("/users/:id", userHandler.GetUser)
+			admin.PUT("/users/:id/roles", userHandler.SetUserRoles)
 		}
+
+		hostVerifications := v1.Group("/admin/host-verifications")
+		hostVerifications.Use(authMiddleware, middleware.RequirePermission(middleware.PermissionVerifyHosts))
+		{
+			hostVerifications.GET("", userHandler.ListHostVerifications)
+			hostVerifications.GET("/:id", userHandler.GetHostVerificationForReview)
+			hostVerifications.GET("/:id/documents/:side", userHandler.GetHostDocument)
+			hostVerifications.POST("/:id/approve", userHandler.ApproveHostVerification)
+			hostVerifications.POST("/:id/reject", userHandler.RejectHostVerification)
+		}
+	}
+
+	internal := router.Group("/internal/v1")
+	internal.Use(middleware.RequireInternalAPIKey(cfg.InternalAPIKey))
+	{
+		internal.GET("/users/:id/host-verification", userHandler.GetUserHostVerification)
+		internal.GET("/users/:id/preferences", userHandler.GetUserPreferences)
 	}
 
 	addr := fmt.Sprintf(":%s", cfg.ServerPort)
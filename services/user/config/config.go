@@ -2,16 +2,115 @@ package config
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
+var oidcProviderNamePattern = regexp.MustCompile(`^[a-z0-9]+$`)
+
 type Config struct {
 	ServerPort  string        `mapstructure:"SERVER_PORT"`
 	DatabaseURL string        `mapstructure:"DATABASE_URL"`
+	RedisURL    string        `mapstructure:"REDIS_URL"`
 	JWTSecret   string        `mapstructure:"JWT_SECRET"`
 	JWTExpiry   time.Duration `mapstructure:"JWT_EXPIRY"`
+
+	// JWTPrivateKeyFiles switches token signing from HS256 (JWT_SECRET) to
+	// RS256/EdDSA. The first key signs new tokens; the others were rotated out
+	// and are still published in the JWKS until their tokens expire.
+	JWTPrivateKeyFiles []string `mapstructure:"JWT_PRIVATE_KEY_FILES"`
+
+	RefreshTokenExpiry time.Duration `mapstructure:"REFRESH_TOKEN_EXPIRY"`
+
+	// AppBaseURL is the frontend URL used to build links in emails.
+	AppBaseURL              string        `mapstructure:"APP_BASE_URL"`
+	EmailVerificationExpiry time.Duration `mapstructure:"EMAIL_VERIFICATION_EXPIRY"`
+	PasswordResetExpiry     time.Duration `mapstructure:"PASSWORD_RESET_EXPIRY"`
+
+	// Emails go through SMTP when SMTP_HOST is set. Otherwise they are written
+	// to EMAIL_OUTBOX_DIR, or printed to the log if that is empty too.
+	SMTPHost       string `mapstructure:"SMTP_HOST"`
+	SMTPPort       int    `mapstructure:"SMTP_PORT"`
+	SMTPUsername   string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword   string `mapstructure:"SMTP_PASSWORD"`
+	EmailFrom      string `mapstructure:"EMAIL_FROM"`
+	EmailOutboxDir string `mapstructure:"EMAIL_OUTBOX_DIR"`
+
+	// StorageDriver selects where uploaded files go. Only "local" exists for now:
+	// files are written to STORAGE_LOCAL_DIR and served at STORAGE_PUBLIC_URL.
+	StorageDriver    string `mapstructure:"STORAGE_DRIVER"`
+	StorageLocalDir  string `mapstructure:"STORAGE_LOCAL_DIR"`
+	StoragePublicURL string `mapstructure:"STORAGE_PUBLIC_URL"`
+
+	ListingServiceURL string `mapstructure:"LISTING_SERVICE_URL"`
+	BookingServiceURL string `mapstructure:"BOOKING_SERVICE_URL"`
+
+	// InternalAPIKey authenticates this service when calling the internal
+	// endpoints of the listing and booking services.
+	InternalAPIKey string `mapstructure:"INTERNAL_API_KEY"`
+
+	// AccountDeletionGracePeriod is how long a deleted account keeps its
+	// personal data before it is anonymized.
+	AccountDeletionGracePeriod time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+
+	// TOTPIssuer is the name authenticator apps show for 2FA entries.
+	TOTPIssuer string `mapstructure:"TOTP_ISSUER"`
+
+	// Failed logins are counted per email and per client IP within
+	// LOGIN_ATTEMPT_WINDOW. Reaching the maximum locks the email or IP for
+	// LOGIN_LOCKOUT_DURATION; before that, retries are progressively delayed.
+	LoginMaxAttemptsPerEmail int           `mapstructure:"LOGIN_MAX_ATTEMPTS_PER_EMAIL"`
+	LoginMaxAttemptsPerIP    int           `mapstructure:"LOGIN_MAX_ATTEMPTS_PER_IP"`
+	LoginAttemptWindow       time.Duration `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LoginLockoutDuration     time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	// OIDCProviders lists the names of the OpenID providers users can log in
+	// with, e.g. "google". Each one is configured by OIDC_<NAME>_* variables.
+	OIDCProviders       []string                      `mapstructure:"OIDC_PROVIDERS"`
+	OIDCProviderConfigs map[string]OIDCProviderConfig `mapstructure:"-"`
+
+	// Personal data exports are written to DATA_EXPORT_DIR, which must not be
+	// served publicly, and can be downloaded for DATA_EXPORT_EXPIRY.
+	DataExportDir    string        `mapstructure:"DATA_EXPORT_DIR"`
+	DataExportExpiry time.Duration `mapstructure:"DATA_EXPORT_EXPIRY"`
+
+	// New passwords need PASSWORD_MIN_LENGTH characters and a strength score
+	// (0-4) of at least PASSWORD_MIN_SCORE. PASSWORD_BREACHED_HASHES_DIR, if
+	// set, holds a Pwned Passwords range copy used to reject breached passwords.
+	PasswordMinLength         int    `mapstructure:"PASSWORD_MIN_LENGTH"`
+	PasswordMinScore          int    `mapstructure:"PASSWORD_MIN_SCORE"`
+	PasswordBreachedHashesDir string `mapstructure:"PASSWORD_BREACHED_HASHES_DIR"`
+
+	// PasswordHashAlgorithm is "bcrypt" or "argon2id". Raising the strength
+	// needs no password reset: older hashes are upgraded at the next login.
+	PasswordHashAlgorithm string `mapstructure:"PASSWORD_HASH_ALGORITHM"`
+	BcryptCost            int    `mapstructure:"BCRYPT_COST"`
+	Argon2MemoryKiB       uint32 `mapstructure:"ARGON2_MEMORY_KIB"`
+	Argon2Iterations      uint32 `mapstructure:"ARGON2_ITERATIONS"`
+	Argon2Parallelism     uint8  `mapstructure:"ARGON2_PARALLELISM"`
+
+	// HostVerificationDir holds the ID documents hosts submit for identity
+	// verification. Like DATA_EXPORT_DIR, it must not be served publicly.
+	HostVerificationDir string `mapstructure:"HOST_VERIFICATION_DIR"`
+
+	// SMSProvider selects how phone verification codes are sent. Only
+	// "console" exists for now: messages are printed to the log.
+	SMSProvider string `mapstructure:"SMS_PROVIDER"`
+}
+
+// OIDCProviderConfig is read from OIDC_<NAME>_ISSUER, OIDC_<NAME>_CLIENT_ID,
+// OIDC_<NAME>_CLIENT_SECRET and OIDC_<NAME>_REDIRECT_URL.
+type OIDCProviderConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+
+	// RedirectURL defaults to APP_BASE_URL/auth/callback/<name>.
+	RedirectURL string
 }
 
 // Validate checks that all required configuration is present.
@@ -22,8 +121,103 @@ func (c *Config) Validate() error {
 	if c.DatabaseURL == "" {
 		return errors.New("DATABASE_URL is required")
 	}
-	if c.JWTSecret == "" {
-		return errors.New("JWT_SECRET is required")
+	if c.RedisURL == "" {
+		return errors.New("REDIS_URL is required")
+	}
+	if c.ListingServiceURL == "" {
+		return errors.New("LISTING_SERVICE_URL is required")
+	}
+	if c.BookingServiceURL == "" {
+		return errors.New("BOOKING_SERVICE_URL is required")
+	}
+	if c.InternalAPIKey == "" {
+		return errors.New("INTERNAL_API_KEY is required")
+	}
+	if c.AccountDeletionGracePeriod <= 0 {
+		return errors.New("ACCOUNT_DELETION_GRACE_PERIOD must be positive")
+	}
+	if c.TOTPIssuer == "" {
+		return errors.New("TOTP_ISSUER is required")
+	}
+	if c.LoginMaxAttemptsPerEmail <= 0 || c.LoginMaxAttemptsPerIP <= 0 {
+		return errors.New("LOGIN_MAX_ATTEMPTS_PER_EMAIL and LOGIN_MAX_ATTEMPTS_PER_IP must be positive")
+	}
+	if c.LoginAttemptWindow <= 0 || c.LoginLockoutDuration <= 0 {
+		return errors.New("LOGIN_ATTEMPT_WINDOW and LOGIN_LOCKOUT_DURATION must be positive")
+	}
+	if c.JWTSecret == "" && len(c.JWTPrivateKeyFiles) == 0 {
+		return errors.New("JWT_SECRET or JWT_PRIVATE_KEY_FILES is required")
+	}
+	if c.RefreshTokenExpiry <= 0 {
+		return errors.New("REFRESH_TOKEN_EXPIRY is required")
+	}
+	if c.AppBaseURL == "" {
+		return errors.New("APP_BASE_URL is required")
+	}
+	if c.EmailVerificationExpiry <= 0 {
+		return errors.New("EMAIL_VERIFICATION_EXPIRY is required")
+	}
+	if c.PasswordResetExpiry <= 0 {
+		return errors.New("PASSWORD_RESET_EXPIRY is required")
+	}
+	if c.SMTPHost != "" {
+		if c.SMTPPort == 0 {
+			return errors.New("SMTP_PORT is required when SMTP_HOST is set")
+		}
+		if c.EmailFrom == "" {
+			return errors.New("EMAIL_FROM is required when SMTP_HOST is set")
+		}
+	}
+	for _, name := range c.OIDCProviders {
+		if !oidcProviderNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid OIDC provider name %q: use lowercase letters and digits", name)
+		}
+		provider := c.OIDCProviderConfigs[name]
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			key := strings.ToUpper(name)
+			return fmt.Errorf("OIDC_%s_ISSUER and OIDC_%s_CLIENT_ID are required", key, key)
+		}
+	}
+	if c.DataExportDir == "" {
+		return errors.New("DATA_EXPORT_DIR is required")
+	}
+	if c.DataExportExpiry <= 0 {
+		return errors.New("DATA_EXPORT_EXPIRY must be positive")
+	}
+	if c.HostVerificationDir == "" {
+		return errors.New("HOST_VERIFICATION_DIR is required")
+	}
+	if c.PasswordMinLength < 8 {
+		return errors.New("PASSWORD_MIN_LENGTH must be at least 8")
+	}
+	if c.PasswordMinScore < 0 || c.PasswordMinScore > 4 {
+		return errors.New("PASSWORD_MIN_SCORE must be between 0 and 4")
+	}
+	switch c.PasswordHashAlgorithm {
+	case "bcrypt":
+		// Below the library default would weaken new hashes; above 31 bcrypt refuses
+		if c.BcryptCost < 10 || c.BcryptCost > 31 {
+			return errors.New("BCRYPT_COST must be between 10 and 31")
+		}
+	case "argon2id":
+		if c.Argon2MemoryKiB == 0 || c.Argon2Iterations == 0 || c.Argon2Parallelism == 0 {
+			return errors.New("ARGON2_MEMORY_KIB, ARGON2_ITERATIONS and ARGON2_PARALLELISM must be positive")
+		}
+	default:
+		return fmt.Errorf("unsupported PASSWORD_HASH_ALGORITHM %q", c.PasswordHashAlgorithm)
+	}
+	switch c.SMSProvider {
+	case "console":
+	default:
+		return fmt.Errorf("unsupported SMS_PROVIDER %q", c.SMSProvider)
+	}
+	switch c.StorageDriver {
+	case "local":
+		if c.StorageLocalDir == "" || c.StoragePublicURL == "" {
+			return errors.New("STORAGE_LOCAL_DIR and STORAGE_PUBLIC_URL are required for the local storage driver")
+		}
+	default:
+		return fmt.Errorf("unsupported STORAGE_DRIVER %q", c.StorageDriver)
 	}
 
 	return nil
@@ -42,6 +236,22 @@ func LoadConfig(path string) (*Config, error) {
 		return nil, err
 	}
 
+	// Provider settings have dynamic keys, so they can't be unmarshaled
+	cfg.OIDCProviderConfigs = make(map[string]OIDCProviderConfig, len(cfg.OIDCProviders))
+	for _, name := range cfg.OIDCProviders {
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		provider := OIDCProviderConfig{
+			IssuerURL:    viper.GetString(prefix + "ISSUER"),
+			ClientID:     viper.GetString(prefix + "CLIENT_ID"),
+			ClientSecret: viper.GetString(prefix + "CLIENT_SECRET"),
+			RedirectURL:  viper.GetString(prefix + "REDIRECT_URL"),
+		}
+		if provider.RedirectURL == "" {
+			provider.RedirectURL = strings.TrimSuffix(cfg.AppBaseURL, "/") + "/auth/callback/" + name
+		}
+		cfg.OIDCProviderConfigs[name] = provider
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return nil, err
 	}
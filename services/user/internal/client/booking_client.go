@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+type BookingClient struct {
+	baseURL        string
+	internalAPIKey string
+	httpClient     *http.Client
+}
+
+func NewBookingClient(baseURL, internalAPIKey string) *BookingClient {
+	return &BookingClient{
+		baseURL:        baseURL,
+		internalAPIKey: internalAPIKey,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// upcomingBookingsAPIResponse maps to Booking Service's internal response.
+// This struct is PRIVATE — only used inside this package for JSON parsing.
+type upcomingBookingsAPIResponse struct {
+	Success bool `json:"success"`
+	Data    *struct {
+		AsGuest int64 `json:"asGuest"`
+		AsHost  int64 `json:"asHost"`
+	} `json:"data"`
+}
+
+// CountUpcomingBookings returns how many confirmed stays the user still has
+// ahead, as a guest and as a host.
+func (c *BookingClient) CountUpcomingBookings(ctx context.Context, userID string) (*model.UpcomingBookingCounts, error) {
+	url := fmt.Sprintf("%s/internal/v1/users/%s/upcoming-bookings", c.baseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, model.ErrBookingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, model.ErrBookingServiceUnavailable
+	}
+
+	var apiResp upcomingBookingsAPIResponse
+	if err = json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode upcoming bookings response: %w", err)
+	}
+
+	if apiResp.Data == nil {
+		return nil, errors.New("upcoming bookings response has no data")
+	}
+
+	return &model.UpcomingBookingCounts{
+		AsGuest: apiResp.Data.AsGuest,
+		AsHost:  apiResp.Data.AsHost,
+	}, nil
+}
+
+// ExportUserBookings returns every booking of the user, as a guest and as a
+// host, as the booking service renders them.
+func (c *BookingClient) ExportUserBookings(ctx context.Context, userID string) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s/internal/v1/users/%s/bookings", c.baseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, model.ErrBookingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, model.ErrBookingServiceUnavailable
+	}
+
+	var apiResp rawDataAPIResponse
+	if err = json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode user bookings response: %w", err)
+	}
+
+	if len(apiResp.Data) == 0 {
+		return nil, errors.New("user bookings response has no data")
+	}
+
+	return apiResp.Data, nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+type ListingClient struct {
+	baseURL        string
+	internalAPIKey string
+	httpClient     *http.Client
+}
+
+func NewListingClient(baseURL, internalAPIKey string) *ListingClient {
+	return &ListingClient{
+		baseURL:        baseURL,
+		internalAPIKey: internalAPIKey,
+		httpClient: &http.Client{
+			Timeout: 5 * time.Second,
+		},
+	}
+}
+
+// hostListingsAPIResponse maps to the parts of Listing Service's paginated
+// response we need. This struct is PRIVATE — only used inside this package.
+type hostListingsAPIResponse struct {
+	Success bool `json:"success"`
+	Meta    struct {
+		Pagination *struct {
+			Total int64 `json:"total"`
+		} `json:"pagination"`
+	} `json:"meta"`
+}
+
+// CountActiveListingsByHost returns how many active listings the host has.
+// It asks for a single item and reads the total from the pagination metadata.
+func (c *ListingClient) CountActiveListingsByHost(ctx context.Context, hostID string) (int64, error) {
+	url := fmt.Sprintf("%s/api/v1/hosts/%s/listings?page=1&pageSize=1", c.baseURL, hostID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, model.ErrListingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, model.ErrListingServiceUnavailable
+	}
+
+	var apiResp hostListingsAPIResponse
+	if err = json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return 0, fmt.Errorf("failed to decode listings response: %w", err)
+	}
+
+	if apiResp.Meta.Pagination == nil {
+		return 0, errors.New("listings response has no pagination")
+	}
+
+	return apiResp.Meta.Pagination.Total, nil
+}
+
+// DeactivateHostListings asks the listing service to take every active listing
+// of the host off the market. It goes through the internal API.
+func (c *ListingClient) DeactivateHostListings(ctx context.Context, hostID string) error {
+	url := fmt.Sprintf("%s/internal/v1/hosts/%s/listings/deactivate", c.baseURL, hostID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return model.ErrListingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return model.ErrListingServiceUnavailable
+	}
+
+	return nil
+}
+
+// rawDataAPIResponse keeps the data of a response as raw JSON, for callers
+// that pass it along without looking inside.
+type rawDataAPIResponse struct {
+	Success bool            `json:"success"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// ExportHostListings returns every listing of the host as the listing service
+// renders it. It goes through the internal API.
+func (c *ListingClient) ExportHostListings(ctx context.Context, hostID string) (json.RawMessage, error) {
+	url := fmt.Sprintf("%s/internal/v1/hosts/%s/listings", c.baseURL, hostID)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set(middleware.InternalAPIKeyHeader, c.internalAPIKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, model.ErrListingServiceUnavailable
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, model.ErrListingServiceUnavailable
+	}
+
+	var apiResp rawDataAPIResponse
+	if err = json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return nil, fmt.Errorf("failed to decode host listings response: %w", err)
+	}
+
+	if len(apiResp.Data) == 0 {
+		return nil, errors.New("host listings response has no data")
+	}
+
+	return apiResp.Data, nil
+}
@@ -0,0 +1,50 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FileSender writes every email to its own file in a directory instead of
+// sending it, so links can be opened during local development.
+type FileSender struct {
+	dir string
+}
+
+var _ Sender = (*FileSender)(nil)
+
+func NewFileSender(dir string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create email outbox directory: %w", err)
+	}
+
+	return &FileSender{dir: dir}, nil
+}
+
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.txt", time.Now().Format("20060102T150405"), uuid.NewString())
+	content := fmt.Sprintf("To: %s\nSubject: %s\n\n%s\n", msg.To, msg.Subject, msg.Body)
+
+	if err := os.WriteFile(filepath.Join(s.dir, name), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write email file: %w", err)
+	}
+
+	return nil
+}
+
+// LogSender prints emails to the application log.
+type LogSender struct{}
+
+var _ Sender = LogSender{}
+
+func (LogSender) Send(_ context.Context, msg Message) error {
+	log.Printf("[EMAIL] to=%s subject=%q\n%s", msg.To, msg.Subject, strings.TrimSpace(msg.Body))
+	return nil
+}
@@ -0,0 +1,16 @@
+package email
+
+import "context"
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails. SMTPSender is used in production; FileSender and
+// LogSender stand in during local development.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
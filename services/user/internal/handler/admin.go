@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// GetUser returns the full profile of any user, for support.
+func (h *UserHandler) GetUser(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to get user: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewUserResponse(user), "")
+}
+
+func (h *UserHandler) SetUserRoles(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	var req SetUserRolesRequest
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	user, err := h.userService.SetUserRoles(c.Request.Context(), model.SetUserRolesParams{
+		ActorID: middleware.MustGetAuthUser(c).ID,
+		UserID:  userID,
+		Roles:   req.Roles,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		case errors.Is(err, model.ErrCannotRemoveOwnAdminRole):
+			response.BadRequest(c, response.CodeValidationFailed, "You cannot remove your own admin role")
+		default:
+			log.Printf("[ERROR] failed to set user roles: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewUserResponse(user), "Roles updated successfully")
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 
 	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
 	"github.com/katatrina/airbnb-clone/pkg/request"
 	"github.com/katatrina/airbnb-clone/pkg/response"
 	"github.com/katatrina/airbnb-clone/services/user/internal/model"
@@ -28,6 +29,9 @@ func (h *UserHandler) Register(c *gin.Context) {
 		case errors.Is(err, model.ErrEmailAlreadyExists):
 			response.Conflict(c, response.CodeEmailAlreadyExists, "Email already exists")
 			return
+		case errors.Is(err, model.ErrPasswordRejected):
+			respondPasswordRejected(c, "password", err)
+			return
 		default:
 			log.Printf("[ERROR] Register failed: %v", err)
 			response.InternalServerError(c)
@@ -47,15 +51,22 @@ func (h *UserHandler) Login(c *gin.Context) {
 	}
 
 	result, err := h.userService.LoginUser(c.Request.Context(), model.LoginUserParams{
-		Email:    req.Email,
-		Password: req.Password,
+		Email:     req.Email,
+		Password:  req.Password,
+		UserAgent: c.Request.UserAgent(),
+		ClientIP:  c.ClientIP(),
 	})
 	if err != nil {
+		var throttled *model.LoginThrottledError
 		switch {
 		case errors.Is(err, model.ErrIncorrectCredentials):
 			response.Unauthorized(c, response.CodeCredentialsInvalid, "Incorrect email or password")
 			return
 
+		case errors.As(err, &throttled):
+			response.TooManyRequests(c, "Too many failed login attempts. Please try again later", throttled.RetryAfter)
+			return
+
 		default:
 			log.Printf("[ERROR] fail to login user: %v", err)
 			response.InternalServerError(c)
@@ -63,9 +74,218 @@ func (h *UserHandler) Login(c *gin.Context) {
 		}
 	}
 
+	if result.TwoFactorChallenge != nil {
+		response.OK(c, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    result.TwoFactorChallenge.Token,
+			ExpiresAt:         result.TwoFactorChallenge.ExpiresAt.Unix(),
+		}, "Two-factor authentication required")
+		return
+	}
+
+	response.OK(c, LoginResponse{
+		AccessToken:           result.AccessToken,
+		AccessTokenExpiresAt:  result.AccessTokenExpiresAt.Unix(),
+		RefreshToken:          result.RefreshToken,
+		RefreshTokenExpiresAt: result.RefreshTokenExpiresAt.Unix(),
+		User:                  NewUserResponse(result.User),
+	}, "User login successfully")
+}
+
+func (h *UserHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req TwoFactorLoginRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	result, err := h.userService.VerifyTwoFactorLogin(c.Request.Context(), model.VerifyTwoFactorLoginParams{
+		ChallengeToken: req.ChallengeToken,
+		Code:           req.Code,
+		UserAgent:      c.Request.UserAgent(),
+		ClientIP:       c.ClientIP(),
+	})
+	if err != nil {
+		var throttled *model.LoginThrottledError
+		switch {
+		case errors.Is(err, model.ErrTwoFactorChallengeInvalid):
+			response.Unauthorized(c, response.CodeTwoFactorChallengeInvalid,
+				"Login session is invalid or has expired. Please log in again")
+		case errors.Is(err, model.ErrTwoFactorCodeInvalid):
+			response.Unauthorized(c, response.CodeTwoFactorCodeInvalid, "Invalid authentication code")
+		case errors.As(err, &throttled):
+			response.TooManyRequests(c, "Too many failed login attempts. Please try again later", throttled.RetryAfter)
+		default:
+			log.Printf("[ERROR] failed to verify two-factor login: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
 	response.OK(c, LoginResponse{
-		AccessToken:          result.AccessToken,
-		AccessTokenExpiresAt: result.AccessTokenExpiresAt.Unix(),
-		User:                 NewUserResponse(result.User),
+		AccessToken:           result.AccessToken,
+		AccessTokenExpiresAt:  result.AccessTokenExpiresAt.Unix(),
+		RefreshToken:          result.RefreshToken,
+		RefreshTokenExpiresAt: result.RefreshTokenExpiresAt.Unix(),
+		User:                  NewUserResponse(result.User),
 	}, "User login successfully")
 }
+
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	result, err := h.userService.RefreshSession(c.Request.Context(), model.RefreshSessionParams{
+		RefreshToken: req.RefreshToken,
+		UserAgent:    c.Request.UserAgent(),
+		ClientIP:     c.ClientIP(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrRefreshTokenInvalid):
+			response.Unauthorized(c, response.CodeTokenInvalid, "Invalid refresh token")
+		case errors.Is(err, model.ErrRefreshTokenExpired):
+			response.Unauthorized(c, response.CodeTokenExpired, "Refresh token has expired")
+		case errors.Is(err, model.ErrRefreshTokenReused):
+			response.Unauthorized(c, response.CodeRefreshTokenReused,
+				"Refresh token has already been used. Please log in again")
+		default:
+			log.Printf("[ERROR] failed to refresh token: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, RefreshTokenResponse{
+		AccessToken:           result.AccessToken,
+		AccessTokenExpiresAt:  result.AccessTokenExpiresAt.Unix(),
+		RefreshToken:          result.RefreshToken,
+		RefreshTokenExpiresAt: result.RefreshTokenExpiresAt.Unix(),
+	}, "Token refreshed successfully")
+}
+
+func (h *UserHandler) Logout(c *gin.Context) {
+	authUser := middleware.MustGetAuthUser(c)
+
+	err := h.userService.Logout(c.Request.Context(), model.LogoutParams{
+		UserID:         authUser.ID,
+		SessionID:      authUser.SessionID,
+		TokenID:        authUser.TokenID,
+		TokenExpiresAt: authUser.TokenExpiresAt,
+	})
+	if err != nil {
+		log.Printf("[ERROR] failed to logout: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *UserHandler) LogoutAll(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	if err := h.userService.LogoutAll(c.Request.Context(), userID); err != nil {
+		log.Printf("[ERROR] failed to logout all sessions: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	err := h.userService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserTokenInvalid):
+			response.BadRequest(c, response.CodeVerificationTokenInvalid,
+				"Verification link is invalid or has expired")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to verify email: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	// Access tokens carry the verification state, so the client has to refresh to pick it up
+	response.OK(c, nil, "Email verified successfully")
+}
+
+func (h *UserHandler) ResendVerificationEmail(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	err := h.userService.ResendVerificationEmail(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEmailAlreadyVerified):
+			response.Conflict(c, response.CodeEmailAlreadyVerified, "Email is already verified")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to resend verification email: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, nil, "Verification email sent")
+}
+
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	// Always answer the same way, whether or not the email belongs to an account
+	if err := h.userService.ForgotPassword(c.Request.Context(), req.Email); err != nil {
+		log.Printf("[ERROR] failed to send password reset email: %v", err)
+	}
+
+	response.OK(c, nil, "If an account exists for this email, a password reset link has been sent")
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	err := h.userService.ResetPassword(c.Request.Context(), model.ResetPasswordParams{
+		Token:       req.Token,
+		NewPassword: req.NewPassword,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserTokenInvalid), errors.Is(err, model.ErrUserNotFound):
+			response.BadRequest(c, response.CodeResetTokenInvalid, "Reset link is invalid or has expired")
+		case errors.Is(err, model.ErrPasswordRejected):
+			respondPasswordRejected(c, "newPassword", err)
+		default:
+			log.Printf("[ERROR] failed to reset password: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, nil, "Password has been reset. Please log in with your new password")
+}
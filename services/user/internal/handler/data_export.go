@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (h *UserHandler) RequestDataExport(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	export, err := h.userService.RequestDataExport(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrDataExportInProgress):
+			response.Conflict(c, response.CodeDataExportInProgress, "A data export is already in progress")
+		default:
+			log.Printf("[ERROR] failed to request data export: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.Created(c, NewDataExportResponse(export),
+		"Data export requested. We will email you a download link when it is ready")
+}
+
+func (h *UserHandler) GetDataExport(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	export, err := h.userService.GetDataExport(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrDataExportNotFound):
+			response.NotFound(c, response.CodeDataExportNotFound, "No data export requested yet")
+		default:
+			log.Printf("[ERROR] failed to get data export: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewDataExportResponse(export), "")
+}
+
+// DownloadDataExport streams the archive behind an emailed download link.
+// The token in the link is the only credential, so no login is needed.
+func (h *UserHandler) DownloadDataExport(c *gin.Context) {
+	export, file, err := h.userService.OpenDataExport(c.Request.Context(), c.Query("token"))
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrDataExportLinkInvalid):
+			response.NotFound(c, response.CodeDataExportLinkInvalid, "Download link is invalid or has expired")
+		default:
+			log.Printf("[ERROR] failed to open data export: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+	defer file.Close()
+
+	var size int64 = -1
+	if export.FileSize != nil {
+		size = *export.FileSize
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, size, "application/zip", file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`attachment; filename="data-export-%s.zip"`,
+			export.CreatedAt.UTC().Format("2006-01-02")),
+	})
+}
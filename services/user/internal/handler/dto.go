@@ -1,6 +1,10 @@
 package handler
 
-import "github.com/katatrina/airbnb-clone/services/user/internal/model"
+import (
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
 
 type RegisterRequest struct {
 	// Lowercase to prevent duplicates
@@ -21,19 +25,280 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	AccessToken          string       `json:"accessToken"`
-	AccessTokenExpiresAt int64        `json:"accessTokenExpiresAt"`
-	User                 UserResponse `json:"user"`
+	AccessToken           string       `json:"accessToken"`
+	AccessTokenExpiresAt  int64        `json:"accessTokenExpiresAt"`
+	RefreshToken          string       `json:"refreshToken"`
+	RefreshTokenExpiresAt int64        `json:"refreshTokenExpiresAt"`
+	User                  UserResponse `json:"user"`
+}
+
+// TwoFactorChallengeResponse is returned by login instead of tokens when the
+// user has 2FA enabled. The challenge token goes to /auth/login/2fa.
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired bool   `json:"twoFactorRequired"`
+	ChallengeToken    string `json:"challengeToken"`
+	ExpiresAt         int64  `json:"expiresAt"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required" normalize:"trim"`
+
+	// A 6-digit TOTP code or a recovery code like "abcde-fghjk"
+	Code string `json:"code" validate:"required,max=32" normalize:"trim"`
+}
+
+type OIDCStartResponse struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+}
+
+// OIDCCallbackRequest carries the query parameters the provider redirected
+// the browser back with.
+type OIDCCallbackRequest struct {
+	State string `json:"state" validate:"required,max=256" normalize:"trim"`
+	Code  string `json:"code" validate:"required,max=2048" normalize:"trim"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required" normalize:"trim"`
+}
+
+type RefreshTokenResponse struct {
+	AccessToken           string `json:"accessToken"`
+	AccessTokenExpiresAt  int64  `json:"accessTokenExpiresAt"`
+	RefreshToken          string `json:"refreshToken"`
+	RefreshTokenExpiresAt int64  `json:"refreshTokenExpiresAt"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required" normalize:"trim"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email" normalize:"trim,lower"`
+}
+
+type ResetPasswordRequest struct {
+	Token string `json:"token" validate:"required" normalize:"trim"`
+
+	// Same rules as RegisterRequest.Password
+	NewPassword string `json:"newPassword" validate:"required,min=8,maxbytes=72"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+
+	// Same rules as RegisterRequest.Password
+	NewPassword string `json:"newPassword" validate:"required,min=8,maxbytes=72"`
+}
+
+type EmailChangeRequest struct {
+	// Lowercase like RegisterRequest.Email
+	NewEmail string `json:"newEmail" validate:"required,email,max=255" normalize:"trim,lower"`
+	Password string `json:"password" validate:"required"`
+}
+
+type EmailChangeTokenRequest struct {
+	Token string `json:"token" validate:"required" normalize:"trim"`
+}
+
+type TOTPCodeRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" normalize:"trim"`
+}
+
+type VerifyPhoneRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric" normalize:"trim"`
+}
+
+type PhoneCodeSentResponse struct {
+	Phone     string `json:"phone"`
+	ExpiresAt int64  `json:"expiresAt"`
+
+	// ResendAt is when the client may ask for another code
+	ResendAt int64 `json:"resendAt"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type TOTPSetupResponse struct {
+	// Secret is for users who type it in instead of scanning the URI as a QR code
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool   `json:"enabled"`
+	EnabledAt              *int64 `json:"enabledAt"`
+	RecoveryCodesRemaining int64  `json:"recoveryCodesRemaining"`
+}
+
+type SessionResponse struct {
+	ID         string `json:"id"`
+	UserAgent  string `json:"userAgent"`
+	ClientIP   string `json:"clientIp"`
+	Current    bool   `json:"current"`
+	LastUsedAt int64  `json:"lastUsedAt"`
+	ExpiresAt  int64  `json:"expiresAt"`
+}
+
+type LoginEventResponse struct {
+	ID        string  `json:"id"`
+	Success   bool    `json:"success"`
+	Reason    *string `json:"reason"`
+	ClientIP  string  `json:"clientIp"`
+	UserAgent string  `json:"userAgent"`
+	NewDevice bool    `json:"newDevice"`
+	CreatedAt int64   `json:"createdAt"`
+}
+
+type DataExportResponse struct {
+	ID          string `json:"id"`
+	Status      string `json:"status"`
+	FileSize    *int64 `json:"fileSize,omitempty"`
+	CreatedAt   int64  `json:"createdAt"`
+	CompletedAt *int64 `json:"completedAt,omitempty"`
+	ExpiresAt   *int64 `json:"expiresAt,omitempty"`
+}
+
+type RejectHostVerificationRequest struct {
+	Reason string `json:"reason" validate:"required,min=10,max=1000" normalize:"trim"`
+}
+
+type HostVerificationResponse struct {
+	ID              string  `json:"id"`
+	UserID          string  `json:"userId"`
+	DocumentType    string  `json:"documentType"`
+	HasBackSide     bool    `json:"hasBackSide"`
+	Status          string  `json:"status"`
+	RejectionReason *string `json:"rejectionReason,omitempty"`
+	ReviewedBy      *string `json:"reviewedBy,omitempty"`
+	ReviewedAt      *int64  `json:"reviewedAt,omitempty"`
+	CreatedAt       int64   `json:"createdAt"`
+}
+
+// HostVerificationStatusResponse is what a host sees: where they stand, and
+// their latest submission if there is one.
+type HostVerificationStatusResponse struct {
+	Status string                    `json:"status"`
+	Latest *HostVerificationResponse `json:"latest"`
+}
+
+// UserHostVerificationResponse is returned to other services, which only need
+// to know whether a host is verified.
+type UserHostVerificationResponse struct {
+	UserID string `json:"userId"`
+	Status string `json:"status"`
+}
+
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"displayName" validate:"omitnil,min=2,max=100" normalize:"trim,singlespace"`
+	Bio         *string `json:"bio" validate:"omitnil,max=1000" normalize:"trim"`
+
+	// Vietnamese mobile number in E.164 format, e.g. +84901234567. Empty
+	// string removes the phone number. A new number has to be verified again.
+	Phone *string `json:"phone" validate:"omitnil,omitempty,vnphone" normalize:"trim"`
+
+	// BCP 47 tags, e.g. "vi", "en". Omit to keep, send [] to clear.
+	Languages []string `json:"languages" validate:"omitempty,max=10,dive,bcp47_language_tag"`
+}
+
+// UpdatePreferencesRequest replaces every preference at once.
+type UpdatePreferencesRequest struct {
+	Locale   string `json:"locale" validate:"required,oneof=vi en"`
+	Currency string `json:"currency" validate:"required,oneof=VND USD"`
+
+	// IANA time zone name, e.g. "Asia/Ho_Chi_Minh".
+	Timezone string `json:"timezone" validate:"required,timezone" normalize:"trim"`
+
+	Notifications *NotificationPreferencesDTO `json:"notifications" validate:"required"`
+}
+
+// NotificationPreferencesDTO lists the channels each kind of notification may
+// use. Security alerts are always sent by email and cannot be turned off.
+type NotificationPreferencesDTO struct {
+	BookingUpdates NotificationChannelsDTO `json:"bookingUpdates"`
+	HostingUpdates NotificationChannelsDTO `json:"hostingUpdates"`
+	Reminders      NotificationChannelsDTO `json:"reminders"`
+	Promotions     NotificationChannelsDTO `json:"promotions"`
+}
+
+type NotificationChannelsDTO struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+}
+
+func (d NotificationChannelsDTO) toModel() model.NotificationChannels {
+	return model.NotificationChannels{Email: d.Email, SMS: d.SMS}
+}
+
+func (d NotificationPreferencesDTO) toModel() model.NotificationPreferences {
+	return model.NotificationPreferences{
+		BookingUpdates: d.BookingUpdates.toModel(),
+		HostingUpdates: d.HostingUpdates.toModel(),
+		Reminders:      d.Reminders.toModel(),
+		Promotions:     d.Promotions.toModel(),
+	}
+}
+
+// PreferencesResponse is returned to the user and, through the internal API,
+// to other services deciding how to localize and notify.
+type PreferencesResponse struct {
+	UserID        string                     `json:"userId"`
+	Locale        string                     `json:"locale"`
+	Currency      string                     `json:"currency"`
+	Timezone      string                     `json:"timezone"`
+	Notifications NotificationPreferencesDTO `json:"notifications"`
+}
+
+func newNotificationChannelsDTO(c model.NotificationChannels) NotificationChannelsDTO {
+	return NotificationChannelsDTO{Email: c.Email, SMS: c.SMS}
+}
+
+func NewPreferencesResponse(user *model.User) PreferencesResponse {
+	n := user.Notifications
+	return PreferencesResponse{
+		UserID:   user.ID,
+		Locale:   user.Locale,
+		Currency: user.Currency,
+		Timezone: user.Timezone,
+		Notifications: NotificationPreferencesDTO{
+			BookingUpdates: newNotificationChannelsDTO(n.BookingUpdates),
+			HostingUpdates: newNotificationChannelsDTO(n.HostingUpdates),
+			Reminders:      newNotificationChannelsDTO(n.Reminders),
+			Promotions:     newNotificationChannelsDTO(n.Promotions),
+		},
+	}
+}
+
+type SetUserRolesRequest struct {
+	Roles []string `json:"roles" validate:"required,min=1,dive,oneof=guest host admin"`
 }
 
 type UserResponse struct {
-	ID            string `json:"id"`
-	DisplayName   string `json:"displayName"`
-	Email         string `json:"email"`
-	EmailVerified bool   `json:"emailVerified"`
-	LastLoginAt   *int64 `json:"lastLoginAt,omitempty"`
-	CreatedAt     int64  `json:"createdAt"`
-	UpdatedAt     int64  `json:"updatedAt"`
+	ID            string   `json:"id"`
+	DisplayName   string   `json:"displayName"`
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"emailVerified"`
+	Roles         []string `json:"roles"`
+	Bio           string   `json:"bio"`
+	Phone         *string  `json:"phone"`
+	Languages     []string `json:"languages"`
+	AvatarURL     *string  `json:"avatarUrl"`
+	LastLoginAt   *int64   `json:"lastLoginAt,omitempty"`
+	CreatedAt     int64    `json:"createdAt"`
+	UpdatedAt     int64    `json:"updatedAt"`
+
+	PhoneVerified          bool   `json:"phoneVerified"`
+	HostVerificationStatus string `json:"hostVerificationStatus"`
 }
 
 func NewUserResponse(user *model.User) UserResponse {
@@ -42,6 +307,11 @@ func NewUserResponse(user *model.User) UserResponse {
 		DisplayName:   user.DisplayName,
 		Email:         user.Email,
 		EmailVerified: user.EmailVerified,
+		Roles:         user.Roles,
+		Bio:           user.Bio,
+		Phone:         user.Phone,
+		Languages:     user.Languages,
+		AvatarURL:     user.AvatarURL,
 		LastLoginAt: func() *int64 {
 			if user.LastLoginAt == nil {
 				return nil
@@ -50,7 +320,109 @@ func NewUserResponse(user *model.User) UserResponse {
 			t := user.LastLoginAt.Unix()
 			return &t
 		}(),
-		CreatedAt: user.CreatedAt.Unix(),
-		UpdatedAt: user.UpdatedAt.Unix(),
+		CreatedAt:              user.CreatedAt.Unix(),
+		UpdatedAt:              user.UpdatedAt.Unix(),
+		PhoneVerified:          user.PhoneVerifiedAt != nil,
+		HostVerificationStatus: string(user.HostVerificationStatus),
+	}
+}
+
+// PublicUserResponse only carries fields that are safe to show to anyone.
+type PublicUserResponse struct {
+	ID           string   `json:"id"`
+	DisplayName  string   `json:"displayName"`
+	Bio          string   `json:"bio"`
+	Languages    []string `json:"languages"`
+	AvatarURL    *string  `json:"avatarUrl"`
+	ListingCount *int64   `json:"listingCount"`
+	HostVerified bool     `json:"hostVerified"`
+	JoinedAt     int64    `json:"joinedAt"`
+}
+
+func NewPublicUserResponse(profile *model.PublicProfile) PublicUserResponse {
+	return PublicUserResponse{
+		ID:           profile.User.ID,
+		DisplayName:  profile.User.DisplayName,
+		Bio:          profile.User.Bio,
+		Languages:    profile.User.Languages,
+		AvatarURL:    profile.User.AvatarURL,
+		ListingCount: profile.ListingCount,
+		HostVerified: profile.User.HostVerificationStatus == model.HostVerificationStatusVerified,
+		JoinedAt:     profile.User.CreatedAt.Unix(),
+	}
+}
+
+func NewSessionsResponse(sessions []model.Session, currentSessionID string) []SessionResponse {
+	resp := make([]SessionResponse, len(sessions))
+	for i := range sessions {
+		s := &sessions[i]
+		resp[i] = SessionResponse{
+			ID:         s.FamilyID,
+			UserAgent:  s.UserAgent,
+			ClientIP:   s.ClientIP,
+			Current:    s.FamilyID == currentSessionID,
+			LastUsedAt: s.CreatedAt.Unix(), // Each refresh creates a new row
+			ExpiresAt:  s.ExpiresAt.Unix(),
+		}
+	}
+	return resp
+}
+
+func NewLoginEventsResponse(events []model.LoginEvent) []LoginEventResponse {
+	resp := make([]LoginEventResponse, len(events))
+	for i := range events {
+		e := &events[i]
+		resp[i] = LoginEventResponse{
+			ID:        e.ID,
+			Success:   e.Success,
+			Reason:    e.Reason,
+			ClientIP:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			NewDevice: e.NewDevice,
+			CreatedAt: e.CreatedAt.Unix(),
+		}
+	}
+	return resp
+}
+
+func NewDataExportResponse(export *model.DataExport) DataExportResponse {
+	return DataExportResponse{
+		ID:          export.ID,
+		Status:      string(export.Status),
+		FileSize:    export.FileSize,
+		CreatedAt:   export.CreatedAt.Unix(),
+		CompletedAt: unixOrNil(export.CompletedAt),
+		ExpiresAt:   unixOrNil(export.ExpiresAt),
+	}
+}
+
+func NewHostVerificationResponse(v *model.HostVerification) HostVerificationResponse {
+	return HostVerificationResponse{
+		ID:              v.ID,
+		UserID:          v.UserID,
+		DocumentType:    string(v.DocumentType),
+		HasBackSide:     v.BackKey != nil,
+		Status:          string(v.Status),
+		RejectionReason: v.RejectionReason,
+		ReviewedBy:      v.ReviewedBy,
+		ReviewedAt:      unixOrNil(v.ReviewedAt),
+		CreatedAt:       v.CreatedAt.Unix(),
 	}
 }
+
+func NewHostVerificationsResponse(verifications []model.HostVerification) []HostVerificationResponse {
+	resp := make([]HostVerificationResponse, len(verifications))
+	for i := range verifications {
+		resp[i] = NewHostVerificationResponse(&verifications[i])
+	}
+	return resp
+}
+
+func unixOrNil(t *time.Time) *int64 {
+	if t == nil {
+		return nil
+	}
+
+	unix := t.Unix()
+	return &unix
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (h *UserHandler) RequestEmailChange(c *gin.Context) {
+	var req EmailChangeRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	userID := middleware.MustGetAuthUser(c).ID
+
+	err := h.userService.RequestEmailChange(c.Request.Context(), model.RequestEmailChangeParams{
+		UserID:   userID,
+		NewEmail: req.NewEmail,
+		Password: req.Password,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIncorrectPassword):
+			response.BadRequest(c, response.CodeCredentialsInvalid, "Password is incorrect")
+		case errors.Is(err, model.ErrEmailUnchanged):
+			response.BadRequest(c, response.CodeEmailUnchanged, "New email is the same as the current one")
+		case errors.Is(err, model.ErrEmailAlreadyExists):
+			response.Conflict(c, response.CodeEmailAlreadyExists, "Email already exists")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to request email change: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, nil, "Confirmation email sent to the new address")
+}
+
+func (h *UserHandler) ConfirmEmailChange(c *gin.Context) {
+	var req EmailChangeTokenRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	err := h.userService.ConfirmEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEmailChangeTokenInvalid):
+			response.BadRequest(c, response.CodeEmailChangeTokenInvalid,
+				"Confirmation link is invalid or has expired")
+		case errors.Is(err, model.ErrEmailAlreadyExists):
+			response.Conflict(c, response.CodeEmailAlreadyExists, "Email already exists")
+		default:
+			log.Printf("[ERROR] failed to confirm email change: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	// Access tokens carry the verification state, so the client has to refresh to pick it up
+	response.OK(c, nil, "Email changed successfully")
+}
+
+func (h *UserHandler) CancelEmailChange(c *gin.Context) {
+	var req EmailChangeTokenRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	err := h.userService.CancelEmailChange(c.Request.Context(), req.Token)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrEmailChangeTokenInvalid):
+			response.BadRequest(c, response.CodeEmailChangeTokenInvalid,
+				"Cancel link is invalid or has expired")
+		case errors.Is(err, model.ErrEmailAlreadyExists):
+			log.Printf("[WARN] old email was taken before the email change could be cancelled: %v", err)
+			response.Conflict(c, response.CodeEmailAlreadyExists,
+				"Your previous email is now used by another account. Please contact support")
+		default:
+			log.Printf("[ERROR] failed to cancel email change: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, nil, "Email change cancelled")
+}
@@ -1,6 +1,14 @@
 package handler
 
-import "github.com/katatrina/airbnb-clone/services/user/internal/service"
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/service"
+)
 
 type UserHandler struct {
 	userService *service.UserService
@@ -9,3 +17,25 @@ type UserHandler struct {
 func NewUserHandler(userService *service.UserService) *UserHandler {
 	return &UserHandler{userService: userService}
 }
+
+// respondPasswordRejected reports every password policy violation as an error
+// of field, like a failed request validation. The password itself is not
+// echoed back.
+func respondPasswordRejected(c *gin.Context, field string, err error) {
+	var rejected *model.PasswordRejectedError
+	if !errors.As(err, &rejected) {
+		response.BadRequest(c, response.CodeValidationFailed, "Password does not meet the password policy")
+		return
+	}
+
+	fieldErrors := make([]request.FieldError, len(rejected.Violations))
+	for i, v := range rejected.Violations {
+		fieldErrors[i] = request.FieldError{
+			Field:   field,
+			Code:    request.FieldErrorCode(v.Code),
+			Message: v.Message,
+		}
+	}
+
+	response.BadRequestWithErrors(c, response.CodeValidationFailed, "Validation failed", fieldErrors)
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/service"
+)
+
+// SubmitHostVerification takes a multipart form with "documentType" and the
+// "front" and (except for passports) "back" images of the ID document.
+func (h *UserHandler) SubmitHostVerification(c *gin.Context) {
+	// Two documents plus room for the multipart framing
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*service.MaxHostDocumentSize+1<<20)
+
+	documentType := model.HostDocumentType(c.PostForm("documentType"))
+	switch documentType {
+	case model.HostDocumentTypeNationalID, model.HostDocumentTypePassport, model.HostDocumentTypeDriversLicense:
+	default:
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(c.Request.ParseMultipartForm(32<<20), &maxBytesErr) {
+			response.BadRequest(c, response.CodeFileTooLarge, "Each document must be at most 10 MB")
+			return
+		}
+		response.BadRequest(c, response.CodeValidationFailed,
+			"documentType must be one of national_id, passport, drivers_license")
+		return
+	}
+
+	front, ok := openHostDocument(c, "front")
+	if !ok {
+		return
+	}
+	if front == nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Front side of the document is required")
+		return
+	}
+	defer front.Close()
+
+	back, ok := openHostDocument(c, "back")
+	if !ok {
+		return
+	}
+
+	arg := model.SubmitHostVerificationParams{
+		UserID:       middleware.MustGetAuthUser(c).ID,
+		DocumentType: documentType,
+		Front:        front,
+	}
+	if back != nil {
+		defer back.Close()
+		arg.Back = back
+	}
+
+	verification, err := h.userService.SubmitHostVerification(c.Request.Context(), arg)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrHostDocumentMissing):
+			response.BadRequest(c, response.CodeValidationFailed, "Both sides of the document are required")
+		case errors.Is(err, model.ErrHostDocumentTooLarge):
+			response.BadRequest(c, response.CodeFileTooLarge, "Each document must be at most 10 MB")
+		case errors.Is(err, model.ErrHostDocumentTypeUnsupported):
+			response.BadRequest(c, response.CodeFileTypeUnsupported, "Documents must be JPEG, PNG, WebP images or PDF files")
+		case errors.Is(err, model.ErrHostAlreadyVerified):
+			response.Conflict(c, response.CodeHostAlreadyVerified, "Your identity is already verified")
+		case errors.Is(err, model.ErrHostVerificationPending):
+			response.Conflict(c, response.CodeHostVerificationPending, "Your previous submission is still being reviewed")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to submit host verification: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.Created(c, newOwnHostVerificationResponse(verification), "Documents submitted for review")
+}
+
+func (h *UserHandler) GetHostVerification(c *gin.Context) {
+	user, latest, err := h.userService.GetHostVerification(c.Request.Context(), middleware.MustGetAuthUser(c).ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to get host verification: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	resp := HostVerificationStatusResponse{Status: string(user.HostVerificationStatus)}
+	if latest != nil {
+		latestResp := newOwnHostVerificationResponse(latest)
+		resp.Latest = &latestResp
+	}
+
+	response.OK(c, resp, "")
+}
+
+// ListHostVerifications is the admin review queue: pending submissions,
+// oldest first. Reviewed ones can be listed with ?status=verified|rejected.
+func (h *UserHandler) ListHostVerifications(c *gin.Context) {
+	status := model.HostVerificationStatus(c.DefaultQuery("status", string(model.HostVerificationStatusPending)))
+	switch status {
+	case model.HostVerificationStatusPending, model.HostVerificationStatusVerified, model.HostVerificationStatusRejected:
+	default:
+		response.BadRequest(c, response.CodeValidationFailed, "status must be one of pending, verified, rejected")
+		return
+	}
+
+	paginationParams := request.ParsePaginationParams(c)
+
+	verifications, total, err := h.userService.ListHostVerifications(
+		c.Request.Context(),
+		status,
+		paginationParams.Limit(),
+		paginationParams.Offset(),
+	)
+	if err != nil {
+		log.Printf("[ERROR] failed to list host verifications: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OKWithPagination(c, NewHostVerificationsResponse(verifications), "", paginationParams.Page, paginationParams.PageSize, total)
+}
+
+func (h *UserHandler) GetHostVerificationForReview(c *gin.Context) {
+	verificationID := c.Param("id")
+	if _, err := uuid.Parse(verificationID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid host verification ID format")
+		return
+	}
+
+	verification, err := h.userService.GetHostVerificationByID(c.Request.Context(), verificationID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrHostVerificationNotFound):
+			response.NotFound(c, response.CodeHostVerificationNotFound, "Host verification not found")
+		default:
+			log.Printf("[ERROR] failed to get host verification: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewHostVerificationResponse(verification), "")
+}
+
+// GetHostDocument streams one side ("front" or "back") of a submitted ID
+// document to the reviewing admin.
+func (h *UserHandler) GetHostDocument(c *gin.Context) {
+	verificationID := c.Param("id")
+	if _, err := uuid.Parse(verificationID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid host verification ID format")
+		return
+	}
+
+	side := model.HostDocumentSide(c.Param("side"))
+	if side != model.HostDocumentSideFront && side != model.HostDocumentSideBack {
+		response.BadRequest(c, response.CodeValidationFailed, "Document side must be front or back")
+		return
+	}
+
+	file, contentType, err := h.userService.OpenHostDocument(c.Request.Context(), verificationID, side)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrHostVerificationNotFound):
+			response.NotFound(c, response.CodeHostVerificationNotFound, "Document not found")
+		default:
+			log.Printf("[ERROR] failed to open host document: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+	defer file.Close()
+
+	// ID documents must never end up in a shared cache
+	c.Header("Cache-Control", "no-store")
+	c.DataFromReader(http.StatusOK, -1, contentType, file, map[string]string{
+		"Content-Disposition": fmt.Sprintf(`inline; filename="%s-%s"`, verificationID, side),
+	})
+}
+
+func (h *UserHandler) ApproveHostVerification(c *gin.Context) {
+	h.reviewHostVerification(c, true, "")
+}
+
+func (h *UserHandler) RejectHostVerification(c *gin.Context) {
+	var req RejectHostVerificationRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	h.reviewHostVerification(c, false, req.Reason)
+}
+
+func (h *UserHandler) reviewHostVerification(c *gin.Context, approve bool, rejectionReason string) {
+	verificationID := c.Param("id")
+	if _, err := uuid.Parse(verificationID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid host verification ID format")
+		return
+	}
+
+	verification, err := h.userService.ReviewHostVerification(c.Request.Context(), model.ReviewHostVerificationParams{
+		ID:              verificationID,
+		ReviewerID:      middleware.MustGetAuthUser(c).ID,
+		Approve:         approve,
+		RejectionReason: rejectionReason,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrHostVerificationNotFound):
+			response.NotFound(c, response.CodeHostVerificationNotFound, "Host verification not found")
+		case errors.Is(err, model.ErrHostVerificationAlreadyReviewed):
+			response.Conflict(c, response.CodeHostVerificationAlreadyReviewed, "Host verification has already been reviewed")
+		case errors.Is(err, model.ErrCannotReviewOwnHostVerification):
+			response.Forbidden(c, response.CodeForbidden, "You cannot review your own host verification")
+		default:
+			log.Printf("[ERROR] failed to review host verification: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewHostVerificationResponse(verification), "Host verification reviewed")
+}
+
+// openHostDocument opens an optional uploaded file. ok is false when an error
+// response was already written.
+func openHostDocument(c *gin.Context, field string) (file multipart.File, ok bool) {
+	fileHeader, err := c.FormFile(field)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.BadRequest(c, response.CodeFileTooLarge, "Each document must be at most 10 MB")
+			return nil, false
+		}
+		return nil, true
+	}
+
+	if fileHeader.Size > service.MaxHostDocumentSize {
+		response.BadRequest(c, response.CodeFileTooLarge, "Each document must be at most 10 MB")
+		return nil, false
+	}
+
+	file, err = fileHeader.Open()
+	if err != nil {
+		log.Printf("[ERROR] failed to open uploaded host document: %v", err)
+		response.InternalServerError(c)
+		return nil, false
+	}
+
+	return file, true
+}
+
+// newOwnHostVerificationResponse is the host's view of their submission,
+// without who reviewed it.
+func newOwnHostVerificationResponse(v *model.HostVerification) HostVerificationResponse {
+	resp := NewHostVerificationResponse(v)
+	resp.ReviewedBy = nil
+	return resp
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// GetUserHostVerification is called by the listing service before a listing
+// goes live, when it only accepts verified hosts.
+func (h *UserHandler) GetUserHostVerification(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	status, err := h.userService.GetHostVerificationStatus(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to get host verification status: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, UserHostVerificationResponse{UserID: userID, Status: string(status)}, "")
+}
+
+// GetUserPreferences lets other services localize messages and pick
+// notification channels for a user who is not the caller, e.g. the host of a
+// booking. The caller's own preferences are already in its access token.
+func (h *UserHandler) GetUserPreferences(c *gin.Context) {
+	userID := c.Param("id")
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to get user preferences: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewPreferencesResponse(user), "")
+}
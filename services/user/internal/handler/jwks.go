@@ -0,0 +1,17 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS publishes the public keys used to sign access tokens, so other services
+// can verify tokens without sharing a secret.
+//
+// The body is a plain JWK Set (RFC 7517) rather than our response envelope,
+// because that is the format JWT libraries expect.
+func (h *UserHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.userService.JWKS())
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (h *UserHandler) StartOIDCLogin(c *gin.Context) {
+	authURL, err := h.userService.StartOIDCLogin(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIdentityProviderNotFound):
+			response.NotFound(c, response.CodeIdentityProviderNotFound, "Identity provider not found")
+		case errors.Is(err, model.ErrIdentityProviderUnavailable):
+			log.Printf("[ERROR] identity provider is unavailable: %v", err)
+			response.ServiceUnavailable(c, "Identity provider is unavailable. Please try again later")
+		default:
+			log.Printf("[ERROR] failed to start OIDC login: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, OIDCStartResponse{AuthorizationURL: authURL}, "Redirect the user to the identity provider")
+}
+
+func (h *UserHandler) CompleteOIDCLogin(c *gin.Context) {
+	var req OIDCCallbackRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	result, err := h.userService.CompleteOIDCLogin(c.Request.Context(), model.CompleteOIDCLoginParams{
+		Provider:  c.Param("provider"),
+		State:     req.State,
+		Code:      req.Code,
+		UserAgent: c.Request.UserAgent(),
+		ClientIP:  c.ClientIP(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIdentityProviderNotFound):
+			response.NotFound(c, response.CodeIdentityProviderNotFound, "Identity provider not found")
+		case errors.Is(err, model.ErrOIDCStateInvalid):
+			response.Unauthorized(c, response.CodeOIDCStateInvalid,
+				"Login session is invalid or has expired. Please log in again")
+		case errors.Is(err, model.ErrOIDCAuthenticationFailed):
+			log.Printf("[WARN] OIDC authentication failed: %v", err)
+			response.Unauthorized(c, response.CodeOIDCAuthenticationFailed,
+				"Could not log in with the identity provider. Please try again")
+		case errors.Is(err, model.ErrOIDCEmailNotVerified):
+			response.Forbidden(c, response.CodeOIDCEmailNotVerified,
+				"The identity provider has not verified your email address")
+		case errors.Is(err, model.ErrEmailAlreadyExists):
+			response.Conflict(c, response.CodeEmailAlreadyExists, "Email already exists")
+		default:
+			log.Printf("[ERROR] failed to complete OIDC login: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	if result.TwoFactorChallenge != nil {
+		response.OK(c, TwoFactorChallengeResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    result.TwoFactorChallenge.Token,
+			ExpiresAt:         result.TwoFactorChallenge.ExpiresAt.Unix(),
+		}, "Two-factor authentication required")
+		return
+	}
+
+	response.OK(c, LoginResponse{
+		AccessToken:           result.AccessToken,
+		AccessTokenExpiresAt:  result.AccessTokenExpiresAt.Unix(),
+		RefreshToken:          result.RefreshToken,
+		RefreshTokenExpiresAt: result.RefreshTokenExpiresAt.Unix(),
+		User:                  NewUserResponse(result.User),
+	}, "User login successfully")
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// SendPhoneVerificationCode texts a code to the phone number on the profile.
+func (h *UserHandler) SendPhoneVerificationCode(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	sent, err := h.userService.SendPhoneVerificationCode(c.Request.Context(), userID)
+	if err != nil {
+		var throttled *model.PhoneCodeThrottledError
+		switch {
+		case errors.Is(err, model.ErrPhoneNumberMissing):
+			response.BadRequest(c, response.CodePhoneNumberMissing, "Add a phone number to your profile first")
+		case errors.Is(err, model.ErrPhoneAlreadyVerified):
+			response.Conflict(c, response.CodePhoneAlreadyVerified, "Phone number is already verified")
+		case errors.As(err, &throttled):
+			response.TooManyRequests(c, "Too many verification codes requested. Please try again later", throttled.RetryAfter)
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to send phone verification code: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, PhoneCodeSentResponse{
+		Phone:     sent.Phone,
+		ExpiresAt: sent.ExpiresAt.Unix(),
+		ResendAt:  sent.ResendAt.Unix(),
+	}, "Verification code sent")
+}
+
+func (h *UserHandler) VerifyPhone(c *gin.Context) {
+	var req VerifyPhoneRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	err := h.userService.VerifyPhone(c.Request.Context(), model.VerifyPhoneParams{
+		UserID: middleware.MustGetAuthUser(c).ID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrPhoneCodeInvalid):
+			response.BadRequest(c, response.CodePhoneCodeInvalid, "Verification code is incorrect")
+		case errors.Is(err, model.ErrPhoneCodeExpired):
+			response.BadRequest(c, response.CodePhoneCodeExpired, "Verification code has expired. Please request a new one")
+		case errors.Is(err, model.ErrPhoneNumberMissing):
+			response.BadRequest(c, response.CodePhoneNumberMissing, "Add a phone number to your profile first")
+		case errors.Is(err, model.ErrPhoneAlreadyVerified):
+			response.Conflict(c, response.CodePhoneAlreadyVerified, "Phone number is already verified")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to verify phone: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, nil, "Phone number verified successfully")
+}
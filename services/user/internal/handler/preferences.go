@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (h *UserHandler) GetPreferences(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to get preferences: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewPreferencesResponse(user), "")
+}
+
+// UpdatePreferences replaces all preferences. The new locale, currency and
+// timezone reach other services with the next access token.
+func (h *UserHandler) UpdatePreferences(c *gin.Context) {
+	var req UpdatePreferencesRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	userID := middleware.MustGetAuthUser(c).ID
+
+	user, err := h.userService.UpdatePreferences(c.Request.Context(), userID, model.UserPreferences{
+		Locale:        req.Locale,
+		Currency:      req.Currency,
+		Timezone:      req.Timezone,
+		Notifications: req.Notifications.toModel(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to update preferences: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewPreferencesResponse(user), "Preferences updated successfully")
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	authUser := middleware.MustGetAuthUser(c)
+
+	sessions, err := h.userService.ListSessions(c.Request.Context(), authUser.ID)
+	if err != nil {
+		log.Printf("[ERROR] failed to list sessions: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OK(c, NewSessionsResponse(sessions, authUser.SessionID), "")
+}
+
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+	sessionID := c.Param("id")
+
+	if _, err := uuid.Parse(sessionID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid session ID format")
+		return
+	}
+
+	err := h.userService.RevokeSession(c.Request.Context(), userID, sessionID)
+	if err != nil {
+		if errors.Is(err, model.ErrSessionNotFound) {
+			response.NotFound(c, response.CodeSessionNotFound, "Session not found")
+			return
+		}
+
+		log.Printf("[ERROR] failed to revoke session: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// RevokeOtherSessions logs out every device except the one making the request.
+func (h *UserHandler) RevokeOtherSessions(c *gin.Context) {
+	authUser := middleware.MustGetAuthUser(c)
+
+	err := h.userService.RevokeOtherSessions(c.Request.Context(), authUser.ID, authUser.SessionID)
+	if err != nil {
+		log.Printf("[ERROR] failed to revoke other sessions: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *UserHandler) ListLoginEvents(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+	paginationParams := request.ParsePaginationParams(c)
+
+	events, total, err := h.userService.ListLoginEvents(
+		c.Request.Context(),
+		userID,
+		paginationParams.Limit(),
+		paginationParams.Offset(),
+	)
+	if err != nil {
+		log.Printf("[ERROR] failed to list login events: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	response.OKWithPagination(c, NewLoginEventsResponse(events), "", paginationParams.Page, paginationParams.PageSize, total)
+}
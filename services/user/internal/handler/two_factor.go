@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"log"
+
+	"github.com/gin-gonic/gin"
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
+	"github.com/katatrina/airbnb-clone/pkg/response"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (h *UserHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	status, err := h.userService.GetTwoFactorStatus(c.Request.Context(), userID)
+	if err != nil {
+		log.Printf("[ERROR] failed to get two-factor status: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+
+	resp := TwoFactorStatusResponse{
+		Enabled:                status.Enabled,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	}
+	if status.EnabledAt != nil {
+		enabledAt := status.EnabledAt.Unix()
+		resp.EnabledAt = &enabledAt
+	}
+
+	response.OK(c, resp, "")
+}
+
+func (h *UserHandler) SetupTOTP(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	setup, err := h.userService.SetupTOTP(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrTwoFactorAlreadyEnabled):
+			response.Conflict(c, response.CodeTwoFactorAlreadyEnabled, "Two-factor authentication is already enabled")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to set up TOTP: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, TOTPSetupResponse{
+		Secret: setup.Secret,
+		URI:    setup.URI,
+	}, "Scan the code with your authenticator app, then confirm with a code from the app")
+}
+
+func (h *UserHandler) EnableTOTP(c *gin.Context) {
+	var req TOTPCodeRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	codes, err := h.userService.EnableTOTP(c.Request.Context(), model.EnableTOTPParams{
+		UserID: middleware.MustGetAuthUser(c).ID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrTwoFactorSetupNotStarted):
+			response.BadRequest(c, response.CodeTwoFactorSetupNotStarted, "Two-factor setup has not been started")
+		case errors.Is(err, model.ErrTwoFactorAlreadyEnabled):
+			response.Conflict(c, response.CodeTwoFactorAlreadyEnabled, "Two-factor authentication is already enabled")
+		case errors.Is(err, model.ErrTwoFactorCodeInvalid):
+			response.BadRequest(c, response.CodeTwoFactorCodeInvalid, "Invalid authentication code")
+		default:
+			log.Printf("[ERROR] failed to enable TOTP: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, RecoveryCodesResponse{RecoveryCodes: codes},
+		"Two-factor authentication enabled. Store the recovery codes somewhere safe, they are only shown once")
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req TOTPCodeRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	codes, err := h.userService.RegenerateRecoveryCodes(c.Request.Context(), model.RegenerateRecoveryCodesParams{
+		UserID: middleware.MustGetAuthUser(c).ID,
+		Code:   req.Code,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrTwoFactorNotEnabled):
+			response.BadRequest(c, response.CodeTwoFactorNotEnabled, "Two-factor authentication is not enabled")
+		case errors.Is(err, model.ErrTwoFactorCodeInvalid):
+			response.BadRequest(c, response.CodeTwoFactorCodeInvalid, "Invalid authentication code")
+		default:
+			log.Printf("[ERROR] failed to regenerate recovery codes: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, RecoveryCodesResponse{RecoveryCodes: codes},
+		"New recovery codes generated. The previous codes no longer work")
+}
+
+func (h *UserHandler) DisableTwoFactor(c *gin.Context) {
+	var req DisableTwoFactorRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	err := h.userService.DisableTwoFactor(c.Request.Context(), model.DisableTwoFactorParams{
+		UserID:   middleware.MustGetAuthUser(c).ID,
+		Password: req.Password,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIncorrectPassword):
+			response.BadRequest(c, response.CodeCredentialsInvalid, "Password is incorrect")
+		case errors.Is(err, model.ErrTwoFactorNotEnabled):
+			response.BadRequest(c, response.CodeTwoFactorNotEnabled, "Two-factor authentication is not enabled")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to disable two-factor authentication: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
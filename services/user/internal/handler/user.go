@@ -3,11 +3,15 @@ package handler
 import (
 	"errors"
 	"log"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/pkg/request"
 	"github.com/katatrina/airbnb-clone/pkg/response"
 	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/service"
 )
 
 func (h *UserHandler) GetProfile(c *gin.Context) {
@@ -37,3 +41,195 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 	response.OK(c, NewUserResponse(user), "")
 }
+
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	authUser := middleware.MustGetAuthUser(c)
+
+	err := h.userService.ChangePassword(c.Request.Context(), model.ChangePasswordParams{
+		UserID:          authUser.ID,
+		SessionID:       authUser.SessionID,
+		CurrentPassword: req.CurrentPassword,
+		NewPassword:     req.NewPassword,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIncorrectPassword):
+			response.BadRequest(c, response.CodeCredentialsInvalid, "Current password is incorrect")
+		case errors.Is(err, model.ErrPasswordRejected):
+			respondPasswordRejected(c, "newPassword", err)
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to change password: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, nil, "Password changed successfully. Other devices have been logged out")
+}
+
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	var req DeleteAccountRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	userID := middleware.MustGetAuthUser(c).ID
+
+	err := h.userService.DeleteAccount(c.Request.Context(), model.DeleteAccountParams{
+		UserID:   userID,
+		Password: req.Password,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrIncorrectPassword):
+			response.BadRequest(c, response.CodeCredentialsInvalid, "Password is incorrect")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		case errors.Is(err, model.ErrAccountHasUpcomingBookings):
+			response.Conflict(c, response.CodeAccountHasUpcomingBookings,
+				"You have upcoming confirmed bookings as a guest or host. Cancel or complete them before deleting your account")
+		case errors.Is(err, model.ErrBookingServiceUnavailable),
+			errors.Is(err, model.ErrListingServiceUnavailable):
+			response.ServiceUnavailable(c, "Unable to delete your account right now. Please try again later")
+		default:
+			log.Printf("[ERROR] failed to delete account: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.NoContent(c)
+}
+
+func (h *UserHandler) UpdateProfile(c *gin.Context) {
+	var req UpdateProfileRequest
+
+	if err := request.ShouldBindJSON(c, &req); err != nil {
+		response.HandleJSONBindingError(c, err)
+		return
+	}
+
+	userID := middleware.MustGetAuthUser(c).ID
+
+	user, err := h.userService.UpdateProfile(c.Request.Context(), userID, model.UpdateUserProfileParams{
+		DisplayName: req.DisplayName,
+		Bio:         req.Bio,
+		Phone:       req.Phone,
+		Languages:   req.Languages,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to update profile: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewUserResponse(user), "Profile updated successfully")
+}
+
+// UploadAvatar accepts a multipart form with the image in the "avatar" field.
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	// Leave some room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, service.MaxAvatarSize+1<<20)
+
+	fileHeader, err := c.FormFile("avatar")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.BadRequest(c, response.CodeFileTooLarge, "Avatar must be at most 5 MB")
+			return
+		}
+		response.BadRequest(c, response.CodeValidationFailed, "Avatar file is required")
+		return
+	}
+
+	if fileHeader.Size > service.MaxAvatarSize {
+		response.BadRequest(c, response.CodeFileTooLarge, "Avatar must be at most 5 MB")
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		log.Printf("[ERROR] failed to open uploaded avatar: %v", err)
+		response.InternalServerError(c)
+		return
+	}
+	defer file.Close()
+
+	user, err := h.userService.UploadAvatar(c.Request.Context(), model.UploadAvatarParams{
+		UserID: middleware.MustGetAuthUser(c).ID,
+		File:   file,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrAvatarTooLarge):
+			response.BadRequest(c, response.CodeFileTooLarge, "Avatar must be at most 5 MB")
+		case errors.Is(err, model.ErrAvatarTypeUnsupported):
+			response.BadRequest(c, response.CodeFileTypeUnsupported, "Avatar must be a JPEG, PNG or WebP image")
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to upload avatar: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewUserResponse(user), "Avatar updated successfully")
+}
+
+func (h *UserHandler) DeleteAvatar(c *gin.Context) {
+	userID := middleware.MustGetAuthUser(c).ID
+
+	user, err := h.userService.DeleteAvatar(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to delete avatar: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewUserResponse(user), "Avatar removed successfully")
+}
+
+func (h *UserHandler) GetPublicProfile(c *gin.Context) {
+	userID := c.Param("id")
+
+	if _, err := uuid.Parse(userID); err != nil {
+		response.BadRequest(c, response.CodeValidationFailed, "Invalid user ID format")
+		return
+	}
+
+	profile, err := h.userService.GetPublicProfile(c.Request.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, model.ErrUserNotFound):
+			response.NotFound(c, response.CodeUserNotFound, "User not found")
+		default:
+			log.Printf("[ERROR] failed to get public profile: %v", err)
+			response.InternalServerError(c)
+		}
+		return
+	}
+
+	response.OK(c, NewPublicUserResponse(profile), "")
+}
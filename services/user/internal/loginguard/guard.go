@@ -0,0 +1,121 @@
+// Package loginguard slows down and then blocks password guessing.
+//
+// Failed logins are counted per email and per client IP. After a few free
+// attempts each further failure requires a growing wait before the next try,
+// and at MaxAttempts the key is locked for LockoutDuration. Waiting is
+// enforced by rejecting early attempts (the handler answers 429 with
+// Retry-After), not by sleeping, so attackers cannot tie up the server.
+package loginguard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// Policy controls how quickly a key gets throttled.
+type Policy struct {
+	// FreeAttempts is how many failures are allowed without any wait.
+	FreeAttempts int
+
+	// BaseDelay is the wait after the first failure past FreeAttempts.
+	// It doubles with every further failure, up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// MaxAttempts failures lock the key for LockoutDuration after the last one.
+	MaxAttempts     int
+	LockoutDuration time.Duration
+
+	// Window is how long failures are remembered after the last one.
+	Window time.Duration
+}
+
+// blockedUntil returns when the next attempt is allowed, or the zero time if it already is.
+func (p Policy) blockedUntil(r Record) time.Time {
+	switch {
+	case r.Failures >= p.MaxAttempts:
+		return r.LastFailure.Add(p.LockoutDuration)
+	case r.Failures > p.FreeAttempts:
+		delay := p.BaseDelay
+		for i := p.FreeAttempts + 1; i < r.Failures && delay < p.MaxDelay; i++ {
+			delay *= 2
+		}
+		return r.LastFailure.Add(min(delay, p.MaxDelay))
+	default:
+		return time.Time{}
+	}
+}
+
+// ttl keeps a record at least as long as the lockout it may cause.
+func (p Policy) ttl() time.Duration {
+	return max(p.Window, p.LockoutDuration)
+}
+
+type Guard struct {
+	store       Store
+	emailPolicy Policy
+	ipPolicy    Policy
+	now         func() time.Time
+}
+
+// NewGuard creates a Guard. The IP policy should be looser than the email
+// policy, since many users can share one address behind a NAT.
+func NewGuard(store Store, emailPolicy, ipPolicy Policy) *Guard {
+	return &Guard{
+		store:       store,
+		emailPolicy: emailPolicy,
+		ipPolicy:    ipPolicy,
+		now:         time.Now,
+	}
+}
+
+// Check reports how long the caller has to wait before a login attempt for
+// email from clientIP is allowed. Zero means it may proceed.
+func (g *Guard) Check(ctx context.Context, email, clientIP string) (time.Duration, error) {
+	emailRecord, err := g.store.Get(ctx, emailKey(email))
+	if err != nil {
+		return 0, err
+	}
+
+	ipRecord, err := g.store.Get(ctx, ipKey(clientIP))
+	if err != nil {
+		return 0, err
+	}
+
+	until := g.emailPolicy.blockedUntil(emailRecord)
+	if ipUntil := g.ipPolicy.blockedUntil(ipRecord); ipUntil.After(until) {
+		until = ipUntil
+	}
+
+	return max(until.Sub(g.now()), 0), nil
+}
+
+// RecordFailure counts a failed attempt against both the email and the IP.
+func (g *Guard) RecordFailure(ctx context.Context, email, clientIP string) error {
+	now := g.now()
+
+	if _, err := g.store.RecordFailure(ctx, emailKey(email), now, g.emailPolicy.ttl()); err != nil {
+		return err
+	}
+
+	_, err := g.store.RecordFailure(ctx, ipKey(clientIP), now, g.ipPolicy.ttl())
+	return err
+}
+
+// RecordSuccess clears the failures of the email. The IP counter is kept on
+// purpose: otherwise an attacker could reset it by logging into their own account.
+func (g *Guard) RecordSuccess(ctx context.Context, email string) error {
+	return g.store.Reset(ctx, emailKey(email))
+}
+
+// emailKey hashes the address so the store does not hold email addresses.
+func emailKey(email string) string {
+	sum := sha256.Sum256([]byte(email))
+	return "email:" + hex.EncodeToString(sum[:])
+}
+
+func ipKey(clientIP string) string {
+	return "ip:" + clientIP
+}
@@ -0,0 +1,176 @@
+package loginguard
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock is a settable time source for the guard and the memory store.
+type fakeClock struct {
+	t time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{t: time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+var testEmailPolicy = Policy{
+	FreeAttempts:    3,
+	BaseDelay:       time.Second,
+	MaxDelay:        8 * time.Second,
+	MaxAttempts:     10,
+	LockoutDuration: 15 * time.Minute,
+	Window:          time.Hour,
+}
+
+var testIPPolicy = Policy{
+	FreeAttempts:    20,
+	BaseDelay:       time.Second,
+	MaxDelay:        time.Minute,
+	MaxAttempts:     100,
+	LockoutDuration: time.Hour,
+	Window:          time.Hour,
+}
+
+// newTestGuard returns a guard over a memory store, both reading clock.
+func newTestGuard(clock *fakeClock) *Guard {
+	store := NewMemoryStore()
+	store.now = clock.now
+
+	guard := NewGuard(store, testEmailPolicy, testIPPolicy)
+	guard.now = clock.now
+	return guard
+}
+
+func TestPolicyBlockedUntil(t *testing.T) {
+	last := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		failures  int
+		wantDelay time.Duration // zero means not blocked
+	}{
+		{failures: 0},
+		{failures: 3},
+		{failures: 4, wantDelay: time.Second},
+		{failures: 5, wantDelay: 2 * time.Second},
+		{failures: 6, wantDelay: 4 * time.Second},
+		{failures: 7, wantDelay: 8 * time.Second},
+		{failures: 9, wantDelay: 8 * time.Second},
+		{failures: 10, wantDelay: 15 * time.Minute},
+		{failures: 50, wantDelay: 15 * time.Minute},
+	}
+
+	for _, tc := range testCases {
+		until := testEmailPolicy.blockedUntil(Record{Failures: tc.failures, LastFailure: last})
+
+		if tc.wantDelay == 0 {
+			assert.True(t, until.IsZero(), "%d failures", tc.failures)
+			continue
+		}
+		assert.Equal(t, tc.wantDelay, until.Sub(last), "%d failures", tc.failures)
+	}
+}
+
+func TestPolicyTTLCoversTheLockout(t *testing.T) {
+	p := testEmailPolicy
+	assert.Equal(t, time.Hour, p.ttl())
+
+	p.LockoutDuration = 2 * time.Hour
+	assert.Equal(t, 2*time.Hour, p.ttl())
+}
+
+func TestGuardDelaysThenLocksOut(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	guard := newTestGuard(clock)
+	const email, ip = "user@example.com", "203.0.113.7"
+
+	fail := func() {
+		t.Helper()
+		require.NoError(t, guard.RecordFailure(ctx, email, ip))
+	}
+	wait := func() time.Duration {
+		t.Helper()
+		d, err := guard.Check(ctx, email, ip)
+		require.NoError(t, err)
+		return d
+	}
+
+	for range testEmailPolicy.FreeAttempts {
+		fail()
+	}
+	assert.Zero(t, wait(), "free attempts")
+
+	fail()
+	assert.Equal(t, time.Second, wait())
+	clock.advance(400 * time.Millisecond)
+	assert.Equal(t, 600*time.Millisecond, wait(), "the wait counts down")
+	clock.advance(600 * time.Millisecond)
+	assert.Zero(t, wait())
+
+	fail()
+	assert.Equal(t, 2*time.Second, wait(), "the delay doubles")
+
+	for range testEmailPolicy.MaxAttempts - testEmailPolicy.FreeAttempts - 2 {
+		fail()
+	}
+	assert.Equal(t, testEmailPolicy.LockoutDuration, wait(), "locked out at MaxAttempts")
+
+	// Another address from the same IP is not locked out
+	d, err := guard.Check(ctx, "other@example.com", ip)
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	clock.advance(testEmailPolicy.LockoutDuration - time.Second)
+	assert.Equal(t, time.Second, wait())
+	clock.advance(time.Second)
+	assert.Zero(t, wait(), "the lockout expires")
+}
+
+func TestGuardForgetsFailuresAfterTheWindow(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	guard := newTestGuard(clock)
+	const email, ip = "user@example.com", "203.0.113.7"
+
+	for range testEmailPolicy.FreeAttempts + 1 {
+		require.NoError(t, guard.RecordFailure(ctx, email, ip))
+	}
+
+	clock.advance(testEmailPolicy.ttl() + time.Second)
+	require.NoError(t, guard.RecordFailure(ctx, email, ip))
+
+	d, err := guard.Check(ctx, email, ip)
+	require.NoError(t, err)
+	assert.Zero(t, d, "the count started over")
+}
+
+func TestGuardSuccessClearsOnlyTheEmail(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	guard := newTestGuard(clock)
+	const ip = "203.0.113.7"
+
+	// Spread over many addresses, so only the IP policy kicks in
+	for i := range testIPPolicy.FreeAttempts + 1 {
+		require.NoError(t, guard.RecordFailure(ctx, fmt.Sprintf("user%d@example.com", i), ip))
+	}
+
+	d, err := guard.Check(ctx, "new@example.com", ip)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, d, "the IP is throttled")
+
+	require.NoError(t, guard.RecordSuccess(ctx, "new@example.com"))
+	d, err = guard.Check(ctx, "new@example.com", ip)
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, d, "logging in does not reset the IP")
+}
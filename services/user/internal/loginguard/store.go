@@ -0,0 +1,205 @@
+package loginguard
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Record is the failed login history of one key (an email or an IP address).
+type Record struct {
+	Failures    int
+	LastFailure time.Time
+}
+
+// Store keeps failed login counters. Counters expire ttl after the last
+// failure, so old mistakes are eventually forgotten.
+type Store interface {
+	Get(ctx context.Context, key string) (Record, error)
+	RecordFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Record, error)
+	Reset(ctx context.Context, key string) error
+}
+
+const failuresKeyPrefix = "auth:login:failures:"
+
+// RedisStore shares counters between replicas of the user service.
+//
+// Keys: auth:login:failures:{key} -> hash {count, last (unix ms)}
+type RedisStore struct {
+	client *redis.Client
+}
+
+var _ Store = (*RedisStore)(nil)
+
+func NewRedisStore(client *redis.Client) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Record, error) {
+	values, err := s.client.HMGet(ctx, failuresKeyPrefix+key, "count", "last").Result()
+	if err != nil {
+		return Record{}, err
+	}
+
+	if values[0] == nil || values[1] == nil {
+		return Record{}, nil
+	}
+
+	rawCount, ok1 := values[0].(string)
+	rawLast, ok2 := values[1].(string)
+	if !ok1 || !ok2 {
+		return Record{}, errors.New("unexpected login failure record value type")
+	}
+
+	count, err := strconv.Atoi(rawCount)
+	if err != nil {
+		return Record{}, err
+	}
+	last, err := strconv.ParseInt(rawLast, 10, 64)
+	if err != nil {
+		return Record{}, err
+	}
+
+	return Record{Failures: count, LastFailure: time.UnixMilli(last)}, nil
+}
+
+// RecordFailure increments the counter in a MULTI block, so concurrent
+// failures are all counted.
+func (s *RedisStore) RecordFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Record, error) {
+	redisKey := failuresKeyPrefix + key
+
+	pipe := s.client.TxPipeline()
+	count := pipe.HIncrBy(ctx, redisKey, "count", 1)
+	pipe.HSet(ctx, redisKey, "last", at.UnixMilli())
+	pipe.Expire(ctx, redisKey, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return Record{}, err
+	}
+
+	return Record{Failures: int(count.Val()), LastFailure: at}, nil
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	return s.client.Del(ctx, failuresKeyPrefix+key).Err()
+}
+
+// MemoryStore keeps counters in process. On its own it only suits tests and
+// single-instance setups; in production it backs up RedisStore via FallbackStore.
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]memoryRecord
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// memorySweepInterval is how often expired counters are dropped, so keys that
+// are never seen again do not pile up.
+const memorySweepInterval = time.Minute
+
+var _ Store = (*MemoryStore)(nil)
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]memoryRecord), now: time.Now}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[key]
+	if !ok || s.now().After(record.expiresAt) {
+		return Record{}, nil
+	}
+
+	return record.Record, nil
+}
+
+func (s *MemoryStore) RecordFailure(_ context.Context, key string, at time.Time, ttl time.Duration) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) > memorySweepInterval {
+		for k, r := range s.records {
+			if now.After(r.expiresAt) {
+				delete(s.records, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	record := s.records[key]
+	if now.After(record.expiresAt) {
+		record = memoryRecord{}
+	}
+
+	record.Failures++
+	record.LastFailure = at
+	record.expiresAt = now.Add(ttl)
+	s.records[key] = record
+
+	return record.Record, nil
+}
+
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
+
+// FallbackStore uses primary and switches to fallback for any call that fails,
+// so a Redis outage neither locks everyone out nor switches protection off.
+// Counters kept in the fallback are per replica and are not merged back.
+type FallbackStore struct {
+	primary  Store
+	fallback Store
+}
+
+var _ Store = (*FallbackStore)(nil)
+
+func NewFallbackStore(primary, fallback Store) *FallbackStore {
+	return &FallbackStore{primary: primary, fallback: fallback}
+}
+
+func (s *FallbackStore) Get(ctx context.Context, key string) (Record, error) {
+	record, err := s.primary.Get(ctx, key)
+	if err != nil {
+		log.Printf("[WARN] login attempt store unavailable, using in-memory fallback: %v", err)
+		return s.fallback.Get(ctx, key)
+	}
+
+	return record, nil
+}
+
+func (s *FallbackStore) RecordFailure(ctx context.Context, key string, at time.Time, ttl time.Duration) (Record, error) {
+	record, err := s.primary.RecordFailure(ctx, key, at, ttl)
+	if err != nil {
+		log.Printf("[WARN] login attempt store unavailable, using in-memory fallback: %v", err)
+		return s.fallback.RecordFailure(ctx, key, at, ttl)
+	}
+
+	return record, nil
+}
+
+func (s *FallbackStore) Reset(ctx context.Context, key string) error {
+	// Clear both, the fallback may hold failures from an earlier outage
+	_ = s.fallback.Reset(ctx, key)
+
+	if err := s.primary.Reset(ctx, key); err != nil {
+		log.Printf("[WARN] login attempt store unavailable, using in-memory fallback: %v", err)
+	}
+
+	return nil
+}
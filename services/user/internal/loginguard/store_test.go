@@ -0,0 +1,129 @@
+package loginguard
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStore runs the scenarios every Store must pass.
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	key := "email:" + uuid.NewString()
+	at := time.Now().Truncate(time.Millisecond)
+
+	record, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, Record{}, record, "unknown key")
+
+	for i := 1; i <= 3; i++ {
+		record, err = store.RecordFailure(ctx, key, at.Add(time.Duration(i)*time.Second), time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, record.Failures)
+	}
+
+	record, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, record.Failures)
+	assert.True(t, at.Add(3*time.Second).Equal(record.LastFailure), "got %v", record.LastFailure)
+
+	record, err = store.Get(ctx, "email:"+uuid.NewString())
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures, "keys are independent")
+
+	require.NoError(t, store.Reset(ctx, key))
+	record, err = store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, Record{}, record)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+// TestRedisStore runs the store scenarios against a real Redis.
+// Set REDIS_TEST_ADDR (e.g. localhost:6379) to run it.
+func TestRedisStore(t *testing.T) {
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR is not set")
+	}
+
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+	require.NoError(t, client.Ping(context.Background()).Err())
+
+	testStore(t, NewRedisStore(client))
+}
+
+func TestMemoryStoreExpiresRecords(t *testing.T) {
+	ctx := context.Background()
+	clock := newFakeClock()
+	store := NewMemoryStore()
+	store.now = clock.now
+
+	_, err := store.RecordFailure(ctx, "ip:203.0.113.7", clock.now(), time.Minute)
+	require.NoError(t, err)
+	_, err = store.RecordFailure(ctx, "ip:198.51.100.1", clock.now(), time.Minute)
+	require.NoError(t, err)
+
+	clock.advance(time.Minute + time.Second)
+	record, err := store.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures, "expired")
+
+	// The next failure starts over and sweeps the keys never seen again
+	record, err = store.RecordFailure(ctx, "ip:203.0.113.7", clock.now(), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, record.Failures)
+	assert.NotContains(t, store.records, "ip:198.51.100.1")
+}
+
+// downRedisStore is a RedisStore whose every command fails, as during an outage.
+func downRedisStore(t *testing.T) *RedisStore {
+	// Nothing listens on port 1
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() })
+
+	return NewRedisStore(client)
+}
+
+func TestFallbackStoreWhenRedisIsDown(t *testing.T) {
+	testStore(t, NewFallbackStore(downRedisStore(t), NewMemoryStore()))
+}
+
+func TestFallbackStorePrefersThePrimary(t *testing.T) {
+	ctx := context.Background()
+	primary, fallback := NewMemoryStore(), NewMemoryStore()
+	store := NewFallbackStore(primary, fallback)
+
+	_, err := store.RecordFailure(ctx, "ip:203.0.113.7", time.Now(), time.Minute)
+	require.NoError(t, err)
+
+	record, err := primary.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, 1, record.Failures)
+
+	record, err = fallback.Get(ctx, "ip:203.0.113.7")
+	require.NoError(t, err)
+	assert.Zero(t, record.Failures, "the fallback is only written during an outage")
+}
+
+func TestGuardKeepsThrottlingWhenRedisIsDown(t *testing.T) {
+	ctx := context.Background()
+	guard := NewGuard(NewFallbackStore(downRedisStore(t), NewMemoryStore()), testEmailPolicy, testIPPolicy)
+
+	for range testEmailPolicy.MaxAttempts {
+		require.NoError(t, guard.RecordFailure(ctx, "user@example.com", "203.0.113.7"))
+	}
+
+	d, err := guard.Check(ctx, "user@example.com", "203.0.113.7")
+	require.NoError(t, err)
+	assert.Greater(t, d, testEmailPolicy.LockoutDuration-time.Minute)
+}
@@ -0,0 +1,33 @@
+package model
+
+import "time"
+
+// DataExportStatus is the state of a personal data export job.
+type DataExportStatus string
+
+const (
+	DataExportStatusPending    DataExportStatus = "pending"
+	DataExportStatusProcessing DataExportStatus = "processing"
+	DataExportStatusCompleted  DataExportStatus = "completed"
+	DataExportStatusFailed     DataExportStatus = "failed"
+
+	// DataExportStatusExpired is a completed export whose archive was deleted.
+	DataExportStatusExpired DataExportStatus = "expired"
+)
+
+// DataExport is a job that packs a user's personal data into a ZIP archive.
+type DataExport struct {
+	ID     string           `db:"id"`
+	UserID string           `db:"user_id"`
+	Status DataExportStatus `db:"status"`
+
+	// FileKey and FileSize describe the archive once the export completed.
+	FileKey  *string `db:"file_key"`
+	FileSize *int64  `db:"file_size"`
+	Error    *string `db:"error"`
+
+	StartedAt   *time.Time `db:"started_at"`
+	CompletedAt *time.Time `db:"completed_at"`
+	ExpiresAt   *time.Time `db:"expires_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
@@ -1,6 +1,9 @@
 package model
 
-import "time"
+import (
+	"io"
+	"time"
+)
 
 type CreateUserParams struct {
 	DisplayName string
@@ -9,12 +12,162 @@ type CreateUserParams struct {
 }
 
 type LoginUserParams struct {
-	Email    string
-	Password string
+	Email     string
+	Password  string
+	UserAgent string
+	ClientIP  string
 }
 
+// LoginUserResult holds the tokens of a completed login. When the user has
+// two-factor authentication enabled, only TwoFactorChallenge is set and the
+// login has to be finished with VerifyTwoFactorLogin.
 type LoginUserResult struct {
-	AccessToken          string
-	AccessTokenExpiresAt time.Time
-	User                 *User
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+	User                  *User
+
+	TwoFactorChallenge *TwoFactorChallenge
+}
+
+// TwoFactorChallenge proves the password step of a login succeeded.
+type TwoFactorChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
+
+type VerifyTwoFactorLoginParams struct {
+	ChallengeToken string
+
+	// Code is either a TOTP code or a recovery code.
+	Code      string
+	UserAgent string
+	ClientIP  string
+}
+
+type CompleteOIDCLoginParams struct {
+	Provider  string
+	State     string
+	Code      string
+	UserAgent string
+	ClientIP  string
+}
+
+type RefreshSessionParams struct {
+	RefreshToken string
+	UserAgent    string
+	ClientIP     string
+}
+
+type RefreshSessionResult struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+type ResetPasswordParams struct {
+	Token       string
+	NewPassword string
+}
+
+type ChangePasswordParams struct {
+	UserID          string
+	SessionID       string
+	CurrentPassword string
+	NewPassword     string
+}
+
+type RequestEmailChangeParams struct {
+	UserID   string
+	NewEmail string
+	Password string
+}
+
+type LogoutParams struct {
+	UserID         string
+	SessionID      string
+	TokenID        string
+	TokenExpiresAt time.Time
+}
+
+// UpdateUserProfileParams holds the profile fields to change. Nil fields are
+// left untouched; a non-nil empty Languages clears the list.
+type UpdateUserProfileParams struct {
+	DisplayName *string
+	Bio         *string
+	Phone       *string
+	Languages   []string
+}
+
+type UploadAvatarParams struct {
+	UserID string
+	File   io.Reader
+}
+
+// PublicProfile is what anyone can see about a user, e.g. a host.
+type PublicProfile struct {
+	User *User
+
+	// ListingCount is the number of active listings, nil if it could not be determined.
+	ListingCount *int64
+}
+
+type DeleteAccountParams struct {
+	UserID   string
+	Password string
+}
+
+// UpcomingBookingCounts is how many confirmed stays a user still has ahead.
+type UpcomingBookingCounts struct {
+	AsGuest int64
+	AsHost  int64
+}
+
+type SetUserRolesParams struct {
+	// ActorID is the admin making the change.
+	ActorID string
+	UserID  string
+	Roles   []string
+}
+
+// TOTPSetup is what the user needs to add the account to an authenticator app.
+type TOTPSetup struct {
+	Secret string
+	URI    string
+}
+
+type EnableTOTPParams struct {
+	UserID string
+	Code   string
+}
+
+type DisableTwoFactorParams struct {
+	UserID   string
+	Password string
+}
+
+type RegenerateRecoveryCodesParams struct {
+	UserID string
+	Code   string
+}
+
+type TwoFactorStatus struct {
+	Enabled                bool
+	EnabledAt              *time.Time
+	RecoveryCodesRemaining int64
+}
+
+// PhoneCodeSent tells the client where the verification code went and when
+// it may ask for another one.
+type PhoneCodeSent struct {
+	Phone     string
+	ExpiresAt time.Time
+	ResendAt  time.Time
+}
+
+type VerifyPhoneParams struct {
+	UserID string
+	Code   string
 }
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// EmailChangeStatus is the state of an email address change.
+type EmailChangeStatus string
+
+const (
+	EmailChangeStatusPending   EmailChangeStatus = "pending"
+	EmailChangeStatusConfirmed EmailChangeStatus = "confirmed"
+
+	// EmailChangeStatusCancelled covers changes withdrawn from the old
+	// address, before or after confirmation, and changes replaced by a newer one.
+	EmailChangeStatusCancelled EmailChangeStatus = "cancelled"
+)
+
+// EmailChange moves a user to a new email address once the new address is
+// confirmed. Until CancelExpiresAt the old address can cancel it, which also
+// restores the old address if the change was already confirmed.
+type EmailChange struct {
+	ID               string            `db:"id"`
+	UserID           string            `db:"user_id"`
+	OldEmail         string            `db:"old_email"`
+	OldEmailVerified bool              `db:"old_email_verified"`
+	NewEmail         string            `db:"new_email"`
+	Status           EmailChangeStatus `db:"status"`
+	ConfirmTokenHash string            `db:"confirm_token_hash"`
+	CancelTokenHash  string            `db:"cancel_token_hash"`
+	ExpiresAt        time.Time         `db:"expires_at"`
+	CancelExpiresAt  time.Time         `db:"cancel_expires_at"`
+	ConfirmedAt      *time.Time        `db:"confirmed_at"`
+	CancelledAt      *time.Time        `db:"cancelled_at"`
+	CreatedAt        time.Time         `db:"created_at"`
+}
@@ -1,11 +1,127 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
 
 var (
 	ErrIncorrectCredentials = errors.New("incorrect email or password")
+	ErrIncorrectPassword    = errors.New("current password is incorrect")
+	ErrPasswordRejected     = errors.New("password does not meet the password policy")
 
 	ErrUserNotFound = errors.New("user not found")
 
 	ErrEmailAlreadyExists = errors.New("email already exists")
+
+	ErrEmailAlreadyVerified = errors.New("email is already verified")
+	ErrUserTokenInvalid     = errors.New("token is invalid or has expired")
+
+	ErrRefreshTokenInvalid = errors.New("refresh token is invalid")
+	ErrRefreshTokenExpired = errors.New("refresh token has expired")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+
+	ErrAvatarTooLarge        = errors.New("avatar image is too large")
+	ErrAvatarTypeUnsupported = errors.New("avatar image type is not supported")
+
+	ErrListingServiceUnavailable = errors.New("listing service is unavailable")
+	ErrBookingServiceUnavailable = errors.New("booking service is unavailable")
+
+	ErrAccountHasUpcomingBookings = errors.New("account has upcoming confirmed bookings")
+
+	ErrCannotRemoveOwnAdminRole = errors.New("admins cannot remove their own admin role")
+
+	ErrTwoFactorAlreadyEnabled   = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorSetupNotStarted  = errors.New("two-factor authentication setup has not been started")
+	ErrTwoFactorCodeInvalid      = errors.New("two-factor code is invalid")
+	ErrTwoFactorChallengeInvalid = errors.New("two-factor challenge is invalid or has expired")
+
+	ErrTooManyLoginAttempts = errors.New("too many failed login attempts")
+
+	ErrIdentityNotFound            = errors.New("identity not found")
+	ErrIdentityProviderNotFound    = errors.New("identity provider not found")
+	ErrIdentityProviderUnavailable = errors.New("identity provider is unavailable")
+	ErrOIDCStateInvalid            = errors.New("login state is invalid or has expired")
+	ErrOIDCAuthenticationFailed    = errors.New("identity provider authentication failed")
+	ErrOIDCEmailNotVerified        = errors.New("identity provider did not verify the email address")
+
+	ErrEmailUnchanged          = errors.New("new email is the same as the current one")
+	ErrEmailChangeTokenInvalid = errors.New("email change token is invalid or has expired")
+
+	ErrDataExportNotFound    = errors.New("data export not found")
+	ErrDataExportInProgress  = errors.New("a data export is already in progress")
+	ErrDataExportLinkInvalid = errors.New("data export download link is invalid or has expired")
+
+	ErrHostVerificationNotFound        = errors.New("host verification not found")
+	ErrHostVerificationPending         = errors.New("a host verification is already waiting for review")
+	ErrHostVerificationAlreadyReviewed = errors.New("host verification has already been reviewed")
+	ErrHostAlreadyVerified             = errors.New("host is already verified")
+	ErrHostDocumentMissing             = errors.New("both sides of the ID document are required")
+	ErrHostDocumentTooLarge            = errors.New("ID document image is too large")
+	ErrHostDocumentTypeUnsupported     = errors.New("ID document file type is not supported")
+	ErrCannotReviewOwnHostVerification = errors.New("admins cannot review their own host verification")
+
+	ErrPhoneNumberMissing         = errors.New("no phone number on the profile")
+	ErrPhoneAlreadyVerified       = errors.New("phone number is already verified")
+	ErrPhoneCodeInvalid           = errors.New("phone verification code is invalid")
+	ErrPhoneCodeExpired           = errors.New("phone verification code has expired or was not requested")
+	ErrTooManyPhoneCodesRequested = errors.New("too many phone verification codes requested")
+
+	ErrSessionNotFound       = errors.New("session not found")
+	ErrSessionAlreadyRotated = errors.New("session has already been rotated")
 )
+
+// PasswordViolation is one rule of the password policy a password breaks.
+type PasswordViolation struct {
+	Code    string
+	Message string
+}
+
+// PasswordRejectedError is returned when a new password breaks the password
+// policy. It matches ErrPasswordRejected with errors.Is.
+type PasswordRejectedError struct {
+	Violations []PasswordViolation
+}
+
+func (e *PasswordRejectedError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		messages[i] = v.Message
+	}
+	return fmt.Sprintf("%s: %s", ErrPasswordRejected, strings.Join(messages, "; "))
+}
+
+func (e *PasswordRejectedError) Unwrap() error {
+	return ErrPasswordRejected
+}
+
+// LoginThrottledError is returned while login attempts for an email or IP are
+// being throttled. It matches ErrTooManyLoginAttempts with errors.Is.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyLoginAttempts, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrTooManyLoginAttempts
+}
+
+// PhoneCodeThrottledError is returned when a new phone verification code is
+// requested too soon. It matches ErrTooManyPhoneCodesRequested with errors.Is.
+type PhoneCodeThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *PhoneCodeThrottledError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrTooManyPhoneCodesRequested, e.RetryAfter.Round(time.Second))
+}
+
+func (e *PhoneCodeThrottledError) Unwrap() error {
+	return ErrTooManyPhoneCodesRequested
+}
@@ -0,0 +1,90 @@
+package model
+
+import (
+	"io"
+	"time"
+)
+
+// HostVerificationStatus is where a host stands in identity verification. It
+// is kept on the user and on each submission, which is never "unverified".
+type HostVerificationStatus string
+
+const (
+	HostVerificationStatusUnverified HostVerificationStatus = "unverified"
+	HostVerificationStatusPending    HostVerificationStatus = "pending"
+	HostVerificationStatusVerified   HostVerificationStatus = "verified"
+	HostVerificationStatusRejected   HostVerificationStatus = "rejected"
+)
+
+// HostDocumentType is the kind of ID document a host submitted.
+type HostDocumentType string
+
+const (
+	HostDocumentTypeNationalID     HostDocumentType = "national_id"
+	HostDocumentTypePassport       HostDocumentType = "passport"
+	HostDocumentTypeDriversLicense HostDocumentType = "drivers_license"
+)
+
+// RequiresBack reports whether the document has a back side to photograph too.
+// Only a passport fits on one image.
+func (t HostDocumentType) RequiresBack() bool {
+	return t != HostDocumentTypePassport
+}
+
+// HostDocumentSide is one of the two images of a submission.
+type HostDocumentSide string
+
+const (
+	HostDocumentSideFront HostDocumentSide = "front"
+	HostDocumentSideBack  HostDocumentSide = "back"
+)
+
+// HostVerification is one submission of ID documents by a host, and the
+// outcome of its review.
+type HostVerification struct {
+	ID           string                 `db:"id"`
+	UserID       string                 `db:"user_id"`
+	DocumentType HostDocumentType       `db:"document_type"`
+	FrontKey     string                 `db:"front_key"`
+	BackKey      *string                `db:"back_key"`
+	Status       HostVerificationStatus `db:"status"`
+
+	// RejectionReason tells the host what to fix before submitting again.
+	RejectionReason *string    `db:"rejection_reason"`
+	ReviewedBy      *string    `db:"reviewed_by"`
+	ReviewedAt      *time.Time `db:"reviewed_at"`
+	CreatedAt       time.Time  `db:"created_at"`
+}
+
+// DocumentKey returns the storage key of one side, if it was submitted.
+func (v *HostVerification) DocumentKey(side HostDocumentSide) (string, bool) {
+	switch side {
+	case HostDocumentSideFront:
+		return v.FrontKey, true
+	case HostDocumentSideBack:
+		if v.BackKey != nil {
+			return *v.BackKey, true
+		}
+	}
+	return "", false
+}
+
+type SubmitHostVerificationParams struct {
+	UserID       string
+	DocumentType HostDocumentType
+	Front        io.Reader
+
+	// Back is nil when no back side was uploaded.
+	Back io.Reader
+}
+
+type ReviewHostVerificationParams struct {
+	ID string
+
+	// ReviewerID is the admin making the decision.
+	ReviewerID string
+	Approve    bool
+
+	// RejectionReason is required when rejecting.
+	RejectionReason string
+}
@@ -0,0 +1,28 @@
+package model
+
+import "time"
+
+// UserIdentity links an account at an external OpenID provider to a user.
+type UserIdentity struct {
+	ID       string `db:"id"`
+	UserID   string `db:"user_id"`
+	Provider string `db:"provider"`
+
+	// Subject is the provider's stable ID of the account ("sub" claim).
+	Subject string `db:"subject"`
+
+	// Email is the address the provider reported when the link was made.
+	Email       string     `db:"email"`
+	LastLoginAt *time.Time `db:"last_login_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+}
+
+// OIDCAuthRequest remembers a login sent to a provider until it comes back.
+type OIDCAuthRequest struct {
+	StateHash    string    `db:"state_hash"`
+	Provider     string    `db:"provider"`
+	Nonce        string    `db:"nonce"`
+	CodeVerifier string    `db:"code_verifier"`
+	ExpiresAt    time.Time `db:"expires_at"`
+	CreatedAt    time.Time `db:"created_at"`
+}
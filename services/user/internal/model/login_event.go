@@ -0,0 +1,29 @@
+package model
+
+import "time"
+
+// Reasons stored with failed login events.
+const (
+	LoginFailureIncorrectPassword      = "incorrect_password"
+	LoginFailureIncorrectTwoFactorCode = "incorrect_two_factor_code"
+)
+
+// LoginEvent is one entry of a user's login history.
+type LoginEvent struct {
+	ID      string  `db:"id"`
+	UserID  string  `db:"user_id"`
+	Success bool    `db:"success"`
+	Reason  *string `db:"reason"`
+
+	ClientIP  string `db:"client_ip"`
+	UserAgent string `db:"user_agent"`
+
+	// DeviceFingerprint identifies the browser/app without its version, so
+	// updates don't make a known device look new.
+	DeviceFingerprint string `db:"device_fingerprint"`
+
+	// NewDevice is set on the first successful login from a fingerprint,
+	// except for the very first login of the account.
+	NewDevice bool      `db:"new_device"`
+	CreatedAt time.Time `db:"created_at"`
+}
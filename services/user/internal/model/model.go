@@ -8,8 +8,19 @@ type User struct {
 	Email         string     `db:"email"`
 	PasswordHash  string     `db:"password_hash"`
 	EmailVerified bool       `db:"email_verified"`
+	Roles         []string   `db:"roles"`
+	Bio           string     `db:"bio"`
+	Phone         *string    `db:"phone"`
+	Languages     []string   `db:"languages"`
+	AvatarKey     *string    `db:"avatar_key"`
+	AvatarURL     *string    `db:"avatar_url"`
 	LastLoginAt   *time.Time `db:"last_login_at"`
 	CreatedAt     time.Time  `db:"created_at"`
 	UpdatedAt     time.Time  `db:"updated_at"`
 	DeletedAt     *time.Time `db:"deleted_at"`
+
+	PhoneVerifiedAt        *time.Time             `db:"phone_verified_at"`
+	HostVerificationStatus HostVerificationStatus `db:"host_verification_status"`
+
+	UserPreferences
 }
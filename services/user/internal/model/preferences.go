@@ -0,0 +1,38 @@
+package model
+
+// Supported display preferences. Prices are stored in VND; other currencies
+// are only converted for display.
+const (
+	LocaleVietnamese = "vi"
+	LocaleEnglish    = "en"
+
+	CurrencyVND = "VND"
+	CurrencyUSD = "USD"
+
+	DefaultTimezone = "Asia/Ho_Chi_Minh"
+)
+
+// NotificationChannels says where one kind of notification may be sent.
+type NotificationChannels struct {
+	Email bool `json:"email"`
+	SMS   bool `json:"sms"`
+}
+
+// NotificationPreferences holds the user's opt-ins per kind of notification.
+// Security alerts such as a new sign-in are not listed because they are
+// always sent by email.
+type NotificationPreferences struct {
+	BookingUpdates NotificationChannels `json:"booking_updates"`
+	HostingUpdates NotificationChannels `json:"hosting_updates"`
+	Reminders      NotificationChannels `json:"reminders"`
+	Promotions     NotificationChannels `json:"promotions"`
+}
+
+// UserPreferences are the settings other services use to localize responses
+// and decide how to reach the user.
+type UserPreferences struct {
+	Locale        string                  `db:"locale"`
+	Currency      string                  `db:"currency"`
+	Timezone      string                  `db:"timezone"`
+	Notifications NotificationPreferences `db:"notification_preferences"`
+}
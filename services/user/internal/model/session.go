@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// Session is a single refresh token.
+//
+// Refresh tokens are rotated on every use, and all tokens descending from the
+// same login share a FamilyID. The family is what users see as a "session"
+// (one logged-in device), so FamilyID is the public session identifier.
+type Session struct {
+	ID               string     `db:"id"`
+	FamilyID         string     `db:"family_id"`
+	UserID           string     `db:"user_id"`
+	RefreshTokenHash string     `db:"refresh_token_hash"`
+	UserAgent        string     `db:"user_agent"`
+	ClientIP         string     `db:"client_ip"`
+	ExpiresAt        time.Time  `db:"expires_at"`
+	RotatedAt        *time.Time `db:"rotated_at"`
+	RevokedAt        *time.Time `db:"revoked_at"`
+	CreatedAt        time.Time  `db:"created_at"`
+}
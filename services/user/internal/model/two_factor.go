@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// UserTOTP is the authenticator app a user set up for two-factor login.
+// It only protects the account once ConfirmedAt is set.
+type UserTOTP struct {
+	UserID string `db:"user_id"`
+
+	// Secret is kept in plain form because codes are computed from it.
+	Secret string `db:"secret"`
+
+	// LastUsedStep is the time step of the last accepted code. Codes from this
+	// step or earlier are rejected so an observed code cannot be replayed.
+	LastUsedStep *int64     `db:"last_used_step"`
+	ConfirmedAt  *time.Time `db:"confirmed_at"`
+	CreatedAt    time.Time  `db:"created_at"`
+}
+
+func (t *UserTOTP) Enabled() bool {
+	return t.ConfirmedAt != nil
+}
+
+// RecoveryCode is a single-use code that replaces a TOTP code.
+// Only its hash is stored.
+type RecoveryCode struct {
+	ID        string     `db:"id"`
+	UserID    string     `db:"user_id"`
+	CodeHash  string     `db:"code_hash"`
+	UsedAt    *time.Time `db:"used_at"`
+	CreatedAt time.Time  `db:"created_at"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// TokenPurpose tells apart the flows that send single-use tokens to users.
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+
+	// TokenPurposeTwoFactorChallenge is handed out by a login that still needs
+	// a second factor. It is never emailed; the client sends it straight back.
+	TokenPurposeTwoFactorChallenge TokenPurpose = "two_factor_challenge"
+
+	// TokenPurposeDataExportDownload is the download link of a personal data
+	// export. Unlike the others it can be used until it expires.
+	TokenPurposeDataExportDownload TokenPurpose = "data_export_download"
+
+	// TokenPurposePhoneVerification is a short numeric code sent by SMS. Its
+	// hash also covers the token ID and the phone number it was sent to.
+	TokenPurposePhoneVerification TokenPurpose = "phone_verification"
+)
+
+// UserToken is a single-use token delivered out of band, e.g. in an email link.
+// Only its hash is stored.
+type UserToken struct {
+	ID         string       `db:"id"`
+	UserID     string       `db:"user_id"`
+	Purpose    TokenPurpose `db:"purpose"`
+	TokenHash  string       `db:"token_hash"`
+	ExpiresAt  time.Time    `db:"expires_at"`
+	ConsumedAt *time.Time   `db:"consumed_at"`
+	Attempts   int          `db:"attempts"`
+	CreatedAt  time.Time    `db:"created_at"`
+}
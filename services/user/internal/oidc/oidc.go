@@ -0,0 +1,296 @@
+// Package oidc is a minimal OpenID Connect relying party: discovery, the
+// authorization code flow with PKCE, and ID token verification.
+package oidc
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/katatrina/airbnb-clone/pkg/token"
+)
+
+const (
+	httpTimeout = 10 * time.Second
+
+	// clockSkew tolerated when checking "exp" and "iat" of ID tokens.
+	clockSkew = time.Minute
+)
+
+// ErrInvalidIDToken is returned when the provider's ID token fails verification.
+var ErrInvalidIDToken = errors.New("invalid ID token")
+
+// idTokenMethods are the signing algorithms we can verify with keys from
+// token.JWKSCache (RSA and Ed25519).
+var idTokenMethods = []string{
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// Config describes one provider registered with us.
+type Config struct {
+	// Name identifies the provider in our URLs and database, e.g. "google".
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+
+	// Scopes requested in addition to "openid".
+	Scopes []string
+}
+
+// Identity is what a provider asserted about the user in a verified ID token.
+type Identity struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// discoveryDocument holds the parts of /.well-known/openid-configuration we use.
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to one OpenID provider. Discovery happens on first use, so
+// the service can start while the provider is unreachable.
+type Provider struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *discoveryDocument
+	keys      *token.JWKSCache
+}
+
+func NewProvider(cfg Config) *Provider {
+	return &Provider{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: httpTimeout},
+	}
+}
+
+func (p *Provider) Name() string {
+	return p.cfg.Name
+}
+
+// AuthCodeURL returns the URL to send the user to. state and nonce must be
+// random per login; codeVerifier is the PKCE secret, of which only the S256
+// challenge leaves our server here.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error) {
+	doc, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(append([]string{"openid"}, p.cfg.Scopes...), " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", CodeChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// tokenResponse is the token endpoint answer; we only need the ID token.
+type tokenResponse struct {
+	IDToken string `json:"id_token"`
+	Error   string `json:"error"`
+}
+
+// Exchange redeems an authorization code and returns the verified identity.
+// nonce must be the one sent in AuthCodeURL for this login.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	doc, keys, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		// client_secret_basic, the default client authentication method
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call token endpoint: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var body tokenResponse
+	if err = json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned %d: %s", resp.StatusCode, body.Error)
+	}
+	if body.IDToken == "" {
+		return nil, fmt.Errorf("%w: token response has no id_token", ErrInvalidIDToken)
+	}
+
+	return p.verifyIDToken(doc, keys, body.IDToken, nonce)
+}
+
+// idTokenClaims are the ID token claims we read.
+type idTokenClaims struct {
+	jwt.RegisteredClaims
+	Nonce           string   `json:"nonce"`
+	AuthorizedParty string   `json:"azp"`
+	Email           string   `json:"email"`
+	EmailVerified   flexBool `json:"email_verified"`
+	Name            string   `json:"name"`
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce
+// (OpenID Connect Core 1.0, section 3.1.3.7).
+func (p *Provider) verifyIDToken(doc *discoveryDocument, keys *token.JWKSCache, rawIDToken, nonce string) (*Identity, error) {
+	var claims idTokenClaims
+	_, err := jwt.ParseWithClaims(rawIDToken, &claims,
+		func(t *jwt.Token) (any, error) {
+			kid, _ := t.Header["kid"].(string)
+
+			ctx, cancel := context.WithTimeout(context.Background(), httpTimeout)
+			defer cancel()
+
+			key, algorithm, err := keys.Key(ctx, kid)
+			if err != nil {
+				return nil, err
+			}
+			if algorithm != "" && algorithm != t.Method.Alg() {
+				return nil, fmt.Errorf("signing key %q is not valid for %s", kid, t.Method.Alg())
+			}
+			return key, nil
+		},
+		jwt.WithValidMethods(idTokenMethods),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(clockSkew),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	// With several audiences the token must have been issued to us specifically
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.cfg.ClientID {
+		return nil, fmt.Errorf("%w: unexpected authorized party %q", ErrInvalidIDToken, claims.AuthorizedParty)
+	}
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(claims.Nonce), []byte(nonce)) != 1 {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrInvalidIDToken)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing subject", ErrInvalidIDToken)
+	}
+
+	return &Identity{
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          strings.TrimSpace(claims.Name),
+	}, nil
+}
+
+// discover fetches and caches the provider metadata. A failed attempt is not
+// cached, so the next login simply tries again.
+func (p *Provider) discover(ctx context.Context) (*discoveryDocument, *token.JWKSCache, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, p.keys, nil
+	}
+
+	issuer := strings.TrimSuffix(p.cfg.IssuerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create discovery request: %w", err)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("unexpected discovery response status: %d", resp.StatusCode)
+	}
+
+	var doc discoveryDocument
+	if err = json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode discovery document: %w", err)
+	}
+
+	// The document must be about the issuer we trust, or tokens from another
+	// issuer could be accepted (OpenID Connect Discovery 1.0, section 4.3)
+	if strings.TrimSuffix(doc.Issuer, "/") != issuer {
+		return nil, nil, fmt.Errorf("discovery issuer %q does not match %q", doc.Issuer, p.cfg.IssuerURL)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, nil, errors.New("discovery document is missing required endpoints")
+	}
+
+	p.discovery = &doc
+	p.keys = token.NewJWKSCache(doc.JWKSURI, token.DefaultJWKSRefreshInterval)
+
+	return p.discovery, p.keys, nil
+}
+
+// CodeChallenge derives the PKCE S256 challenge from a verifier (RFC 7636).
+func CodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// flexBool accepts both true and "true"; some providers send
+// email_verified as a string.
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	case "false", "null", "":
+		*b = false
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
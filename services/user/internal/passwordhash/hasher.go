@@ -0,0 +1,166 @@
+// Package passwordhash hashes passwords with bcrypt or argon2id and verifies
+// them against hashes of either kind.
+//
+// Hashes carry their own parameters (bcrypt's "$2a$<cost>$..." and the PHC
+// string "$argon2id$v=19$m=...,t=...,p=...$<salt>$<key>"), so the configured
+// strength can be raised at any time: old hashes still verify, and Verify
+// reports when one should be replaced by a hash with the current settings.
+package passwordhash
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+// ErrMismatch is returned by Verify when the password does not match the hash.
+var ErrMismatch = errors.New("password does not match")
+
+var encoding = base64.RawStdEncoding
+
+// Argon2Params are the argon2id cost parameters. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// Config selects the algorithm new hashes use and its strength. Only the
+// settings of the selected algorithm matter.
+type Config struct {
+	Algorithm  string
+	BcryptCost int
+	Argon2     Argon2Params
+}
+
+type Hasher struct {
+	cfg Config
+}
+
+// NewHasher returns a Hasher for cfg, which is expected to be validated.
+func NewHasher(cfg Config) *Hasher {
+	return &Hasher{cfg: cfg}
+}
+
+// Hash returns an encoded hash of password with the configured algorithm.
+func (h *Hasher) Hash(password string) (string, error) {
+	if h.cfg.Algorithm == AlgorithmArgon2id {
+		return hashArgon2id(password, h.cfg.Argon2)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cfg.BcryptCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// Verify checks password against an encoded hash of any supported algorithm
+// and returns ErrMismatch if it is wrong. needsRehash is true when the hash
+// was made with another algorithm than the configured one, or with weaker
+// parameters; the caller should then store a fresh Hash of the password.
+func (h *Hasher) Verify(hash, password string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, "$argon2id$"):
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false, err
+		}
+
+		computed := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+		if subtle.ConstantTimeCompare(computed, key) != 1 {
+			return false, ErrMismatch
+		}
+
+		want := h.cfg.Argon2
+		return h.cfg.Algorithm != AlgorithmArgon2id ||
+			params.Memory < want.Memory ||
+			params.Iterations < want.Iterations ||
+			params.Parallelism < want.Parallelism ||
+			len(salt) < argon2SaltLength ||
+			len(key) < argon2KeyLength, nil
+
+	case strings.HasPrefix(hash, "$2"):
+		err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if err != nil {
+			if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+				return false, ErrMismatch
+			}
+			return false, err
+		}
+
+		cost, err := bcrypt.Cost([]byte(hash))
+		if err != nil {
+			return false, err
+		}
+		return h.cfg.Algorithm != AlgorithmBcrypt || cost < h.cfg.BcryptCost, nil
+
+	default:
+		return false, errors.New("unrecognized password hash format")
+	}
+}
+
+func hashArgon2id(password string, params Argon2Params) (string, error) {
+	salt := make([]byte, argon2SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, argon2KeyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		encoding.EncodeToString(salt), encoding.EncodeToString(key)), nil
+}
+
+func decodeArgon2id(hash string) (params Argon2Params, salt, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, key
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err = fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id version: %w", err)
+	}
+	if version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2id version %d", version)
+	}
+
+	if _, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	// argon2 panics on these rather than returning an error
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, errors.New("malformed argon2id parameters")
+	}
+
+	if salt, err = encoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	if key, err = encoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, fmt.Errorf("malformed argon2id key: %w", err)
+	}
+	if len(key) == 0 {
+		return params, nil, nil, errors.New("malformed argon2id hash")
+	}
+
+	return params, salt, key, nil
+}
@@ -0,0 +1,161 @@
+package passwordhash
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Cheap settings keep the tests fast; only their relative strength matters.
+var (
+	testArgon2 = Argon2Params{Memory: 64, Iterations: 2, Parallelism: 1}
+
+	testBcryptConfig = Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 1}
+	testArgon2Config = Config{Algorithm: AlgorithmArgon2id, Argon2: testArgon2}
+)
+
+const testPassword = "correct horse battery staple"
+
+func mustHash(t *testing.T, cfg Config) string {
+	t.Helper()
+
+	hash, err := NewHasher(cfg).Hash(testPassword)
+	require.NoError(t, err)
+	return hash
+}
+
+func TestDecodeArgon2id(t *testing.T) {
+	valid := mustHash(t, testArgon2Config)
+	parts := strings.Split(valid, "$")
+	withPart := func(i int, value string) string {
+		p := append([]string(nil), parts...)
+		p[i] = value
+		return strings.Join(p, "$")
+	}
+
+	testCases := []struct {
+		name    string
+		hash    string
+		wantErr bool
+	}{
+		{name: "valid", hash: valid},
+		{name: "missing key", hash: strings.Join(parts[:5], "$"), wantErr: true},
+		{name: "truncated after the parameters", hash: strings.Join(parts[:4], "$"), wantErr: true},
+		{name: "extra field", hash: valid + "$extra", wantErr: true},
+		{name: "empty key", hash: withPart(5, ""), wantErr: true},
+		{name: "older version", hash: withPart(2, "v=16"), wantErr: true},
+		{name: "no version", hash: withPart(2, "version"), wantErr: true},
+		{name: "missing parameter", hash: withPart(3, "m=64,t=2"), wantErr: true},
+		{name: "zero iterations", hash: withPart(3, "m=64,t=0,p=1"), wantErr: true},
+		{name: "zero parallelism", hash: withPart(3, "m=64,t=2,p=0"), wantErr: true},
+		{name: "salt not base64", hash: withPart(4, "not*base64"), wantErr: true},
+		{name: "key not base64", hash: withPart(5, "not*base64"), wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, salt, key, err := decodeArgon2id(tc.hash)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testArgon2, params)
+			assert.Len(t, salt, argon2SaltLength)
+			assert.Len(t, key, argon2KeyLength)
+		})
+	}
+}
+
+func TestVerifyRejectsMalformedHashes(t *testing.T) {
+	hasher := NewHasher(testArgon2Config)
+
+	for _, hash := range []string{"", "plaintext", "$argon2id$v=19$m=64,t=2,p=1$c2FsdA", "$argon2id$v=16$m=64,t=2,p=1$c2FsdA$a2V5"} {
+		_, err := hasher.Verify(hash, testPassword)
+		require.Error(t, err, hash)
+		assert.NotErrorIs(t, err, ErrMismatch, hash)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	bcryptHash := mustHash(t, testBcryptConfig)
+	argon2Hash := mustHash(t, testArgon2Config)
+
+	_, err := NewHasher(testBcryptConfig).Verify(bcryptHash, "wrong password")
+	require.ErrorIs(t, err, ErrMismatch)
+	_, err = NewHasher(testArgon2Config).Verify(argon2Hash, "wrong password")
+	require.ErrorIs(t, err, ErrMismatch)
+
+	testCases := []struct {
+		name            string
+		hash            string
+		cfg             Config
+		wantNeedsRehash bool
+	}{
+		{name: "bcrypt at the configured cost", hash: bcryptHash, cfg: testBcryptConfig},
+		{
+			name: "bcrypt above the configured cost",
+			hash: bcryptHash,
+			cfg:  Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost},
+		},
+		{
+			name:            "bcrypt below the configured cost",
+			hash:            bcryptHash,
+			cfg:             Config{Algorithm: AlgorithmBcrypt, BcryptCost: bcrypt.MinCost + 2},
+			wantNeedsRehash: true,
+		},
+		{name: "bcrypt with argon2id configured", hash: bcryptHash, cfg: testArgon2Config, wantNeedsRehash: true},
+		{name: "argon2id with the configured parameters", hash: argon2Hash, cfg: testArgon2Config},
+		{
+			name:            "argon2id with less memory",
+			hash:            argon2Hash,
+			cfg:             Config{Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 128, Iterations: 2, Parallelism: 1}},
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "argon2id with fewer iterations",
+			hash:            argon2Hash,
+			cfg:             Config{Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 64, Iterations: 3, Parallelism: 1}},
+			wantNeedsRehash: true,
+		},
+		{
+			name:            "argon2id with less parallelism",
+			hash:            argon2Hash,
+			cfg:             Config{Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 64, Iterations: 2, Parallelism: 2}},
+			wantNeedsRehash: true,
+		},
+		{
+			name: "argon2id stronger than configured",
+			hash: argon2Hash,
+			cfg:  Config{Algorithm: AlgorithmArgon2id, Argon2: Argon2Params{Memory: 32, Iterations: 1, Parallelism: 1}},
+		},
+		{name: "argon2id with bcrypt configured", hash: argon2Hash, cfg: testBcryptConfig, wantNeedsRehash: true},
+		{
+			name:            "argon2id with a short salt",
+			hash:            shortSaltArgon2Hash(),
+			cfg:             testArgon2Config,
+			wantNeedsRehash: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			needsRehash, err := NewHasher(tc.cfg).Verify(tc.hash, testPassword)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantNeedsRehash, needsRehash)
+		})
+	}
+}
+
+// shortSaltArgon2Hash hashes testPassword with an 8 byte salt, as an older
+// or foreign implementation might have.
+func shortSaltArgon2Hash() string {
+	salt := []byte("saltsalt")
+	key := argon2.IDKey([]byte(testPassword), salt, testArgon2.Iterations, testArgon2.Memory, testArgon2.Parallelism, argon2KeyLength)
+	return "$argon2id$v=19$m=64,t=2,p=1$" + encoding.EncodeToString(salt) + "$" + encoding.EncodeToString(key)
+}
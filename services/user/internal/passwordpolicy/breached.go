@@ -0,0 +1,69 @@
+package passwordpolicy
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// hashPrefixLength is the number of hex characters that name a range file,
+// as in the Pwned Passwords k-anonymity range API.
+const hashPrefixLength = 5
+
+// HashRangeDir looks passwords up in a local copy of a breached password
+// list in the Pwned Passwords range format: one file per 5 character prefix
+// of the uppercase SHA-1 hash, named "<PREFIX>.txt", listing the remaining 35
+// characters of each hash as "SUFFIX:COUNT" lines. The official downloader
+// writes this layout when asked for one file per range.
+//
+// Only the file of the password's prefix is read, so lookups stay cheap no
+// matter how large the list is.
+type HashRangeDir struct {
+	dir string
+}
+
+var _ BreachedSet = (*HashRangeDir)(nil)
+
+func NewHashRangeDir(dir string) (*HashRangeDir, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("breached password path %q is not a directory", dir)
+	}
+
+	return &HashRangeDir{dir: dir}, nil
+}
+
+func (d *HashRangeDir) Contains(_ context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:hashPrefixLength], hash[hashPrefixLength:]
+
+	f, err := os.Open(filepath.Join(d.dir, prefix+".txt"))
+	if err != nil {
+		// A partial copy of the list simply has no entries for this range
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(line), suffix) {
+			return true, nil
+		}
+	}
+
+	return false, scanner.Err()
+}
@@ -0,0 +1,147 @@
+package passwordpolicy
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rangeOf splits the uppercase SHA-1 hash of password into the range file
+// prefix and the suffix listed in it.
+func rangeOf(password string) (prefix, suffix string) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	return hash[:hashPrefixLength], hash[hashPrefixLength:]
+}
+
+// writeRange writes a range file listing lines, with the CRLF line endings of
+// the official downloader.
+func writeRange(t *testing.T, dir, prefix string, lines ...string) {
+	t.Helper()
+
+	content := strings.Join(lines, "\r\n") + "\r\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, prefix+".txt"), []byte(content), 0o600))
+}
+
+func TestHashRangeDirContains(t *testing.T) {
+	ctx := context.Background()
+	otherSuffix := "0018A45C4D1DEF81644B54AB7F969B88D65:1"
+
+	testCases := []struct {
+		name     string
+		password string
+		setup    func(t *testing.T, dir string)
+		want     bool
+	}{
+		{
+			name:     "listed",
+			password: "password123",
+			setup: func(t *testing.T, dir string) {
+				prefix, suffix := rangeOf("password123")
+				writeRange(t, dir, prefix, otherSuffix, suffix+":251682")
+			},
+			want: true,
+		},
+		{
+			name:     "listed in lowercase",
+			password: "password123",
+			setup: func(t *testing.T, dir string) {
+				prefix, suffix := rangeOf("password123")
+				writeRange(t, dir, prefix, strings.ToLower(suffix)+":251682")
+			},
+			want: true,
+		},
+		{
+			name:     "not in its range file",
+			password: "password123",
+			setup: func(t *testing.T, dir string) {
+				prefix, _ := rangeOf("password123")
+				writeRange(t, dir, prefix, otherSuffix)
+			},
+			want: false,
+		},
+		{
+			name:     "passwords are case sensitive",
+			password: "Password123",
+			setup: func(t *testing.T, dir string) {
+				prefix, suffix := rangeOf("password123")
+				writeRange(t, dir, prefix, suffix+":251682")
+			},
+			want: false,
+		},
+		{
+			name:     "no range file in a partial copy",
+			password: "password123",
+			setup:    func(t *testing.T, dir string) {},
+			want:     false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tc.setup(t, dir)
+
+			set, err := NewHashRangeDir(dir)
+			require.NoError(t, err)
+
+			got, err := set.Contains(ctx, tc.password)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestHashRangeDirContainsUnreadableRange(t *testing.T) {
+	dir := t.TempDir()
+	prefix, _ := rangeOf("password123")
+	// A directory where the range file should be cannot be read as one
+	require.NoError(t, os.Mkdir(filepath.Join(dir, prefix+".txt"), 0o700))
+
+	set, err := NewHashRangeDir(dir)
+	require.NoError(t, err)
+
+	_, err = set.Contains(context.Background(), "password123")
+	require.Error(t, err)
+}
+
+func TestNewHashRangeDir(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := NewHashRangeDir(filepath.Join(dir, "missing"))
+	require.Error(t, err)
+
+	file := filepath.Join(dir, "00000.txt")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+	_, err = NewHashRangeDir(file)
+	require.Error(t, err, "a file is not a directory")
+
+	_, err = NewHashRangeDir(dir)
+	require.NoError(t, err)
+}
+
+func TestCheckerWhenTheBreachedListFails(t *testing.T) {
+	dir := t.TempDir()
+	prefix, _ := rangeOf("short")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, prefix+".txt"), 0o700))
+	set, err := NewHashRangeDir(dir)
+	require.NoError(t, err)
+
+	checker := NewChecker(Config{MinLength: 8, MinScore: 2}, set)
+	violations, err := checker.Check(context.Background(), "short")
+
+	require.Error(t, err)
+	// The other rules were still checked
+	codes := make([]string, len(violations))
+	for i, v := range violations {
+		codes[i] = v.Code
+	}
+	assert.Equal(t, []string{CodeTooShort, CodeTooWeak}, codes)
+}
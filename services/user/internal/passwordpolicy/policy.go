@@ -0,0 +1,130 @@
+// Package passwordpolicy decides whether a new password is good enough.
+//
+// A password is rejected when it is shorter than the minimum length, when its
+// estimated strength is below the minimum score, when it contains the user's
+// email or name, or when it appears in a list of breached passwords. Every
+// reason found is reported, so the user can fix them all at once.
+package passwordpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Violation codes, returned to clients as field error codes.
+const (
+	CodeTooShort             = "PASSWORD_TOO_SHORT"
+	CodeTooWeak              = "PASSWORD_TOO_WEAK"
+	CodeContainsPersonalInfo = "PASSWORD_CONTAINS_PERSONAL_INFO"
+	CodeBreached             = "PASSWORD_BREACHED"
+)
+
+// minPersonalInfoLength keeps short names like "An" from banning every
+// password that happens to contain them.
+const minPersonalInfoLength = 4
+
+// Violation is one reason a password was rejected.
+type Violation struct {
+	Code    string
+	Message string
+}
+
+// Config holds the rules passwords must follow.
+type Config struct {
+	MinLength int
+
+	// MinScore is the lowest accepted Score, from 0 (anything) to 4.
+	MinScore int
+}
+
+// BreachedSet tells whether a password is known from data breaches.
+type BreachedSet interface {
+	Contains(ctx context.Context, password string) (bool, error)
+}
+
+type Checker struct {
+	cfg      Config
+	breached BreachedSet
+}
+
+// NewChecker returns a Checker for cfg. breached may be nil to skip the
+// breached password check.
+func NewChecker(cfg Config, breached BreachedSet) *Checker {
+	return &Checker{cfg: cfg, breached: breached}
+}
+
+// Check returns every rule the password breaks. personalInfo lists values the
+// password must not contain, such as the user's email and display name; only
+// the local part of an email is considered.
+//
+// An error means the breached password list could not be read. The other
+// rules were still checked and their violations are returned with it.
+func (c *Checker) Check(ctx context.Context, password string, personalInfo ...string) ([]Violation, error) {
+	var violations []Violation
+
+	if utf8.RuneCountInString(password) < c.cfg.MinLength {
+		violations = append(violations, Violation{
+			Code:    CodeTooShort,
+			Message: fmt.Sprintf("password must be at least %d characters long", c.cfg.MinLength),
+		})
+	}
+
+	if Score(password) < c.cfg.MinScore {
+		violations = append(violations, Violation{
+			Code:    CodeTooWeak,
+			Message: "password is too easy to guess: make it longer or mix letters, digits and symbols",
+		})
+	}
+
+	if containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, Violation{
+			Code:    CodeContainsPersonalInfo,
+			Message: "password must not contain your email or name",
+		})
+	}
+
+	if c.breached == nil {
+		return violations, nil
+	}
+
+	breached, err := c.breached.Contains(ctx, password)
+	if err != nil {
+		return violations, fmt.Errorf("failed to check breached passwords: %w", err)
+	}
+	if breached {
+		violations = append(violations, Violation{
+			Code:    CodeBreached,
+			Message: "password has appeared in a data breach, please choose another one",
+		})
+	}
+
+	return violations, nil
+}
+
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	lowered := strings.ToLower(password)
+
+	for _, info := range personalInfo {
+		info = strings.ToLower(info)
+		if local, _, ok := strings.Cut(info, "@"); ok {
+			info = local
+		}
+
+		// The whole value and each of its words, e.g. "nguyen van an",
+		// "nguyen", "van" for a display name
+		candidates := append(strings.FieldsFunc(info, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		}), strings.Join(strings.Fields(info), ""))
+
+		for _, candidate := range candidates {
+			if utf8.RuneCountInString(candidate) >= minPersonalInfoLength && strings.Contains(lowered, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
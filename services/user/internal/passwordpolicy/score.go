@@ -0,0 +1,133 @@
+package passwordpolicy
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// keyboardRows are checked for runs like "qwerty" or "asdf".
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// Score estimates how hard a password is to guess, from 0 (trivial) to 4
+// (very strong). It is a rough entropy estimate: the size of the character
+// pool raised to the password length, where characters that repeat or
+// continue a sequence ("aaa", "abc", "321", "qwe") add nothing, and a password
+// made of one repeated chunk ("abcabc") only counts the chunk once.
+//
+// It does not know dictionary words; the breached password list covers the
+// common ones.
+func Score(password string) int {
+	runes := []rune(strings.ToLower(password))
+	runes = runes[:repeatedUnitLength(runes)]
+
+	bits := effectiveLength(runes) * math.Log2(float64(poolSize(password)))
+
+	switch {
+	case bits < 28:
+		return 0
+	case bits < 36:
+		return 1
+	case bits < 60:
+		return 2
+	case bits < 90:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// poolSize is the number of characters an attacker must try per position,
+// given the classes of characters the password uses.
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+	}
+
+	size := 0
+	if lower {
+		size += 26
+	}
+	if upper {
+		size += 26
+	}
+	if digit {
+		size += 10
+	}
+	if symbol {
+		size += 33
+	}
+	if other {
+		// Accented letters, e.g. Vietnamese "ư" or "ế"
+		size += 100
+	}
+
+	return max(size, 1)
+}
+
+// effectiveLength counts the characters that are not predictable from the
+// one before them.
+func effectiveLength(runes []rune) float64 {
+	length := 0.0
+	for i, r := range runes {
+		if i > 0 && isPredictable(runes[i-1], r) {
+			continue
+		}
+		length++
+	}
+	return length
+}
+
+func isPredictable(prev, r rune) bool {
+	if r == prev || r == prev+1 || r == prev-1 {
+		return true
+	}
+
+	for _, row := range keyboardRows {
+		i := strings.IndexRune(row, prev)
+		if i < 0 {
+			continue
+		}
+		if (i+1 < len(row) && rune(row[i+1]) == r) || (i > 0 && rune(row[i-1]) == r) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// repeatedUnitLength returns the length of the shortest chunk the password
+// is a repetition of, or the full length if there is none.
+func repeatedUnitLength(runes []rune) int {
+	n := len(runes)
+	for unit := 1; unit <= n/2; unit++ {
+		if n%unit != 0 {
+			continue
+		}
+
+		repeated := true
+		for i := unit; i < n; i++ {
+			if runes[i] != runes[i-unit] {
+				repeated = false
+				break
+			}
+		}
+		if repeated {
+			return unit
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,83 @@
+package passwordpolicy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScore(t *testing.T) {
+	testCases := []struct {
+		name     string
+		password string
+		want     int
+	}{
+		{name: "empty", password: "", want: 0},
+		{name: "one repeated letter", password: "aaaaaaaa", want: 0},
+		{name: "alphabet run", password: "abcdefgh", want: 0},
+		{name: "digit run", password: "12345678", want: 0},
+		{name: "descending run", password: "87654321", want: 0},
+		{name: "keyboard row", password: "qwertyui", want: 0},
+		{name: "repeated chunk", password: "xmkqzpxmkqzpxmkqzp", want: 1},
+
+		// Random lowercase letters, about 4.7 bits each
+		{name: "5 letters, under 28 bits", password: "xmkqz", want: 0},
+		{name: "6 letters, just over 28 bits", password: "xmkqzp", want: 1},
+		{name: "7 letters, under 36 bits", password: "xmkqzpw", want: 1},
+		{name: "8 letters, just over 36 bits", password: "xmkqzpwr", want: 2},
+		{name: "12 letters, under 60 bits", password: "xmkqzpwrbjfh", want: 2},
+		{name: "13 letters, just over 60 bits", password: "xmkqzpwrbjfhv", want: 3},
+		{name: "19 letters, under 90 bits", password: "xmkqzpwrbjfhvxmkqzp", want: 3},
+		{name: "20 letters, just over 90 bits", password: "xmkqzpwrbjfhvxmkqzpw", want: 4},
+
+		{name: "mixed classes grow the pool", password: "Xk9#mQ2$", want: 2},
+		{name: "long mixed", password: "Xk9#mQ2$vL7", want: 3},
+		{name: "passphrase", password: "correct horse battery staple", want: 4},
+		{name: "Vietnamese passphrase", password: "Phở-bò-Hà-Nội-2026", want: 4},
+		{name: "case does not hide a run", password: "AbCdEfGh", want: 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, Score(tc.password))
+		})
+	}
+}
+
+func TestPoolSize(t *testing.T) {
+	testCases := []struct {
+		password string
+		want     int
+	}{
+		{password: "", want: 1},
+		{password: "abc", want: 26},
+		{password: "abcDEF", want: 52},
+		{password: "abc123", want: 36},
+		{password: "abc!", want: 59},
+		{password: "aB1!", want: 95},
+		{password: "mật", want: 126},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, poolSize(tc.password), tc.password)
+	}
+}
+
+func TestRepeatedUnitLength(t *testing.T) {
+	testCases := []struct {
+		password string
+		want     int
+	}{
+		{password: "", want: 0},
+		{password: "a", want: 1},
+		{password: "aaaa", want: 1},
+		{password: "abab", want: 2},
+		{password: "abcabcabc", want: 3},
+		{password: "abcabca", want: 7},
+		{password: "abcd", want: 4},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.want, repeatedUnitLength([]rune(tc.password)), tc.password)
+	}
+}
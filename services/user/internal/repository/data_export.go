@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+const dataExportColumns = `id, user_id, status, file_key, file_size, error,
+		started_at, completed_at, expires_at, created_at`
+
+// CreateDataExport queues a new export. It fails with ErrDataExportInProgress
+// while another export of the user is still pending or processing.
+func (r *DataExportRepository) CreateDataExport(ctx context.Context, export model.DataExport) (*model.DataExport, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO data_exports (id, user_id, status, created_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING %s
+	`, dataExportColumns)
+
+	rows, _ := r.db.Query(ctx, query, export.ID, export.UserID, export.Status, export.CreatedAt)
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.DataExport])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_data_exports_user_id_in_progress" {
+			return nil, model.ErrDataExportInProgress
+		}
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// FindLatestDataExport returns the most recently requested export of the user.
+func (r *DataExportRepository) FindLatestDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM data_exports
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, dataExportColumns)
+
+	rows, _ := r.db.Query(ctx, query, userID)
+	export, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.DataExport])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrDataExportNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// FindDownloadableDataExport returns the user's most recent completed export
+// that has not expired yet.
+func (r *DataExportRepository) FindDownloadableDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM data_exports
+		WHERE user_id = $1 AND status = 'completed' AND expires_at > NOW()
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, dataExportColumns)
+
+	rows, _ := r.db.Query(ctx, query, userID)
+	export, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.DataExport])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrDataExportNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// ClaimDataExport marks the oldest pending export as processing and returns
+// it. Exports stuck in processing since before staleBefore are claimed again,
+// so a crashed worker does not leave them hanging. SKIP LOCKED lets several
+// replicas run the worker without picking the same job.
+//
+// It returns ErrDataExportNotFound when there is nothing to do.
+func (r *DataExportRepository) ClaimDataExport(ctx context.Context, staleBefore time.Time) (*model.DataExport, error) {
+	query := fmt.Sprintf(`
+		UPDATE data_exports
+		SET status = 'processing', started_at = NOW(), error = NULL
+		WHERE id = (
+			SELECT id
+			FROM data_exports
+			WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s
+	`, dataExportColumns)
+
+	rows, _ := r.db.Query(ctx, query, staleBefore)
+	export, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.DataExport])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrDataExportNotFound
+		}
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+func (r *DataExportRepository) CompleteDataExport(ctx context.Context, id, fileKey string, fileSize int64, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = 'completed', file_key = $1, file_size = $2, completed_at = NOW(), expires_at = $3
+		WHERE id = $4
+	`
+
+	_, err := r.db.Exec(ctx, query, fileKey, fileSize, expiresAt, id)
+	return err
+}
+
+func (r *DataExportRepository) FailDataExport(ctx context.Context, id, reason string) error {
+	query := `
+		UPDATE data_exports
+		SET status = 'failed', error = $1, completed_at = NOW()
+		WHERE id = $2
+	`
+
+	_, err := r.db.Exec(ctx, query, reason, id)
+	return err
+}
+
+// ExpireDataExports marks completed exports past their expiry as expired and
+// returns their file keys so the caller can delete the archives.
+func (r *DataExportRepository) ExpireDataExports(ctx context.Context) ([]string, error) {
+	query := `
+		UPDATE data_exports
+		SET status = 'expired'
+		WHERE status = 'completed' AND expires_at <= NOW()
+		RETURNING COALESCE(file_key, '')
+	`
+
+	rows, _ := r.db.Query(ctx, query)
+	fileKeys, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(fileKeys))
+	for _, key := range fileKeys {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+const emailChangeColumns = `id, user_id, old_email, old_email_verified, new_email, status,
+		confirm_token_hash, cancel_token_hash, expires_at, cancel_expires_at,
+		confirmed_at, cancelled_at, created_at`
+
+// CreateEmailChange records a pending email change, cancelling the one the
+// user may still have pending.
+func (r *EmailChangeRepository) CreateEmailChange(ctx context.Context, change model.EmailChange) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE email_changes
+		SET status = 'cancelled', cancelled_at = NOW()
+		WHERE user_id = $1 AND status = 'pending'
+	`, change.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO email_changes (id, user_id, old_email, old_email_verified, new_email, status,
+		                           confirm_token_hash, cancel_token_hash, expires_at, cancel_expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`,
+		change.ID, change.UserID, change.OldEmail, change.OldEmailVerified, change.NewEmail, change.Status,
+		change.ConfirmTokenHash, change.CancelTokenHash, change.ExpiresAt, change.CancelExpiresAt, change.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ConfirmEmailChange moves the user to the new address of the pending change
+// with the confirm token. The new address is verified by the confirmation
+// itself.
+//
+// The swap only happens while the user still has the old address, and fails
+// with ErrEmailAlreadyExists if someone registered the new one in the meantime.
+func (r *EmailChangeRepository) ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (*model.EmailChange, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	change, err := lockEmailChange(ctx, tx, fmt.Sprintf(`
+		SELECT %s
+		FROM email_changes
+		WHERE confirm_token_hash = $1 AND status = 'pending' AND expires_at > NOW()
+		FOR UPDATE
+	`, emailChangeColumns), confirmTokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	err = setUserEmail(ctx, tx, change.UserID, change.OldEmail, change.NewEmail, true)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE email_changes
+		SET status = 'confirmed', confirmed_at = NOW()
+		WHERE id = $1
+	`, change.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// CancelEmailChange withdraws the change with the cancel token. A change that
+// was already confirmed is rolled back: the user gets the old address back,
+// with its previous verification state.
+//
+// It returns the change as it was before cancelling.
+func (r *EmailChangeRepository) CancelEmailChange(ctx context.Context, cancelTokenHash string) (*model.EmailChange, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	change, err := lockEmailChange(ctx, tx, fmt.Sprintf(`
+		SELECT %s
+		FROM email_changes
+		WHERE cancel_token_hash = $1 AND status IN ('pending', 'confirmed') AND cancel_expires_at > NOW()
+		FOR UPDATE
+	`, emailChangeColumns), cancelTokenHash)
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Status == model.EmailChangeStatusConfirmed {
+		err = setUserEmail(ctx, tx, change.UserID, change.NewEmail, change.OldEmail, change.OldEmailVerified)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE email_changes
+		SET status = 'cancelled', cancelled_at = NOW()
+		WHERE id = $1
+	`, change.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func lockEmailChange(ctx context.Context, tx pgx.Tx, query, tokenHash string) (*model.EmailChange, error) {
+	rows, _ := tx.Query(ctx, query, tokenHash)
+	change, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.EmailChange])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrEmailChangeTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &change, nil
+}
+
+// setUserEmail replaces the user's email, provided it is still fromEmail.
+// Otherwise the account moved on since the change was requested and the
+// token no longer applies.
+func setUserEmail(ctx context.Context, tx pgx.Tx, userID, fromEmail, toEmail string, verified bool) error {
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET email = $1, email_verified = $2, updated_at = NOW()
+		WHERE id = $3 AND email = $4 AND deleted_at IS NULL
+	`, toEmail, verified, userID, fromEmail)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_email_key" {
+			return model.ErrEmailAlreadyExists
+		}
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return model.ErrEmailChangeTokenInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+const hostVerificationColumns = `id, user_id, document_type, front_key, back_key, status,
+		rejection_reason, reviewed_by, reviewed_at, created_at`
+
+// CreateHostVerification stores a new submission and marks the user as
+// pending. It fails with ErrHostVerificationPending while another submission
+// of the user waits for review.
+func (r *HostVerificationRepository) CreateHostVerification(ctx context.Context, v model.HostVerification) (*model.HostVerification, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, _ := tx.Query(ctx, fmt.Sprintf(`
+		INSERT INTO host_verifications (id, user_id, document_type, front_key, back_key, status, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING %s
+	`, hostVerificationColumns), v.ID, v.UserID, v.DocumentType, v.FrontKey, v.BackKey, v.Status, v.CreatedAt)
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.HostVerification])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "idx_host_verifications_user_id_pending" {
+			return nil, model.ErrHostVerificationPending
+		}
+		return nil, err
+	}
+
+	result, err := tx.Exec(ctx, `
+		UPDATE users
+		SET host_verification_status = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, model.HostVerificationStatusPending, v.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected() == 0 {
+		return nil, model.ErrUserNotFound
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (r *HostVerificationRepository) FindHostVerificationByID(ctx context.Context, id string) (*model.HostVerification, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM host_verifications
+		WHERE id = $1
+	`, hostVerificationColumns)
+
+	rows, _ := r.db.Query(ctx, query, id)
+	v, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.HostVerification])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrHostVerificationNotFound
+		}
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// FindLatestHostVerification returns the most recent submission of the user.
+func (r *HostVerificationRepository) FindLatestHostVerification(ctx context.Context, userID string) (*model.HostVerification, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM host_verifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, hostVerificationColumns)
+
+	rows, _ := r.db.Query(ctx, query, userID)
+	v, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.HostVerification])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrHostVerificationNotFound
+		}
+		return nil, err
+	}
+
+	return &v, nil
+}
+
+// ListHostVerificationsByUser returns every submission of the user, newest first.
+func (r *HostVerificationRepository) ListHostVerificationsByUser(ctx context.Context, userID string) ([]model.HostVerification, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM host_verifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, hostVerificationColumns)
+
+	rows, _ := r.db.Query(ctx, query, userID)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.HostVerification])
+}
+
+// ListHostVerificationsByStatus returns submissions in the order they came
+// in, so the review queue is worked oldest first.
+func (r *HostVerificationRepository) ListHostVerificationsByStatus(ctx context.Context, status model.HostVerificationStatus, limit, offset int) ([]model.HostVerification, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM host_verifications
+		WHERE status = $1
+		ORDER BY created_at, id
+		LIMIT $2 OFFSET $3
+	`, hostVerificationColumns)
+
+	rows, _ := r.db.Query(ctx, query, status, limit, offset)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.HostVerification])
+}
+
+func (r *HostVerificationRepository) CountHostVerificationsByStatus(ctx context.Context, status model.HostVerificationStatus) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM host_verifications
+		WHERE status = $1
+	`
+
+	var count int64
+	err := r.db.QueryRow(ctx, query, status).Scan(&count)
+	return count, err
+}
+
+// ReviewHostVerification records the decision on a pending submission and
+// copies it to the user. It fails with ErrHostVerificationAlreadyReviewed if the
+// submission was already reviewed.
+func (r *HostVerificationRepository) ReviewHostVerification(ctx context.Context, arg model.ReviewHostVerificationParams) (*model.HostVerification, error) {
+	status := model.HostVerificationStatusRejected
+	var rejectionReason *string
+	if arg.Approve {
+		status = model.HostVerificationStatusVerified
+	} else {
+		rejectionReason = &arg.RejectionReason
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, _ := tx.Query(ctx, fmt.Sprintf(`
+		UPDATE host_verifications
+		SET status = $1, rejection_reason = $2, reviewed_by = $3, reviewed_at = NOW()
+		WHERE id = $4 AND status = $5
+		RETURNING %s
+	`, hostVerificationColumns), status, rejectionReason, arg.ReviewerID, arg.ID, model.HostVerificationStatusPending)
+	reviewed, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.HostVerification])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Tell a missing submission apart from one that was already reviewed
+			var exists bool
+			if err = tx.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM host_verifications WHERE id = $1)`, arg.ID).Scan(&exists); err != nil {
+				return nil, err
+			}
+			if exists {
+				return nil, model.ErrHostVerificationAlreadyReviewed
+			}
+			return nil, model.ErrHostVerificationNotFound
+		}
+		return nil, err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE users
+		SET host_verification_status = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`, status, reviewed.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &reviewed, nil
+}
+
+// DeleteAnonymizedHostVerifications removes the submissions of anonymized
+// accounts and returns the document keys, so the files can be deleted too.
+func (r *HostVerificationRepository) DeleteAnonymizedHostVerifications(ctx context.Context) ([]string, error) {
+	query := `
+		WITH deleted AS (
+			DELETE FROM host_verifications hv
+			USING users u
+			WHERE hv.user_id = u.id AND u.anonymized_at IS NOT NULL
+			RETURNING hv.front_key, hv.back_key
+		)
+		SELECT front_key FROM deleted
+		UNION ALL
+		SELECT back_key FROM deleted WHERE back_key IS NOT NULL
+	`
+
+	rows, _ := r.db.Query(ctx, query)
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
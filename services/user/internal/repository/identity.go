@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+const identityColumns = `id, user_id, provider, subject, email, last_login_at, created_at`
+
+func (r *IdentityRepository) CreateAuthRequest(ctx context.Context, authRequest model.OIDCAuthRequest) error {
+	query := `
+		INSERT INTO oidc_auth_requests (state_hash, provider, nonce, code_verifier, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		authRequest.StateHash, authRequest.Provider, authRequest.Nonce,
+		authRequest.CodeVerifier, authRequest.ExpiresAt, authRequest.CreatedAt,
+	)
+	return err
+}
+
+// ConsumeAuthRequest deletes and returns the unexpired request with the given
+// state, so each state can complete at most one login. Expired requests of
+// other logins are cleaned up on the way. Anything else yields model.ErrOIDCStateInvalid.
+func (r *IdentityRepository) ConsumeAuthRequest(ctx context.Context, stateHash string) (*model.OIDCAuthRequest, error) {
+	query := `
+		DELETE FROM oidc_auth_requests
+		WHERE state_hash = $1 OR expires_at < NOW()
+		RETURNING state_hash, provider, nonce, code_verifier, expires_at, created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query, stateHash)
+	authRequests, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.OIDCAuthRequest])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, authRequest := range authRequests {
+		if authRequest.StateHash == stateHash && authRequest.ExpiresAt.After(time.Now()) {
+			return &authRequest, nil
+		}
+	}
+
+	return nil, model.ErrOIDCStateInvalid
+}
+
+// FindIdentity returns the link of a provider account, or model.ErrIdentityNotFound.
+func (r *IdentityRepository) FindIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`, identityColumns)
+
+	rows, _ := r.db.Query(ctx, query, provider, subject)
+	identity, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.UserIdentity])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrIdentityNotFound
+		}
+		return nil, err
+	}
+
+	return &identity, nil
+}
+
+// CreateUserWithIdentity registers a new account together with its provider
+// link, so there is never an account that nobody can log into.
+func (r *IdentityRepository) CreateUserWithIdentity(ctx context.Context, user model.User, identity model.UserIdentity) (*model.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	rows, _ := tx.Query(ctx, fmt.Sprintf(`
+		INSERT INTO users (id, display_name, email, password_hash, email_verified, created_at, updated_at)
+		VALUES ($1, $2, $3, NULL, $4, $5, $6)
+		RETURNING %s
+	`, userColumns), user.ID, user.DisplayName, user.Email, user.EmailVerified, user.CreatedAt, user.UpdatedAt)
+	createdUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "users_email_key" {
+			return nil, model.ErrEmailAlreadyExists
+		}
+		return nil, err
+	}
+
+	if err = insertIdentity(ctx, tx, identity); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &createdUser, nil
+}
+
+// LinkIdentity links a provider account to an existing user.
+//
+// With claimUnverified the user's email was never verified, so whoever
+// registered it may not own it. The provider just proved who does: the email is
+// marked verified and the password, which the registrant chose, is removed.
+func (r *IdentityRepository) LinkIdentity(ctx context.Context, identity model.UserIdentity, claimUnverified bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if claimUnverified {
+		result, err := tx.Exec(ctx, `
+			UPDATE users
+			SET email_verified = TRUE, password_hash = NULL, updated_at = NOW()
+			WHERE id = $1 AND email_verified = FALSE AND deleted_at IS NULL
+		`, identity.UserID)
+		if err != nil {
+			return err
+		}
+		if result.RowsAffected() == 0 {
+			return model.ErrUserNotFound
+		}
+	}
+
+	if err = insertIdentity(ctx, tx, identity); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func insertIdentity(ctx context.Context, tx pgx.Tx, identity model.UserIdentity) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO user_identities (id, user_id, provider, subject, email, last_login_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`,
+		identity.ID, identity.UserID, identity.Provider, identity.Subject,
+		identity.Email, identity.LastLoginAt, identity.CreatedAt,
+	)
+	return err
+}
+
+func (r *IdentityRepository) UpdateIdentityLastLogin(ctx context.Context, id string) error {
+	query := `
+		UPDATE user_identities
+		SET last_login_at = NOW()
+		WHERE id = $1
+	`
+
+	_, err := r.db.Exec(ctx, query, id)
+	return err
+}
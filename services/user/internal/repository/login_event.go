@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (r *LoginEventRepository) CreateLoginEvent(ctx context.Context, event model.LoginEvent) error {
+	query := `
+		INSERT INTO login_events (id, user_id, success, reason, client_ip, user_agent, device_fingerprint, new_device, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		event.ID, event.UserID, event.Success, event.Reason, event.ClientIP,
+		event.UserAgent, event.DeviceFingerprint, event.NewDevice, event.CreatedAt,
+	)
+	return err
+}
+
+// HasSuccessfulLogin reports whether the user ever logged in successfully from
+// the device. An empty fingerprint matches any device.
+func (r *LoginEventRepository) HasSuccessfulLogin(ctx context.Context, userID, deviceFingerprint string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM login_events
+			WHERE user_id = $1 AND success AND ($2 = '' OR device_fingerprint = $2)
+		)
+	`
+
+	var exists bool
+	err := r.db.QueryRow(ctx, query, userID, deviceFingerprint).Scan(&exists)
+	return exists, err
+}
+
+// ListLoginEvents returns the login history of a user, newest first.
+func (r *LoginEventRepository) ListLoginEvents(ctx context.Context, userID string, limit, offset int) ([]model.LoginEvent, error) {
+	query := `
+		SELECT id, user_id, success, reason, client_ip, user_agent, device_fingerprint, new_device, created_at
+		FROM login_events
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, _ := r.db.Query(ctx, query, userID, limit, offset)
+	return pgx.CollectRows(rows, pgx.RowToStructByName[model.LoginEvent])
+}
+
+func (r *LoginEventRepository) CountLoginEvents(ctx context.Context, userID string) (int64, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM login_events
+		WHERE user_id = $1
+	`
+
+	var count int64
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -11,6 +13,15 @@ import (
 	"github.com/katatrina/airbnb-clone/services/user/internal/model"
 )
 
+// userColumns lists the columns scanned into model.User, in one place so every
+// query stays in sync as the table grows.
+//
+// password_hash is NULL for accounts without a password and scanned as "".
+const userColumns = `id, display_name, email, COALESCE(password_hash, '') AS password_hash, email_verified, roles,
+		bio, phone, phone_verified_at, languages, avatar_key, avatar_url, host_verification_status,
+		locale, currency, timezone, notification_preferences,
+		last_login_at, created_at, updated_at, deleted_at`
+
 type UserRepository struct {
 	db *pgxpool.Pool
 }
@@ -23,12 +34,76 @@ func (r *UserRepository) Close() {
 	r.db.Close()
 }
 
+type SessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+type UserTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserTokenRepository(db *pgxpool.Pool) *UserTokenRepository {
+	return &UserTokenRepository{db: db}
+}
+
+type TwoFactorRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTwoFactorRepository(db *pgxpool.Pool) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+type IdentityRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewIdentityRepository(db *pgxpool.Pool) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+type LoginEventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginEventRepository(db *pgxpool.Pool) *LoginEventRepository {
+	return &LoginEventRepository{db: db}
+}
+
+type EmailChangeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewEmailChangeRepository(db *pgxpool.Pool) *EmailChangeRepository {
+	return &EmailChangeRepository{db: db}
+}
+
+type DataExportRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewDataExportRepository(db *pgxpool.Pool) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+type HostVerificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewHostVerificationRepository(db *pgxpool.Pool) *HostVerificationRepository {
+	return &HostVerificationRepository{db: db}
+}
+
 func (r *UserRepository) CreateUser(ctx context.Context, user model.User) (*model.User, error) {
-	query := `
+	query := fmt.Sprintf(`
 		INSERT INTO users (id, display_name, email, password_hash, email_verified, last_login_at, created_at, updated_at, deleted_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, display_name, email, password_hash, email_verified, last_login_at, created_at, updated_at, deleted_at
-	`
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9)
+		RETURNING %s
+	`, userColumns)
 
 	rows, _ := r.db.Query(ctx, query, user.ID, user.DisplayName, user.Email, user.PasswordHash, user.EmailVerified, user.LastLoginAt, user.CreatedAt, user.UpdatedAt, user.DeletedAt)
 	createdUser, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
@@ -46,11 +121,11 @@ func (r *UserRepository) CreateUser(ctx context.Context, user model.User) (*mode
 }
 
 func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*model.User, error) {
-	query := `
-		SELECT id, display_name, email, password_hash, email_verified, last_login_at, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM users
 		WHERE email = $1 and deleted_at IS NULL
-	`
+	`, userColumns)
 
 	rows, _ := r.db.Query(ctx, query, email)
 	user, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
@@ -75,11 +150,11 @@ func (r *UserRepository) CheckEmailExists(ctx context.Context, email string) (bo
 }
 
 func (r *UserRepository) FindUserByID(ctx context.Context, id string) (*model.User, error) {
-	query := `
-		SELECT id, display_name, email, password_hash, email_verified, last_login_at, created_at, updated_at, deleted_at
+	query := fmt.Sprintf(`
+		SELECT %s
 		FROM users
 		WHERE id = $1 and deleted_at IS NULL
-	`
+	`, userColumns)
 
 	rows, _ := r.db.Query(ctx, query, id)
 	user, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
@@ -105,3 +180,286 @@ func (r *UserRepository) UpdateUserLastLogin(ctx context.Context, id string, las
 	// No need to check for affected rows here
 	return err
 }
+
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, id string) error {
+	query := `
+		UPDATE users
+		SET email_verified = TRUE, updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// MarkPhoneVerified marks phone as verified, provided it is still the user's
+// number. Otherwise it returns model.ErrPhoneCodeExpired: the code was sent to
+// a number the user has since replaced.
+func (r *UserRepository) MarkPhoneVerified(ctx context.Context, id, phone string) error {
+	query := `
+		UPDATE users
+		SET phone_verified_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND phone = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id, phone)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrPhoneCodeExpired
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdateUserPassword(ctx context.Context, id, passwordHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, passwordHash, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// RehashUserPassword swaps currentHash for newHash, an equivalent hash of the
+// same password. Nothing happens if the password was changed in the meantime.
+// updated_at is left alone since the user changed nothing.
+func (r *UserRepository) RehashUserPassword(ctx context.Context, id, currentHash, newHash string) error {
+	query := `
+		UPDATE users
+		SET password_hash = $1
+		WHERE id = $2 AND password_hash = $3 AND deleted_at IS NULL
+	`
+
+	_, err := r.db.Exec(ctx, query, newHash, id, currentHash)
+	return err
+}
+
+func (r *UserRepository) UpdateUserProfile(ctx context.Context, id string, params model.UpdateUserProfileParams) (*model.User, error) {
+	var setClauses []string
+	var args []interface{}
+	paramIndex := 1
+
+	if params.DisplayName != nil {
+		setClauses = append(setClauses, fmt.Sprintf("display_name = $%d", paramIndex))
+		args = append(args, *params.DisplayName)
+		paramIndex++
+	}
+
+	if params.Bio != nil {
+		setClauses = append(setClauses, fmt.Sprintf("bio = $%d", paramIndex))
+		args = append(args, *params.Bio)
+		paramIndex++
+	}
+
+	if params.Phone != nil {
+		// An empty phone number removes it
+		setClauses = append(setClauses, fmt.Sprintf("phone = NULLIF($%d, '')", paramIndex))
+		// A new number has to be verified again; the right-hand side still sees the old phone
+		setClauses = append(setClauses, fmt.Sprintf(
+			"phone_verified_at = CASE WHEN phone IS NOT DISTINCT FROM NULLIF($%d, '') THEN phone_verified_at END", paramIndex))
+		args = append(args, *params.Phone)
+		paramIndex++
+	}
+
+	if params.Languages != nil {
+		setClauses = append(setClauses, fmt.Sprintf("languages = $%d", paramIndex))
+		args = append(args, params.Languages)
+		paramIndex++
+	}
+
+	if len(setClauses) == 0 {
+		return r.FindUserByID(ctx, id)
+	}
+
+	setClauses = append(setClauses, fmt.Sprintf("updated_at = $%d", paramIndex))
+	args = append(args, time.Now())
+	paramIndex++
+
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE users
+		SET %s
+		WHERE id = $%d AND deleted_at IS NULL
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), paramIndex, userColumns)
+
+	rows, _ := r.db.Query(ctx, query, args...)
+	user, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UpdateUserAvatar replaces the avatar of the user. Passing nil key and url removes it.
+func (r *UserRepository) UpdateUserAvatar(ctx context.Context, id string, avatarKey, avatarURL *string) (*model.User, error) {
+	query := fmt.Sprintf(`
+		UPDATE users
+		SET avatar_key = $1, avatar_url = $2, updated_at = NOW()
+		WHERE id = $3 AND deleted_at IS NULL
+		RETURNING %s
+	`, userColumns)
+
+	rows, _ := r.db.Query(ctx, query, avatarKey, avatarURL, id)
+	user, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// UpdateUserPreferences replaces all preferences of the user.
+func (r *UserRepository) UpdateUserPreferences(ctx context.Context, id string, prefs model.UserPreferences) (*model.User, error) {
+	query := fmt.Sprintf(`
+		UPDATE users
+		SET locale = $1, currency = $2, timezone = $3, notification_preferences = $4, updated_at = NOW()
+		WHERE id = $5 AND deleted_at IS NULL
+		RETURNING %s
+	`, userColumns)
+
+	rows, _ := r.db.Query(ctx, query, prefs.Locale, prefs.Currency, prefs.Timezone, prefs.Notifications, id)
+	user, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// SoftDeleteUser marks the account as deleted. Every other query ignores
+// deleted users, so from here on the account can neither log in nor be found.
+func (r *UserRepository) SoftDeleteUser(ctx context.Context, id string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrUserNotFound
+	}
+
+	return nil
+}
+
+// AnonymizeDeletedUsers scrubs the personal data of accounts deleted before
+// deletedBefore, together with their sessions, one-time tokens, 2FA secrets,
+// links to identity providers, login history and past email changes.
+// The row itself stays so IDs referenced by other services remain valid; the
+// email is replaced by a unique placeholder, which also frees the address for
+// a new registration.
+//
+// It returns the avatar keys that were cleared so the caller can delete the files.
+func (r *UserRepository) AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	query := `
+		WITH targets AS (
+			SELECT id, avatar_key
+			FROM users
+			WHERE deleted_at < $1 AND anonymized_at IS NULL
+			FOR UPDATE
+		), deleted_sessions AS (
+			DELETE FROM sessions WHERE user_id IN (SELECT id FROM targets)
+		), deleted_tokens AS (
+			DELETE FROM user_tokens WHERE user_id IN (SELECT id FROM targets)
+		), deleted_totp AS (
+			DELETE FROM user_totp WHERE user_id IN (SELECT id FROM targets)
+		), deleted_recovery_codes AS (
+			DELETE FROM recovery_codes WHERE user_id IN (SELECT id FROM targets)
+		), deleted_identities AS (
+			DELETE FROM user_identities WHERE user_id IN (SELECT id FROM targets)
+		), deleted_login_events AS (
+			DELETE FROM login_events WHERE user_id IN (SELECT id FROM targets)
+		), deleted_email_changes AS (
+			DELETE FROM email_changes WHERE user_id IN (SELECT id FROM targets)
+		)
+		UPDATE users u
+		SET display_name  = 'Deleted user',
+			email         = 'deleted-' || u.id || '@deleted.invalid',
+			password_hash = NULL,
+			bio           = '',
+			phone         = NULL,
+			phone_verified_at = NULL,
+			languages     = '{}',
+			avatar_key    = NULL,
+			avatar_url    = NULL,
+			anonymized_at = NOW(),
+			host_verification_status = 'unverified',
+			updated_at    = NOW()
+		FROM targets t
+		WHERE u.id = t.id
+		RETURNING t.avatar_key
+	`
+
+	rows, _ := r.db.Query(ctx, query, deletedBefore)
+	avatarKeys, err := pgx.CollectRows(rows, pgx.RowTo[*string])
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(avatarKeys))
+	for _, key := range avatarKeys {
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *UserRepository) UpdateUserRoles(ctx context.Context, id string, roles []string) (*model.User, error) {
+	query := fmt.Sprintf(`
+		UPDATE users
+		SET roles = $1, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING %s
+	`, userColumns)
+
+	rows, _ := r.db.Query(ctx, query, roles, id)
+	user, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.User])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
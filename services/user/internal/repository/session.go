@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (r *SessionRepository) CreateSession(ctx context.Context, session model.Session) (*model.Session, error) {
+	query := `
+		INSERT INTO sessions (id, family_id, user_id, refresh_token_hash, user_agent, client_ip, expires_at, rotated_at, revoked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, family_id, user_id, refresh_token_hash, user_agent, client_ip, expires_at, rotated_at, revoked_at, created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query,
+		session.ID, session.FamilyID, session.UserID, session.RefreshTokenHash,
+		session.UserAgent, session.ClientIP, session.ExpiresAt,
+		session.RotatedAt, session.RevokedAt, session.CreatedAt,
+	)
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Session])
+	if err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// FindSessionByTokenHash returns the session row for a refresh token regardless
+// of its state, so callers can tell a reused (rotated) token from an unknown one.
+func (r *SessionRepository) FindSessionByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	query := `
+		SELECT id, family_id, user_id, refresh_token_hash, user_agent, client_ip, expires_at, rotated_at, revoked_at, created_at
+		FROM sessions
+		WHERE refresh_token_hash = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, tokenHash)
+	session, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Session])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrSessionNotFound
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RotateSession marks the current refresh token as used and stores its successor
+// in a single transaction. It returns model.ErrSessionAlreadyRotated when another
+// request rotated the same token first.
+func (r *SessionRepository) RotateSession(ctx context.Context, currentID string, next model.Session) (*model.Session, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, `
+		UPDATE sessions
+		SET rotated_at = $1
+		WHERE id = $2 AND rotated_at IS NULL AND revoked_at IS NULL
+	`, next.CreatedAt, currentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.RowsAffected() == 0 {
+		return nil, model.ErrSessionAlreadyRotated
+	}
+
+	rows, _ := tx.Query(ctx, `
+		INSERT INTO sessions (id, family_id, user_id, refresh_token_hash, user_agent, client_ip, expires_at, rotated_at, revoked_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, family_id, user_id, refresh_token_hash, user_agent, client_ip, expires_at, rotated_at, revoked_at, created_at
+	`,
+		next.ID, next.FamilyID, next.UserID, next.RefreshTokenHash,
+		next.UserAgent, next.ClientIP, next.ExpiresAt,
+		next.RotatedAt, next.RevokedAt, next.CreatedAt,
+	)
+	created, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.Session])
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+// ListActiveSessions returns the current refresh token of every live family.
+func (r *SessionRepository) ListActiveSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	query := `
+		SELECT id, family_id, user_id, refresh_token_hash, user_agent, client_ip, expires_at, rotated_at, revoked_at, created_at
+		FROM sessions
+		WHERE user_id = $1 AND rotated_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+	`
+
+	rows, _ := r.db.Query(ctx, query, userID)
+	sessions, err := pgx.CollectRows(rows, pgx.RowToStructByName[model.Session])
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeSessionFamily revokes every refresh token in the family.
+func (r *SessionRepository) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`
+
+	_, err := r.db.Exec(ctx, query, familyID)
+	return err
+}
+
+// RevokeUserSession revokes one of the user's sessions.
+// Returns model.ErrSessionNotFound if the session is not active or belongs to someone else.
+func (r *SessionRepository) RevokeUserSession(ctx context.Context, userID, familyID string) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND family_id = $2 AND revoked_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, familyID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrSessionNotFound
+	}
+
+	return nil
+}
+
+// RevokeUserSessions revokes all of the user's sessions.
+func (r *SessionRepository) RevokeUserSessions(ctx context.Context, userID string) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW()
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`
+
+	_, err := r.db.Exec(ctx, query, userID)
+	return err
+}
+
+// RevokeOtherUserSessions revokes all of the user's sessions except
+// currentFamilyID and returns the families it revoked. A session created
+// while it runs is either revoked and returned, or left alone.
+func (r *SessionRepository) RevokeOtherUserSessions(ctx context.Context, userID, currentFamilyID string) ([]string, error) {
+	query := `
+		WITH revoked AS (
+			UPDATE sessions
+			SET revoked_at = NOW()
+			WHERE user_id = $1 AND family_id::text <> $2 AND revoked_at IS NULL
+			RETURNING family_id
+		)
+		SELECT DISTINCT family_id::text FROM revoked
+	`
+
+	rows, _ := r.db.Query(ctx, query, userID, currentFamilyID)
+	families, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, err
+	}
+
+	return families, nil
+}
@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// FindTOTP returns the authenticator of the user, confirmed or not.
+// It returns model.ErrTwoFactorNotEnabled when the user never started a setup.
+func (r *TwoFactorRepository) FindTOTP(ctx context.Context, userID string) (*model.UserTOTP, error) {
+	query := `
+		SELECT user_id, secret, last_used_step, confirmed_at, created_at
+		FROM user_totp
+		WHERE user_id = $1
+	`
+
+	rows, _ := r.db.Query(ctx, query, userID)
+	totp, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.UserTOTP])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrTwoFactorNotEnabled
+		}
+		return nil, err
+	}
+
+	return &totp, nil
+}
+
+// SaveTOTPSecret starts (or restarts) a setup with a new secret.
+// A confirmed authenticator is never overwritten: model.ErrTwoFactorAlreadyEnabled
+// is returned instead.
+func (r *TwoFactorRepository) SaveTOTPSecret(ctx context.Context, userID, secret string) error {
+	query := `
+		INSERT INTO user_totp (user_id, secret, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+			SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+			WHERE user_totp.confirmed_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrTwoFactorAlreadyEnabled
+	}
+
+	return nil
+}
+
+// EnableTOTP confirms the pending authenticator of the user and stores their
+// recovery codes in one transaction. usedStep is the step of the code that
+// proved the setup, so it cannot be replayed to log in.
+func (r *TwoFactorRepository) EnableTOTP(ctx context.Context, userID string, usedStep int64, codes []model.RecoveryCode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := tx.Exec(ctx, `
+		UPDATE user_totp
+		SET confirmed_at = NOW(), last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NULL
+	`, userID, usedStep)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrTwoFactorAlreadyEnabled
+	}
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, codes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ReplaceRecoveryCodes discards every recovery code of the user and stores new ones.
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err = replaceRecoveryCodes(ctx, tx, userID, codes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userID string, codes []model.RecoveryCode) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for _, code := range codes {
+		_, err := tx.Exec(ctx, `
+			INSERT INTO recovery_codes (id, user_id, code_hash, used_at, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+		`, code.ID, code.UserID, code.CodeHash, code.UsedAt, code.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// MarkTOTPStepUsed records that a code of step was accepted. The update only
+// matches steps newer than the last accepted one, so of two requests racing
+// with the same code only one wins; the other gets model.ErrTwoFactorCodeInvalid.
+func (r *TwoFactorRepository) MarkTOTPStepUsed(ctx context.Context, userID string, step int64) error {
+	query := `
+		UPDATE user_totp
+		SET last_used_step = $2
+		WHERE user_id = $1 AND confirmed_at IS NOT NULL
+			AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, step)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrTwoFactorCodeInvalid
+	}
+
+	return nil
+}
+
+// ConsumeRecoveryCode marks an unused recovery code as used.
+// It returns model.ErrTwoFactorCodeInvalid when there is no such code.
+func (r *TwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	query := `
+		UPDATE recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	result, err := r.db.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrTwoFactorCodeInvalid
+	}
+
+	return nil
+}
+
+func (r *TwoFactorRepository) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	query := `
+		SELECT COUNT(*) FROM recovery_codes WHERE user_id = $1 AND used_at IS NULL
+	`
+
+	var count int64
+	err := r.db.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// DeleteTOTP removes the authenticator and recovery codes of the user.
+func (r *TwoFactorRepository) DeleteTOTP(ctx context.Context, userID string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err = tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(ctx, `DELETE FROM user_totp WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+
+	if result.RowsAffected() == 0 {
+		return model.ErrTwoFactorNotEnabled
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+func (r *UserTokenRepository) CreateUserToken(ctx context.Context, userToken model.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (id, user_id, purpose, token_hash, expires_at, consumed_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	_, err := r.db.Exec(ctx, query,
+		userToken.ID, userToken.UserID, userToken.Purpose, userToken.TokenHash,
+		userToken.ExpiresAt, userToken.ConsumedAt, userToken.CreatedAt,
+	)
+	return err
+}
+
+// ConsumeUserToken marks a token as used and returns it. The update only matches
+// unused, unexpired tokens, so a token can be consumed at most once even under
+// concurrent requests. Anything else yields model.ErrUserTokenInvalid.
+func (r *UserTokenRepository) ConsumeUserToken(ctx context.Context, purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error) {
+	query := `
+		UPDATE user_tokens
+		SET consumed_at = NOW()
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_id, purpose, token_hash, expires_at, consumed_at, attempts, created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query, tokenHash, purpose)
+	userToken, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.UserToken])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &userToken, nil
+}
+
+// InvalidateUserTokens consumes every outstanding token of the user for purpose,
+// e.g. so only the most recently sent email link keeps working.
+func (r *UserTokenRepository) InvalidateUserTokens(ctx context.Context, userID string, purpose model.TokenPurpose) error {
+	query := `
+		UPDATE user_tokens
+		SET consumed_at = NOW()
+		WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL
+	`
+
+	_, err := r.db.Exec(ctx, query, userID, purpose)
+	return err
+}
+
+// FindUserToken returns an unused, unexpired token without consuming it.
+// Anything else yields model.ErrUserTokenInvalid.
+func (r *UserTokenRepository) FindUserToken(ctx context.Context, purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, consumed_at, attempts, created_at
+		FROM user_tokens
+		WHERE token_hash = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+	`
+
+	rows, _ := r.db.Query(ctx, query, tokenHash, purpose)
+	userToken, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.UserToken])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &userToken, nil
+}
+
+// FindOutstandingUserToken returns the user's unused, unexpired token for
+// purpose, for flows where the user sends back a short code rather than the
+// token itself. Issuing a token invalidates the previous ones, so there is at
+// most one. Anything else yields model.ErrUserTokenInvalid.
+func (r *UserTokenRepository) FindOutstandingUserToken(ctx context.Context, userID string, purpose model.TokenPurpose) (*model.UserToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, consumed_at, attempts, created_at
+		FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2 AND consumed_at IS NULL AND expires_at > NOW()
+		ORDER BY created_at DESC
+		LIMIT 1
+	`
+
+	rows, _ := r.db.Query(ctx, query, userID, purpose)
+	userToken, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.UserToken])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &userToken, nil
+}
+
+// ListUserTokenIssueTimes returns when the user's tokens for purpose created
+// since the given time were issued, oldest first, used or not.
+func (r *UserTokenRepository) ListUserTokenIssueTimes(ctx context.Context, userID string, purpose model.TokenPurpose, since time.Time) ([]time.Time, error) {
+	query := `
+		SELECT created_at
+		FROM user_tokens
+		WHERE user_id = $1 AND purpose = $2 AND created_at >= $3
+		ORDER BY created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query, userID, purpose, since)
+	return pgx.CollectRows(rows, pgx.RowTo[time.Time])
+}
+
+// ClaimUserTokenAttempt counts an attempt at guessing the code or secret behind
+// a token before it is checked. It returns model.ErrUserTokenInvalid once
+// maxAttempts have been used, so concurrent guesses cannot get past the limit
+// by all reading the counter before any of them is recorded.
+func (r *UserTokenRepository) ClaimUserTokenAttempt(ctx context.Context, id string, maxAttempts int) (*model.UserToken, error) {
+	query := `
+		UPDATE user_tokens
+		SET attempts = attempts + 1
+		WHERE id = $1 AND attempts < $2 AND consumed_at IS NULL AND expires_at > NOW()
+		RETURNING id, user_id, purpose, token_hash, expires_at, consumed_at, attempts, created_at
+	`
+
+	rows, _ := r.db.Query(ctx, query, id, maxAttempts)
+	userToken, err := pgx.CollectOneRow(rows, pgx.RowToStructByName[model.UserToken])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrUserTokenInvalid
+		}
+		return nil, err
+	}
+
+	return &userToken, nil
+}
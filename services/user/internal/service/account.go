@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+)
+
+// DeleteAccount closes the user's account after re-checking their password.
+//
+// Deletion is refused while the user has confirmed stays ahead, as guest or as
+// host, since someone is counting on them. Otherwise the host's listings are
+// taken off the market first, then the account is soft-deleted and logged out
+// everywhere. Personal data is kept for the grace period and then scrubbed by
+// AnonymizeDeletedAccounts.
+func (s *UserService) DeleteAccount(ctx context.Context, arg model.DeleteAccountParams) error {
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = s.comparePassword(user, arg.Password)
+	if err != nil {
+		if errors.Is(err, passwordhash.ErrMismatch) {
+			return model.ErrIncorrectPassword
+		}
+		return fmt.Errorf("unexpected error occur when comparing password: %w", err)
+	}
+
+	upcoming, err := s.bookingClient.CountUpcomingBookings(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if upcoming.AsGuest > 0 || upcoming.AsHost > 0 {
+		return model.ErrAccountHasUpcomingBookings
+	}
+
+	// Done before the soft delete: if it fails the account is still intact and
+	// the user can simply retry, instead of leaving bookable listings behind.
+	if err = s.listingClient.DeactivateHostListings(ctx, user.ID); err != nil {
+		return err
+	}
+
+	if err = s.userRepo.SoftDeleteUser(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return s.LogoutAll(ctx, user.ID)
+}
+
+// AnonymizeDeletedAccounts scrubs the personal data of accounts deleted longer
+// than the grace period ago, including their avatar files and ID documents.
+func (s *UserService) AnonymizeDeletedAccounts(ctx context.Context) error {
+	deletedBefore := time.Now().Add(-s.cfg.AccountDeletionGracePeriod)
+
+	avatarKeys, err := s.userRepo.AnonymizeDeletedUsers(ctx, deletedBefore)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range avatarKeys {
+		s.deleteAvatarFile(ctx, key)
+	}
+
+	return s.deleteAnonymizedHostDocuments(ctx)
+}
+
+// RunAccountAnonymizer calls AnonymizeDeletedAccounts every interval until ctx is done.
+func (s *UserService) RunAccountAnonymizer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.AnonymizeDeletedAccounts(ctx); err != nil {
+			log.Printf("[ERROR] failed to anonymize deleted accounts: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
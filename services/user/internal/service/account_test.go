@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeleteAccount(t *testing.T) {
+	const testUserID = "user-123"
+	const testPassword = "correctPassword123"
+
+	testCases := []struct {
+		name        string
+		password    string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name:     "success - deactivates listings, soft-deletes and logs out everywhere",
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+				m.bookingClient.On("CountUpcomingBookings", mock.Anything, testUserID).
+					Return(&model.UpcomingBookingCounts{}, nil)
+				m.listingClient.On("DeactivateHostListings", mock.Anything, testUserID).
+					Return(nil)
+				m.userRepo.On("SoftDeleteUser", mock.Anything, testUserID).
+					Return(nil)
+				m.sessionRepo.On("RevokeUserSessions", mock.Anything, testUserID).
+					Return(nil)
+			},
+		},
+		{
+			name:     "error - wrong password",
+			password: "wrongPassword",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrIncorrectPassword,
+		},
+		{
+			name:     "error - upcoming stay as guest",
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+				m.bookingClient.On("CountUpcomingBookings", mock.Anything, testUserID).
+					Return(&model.UpcomingBookingCounts{AsGuest: 1}, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrAccountHasUpcomingBookings,
+		},
+		{
+			name:     "error - upcoming stay as host",
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+				m.bookingClient.On("CountUpcomingBookings", mock.Anything, testUserID).
+					Return(&model.UpcomingBookingCounts{AsHost: 2}, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrAccountHasUpcomingBookings,
+		},
+		{
+			name:     "error - booking service down, nothing is deleted",
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+				m.bookingClient.On("CountUpcomingBookings", mock.Anything, testUserID).
+					Return(nil, model.ErrBookingServiceUnavailable)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrBookingServiceUnavailable,
+		},
+		{
+			name:     "error - listing service down, account is kept",
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+				m.bookingClient.On("CountUpcomingBookings", mock.Anything, testUserID).
+					Return(&model.UpcomingBookingCounts{}, nil)
+				m.listingClient.On("DeactivateHostListings", mock.Anything, testUserID).
+					Return(model.ErrListingServiceUnavailable)
+				// SoftDeleteUser KHÔNG được gọi
+			},
+			wantErr:     true,
+			expectedErr: model.ErrListingServiceUnavailable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			err := service.DeleteAccount(context.Background(), model.DeleteAccountParams{
+				UserID:   testUserID,
+				Password: tc.password,
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+
+				revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: testUserID, IssuedAt: time.Now().Add(-time.Minute)})
+				require.NoError(t, err)
+				assert.True(t, revoked)
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAnonymizeDeletedAccounts(t *testing.T) {
+	m, service := newMocksAndService()
+	m.userRepo.On("AnonymizeDeletedUsers", mock.Anything, mock.MatchedBy(func(deletedBefore time.Time) bool {
+		// Chỉ các tài khoản đã xoá lâu hơn grace period
+		expected := time.Now().Add(-testAccountDeletionGracePeriod)
+		return deletedBefore.Sub(expected).Abs() < time.Minute
+	})).
+		Return([]string{"avatars/user-1/a.png", "avatars/user-2/b.jpg"}, nil)
+	m.avatarStore.On("Delete", mock.Anything, "avatars/user-1/a.png").Return(nil)
+	m.avatarStore.On("Delete", mock.Anything, "avatars/user-2/b.jpg").Return(nil)
+	m.hostVerifies.On("DeleteAnonymizedHostVerifications", mock.Anything).
+		Return([]string{"host-verifications/user-1/v-1/front.jpg", "host-verifications/user-1/v-1/back.jpg"}, nil)
+	m.documentStore.On("Delete", mock.Anything, "host-verifications/user-1/v-1/front.jpg").Return(nil)
+	m.documentStore.On("Delete", mock.Anything, "host-verifications/user-1/v-1/back.jpg").Return(nil)
+
+	require.NoError(t, service.AnonymizeDeletedAccounts(context.Background()))
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/middleware"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// SetUserRoles replaces the roles of a user. Admins cannot drop their own admin
+// role, so the last admin cannot lock everyone out by accident.
+//
+// Roles travel in access tokens, so the user's current tokens are revoked; the
+// client's next refresh picks up the new roles.
+func (s *UserService) SetUserRoles(ctx context.Context, arg model.SetUserRolesParams) (*model.User, error) {
+	roles := slices.Clone(arg.Roles)
+	slices.Sort(roles)
+	roles = slices.Compact(roles)
+
+	if arg.ActorID == arg.UserID && !slices.Contains(roles, string(middleware.RoleAdmin)) {
+		return nil, model.ErrCannotRemoveOwnAdminRole
+	}
+
+	user, err := s.userRepo.UpdateUserRoles(ctx, arg.UserID, roles)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.revocations.RevokeUserTokens(ctx, user.ID, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return user, nil
+}
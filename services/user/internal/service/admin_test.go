@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetUserRoles(t *testing.T) {
+	const adminID = "admin-1"
+	const testUserID = "user-123"
+
+	testCases := []struct {
+		name        string
+		input       model.SetUserRolesParams
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - roles are deduplicated and sorted",
+			input: model.SetUserRolesParams{
+				ActorID: adminID,
+				UserID:  testUserID,
+				Roles:   []string{"host", "guest", "host"},
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("UpdateUserRoles", mock.Anything, testUserID, []string{"guest", "host"}).
+					Return(&model.User{ID: testUserID, Roles: []string{"guest", "host"}}, nil)
+			},
+		},
+		{
+			name: "success - admin keeps own admin role",
+			input: model.SetUserRolesParams{
+				ActorID: adminID,
+				UserID:  adminID,
+				Roles:   []string{"admin", "guest"},
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("UpdateUserRoles", mock.Anything, adminID, []string{"admin", "guest"}).
+					Return(&model.User{ID: adminID, Roles: []string{"admin", "guest"}}, nil)
+			},
+		},
+		{
+			name: "error - admin removes own admin role",
+			input: model.SetUserRolesParams{
+				ActorID: adminID,
+				UserID:  adminID,
+				Roles:   []string{"guest"},
+			},
+			setupMock:   func(m *serviceMocks) {},
+			wantErr:     true,
+			expectedErr: model.ErrCannotRemoveOwnAdminRole,
+		},
+		{
+			name: "error - user not found",
+			input: model.SetUserRolesParams{
+				ActorID: adminID,
+				UserID:  testUserID,
+				Roles:   []string{"guest"},
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("UpdateUserRoles", mock.Anything, testUserID, []string{"guest"}).
+					Return(nil, model.ErrUserNotFound)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			user, err := service.SetUserRoles(context.Background(), tc.input)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				assert.ErrorIs(t, err, tc.expectedErr)
+				assert.Nil(t, user)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.input.UserID, user.ID)
+
+				// Token cũ mang roles cũ nên phải bị thu hồi
+				revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: tc.input.UserID, IssuedAt: time.Now().Add(-time.Minute)})
+				require.NoError(t, err)
+				assert.True(t, revoked)
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+const (
+	// dataExportStaleAfter is how long an export may stay in processing before
+	// the worker assumes its builder crashed and starts over.
+	dataExportStaleAfter = 30 * time.Minute
+
+	dataExportLoginEventsPageSize = 500
+)
+
+// RequestDataExport queues a copy of the user's personal data. The archive is
+// built in the background by RunDataExportWorker, and the user gets an email
+// with a download link once it is ready.
+func (s *UserService) RequestDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	exportID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating data export ID: %w", err)
+	}
+
+	return s.dataExportRepo.CreateDataExport(ctx, model.DataExport{
+		ID:        exportID.String(),
+		UserID:    userID,
+		Status:    model.DataExportStatusPending,
+		CreatedAt: time.Now(),
+	})
+}
+
+// GetDataExport returns the user's most recent data export.
+func (s *UserService) GetDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	return s.dataExportRepo.FindLatestDataExport(ctx, userID)
+}
+
+// OpenDataExport checks a download link and opens the archive it points to.
+// The link stays valid until the export expires, so an interrupted download
+// can be retried. The caller must close the reader.
+func (s *UserService) OpenDataExport(ctx context.Context, rawToken string) (*model.DataExport, io.ReadCloser, error) {
+	userToken, err := s.userTokenRepo.FindUserToken(ctx, model.TokenPurposeDataExportDownload, hashOpaqueToken(rawToken))
+	if err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return nil, nil, model.ErrDataExportLinkInvalid
+		}
+		return nil, nil, err
+	}
+
+	// Deleted accounts keep their tokens until anonymization, not their exports
+	if _, err = s.userRepo.FindUserByID(ctx, userToken.UserID); err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, nil, model.ErrDataExportLinkInvalid
+		}
+		return nil, nil, err
+	}
+
+	export, err := s.dataExportRepo.FindDownloadableDataExport(ctx, userToken.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrDataExportNotFound) {
+			return nil, nil, model.ErrDataExportLinkInvalid
+		}
+		return nil, nil, err
+	}
+
+	file, err := s.exportStore.Open(ctx, *export.FileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return export, file, nil
+}
+
+// RunDataExportWorker calls ProcessDataExports every interval until ctx is done.
+func (s *UserService) RunDataExportWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.ProcessDataExports(ctx); err != nil {
+			log.Printf("[ERROR] failed to process data exports: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDataExports builds every queued export, then deletes the archives
+// of exports that have expired.
+func (s *UserService) ProcessDataExports(ctx context.Context) error {
+	for {
+		export, err := s.dataExportRepo.ClaimDataExport(ctx, time.Now().Add(-dataExportStaleAfter))
+		if err != nil {
+			if errors.Is(err, model.ErrDataExportNotFound) {
+				break
+			}
+			return err
+		}
+
+		s.processDataExport(ctx, export)
+	}
+
+	fileKeys, err := s.dataExportRepo.ExpireDataExports(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range fileKeys {
+		if err = s.exportStore.Delete(ctx, key); err != nil {
+			log.Printf("[WARN] Failed to delete expired data export %s: %v", key, err)
+		}
+	}
+
+	return nil
+}
+
+// processDataExport builds one export and marks it completed or failed.
+// A failed export is not retried; the user can simply request a new one.
+func (s *UserService) processDataExport(ctx context.Context, export *model.DataExport) {
+	if err := s.completeDataExport(ctx, export); err != nil {
+		log.Printf("[ERROR] failed to build data export %s: %v", export.ID, err)
+
+		if err = s.dataExportRepo.FailDataExport(ctx, export.ID, err.Error()); err != nil {
+			log.Printf("[ERROR] failed to mark data export %s as failed: %v", export.ID, err)
+		}
+	}
+}
+
+func (s *UserService) completeDataExport(ctx context.Context, export *model.DataExport) error {
+	user, err := s.userRepo.FindUserByID(ctx, export.UserID)
+	if err != nil {
+		return err
+	}
+
+	archive, err := s.buildDataExportArchive(ctx, user)
+	if err != nil {
+		return err
+	}
+
+	fileKey := fmt.Sprintf("data-exports/%s/%s.zip", user.ID, export.ID)
+	if err = s.exportStore.Put(ctx, fileKey, bytes.NewReader(archive), "application/zip"); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(s.cfg.DataExportExpiry)
+	if err = s.dataExportRepo.CompleteDataExport(ctx, export.ID, fileKey, int64(len(archive)), expiresAt); err != nil {
+		_ = s.exportStore.Delete(ctx, fileKey)
+		return err
+	}
+
+	// The archive is there either way; without the email the user still sees
+	// the export as completed and can request a new one
+	if err = s.sendDataExportReadyEmail(ctx, user); err != nil {
+		log.Printf("[WARN] Failed to send data export email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *UserService) sendDataExportReadyEmail(ctx context.Context, user *model.User) error {
+	rawToken, err := s.issueUserToken(ctx, user.ID, model.TokenPurposeDataExportDownload, s.cfg.DataExportExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/account/data-export/download?token=%s", s.cfg.AppBaseURL, url.QueryEscape(rawToken))
+
+	return s.emailSender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "Your data export is ready",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nThe copy of your personal data you asked for is ready. Download it here:\n\n%s\n\nThe link expires in %s. Keep the file somewhere safe: it contains your personal information.\n",
+			user.DisplayName, link, formatExpiry(s.cfg.DataExportExpiry),
+		),
+	})
+}
+
+// buildDataExportArchive gathers the user's data from every service and packs
+// it into a ZIP archive of JSON files.
+func (s *UserService) buildDataExportArchive(ctx context.Context, user *model.User) ([]byte, error) {
+	loginEvents, err := s.listAllLoginEvents(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	listings, err := s.listingClient.ExportHostListings(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookings, err := s.bookingClient.ExportUserBookings(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	verifications, err := s.hostVerifyRepo.ListHostVerificationsByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", newExportedProfile(user)},
+		{"login_history.json", newExportedLoginEvents(loginEvents)},
+		{"listings.json", listings},
+		{"bookings.json", bookings},
+		{"host_verifications.json", newExportedHostVerifications(verifications)},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("unexpected error occur when encoding %s: %w", file.name, err)
+		}
+
+		w, err := zw.Create(file.name)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected error occur when writing %s: %w", file.name, err)
+		}
+		if _, err = w.Write(content); err != nil {
+			return nil, fmt.Errorf("unexpected error occur when writing %s: %w", file.name, err)
+		}
+	}
+
+	// The ID documents the user uploaded are theirs too
+	for _, v := range verifications {
+		for _, key := range hostDocumentKeys(&v) {
+			if err = s.copyHostDocument(ctx, zw, exportedHostDocumentName(&v, key), key); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err = zw.Close(); err != nil {
+		return nil, fmt.Errorf("unexpected error occur when writing data export archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s *UserService) copyHostDocument(ctx context.Context, zw *zip.Writer, name, key string) error {
+	file, err := s.documentStore.Open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("unexpected error occur when writing %s: %w", name, err)
+	}
+	if _, err = io.Copy(w, file); err != nil {
+		return fmt.Errorf("unexpected error occur when writing %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func (s *UserService) listAllLoginEvents(ctx context.Context, userID string) ([]model.LoginEvent, error) {
+	var all []model.LoginEvent
+
+	for offset := 0; ; offset += dataExportLoginEventsPageSize {
+		events, err := s.loginEventRepo.ListLoginEvents(ctx, userID, dataExportLoginEventsPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, events...)
+		if len(events) < dataExportLoginEventsPageSize {
+			return all, nil
+		}
+	}
+}
+
+// exportedProfile is the user's account as written to profile.json. Every
+// field of model.User is here unless it is listed in unexportedUserFields.
+type exportedProfile struct {
+	ID                     string                       `json:"id"`
+	DisplayName            string                       `json:"displayName"`
+	Email                  string                       `json:"email"`
+	EmailVerified          bool                         `json:"emailVerified"`
+	Roles                  []string                     `json:"roles"`
+	Bio                    string                       `json:"bio"`
+	Phone                  *string                      `json:"phone"`
+	PhoneVerifiedAt        *time.Time                   `json:"phoneVerifiedAt"`
+	Languages              []string                     `json:"languages"`
+	AvatarURL              *string                      `json:"avatarUrl"`
+	HostVerificationStatus model.HostVerificationStatus `json:"hostVerificationStatus"`
+	Preferences            exportedPreferences          `json:"preferences"`
+	LastLoginAt            *time.Time                   `json:"lastLoginAt"`
+	CreatedAt              time.Time                    `json:"createdAt"`
+	UpdatedAt              time.Time                    `json:"updatedAt"`
+}
+
+type exportedPreferences struct {
+	Locale        string                        `json:"locale"`
+	Currency      string                        `json:"currency"`
+	Timezone      string                        `json:"timezone"`
+	Notifications model.NotificationPreferences `json:"notifications"`
+}
+
+// unexportedUserFields are the fields of model.User left out of profile.json
+// on purpose: secrets, storage internals, and what only matters once the
+// account is gone.
+var unexportedUserFields = map[string]bool{
+	"PasswordHash": true,
+	"AvatarKey":    true,
+	"DeletedAt":    true,
+}
+
+func newExportedProfile(user *model.User) exportedProfile {
+	return exportedProfile{
+		ID:                     user.ID,
+		DisplayName:            user.DisplayName,
+		Email:                  user.Email,
+		EmailVerified:          user.EmailVerified,
+		Roles:                  user.Roles,
+		Bio:                    user.Bio,
+		Phone:                  user.Phone,
+		PhoneVerifiedAt:        user.PhoneVerifiedAt,
+		Languages:              user.Languages,
+		AvatarURL:              user.AvatarURL,
+		HostVerificationStatus: user.HostVerificationStatus,
+		Preferences: exportedPreferences{
+			Locale:        user.Locale,
+			Currency:      user.Currency,
+			Timezone:      user.Timezone,
+			Notifications: user.Notifications,
+		},
+		LastLoginAt: user.LastLoginAt,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+	}
+}
+
+// exportedHostVerification is one entry of host_verifications.json. Documents
+// are the names of the uploaded images within the archive.
+type exportedHostVerification struct {
+	ID              string                       `json:"id"`
+	DocumentType    model.HostDocumentType       `json:"documentType"`
+	Status          model.HostVerificationStatus `json:"status"`
+	RejectionReason *string                      `json:"rejectionReason"`
+	ReviewedAt      *time.Time                   `json:"reviewedAt"`
+	Documents       []string                     `json:"documents"`
+	CreatedAt       time.Time                    `json:"createdAt"`
+}
+
+func newExportedHostVerifications(verifications []model.HostVerification) []exportedHostVerification {
+	exported := make([]exportedHostVerification, len(verifications))
+	for i := range verifications {
+		v := &verifications[i]
+
+		var documents []string
+		for _, key := range hostDocumentKeys(v) {
+			documents = append(documents, exportedHostDocumentName(v, key))
+		}
+
+		exported[i] = exportedHostVerification{
+			ID:              v.ID,
+			DocumentType:    v.DocumentType,
+			Status:          v.Status,
+			RejectionReason: v.RejectionReason,
+			ReviewedAt:      v.ReviewedAt,
+			Documents:       documents,
+			CreatedAt:       v.CreatedAt,
+		}
+	}
+
+	return exported
+}
+
+// exportedHostDocumentName places a document in the archive, e.g.
+// host_verifications/{id}/front.jpg.
+func exportedHostDocumentName(v *model.HostVerification, key string) string {
+	return "host_verifications/" + v.ID + "/" + path.Base(key)
+}
+
+// exportedLoginEvent is one entry of login_history.json.
+type exportedLoginEvent struct {
+	Success   bool      `json:"success"`
+	Reason    *string   `json:"reason"`
+	ClientIP  string    `json:"clientIp"`
+	UserAgent string    `json:"userAgent"`
+	NewDevice bool      `json:"newDevice"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func newExportedLoginEvents(events []model.LoginEvent) []exportedLoginEvent {
+	exported := make([]exportedLoginEvent, len(events))
+	for i, e := range events {
+		exported[i] = exportedLoginEvent{
+			Success:   e.Success,
+			Reason:    e.Reason,
+			ClientIP:  e.ClientIP,
+			UserAgent: e.UserAgent,
+			NewDevice: e.NewDevice,
+			CreatedAt: e.CreatedAt,
+		}
+	}
+	return exported
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestDataExport(t *testing.T) {
+	const testUserID = "user-123"
+
+	t.Run("success - export is queued", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.dataExports.On("CreateDataExport", mock.Anything, mock.MatchedBy(func(e model.DataExport) bool {
+			return e.ID != "" && e.UserID == testUserID && e.Status == model.DataExportStatusPending
+		})).Return(&model.DataExport{ID: "export-1", UserID: testUserID, Status: model.DataExportStatusPending}, nil)
+
+		export, err := service.RequestDataExport(context.Background(), testUserID)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.DataExportStatusPending, export.Status)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - another export is in progress", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.dataExports.On("CreateDataExport", mock.Anything, mock.AnythingOfType("model.DataExport")).
+			Return(nil, model.ErrDataExportInProgress)
+
+		_, err := service.RequestDataExport(context.Background(), testUserID)
+
+		require.ErrorIs(t, err, model.ErrDataExportInProgress)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestProcessDataExports(t *testing.T) {
+	const testUserID = "user-123"
+	const testExportID = "export-1"
+
+	phoneVerifiedAt := time.Date(2025, 3, 1, 9, 0, 0, 0, time.UTC)
+	testUser := &model.User{
+		ID:                     testUserID,
+		DisplayName:            "Test User",
+		Email:                  "user@example.com",
+		PasswordHash:           "secret-hash",
+		PhoneVerifiedAt:        &phoneVerifiedAt,
+		HostVerificationStatus: model.HostVerificationStatusRejected,
+		UserPreferences: model.UserPreferences{
+			Locale:   model.LocaleEnglish,
+			Currency: model.CurrencyUSD,
+			Timezone: "Europe/Berlin",
+		},
+	}
+	rejectionReason := "The photo is blurry"
+	backKey := "host-verifications/" + testUserID + "/verification-1/back.png"
+	verification := model.HostVerification{
+		ID:              "verification-1",
+		UserID:          testUserID,
+		DocumentType:    model.HostDocumentTypeNationalID,
+		FrontKey:        "host-verifications/" + testUserID + "/verification-1/front.jpg",
+		BackKey:         &backKey,
+		Status:          model.HostVerificationStatusRejected,
+		RejectionReason: &rejectionReason,
+	}
+	pending := &model.DataExport{ID: testExportID, UserID: testUserID, Status: model.DataExportStatusProcessing}
+	fileKey := "data-exports/" + testUserID + "/" + testExportID + ".zip"
+	listings := json.RawMessage(`[{"id":"listing-1","title":"Cozy flat"}]`)
+	bookings := json.RawMessage(`{"asGuest":[],"asHost":[{"id":"booking-1"}]}`)
+
+	expectClaim := func(m *serviceMocks) {
+		m.dataExports.On("ClaimDataExport", mock.Anything, mock.AnythingOfType("time.Time")).Return(pending, nil).Once()
+		m.dataExports.On("ClaimDataExport", mock.Anything, mock.AnythingOfType("time.Time")).
+			Return(nil, model.ErrDataExportNotFound).Once()
+	}
+	expectGather := func(m *serviceMocks) {
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(testUser, nil)
+		m.loginEvents.On("ListLoginEvents", mock.Anything, testUserID, dataExportLoginEventsPageSize, 0).
+			Return([]model.LoginEvent{{ID: "event-1", UserID: testUserID, Success: true, ClientIP: "203.0.113.7"}}, nil)
+	}
+
+	testCases := []struct {
+		name        string
+		setupMock   func(*serviceMocks, *bytes.Buffer)
+		checkResult func(*testing.T, []byte)
+	}{
+		{
+			name: "success - archive is stored and the user gets a link",
+			setupMock: func(m *serviceMocks, archive *bytes.Buffer) {
+				expectClaim(m)
+				expectGather(m)
+				m.listingClient.On("ExportHostListings", mock.Anything, testUserID).Return(listings, nil)
+				m.bookingClient.On("ExportUserBookings", mock.Anything, testUserID).Return(bookings, nil)
+				m.hostVerifies.On("ListHostVerificationsByUser", mock.Anything, testUserID).
+					Return([]model.HostVerification{verification}, nil)
+				m.documentStore.On("Open", mock.Anything, verification.FrontKey).
+					Return(io.NopCloser(strings.NewReader("front-image")), nil)
+				m.documentStore.On("Open", mock.Anything, backKey).
+					Return(io.NopCloser(strings.NewReader("back-image")), nil)
+				m.exportStore.On("Put", mock.Anything, fileKey, mock.Anything, "application/zip").
+					Run(func(args mock.Arguments) {
+						_, _ = io.Copy(archive, args.Get(2).(io.Reader))
+					}).Return(nil)
+				m.dataExports.On("CompleteDataExport", mock.Anything, testExportID, fileKey,
+					mock.AnythingOfType("int64"), mock.MatchedBy(func(expiresAt time.Time) bool {
+						return time.Until(expiresAt) > testDataExportExpiry-time.Minute
+					})).Return(nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeDataExportDownload).Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(tok model.UserToken) bool {
+					return tok.Purpose == model.TokenPurposeDataExportDownload
+				})).Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return msg.To == "user@example.com" &&
+						strings.Contains(msg.Body, testAppBaseURL+"/account/data-export/download?token=") &&
+						strings.Contains(msg.Body, "72 hours")
+				})).Return(nil)
+				m.dataExports.On("ExpireDataExports", mock.Anything).Return([]string{}, nil)
+			},
+			checkResult: func(t *testing.T, archive []byte) {
+				zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+				require.NoError(t, err)
+
+				files := make(map[string]string)
+				for _, f := range zr.File {
+					rc, err := f.Open()
+					require.NoError(t, err)
+					content, err := io.ReadAll(rc)
+					require.NoError(t, err)
+					_ = rc.Close()
+					files[f.Name] = string(content)
+				}
+
+				require.Len(t, files, 7)
+				assert.Contains(t, files["profile.json"], `"email": "user@example.com"`)
+				assert.Contains(t, files["profile.json"], `"phoneVerifiedAt": "2025-03-01T09:00:00Z"`)
+				assert.Contains(t, files["profile.json"], `"hostVerificationStatus": "rejected"`)
+				assert.Contains(t, files["profile.json"], `"timezone": "Europe/Berlin"`)
+				assert.NotContains(t, files["profile.json"], "secret-hash")
+				assert.Contains(t, files["host_verifications.json"], "The photo is blurry")
+				assert.Contains(t, files["host_verifications.json"], `"host_verifications/verification-1/front.jpg"`)
+				assert.NotContains(t, files["host_verifications.json"], "host-verifications/")
+				assert.Equal(t, "front-image", files["host_verifications/verification-1/front.jpg"])
+				assert.Equal(t, "back-image", files["host_verifications/verification-1/back.png"])
+				assert.Contains(t, files["login_history.json"], "203.0.113.7")
+				assert.NotContains(t, files["login_history.json"], "event-1")
+				assert.Contains(t, files["listings.json"], "Cozy flat")
+				assert.Contains(t, files["bookings.json"], "booking-1")
+			},
+		},
+		{
+			name: "error - a service being down fails the export",
+			setupMock: func(m *serviceMocks, _ *bytes.Buffer) {
+				expectClaim(m)
+				expectGather(m)
+				m.listingClient.On("ExportHostListings", mock.Anything, testUserID).
+					Return(nil, model.ErrListingServiceUnavailable)
+				m.dataExports.On("FailDataExport", mock.Anything, testExportID, model.ErrListingServiceUnavailable.Error()).
+					Return(nil)
+				m.dataExports.On("ExpireDataExports", mock.Anything).Return([]string{}, nil)
+			},
+		},
+		{
+			name: "success - expired archives are deleted",
+			setupMock: func(m *serviceMocks, _ *bytes.Buffer) {
+				m.dataExports.On("ClaimDataExport", mock.Anything, mock.AnythingOfType("time.Time")).
+					Return(nil, model.ErrDataExportNotFound)
+				m.dataExports.On("ExpireDataExports", mock.Anything).
+					Return([]string{"data-exports/user-1/old-1.zip", "data-exports/user-2/old-2.zip"}, nil)
+				m.exportStore.On("Delete", mock.Anything, "data-exports/user-1/old-1.zip").Return(nil)
+				m.exportStore.On("Delete", mock.Anything, "data-exports/user-2/old-2.zip").
+					Return(errors.New("disk error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			var archive bytes.Buffer
+			tc.setupMock(m, &archive)
+
+			err := service.ProcessDataExports(context.Background())
+
+			require.NoError(t, err)
+			if tc.checkResult != nil {
+				tc.checkResult(t, archive.Bytes())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+// TestExportedProfileCoversUser fails when a field is added to model.User
+// without deciding whether the data export includes it.
+func TestExportedProfileCoversUser(t *testing.T) {
+	exported := structFieldNames(reflect.TypeFor[exportedProfile]())
+
+	for _, name := range structFieldNames(reflect.TypeFor[model.User]()) {
+		if unexportedUserFields[name] {
+			assert.NotContains(t, exported, name, "%s is excluded from the export but still exported", name)
+			continue
+		}
+		assert.Contains(t, exported, name,
+			"model.User.%s is missing from the data export: add it to exportedProfile, or to unexportedUserFields if it must stay out", name)
+	}
+}
+
+// structFieldNames lists the fields of a struct, flattening embedded structs
+// and the exported* structs that group fields of the export.
+func structFieldNames(typ reflect.Type) []string {
+	servicePkg := reflect.TypeFor[exportedProfile]().PkgPath()
+
+	var names []string
+	for i := range typ.NumField() {
+		field := typ.Field(i)
+		if field.Anonymous || field.Type.PkgPath() == servicePkg {
+			names = append(names, structFieldNames(field.Type)...)
+			continue
+		}
+		names = append(names, field.Name)
+	}
+	return names
+}
+
+func TestOpenDataExport(t *testing.T) {
+	const testUserID = "user-123"
+	const rawToken = "download-token"
+
+	fileKey := "data-exports/user-123/export-1.zip"
+	completed := &model.DataExport{ID: "export-1", UserID: testUserID, Status: model.DataExportStatusCompleted, FileKey: &fileKey}
+
+	testCases := []struct {
+		name        string
+		setupMock   func(*serviceMocks)
+		expectedErr error
+	}{
+		{
+			name: "success - link opens the archive",
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeDataExportDownload, hashOpaqueToken(rawToken)).
+					Return(&model.UserToken{UserID: testUserID}, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(&model.User{ID: testUserID}, nil)
+				m.dataExports.On("FindDownloadableDataExport", mock.Anything, testUserID).Return(completed, nil)
+				m.exportStore.On("Open", mock.Anything, fileKey).Return(io.NopCloser(strings.NewReader("zip")), nil)
+			},
+		},
+		{
+			name: "error - unknown or expired token",
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeDataExportDownload, hashOpaqueToken(rawToken)).
+					Return(nil, model.ErrUserTokenInvalid)
+			},
+			expectedErr: model.ErrDataExportLinkInvalid,
+		},
+		{
+			name: "error - account was deleted",
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeDataExportDownload, hashOpaqueToken(rawToken)).
+					Return(&model.UserToken{UserID: testUserID}, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(nil, model.ErrUserNotFound)
+			},
+			expectedErr: model.ErrDataExportLinkInvalid,
+		},
+		{
+			name: "error - export has expired",
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeDataExportDownload, hashOpaqueToken(rawToken)).
+					Return(&model.UserToken{UserID: testUserID}, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(&model.User{ID: testUserID}, nil)
+				m.dataExports.On("FindDownloadableDataExport", mock.Anything, testUserID).
+					Return(nil, model.ErrDataExportNotFound)
+			},
+			expectedErr: model.ErrDataExportLinkInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			export, file, err := service.OpenDataExport(context.Background(), rawToken)
+
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, completed, export)
+				require.NoError(t, file.Close())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+)
+
+// emailChangeCancelWindow is how long the old address can undo an email
+// change, counted from the request. It outlasts the confirm link on purpose:
+// the owner may only read their mail after someone else confirmed.
+const emailChangeCancelWindow = 7 * 24 * time.Hour
+
+// RequestEmailChange starts moving the user to a new email address after
+// re-checking their password.
+//
+// Nothing changes until the link sent to the new address is opened. The old
+// address is told about the request and gets a link to cancel it, which also
+// works after confirmation in case the account was taken over.
+func (s *UserService) RequestEmailChange(ctx context.Context, arg model.RequestEmailChangeParams) error {
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = s.comparePassword(user, arg.Password)
+	if err != nil {
+		if errors.Is(err, passwordhash.ErrMismatch) {
+			return model.ErrIncorrectPassword
+		}
+		return fmt.Errorf("unexpected error occur when comparing password: %w", err)
+	}
+
+	if arg.NewEmail == user.Email {
+		return model.ErrEmailUnchanged
+	}
+
+	exists, err := s.userRepo.CheckEmailExists(ctx, arg.NewEmail)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return model.ErrEmailAlreadyExists
+	}
+
+	confirmToken, confirmTokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+	cancelToken, cancelTokenHash, err := newOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	changeID, err := uuid.NewV7()
+	if err != nil {
+		return fmt.Errorf("unexpected error occur when generating email change ID: %w", err)
+	}
+
+	now := time.Now()
+	err = s.emailChangeRepo.CreateEmailChange(ctx, model.EmailChange{
+		ID:               changeID.String(),
+		UserID:           user.ID,
+		OldEmail:         user.Email,
+		OldEmailVerified: user.EmailVerified,
+		NewEmail:         arg.NewEmail,
+		Status:           model.EmailChangeStatusPending,
+		ConfirmTokenHash: confirmTokenHash,
+		CancelTokenHash:  cancelTokenHash,
+		ExpiresAt:        now.Add(s.cfg.EmailVerificationExpiry),
+		CancelExpiresAt:  now.Add(emailChangeCancelWindow),
+		CreatedAt:        now,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = s.sendEmailChangeNotice(ctx, user, arg.NewEmail, cancelToken); err != nil {
+		return err
+	}
+
+	return s.sendEmailChangeConfirmation(ctx, user, arg.NewEmail, confirmToken)
+}
+
+// ConfirmEmailChange redeems the link sent to the new address and switches
+// the account over to it. Links emailed to the old address before, such as
+// password resets, stop working.
+func (s *UserService) ConfirmEmailChange(ctx context.Context, rawToken string) error {
+	change, err := s.emailChangeRepo.ConfirmEmailChange(ctx, hashOpaqueToken(rawToken))
+	if err != nil {
+		return err
+	}
+
+	return s.invalidateEmailedTokens(ctx, change.UserID)
+}
+
+// CancelEmailChange redeems the link sent to the old address. A confirmed
+// change is rolled back, and since whoever confirmed it may be in control of
+// the account, every session is ended too.
+func (s *UserService) CancelEmailChange(ctx context.Context, rawToken string) error {
+	change, err := s.emailChangeRepo.CancelEmailChange(ctx, hashOpaqueToken(rawToken))
+	if err != nil {
+		return err
+	}
+
+	if change.Status != model.EmailChangeStatusConfirmed {
+		return nil
+	}
+
+	if err = s.invalidateEmailedTokens(ctx, change.UserID); err != nil {
+		return err
+	}
+
+	return s.LogoutAll(ctx, change.UserID)
+}
+
+// invalidateEmailedTokens drops outstanding links that went to an address the
+// user no longer has.
+func (s *UserService) invalidateEmailedTokens(ctx context.Context, userID string) error {
+	for _, purpose := range []model.TokenPurpose{model.TokenPurposeEmailVerification, model.TokenPurposePasswordReset} {
+		if err := s.userTokenRepo.InvalidateUserTokens(ctx, userID, purpose); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *UserService) sendEmailChangeConfirmation(ctx context.Context, user *model.User, newEmail, rawToken string) error {
+	link := fmt.Sprintf("%s/confirm-email-change?token=%s", s.cfg.AppBaseURL, url.QueryEscape(rawToken))
+
+	return s.emailSender.Send(ctx, email.Message{
+		To:      newEmail,
+		Subject: "Confirm your new email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm that you want to use this address for your account by opening the link below:\n\n%s\n\nThe link expires in %s. Until then you keep logging in with your current address. If you did not ask for this, you can ignore this email.\n",
+			user.DisplayName, link, formatExpiry(s.cfg.EmailVerificationExpiry),
+		),
+	})
+}
+
+func (s *UserService) sendEmailChangeNotice(ctx context.Context, user *model.User, newEmail, rawToken string) error {
+	link := fmt.Sprintf("%s/cancel-email-change?token=%s", s.cfg.AppBaseURL, url.QueryEscape(rawToken))
+
+	return s.emailSender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "Your email address is being changed",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nSomeone asked to change the email address of your account to %s. If this was you, there is nothing to do.\n\nIf not, cancel the change right away with the link below. It also brings your address back if the change was already confirmed, and logs out every device:\n\n%s\n\nThe link expires in %s.\n",
+			user.DisplayName, newEmail, link, formatExpiry(emailChangeCancelWindow),
+		),
+	})
+}
@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestEmailChange(t *testing.T) {
+	const testUserID = "user-123"
+	const testEmail = "old@example.com"
+	const testNewEmail = "new@example.com"
+	const testPassword = "password123"
+
+	newUser := func() *model.User {
+		user := createTestUser(testUserID, testEmail, testPassword)
+		user.EmailVerified = true
+		return user
+	}
+
+	testCases := []struct {
+		name        string
+		newEmail    string
+		password    string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name:     "success - confirmation to the new address, cancel link to the old one",
+			newEmail: testNewEmail,
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(newUser(), nil)
+				m.userRepo.On("CheckEmailExists", mock.Anything, testNewEmail).Return(false, nil)
+				m.emailChanges.On("CreateEmailChange", mock.Anything, mock.MatchedBy(func(c model.EmailChange) bool {
+					return c.UserID == testUserID && c.OldEmail == testEmail && c.OldEmailVerified &&
+						c.NewEmail == testNewEmail && c.Status == model.EmailChangeStatusPending &&
+						c.ConfirmTokenHash != "" && c.CancelTokenHash != "" && c.ConfirmTokenHash != c.CancelTokenHash &&
+						time.Until(c.ExpiresAt) > testEmailVerificationExpiry-time.Minute &&
+						c.CancelExpiresAt.After(c.ExpiresAt)
+				})).Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return msg.To == testEmail &&
+						strings.Contains(msg.Body, testNewEmail) &&
+						strings.Contains(msg.Body, testAppBaseURL+"/cancel-email-change?token=")
+				})).Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return msg.To == testNewEmail &&
+						strings.Contains(msg.Body, testAppBaseURL+"/confirm-email-change?token=")
+				})).Return(nil)
+			},
+		},
+		{
+			name:     "error - incorrect password",
+			newEmail: testNewEmail,
+			password: "wrongPassword",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(newUser(), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrIncorrectPassword,
+		},
+		{
+			name:     "error - same email as now",
+			newEmail: testEmail,
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(newUser(), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrEmailUnchanged,
+		},
+		{
+			name:     "error - new email belongs to another account",
+			newEmail: testNewEmail,
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(newUser(), nil)
+				m.userRepo.On("CheckEmailExists", mock.Anything, testNewEmail).Return(true, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrEmailAlreadyExists,
+		},
+		{
+			name:     "error - email sending fails",
+			newEmail: testNewEmail,
+			password: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).Return(newUser(), nil)
+				m.userRepo.On("CheckEmailExists", mock.Anything, testNewEmail).Return(false, nil)
+				m.emailChanges.On("CreateEmailChange", mock.Anything, mock.AnythingOfType("model.EmailChange")).Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.AnythingOfType("email.Message")).
+					Return(errors.New("smtp unavailable"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			err := service.RequestEmailChange(context.Background(), model.RequestEmailChangeParams{
+				UserID:   testUserID,
+				NewEmail: tc.newEmail,
+				Password: tc.password,
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestConfirmEmailChange(t *testing.T) {
+	const testUserID = "user-123"
+	const testRawToken = "confirm-token-abc"
+
+	t.Run("success - switches the address and drops links sent to the old one", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.emailChanges.On("ConfirmEmailChange", mock.Anything, hashOpaqueToken(testRawToken)).
+			Return(&model.EmailChange{UserID: testUserID, Status: model.EmailChangeStatusPending}, nil)
+		m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeEmailVerification).Return(nil)
+		m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposePasswordReset).Return(nil)
+
+		err := service.ConfirmEmailChange(context.Background(), testRawToken)
+
+		require.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - new email was taken in the meantime", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.emailChanges.On("ConfirmEmailChange", mock.Anything, hashOpaqueToken(testRawToken)).
+			Return(nil, model.ErrEmailAlreadyExists)
+
+		err := service.ConfirmEmailChange(context.Background(), testRawToken)
+
+		require.ErrorIs(t, err, model.ErrEmailAlreadyExists)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestCancelEmailChange(t *testing.T) {
+	const testUserID = "user-123"
+	const testRawToken = "cancel-token-abc"
+
+	t.Run("success - pending change is just withdrawn", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.emailChanges.On("CancelEmailChange", mock.Anything, hashOpaqueToken(testRawToken)).
+			Return(&model.EmailChange{UserID: testUserID, Status: model.EmailChangeStatusPending}, nil)
+
+		err := service.CancelEmailChange(context.Background(), testRawToken)
+
+		require.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("success - confirmed change is rolled back and every session ends", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.emailChanges.On("CancelEmailChange", mock.Anything, hashOpaqueToken(testRawToken)).
+			Return(&model.EmailChange{UserID: testUserID, Status: model.EmailChangeStatusConfirmed}, nil)
+		m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeEmailVerification).Return(nil)
+		m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposePasswordReset).Return(nil)
+		m.sessionRepo.On("RevokeUserSessions", mock.Anything, testUserID).Return(nil)
+
+		err := service.CancelEmailChange(context.Background(), testRawToken)
+
+		require.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - invalid or expired token", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.emailChanges.On("CancelEmailChange", mock.Anything, hashOpaqueToken(testRawToken)).
+			Return(nil, model.ErrEmailChangeTokenInvalid)
+
+		err := service.CancelEmailChange(context.Background(), testRawToken)
+
+		require.ErrorIs(t, err, model.ErrEmailChangeTokenInvalid)
+		m.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// ResendVerificationEmail sends a fresh verification link, invalidating the previous ones.
+func (s *UserService) ResendVerificationEmail(ctx context.Context, userID string) error {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified {
+		return model.ErrEmailAlreadyVerified
+	}
+
+	return s.sendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail redeems a verification token and marks the owner's email as verified.
+func (s *UserService) VerifyEmail(ctx context.Context, rawToken string) error {
+	userToken, err := s.consumeUserToken(ctx, model.TokenPurposeEmailVerification, rawToken)
+	if err != nil {
+		return err
+	}
+
+	return s.userRepo.MarkEmailVerified(ctx, userToken.UserID)
+}
+
+func (s *UserService) sendVerificationEmail(ctx context.Context, user *model.User) error {
+	rawToken, err := s.issueUserToken(ctx, user.ID, model.TokenPurposeEmailVerification, s.cfg.EmailVerificationExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.cfg.AppBaseURL, url.QueryEscape(rawToken))
+
+	return s.emailSender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nPlease confirm your email address by opening the link below:\n\n%s\n\nThe link expires in %s. If you did not create an account, you can ignore this email.\n",
+			user.DisplayName, link, formatExpiry(s.cfg.EmailVerificationExpiry),
+		),
+	})
+}
@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResendVerificationEmail(t *testing.T) {
+	const testUserID = "user-123"
+
+	testCases := []struct {
+		name        string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - stores only the hash of the token that is emailed",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, Email: "user@example.com"}, nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeEmailVerification).
+					Return(nil)
+
+				var storedHash string
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(userToken model.UserToken) bool {
+					storedHash = userToken.TokenHash
+					return userToken.UserID == testUserID &&
+						userToken.Purpose == model.TokenPurposeEmailVerification &&
+						userToken.ExpiresAt.After(time.Now().Add(testEmailVerificationExpiry-time.Minute))
+				})).
+					Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					prefix := testAppBaseURL + "/verify-email?token="
+					start := strings.Index(msg.Body, prefix)
+					if msg.To != "user@example.com" || start < 0 {
+						return false
+					}
+					rawToken := strings.Fields(msg.Body[start+len(prefix):])[0]
+					return rawToken != storedHash && hashOpaqueToken(rawToken) == storedHash
+				})).
+					Return(nil)
+			},
+		},
+		{
+			name: "error - email already verified",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, EmailVerified: true}, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrEmailAlreadyVerified,
+		},
+		{
+			name: "error - user not found",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(nil, model.ErrUserNotFound)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, svc := newMocksAndService()
+			tc.setupMock(m)
+
+			err := svc.ResendVerificationEmail(context.Background(), testUserID)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	const testUserID = "user-123"
+	const testRawToken = "verification-token-abc"
+
+	t.Run("success - marks the token owner as verified", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userTokenRepo.On("ConsumeUserToken", mock.Anything, model.TokenPurposeEmailVerification, hashOpaqueToken(testRawToken)).
+			Return(&model.UserToken{UserID: testUserID}, nil)
+		m.userRepo.On("MarkEmailVerified", mock.Anything, testUserID).
+			Return(nil)
+
+		require.NoError(t, svc.VerifyEmail(context.Background(), testRawToken))
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - invalid, expired or already used token", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userTokenRepo.On("ConsumeUserToken", mock.Anything, model.TokenPurposeEmailVerification, hashOpaqueToken(testRawToken)).
+			Return(nil, model.ErrUserTokenInvalid)
+
+		err := svc.VerifyEmail(context.Background(), testRawToken)
+		assert.ErrorIs(t, err, model.ErrUserTokenInvalid)
+		m.AssertExpectations(t)
+	})
+}
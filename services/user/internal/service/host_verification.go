@@ -0,0 +1,287 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// MaxHostDocumentSize is the largest ID document file accepted, in bytes.
+const MaxHostDocumentSize = 10 << 20
+
+// hostDocumentExtensions maps the accepted (sniffed) document types to file
+// extensions. Scans often come as PDF rather than photos.
+var hostDocumentExtensions = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
+}
+
+// SubmitHostVerification stores the host's ID documents and puts them in the
+// review queue. A host who was rejected may submit again; a verified host or
+// one already waiting for review may not.
+func (s *UserService) SubmitHostVerification(ctx context.Context, arg model.SubmitHostVerificationParams) (*model.HostVerification, error) {
+	if arg.Front == nil || (arg.DocumentType.RequiresBack() && arg.Back == nil) {
+		return nil, model.ErrHostDocumentMissing
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch user.HostVerificationStatus {
+	case model.HostVerificationStatusVerified:
+		return nil, model.ErrHostAlreadyVerified
+	case model.HostVerificationStatusPending:
+		return nil, model.ErrHostVerificationPending
+	}
+
+	verificationID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating host verification ID: %w", err)
+	}
+
+	// Both files are read before anything is stored, so a bad back side does
+	// not leave an orphaned front side behind
+	front, frontType, err := readHostDocument(arg.Front)
+	if err != nil {
+		return nil, err
+	}
+	var back []byte
+	var backType string
+	if arg.Back != nil {
+		if back, backType, err = readHostDocument(arg.Back); err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := fmt.Sprintf("host-verifications/%s/%s", user.ID, verificationID)
+	verification := model.HostVerification{
+		ID:           verificationID.String(),
+		UserID:       user.ID,
+		DocumentType: arg.DocumentType,
+		FrontKey:     prefix + "/front" + hostDocumentExtensions[frontType],
+		Status:       model.HostVerificationStatusPending,
+		CreatedAt:    time.Now(),
+	}
+
+	if err = s.documentStore.Put(ctx, verification.FrontKey, bytes.NewReader(front), frontType); err != nil {
+		return nil, err
+	}
+	if back != nil {
+		backKey := prefix + "/back" + hostDocumentExtensions[backType]
+		verification.BackKey = &backKey
+		if err = s.documentStore.Put(ctx, backKey, bytes.NewReader(back), backType); err != nil {
+			s.deleteHostDocuments(ctx, verification.FrontKey)
+			return nil, err
+		}
+	}
+
+	created, err := s.hostVerifyRepo.CreateHostVerification(ctx, verification)
+	if err != nil {
+		s.deleteHostDocuments(ctx, hostDocumentKeys(&verification)...)
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// GetHostVerification returns the user's latest submission, or nil if they
+// never submitted one. The user carries the overall status.
+func (s *UserService) GetHostVerification(ctx context.Context, userID string) (*model.User, *model.HostVerification, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	latest, err := s.hostVerifyRepo.FindLatestHostVerification(ctx, userID)
+	if err != nil {
+		if errors.Is(err, model.ErrHostVerificationNotFound) {
+			return user, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	return user, latest, nil
+}
+
+// GetHostVerificationStatus is what other services need to know about a
+// host: whether they passed identity verification.
+func (s *UserService) GetHostVerificationStatus(ctx context.Context, userID string) (model.HostVerificationStatus, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return user.HostVerificationStatus, nil
+}
+
+// ListHostVerifications returns the submissions with the given status, oldest
+// first, together with their total count.
+func (s *UserService) ListHostVerifications(ctx context.Context, status model.HostVerificationStatus, limit, offset int) ([]model.HostVerification, int64, error) {
+	verifications, err := s.hostVerifyRepo.ListHostVerificationsByStatus(ctx, status, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.hostVerifyRepo.CountHostVerificationsByStatus(ctx, status)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return verifications, total, nil
+}
+
+func (s *UserService) GetHostVerificationByID(ctx context.Context, id string) (*model.HostVerification, error) {
+	return s.hostVerifyRepo.FindHostVerificationByID(ctx, id)
+}
+
+// OpenHostDocument opens one side of a submission for an admin to look at.
+// The caller must close the file.
+func (s *UserService) OpenHostDocument(ctx context.Context, id string, side model.HostDocumentSide) (io.ReadCloser, string, error) {
+	verification, err := s.hostVerifyRepo.FindHostVerificationByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, ok := verification.DocumentKey(side)
+	if !ok {
+		return nil, "", model.ErrHostVerificationNotFound
+	}
+
+	file, err := s.documentStore.Open(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return file, hostDocumentContentType(key), nil
+}
+
+// ReviewHostVerification approves or rejects a pending submission and tells
+// the host by email. Admins cannot review their own submission.
+func (s *UserService) ReviewHostVerification(ctx context.Context, arg model.ReviewHostVerificationParams) (*model.HostVerification, error) {
+	verification, err := s.hostVerifyRepo.FindHostVerificationByID(ctx, arg.ID)
+	if err != nil {
+		return nil, err
+	}
+	if verification.UserID == arg.ReviewerID {
+		return nil, model.ErrCannotReviewOwnHostVerification
+	}
+
+	reviewed, err := s.hostVerifyRepo.ReviewHostVerification(ctx, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	// The decision is already recorded, the host can also see it in the app
+	user, err := s.userRepo.FindUserByID(ctx, reviewed.UserID)
+	if err != nil {
+		log.Printf("[WARN] Failed to load host for verification result email: %v", err)
+		return reviewed, nil
+	}
+	if err = s.sendHostVerificationResult(ctx, user, reviewed); err != nil {
+		log.Printf("[WARN] Failed to send host verification result email: %v", err)
+	}
+
+	return reviewed, nil
+}
+
+// deleteAnonymizedHostDocuments removes the ID documents of anonymized
+// accounts. It runs after every anonymization, so documents left over by a
+// failed run are picked up by the next one.
+func (s *UserService) deleteAnonymizedHostDocuments(ctx context.Context) error {
+	keys, err := s.hostVerifyRepo.DeleteAnonymizedHostVerifications(ctx)
+	if err != nil {
+		return err
+	}
+
+	s.deleteHostDocuments(ctx, keys...)
+	return nil
+}
+
+func (s *UserService) sendHostVerificationResult(ctx context.Context, user *model.User, verification *model.HostVerification) error {
+	link := fmt.Sprintf("%s/account/host-verification", s.cfg.AppBaseURL)
+
+	msg := email.Message{
+		To:      user.Email,
+		Subject: "Your identity has been verified",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nGood news: we checked your ID document and your identity is now verified. You can publish your listings right away.\n\n%s\n",
+			user.DisplayName, link,
+		),
+	}
+	if verification.Status == model.HostVerificationStatusRejected {
+		reason := ""
+		if verification.RejectionReason != nil {
+			reason = *verification.RejectionReason
+		}
+		msg.Subject = "We could not verify your identity"
+		msg.Body = fmt.Sprintf(
+			"Hi %s,\n\nWe could not verify your identity with the document you sent:\n\n%s\n\nPlease submit your ID document again:\n\n%s\n",
+			user.DisplayName, reason, link,
+		)
+	}
+
+	return s.emailSender.Send(ctx, msg)
+}
+
+// deleteHostDocuments is best-effort: the files are private, so an orphan is
+// never exposed.
+func (s *UserService) deleteHostDocuments(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if err := s.documentStore.Delete(ctx, key); err != nil {
+			log.Printf("[WARN] failed to delete host document %s: %v", key, err)
+		}
+	}
+}
+
+// readHostDocument reads an uploaded document and sniffs its type from the
+// content rather than trusting the client.
+func readHostDocument(r io.Reader) ([]byte, string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxHostDocumentSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read host document: %w", err)
+	}
+	if len(data) > MaxHostDocumentSize {
+		return nil, "", model.ErrHostDocumentTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	if _, ok := hostDocumentExtensions[contentType]; !ok {
+		return nil, "", model.ErrHostDocumentTypeUnsupported
+	}
+
+	return data, contentType, nil
+}
+
+func hostDocumentKeys(v *model.HostVerification) []string {
+	keys := []string{v.FrontKey}
+	if v.BackKey != nil {
+		keys = append(keys, *v.BackKey)
+	}
+	return keys
+}
+
+// hostDocumentContentType recovers the sniffed type of a stored document from
+// the extension it was saved with.
+func hostDocumentContentType(key string) string {
+	ext := path.Ext(key)
+	for contentType, e := range hostDocumentExtensions {
+		if e == ext {
+			return contentType
+		}
+	}
+	return "application/octet-stream"
+}
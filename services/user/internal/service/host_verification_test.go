@@ -0,0 +1,285 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// Smallest contents http.DetectContentType recognizes.
+const (
+	testJPEG = "\xff\xd8\xff\xe0 jpeg"
+	testPDF  = "%PDF-1.7\n"
+)
+
+func TestSubmitHostVerification(t *testing.T) {
+	const testUserID = "user-123"
+
+	newUser := func(status model.HostVerificationStatus) *model.User {
+		user := createTestUser(testUserID, "host@example.com", "password123")
+		user.HostVerificationStatus = status
+		return user
+	}
+	isDocumentKey := func(side, ext string) any {
+		return mock.MatchedBy(func(key string) bool {
+			return strings.HasPrefix(key, "host-verifications/"+testUserID+"/") && strings.HasSuffix(key, "/"+side+ext)
+		})
+	}
+
+	testCases := []struct {
+		name         string
+		documentType model.HostDocumentType
+		front        string
+		back         string
+		setupMock    func(*serviceMocks)
+		wantErr      bool
+		expectedErr  error
+	}{
+		{
+			name:         "success - national ID with both sides goes to the review queue",
+			documentType: model.HostDocumentTypeNationalID,
+			front:        testJPEG,
+			back:         testPDF,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newUser(model.HostVerificationStatusUnverified), nil)
+				m.documentStore.On("Put", mock.Anything, isDocumentKey("front", ".jpg"), mock.Anything, "image/jpeg").Return(nil)
+				m.documentStore.On("Put", mock.Anything, isDocumentKey("back", ".pdf"), mock.Anything, "application/pdf").Return(nil)
+				m.hostVerifies.On("CreateHostVerification", mock.Anything, mock.MatchedBy(func(v model.HostVerification) bool {
+					return v.UserID == testUserID && v.DocumentType == model.HostDocumentTypeNationalID &&
+						v.Status == model.HostVerificationStatusPending && v.BackKey != nil
+				})).Return(&model.HostVerification{ID: "verification-1", UserID: testUserID}, nil)
+			},
+		},
+		{
+			name:         "success - a rejected host submits a passport again",
+			documentType: model.HostDocumentTypePassport,
+			front:        testJPEG,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newUser(model.HostVerificationStatusRejected), nil)
+				m.documentStore.On("Put", mock.Anything, isDocumentKey("front", ".jpg"), mock.Anything, "image/jpeg").Return(nil)
+				m.hostVerifies.On("CreateHostVerification", mock.Anything, mock.MatchedBy(func(v model.HostVerification) bool {
+					return v.DocumentType == model.HostDocumentTypePassport && v.BackKey == nil
+				})).Return(&model.HostVerification{ID: "verification-2", UserID: testUserID}, nil)
+			},
+		},
+		{
+			name:         "error - national ID without its back side",
+			documentType: model.HostDocumentTypeNationalID,
+			front:        testJPEG,
+			setupMock:    func(m *serviceMocks) {},
+			wantErr:      true,
+			expectedErr:  model.ErrHostDocumentMissing,
+		},
+		{
+			name:         "error - host is already verified",
+			documentType: model.HostDocumentTypePassport,
+			front:        testJPEG,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newUser(model.HostVerificationStatusVerified), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrHostAlreadyVerified,
+		},
+		{
+			name:         "error - previous submission still waits for review",
+			documentType: model.HostDocumentTypePassport,
+			front:        testJPEG,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newUser(model.HostVerificationStatusPending), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrHostVerificationPending,
+		},
+		{
+			name:         "error - back side is not an image or PDF, nothing is stored",
+			documentType: model.HostDocumentTypeDriversLicense,
+			front:        testJPEG,
+			back:         "just some text",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newUser(model.HostVerificationStatusUnverified), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrHostDocumentTypeUnsupported,
+		},
+		{
+			name:         "error - saving the submission fails, uploaded files are removed",
+			documentType: model.HostDocumentTypeNationalID,
+			front:        testJPEG,
+			back:         testJPEG,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newUser(model.HostVerificationStatusUnverified), nil)
+				m.documentStore.On("Put", mock.Anything, mock.Anything, mock.Anything, "image/jpeg").Return(nil).Twice()
+				m.hostVerifies.On("CreateHostVerification", mock.Anything, mock.AnythingOfType("model.HostVerification")).
+					Return(nil, errors.New("connection reset"))
+				m.documentStore.On("Delete", mock.Anything, isDocumentKey("front", ".jpg")).Return(nil)
+				m.documentStore.On("Delete", mock.Anything, isDocumentKey("back", ".jpg")).Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			arg := model.SubmitHostVerificationParams{
+				UserID:       testUserID,
+				DocumentType: tc.documentType,
+				Front:        strings.NewReader(tc.front),
+			}
+			if tc.back != "" {
+				arg.Back = strings.NewReader(tc.back)
+			}
+
+			verification, err := service.SubmitHostVerification(context.Background(), arg)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, verification)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestReviewHostVerification(t *testing.T) {
+	const testAdminID = "admin-1"
+	const testHostID = "host-1"
+	const testVerificationID = "verification-1"
+
+	pending := &model.HostVerification{ID: testVerificationID, UserID: testHostID, Status: model.HostVerificationStatusPending}
+	reason := "The photo is blurry"
+
+	testCases := []struct {
+		name        string
+		arg         model.ReviewHostVerificationParams
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - approval is emailed to the host",
+			arg:  model.ReviewHostVerificationParams{ID: testVerificationID, ReviewerID: testAdminID, Approve: true},
+			setupMock: func(m *serviceMocks) {
+				m.hostVerifies.On("FindHostVerificationByID", mock.Anything, testVerificationID).Return(pending, nil)
+				m.hostVerifies.On("ReviewHostVerification", mock.Anything, mock.Anything).
+					Return(&model.HostVerification{ID: testVerificationID, UserID: testHostID, Status: model.HostVerificationStatusVerified}, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testHostID).
+					Return(createTestUser(testHostID, "host@example.com", "password123"), nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return msg.To == "host@example.com" && strings.Contains(msg.Subject, "verified")
+				})).Return(nil)
+			},
+		},
+		{
+			name: "success - rejection email carries the reason, even if sending fails",
+			arg:  model.ReviewHostVerificationParams{ID: testVerificationID, ReviewerID: testAdminID, RejectionReason: reason},
+			setupMock: func(m *serviceMocks) {
+				m.hostVerifies.On("FindHostVerificationByID", mock.Anything, testVerificationID).Return(pending, nil)
+				m.hostVerifies.On("ReviewHostVerification", mock.Anything, mock.Anything).
+					Return(&model.HostVerification{ID: testVerificationID, UserID: testHostID, Status: model.HostVerificationStatusRejected, RejectionReason: &reason}, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testHostID).
+					Return(createTestUser(testHostID, "host@example.com", "password123"), nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return strings.Contains(msg.Body, reason)
+				})).Return(errors.New("smtp unavailable"))
+			},
+		},
+		{
+			name: "error - admin reviews their own submission",
+			arg:  model.ReviewHostVerificationParams{ID: testVerificationID, ReviewerID: testHostID, Approve: true},
+			setupMock: func(m *serviceMocks) {
+				m.hostVerifies.On("FindHostVerificationByID", mock.Anything, testVerificationID).Return(pending, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrCannotReviewOwnHostVerification,
+		},
+		{
+			name: "error - another admin reviewed it first",
+			arg:  model.ReviewHostVerificationParams{ID: testVerificationID, ReviewerID: testAdminID, Approve: true},
+			setupMock: func(m *serviceMocks) {
+				m.hostVerifies.On("FindHostVerificationByID", mock.Anything, testVerificationID).Return(pending, nil)
+				m.hostVerifies.On("ReviewHostVerification", mock.Anything, mock.Anything).
+					Return(nil, model.ErrHostVerificationAlreadyReviewed)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrHostVerificationAlreadyReviewed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			verification, err := service.ReviewHostVerification(context.Background(), tc.arg)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, verification)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestOpenHostDocument(t *testing.T) {
+	const testVerificationID = "verification-1"
+	const frontKey = "host-verifications/host-1/verification-1/front.pdf"
+
+	passport := &model.HostVerification{
+		ID:           testVerificationID,
+		UserID:       "host-1",
+		DocumentType: model.HostDocumentTypePassport,
+		FrontKey:     frontKey,
+		CreatedAt:    time.Now(),
+	}
+
+	t.Run("success - content type follows the stored file", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.hostVerifies.On("FindHostVerificationByID", mock.Anything, testVerificationID).Return(passport, nil)
+		m.documentStore.On("Open", mock.Anything, frontKey).Return(io.NopCloser(strings.NewReader(testPDF)), nil)
+
+		file, contentType, err := service.OpenHostDocument(context.Background(), testVerificationID, model.HostDocumentSideFront)
+		require.NoError(t, err)
+		defer file.Close()
+
+		assert.Equal(t, "application/pdf", contentType)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - passport has no back side", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.hostVerifies.On("FindHostVerificationByID", mock.Anything, testVerificationID).Return(passport, nil)
+
+		_, _, err := service.OpenHostDocument(context.Background(), testVerificationID, model.HostDocumentSideBack)
+		require.ErrorIs(t, err, model.ErrHostVerificationNotFound)
+		m.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,13 @@
+package service
+
+import "github.com/katatrina/airbnb-clone/pkg/token"
+
+// JWKS returns the public keys other services use to verify our access tokens.
+// With a symmetric (HS256) maker there is nothing to publish, so the set is empty.
+func (s *UserService) JWKS() token.JWKSet {
+	if provider, ok := s.tokenMaker.(token.JWKSProvider); ok {
+		return provider.JWKS()
+	}
+
+	return token.JWKSet{Keys: []token.JWK{}}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// userAgentVersionPattern matches version numbers in a User-Agent string.
+var userAgentVersionPattern = regexp.MustCompile(`[0-9][0-9._]*`)
+
+// ListLoginEvents returns a page of the user's login history, newest first.
+func (s *UserService) ListLoginEvents(ctx context.Context, userID string, limit, offset int) ([]model.LoginEvent, int64, error) {
+	events, err := s.loginEventRepo.ListLoginEvents(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := s.loginEventRepo.CountLoginEvents(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return events, total, nil
+}
+
+// recordLoginFailure adds a failed attempt to the user's login history.
+// Like every audit write, it is best-effort and never fails the login itself.
+func (s *UserService) recordLoginFailure(ctx context.Context, userID, userAgent, clientIP, reason string) {
+	event, err := newLoginEvent(userID, userAgent, clientIP)
+	if err != nil {
+		log.Printf("[WARN] Failed to record login failure: %v", err)
+		return
+	}
+	event.Reason = &reason
+
+	if err = s.loginEventRepo.CreateLoginEvent(ctx, *event); err != nil {
+		log.Printf("[WARN] Failed to record login failure: %v", err)
+	}
+}
+
+// recordLoginSuccess adds a successful login to the user's history and alerts
+// the user when it comes from a device they never logged in from before.
+func (s *UserService) recordLoginSuccess(ctx context.Context, user *model.User, userAgent, clientIP string) {
+	event, err := newLoginEvent(user.ID, userAgent, clientIP)
+	if err != nil {
+		log.Printf("[WARN] Failed to record login: %v", err)
+		return
+	}
+	event.Success = true
+
+	known, err := s.loginEventRepo.HasSuccessfulLogin(ctx, user.ID, event.DeviceFingerprint)
+	if err != nil {
+		log.Printf("[WARN] Failed to look up known devices: %v", err)
+		return
+	}
+	if !known {
+		// The device an account is created on is not worth an alert
+		event.NewDevice, err = s.loginEventRepo.HasSuccessfulLogin(ctx, user.ID, "")
+		if err != nil {
+			log.Printf("[WARN] Failed to look up login history: %v", err)
+			return
+		}
+	}
+
+	if err = s.loginEventRepo.CreateLoginEvent(ctx, *event); err != nil {
+		log.Printf("[WARN] Failed to record login: %v", err)
+		return
+	}
+
+	if event.NewDevice {
+		if err = s.sendNewDeviceAlert(ctx, user, event); err != nil {
+			log.Printf("[WARN] Failed to send new device alert: %v", err)
+		}
+	}
+}
+
+// sendNewDeviceAlert tells the user about a login from an unknown device, so
+// they can react if it was not them.
+func (s *UserService) sendNewDeviceAlert(ctx context.Context, user *model.User, event *model.LoginEvent) error {
+	link := fmt.Sprintf("%s/account/security", s.cfg.AppBaseURL)
+
+	return s.emailSender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "New login to your account",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nYour account was just accessed from a new device:\n\nTime: %s\nIP address: %s\nDevice: %s\n\nIf this was you, there is nothing to do. If not, change your password and log out all other sessions right away:\n\n%s\n",
+			user.DisplayName, event.CreatedAt.UTC().Format(time.RFC1123), event.ClientIP, event.UserAgent, link,
+		),
+	})
+}
+
+func newLoginEvent(userID, userAgent, clientIP string) (*model.LoginEvent, error) {
+	eventID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating login event ID: %w", err)
+	}
+
+	return &model.LoginEvent{
+		ID:                eventID.String(),
+		UserID:            userID,
+		ClientIP:          clientIP,
+		UserAgent:         userAgent,
+		DeviceFingerprint: deviceFingerprint(userAgent),
+		CreatedAt:         time.Now(),
+	}, nil
+}
+
+// deviceFingerprint identifies a device by its User-Agent with the version
+// numbers removed, so browser and OS updates keep the same fingerprint.
+// The IP address is left out on purpose: it changes with every network.
+func deviceFingerprint(userAgent string) string {
+	sum := sha256.Sum256([]byte(userAgentVersionPattern.ReplaceAllString(userAgent, "")))
+	return hex.EncodeToString(sum[:])
+}
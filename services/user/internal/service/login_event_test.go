@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordLoginSuccess(t *testing.T) {
+	const testUserID = "user-123"
+	const testUserAgent = "Mozilla/5.0 (Macintosh; Intel Mac OS X 14_5) AppleWebKit/605.1.15 Version/17.5 Safari/605.1.15"
+	const testIP = "203.0.113.7"
+
+	testUser := &model.User{ID: testUserID, DisplayName: "Test User", Email: "user@example.com"}
+	fingerprint := deviceFingerprint(testUserAgent)
+
+	testCases := []struct {
+		name      string
+		setupMock func(*serviceMocks)
+	}{
+		{
+			name: "success - known device is recorded without an alert",
+			setupMock: func(m *serviceMocks) {
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, fingerprint).Return(true, nil)
+				m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.MatchedBy(func(e model.LoginEvent) bool {
+					return e.Success && !e.NewDevice && e.ClientIP == testIP && e.UserAgent == testUserAgent &&
+						e.DeviceFingerprint == fingerprint && e.Reason == nil
+				})).Return(nil)
+			},
+		},
+		{
+			name: "success - new device sends an alert",
+			setupMock: func(m *serviceMocks) {
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, fingerprint).Return(false, nil)
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, "").Return(true, nil)
+				m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.MatchedBy(func(e model.LoginEvent) bool {
+					return e.Success && e.NewDevice
+				})).Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return msg.To == "user@example.com" &&
+						strings.Contains(msg.Body, testIP) &&
+						strings.Contains(msg.Body, testUserAgent) &&
+						strings.Contains(msg.Body, testAppBaseURL+"/account/security")
+				})).Return(nil)
+			},
+		},
+		{
+			name: "success - first login of the account sends no alert",
+			setupMock: func(m *serviceMocks) {
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, fingerprint).Return(false, nil)
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, "").Return(false, nil)
+				m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.MatchedBy(func(e model.LoginEvent) bool {
+					return e.Success && !e.NewDevice
+				})).Return(nil)
+			},
+		},
+		{
+			name: "success - alert failure is only logged",
+			setupMock: func(m *serviceMocks) {
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, fingerprint).Return(false, nil)
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, "").Return(true, nil)
+				m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.AnythingOfType("model.LoginEvent")).Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.AnythingOfType("email.Message")).
+					Return(errors.New("smtp unavailable"))
+			},
+		},
+		{
+			name: "success - no alert when the event could not be stored",
+			setupMock: func(m *serviceMocks) {
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, fingerprint).Return(false, nil)
+				m.loginEvents.On("HasSuccessfulLogin", mock.Anything, testUserID, "").Return(true, nil)
+				m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.AnythingOfType("model.LoginEvent")).
+					Return(errors.New("database error"))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			service.recordLoginSuccess(context.Background(), testUser, testUserAgent, testIP)
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListLoginEvents(t *testing.T) {
+	const testUserID = "user-123"
+
+	t.Run("success - returns page and total", func(t *testing.T) {
+		m, service := newMocksAndService()
+		events := []model.LoginEvent{{ID: "event-2", UserID: testUserID}, {ID: "event-1", UserID: testUserID}}
+		m.loginEvents.On("ListLoginEvents", mock.Anything, testUserID, 10, 20).Return(events, nil)
+		m.loginEvents.On("CountLoginEvents", mock.Anything, testUserID).Return(int64(22), nil)
+
+		result, total, err := service.ListLoginEvents(context.Background(), testUserID, 10, 20)
+
+		require.NoError(t, err)
+		assert.Equal(t, events, result)
+		assert.Equal(t, int64(22), total)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - repository failure", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.loginEvents.On("ListLoginEvents", mock.Anything, testUserID, 10, 0).
+			Return(nil, errors.New("database error"))
+
+		_, _, err := service.ListLoginEvents(context.Background(), testUserID, 10, 0)
+
+		require.Error(t, err)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestDeviceFingerprint(t *testing.T) {
+	chrome120 := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.109 Safari/537.36"
+	chrome126 := "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/126.0.6478.127 Safari/537.36"
+	firefox := "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:127.0) Gecko/20100101 Firefox/127.0"
+
+	// Browser updates keep the device, another browser is another device
+	assert.Equal(t, deviceFingerprint(chrome120), deviceFingerprint(chrome126))
+	assert.NotEqual(t, deviceFingerprint(chrome120), deviceFingerprint(firefox))
+	assert.Len(t, deviceFingerprint(""), 64)
+}
@@ -20,10 +20,14 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"io"
 	"time"
 
 	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
 	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/sms"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -113,6 +117,753 @@ func (m *MockUserRepository) UpdateUserLastLogin(ctx context.Context, id string,
 	return args.Error(0)
 }
 
+// MarkEmailVerified giả lập việc đánh dấu email đã xác thực.
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+// MarkPhoneVerified giả lập việc đánh dấu số điện thoại đã xác thực.
+func (m *MockUserRepository) MarkPhoneVerified(ctx context.Context, id, phone string) error {
+	args := m.Called(ctx, id, phone)
+
+	return args.Error(0)
+}
+
+// UpdateUserPassword giả lập việc cập nhật password hash.
+func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, id, passwordHash string) error {
+	args := m.Called(ctx, id, passwordHash)
+
+	return args.Error(0)
+}
+
+// RehashUserPassword giả lập việc thay password hash bằng hash mới của cùng password.
+func (m *MockUserRepository) RehashUserPassword(ctx context.Context, id, currentHash, newHash string) error {
+	args := m.Called(ctx, id, currentHash, newHash)
+
+	return args.Error(0)
+}
+
+// UpdateUserProfile giả lập việc cập nhật các field profile được gửi lên.
+func (m *MockUserRepository) UpdateUserProfile(ctx context.Context, id string, arg model.UpdateUserProfileParams) (*model.User, error) {
+	args := m.Called(ctx, id, arg)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+// UpdateUserAvatar giả lập việc lưu (hoặc xoá, khi truyền nil) avatar của user.
+func (m *MockUserRepository) UpdateUserAvatar(ctx context.Context, id string, avatarKey, avatarURL *string) (*model.User, error) {
+	args := m.Called(ctx, id, avatarKey, avatarURL)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+// UpdateUserRoles giả lập việc admin thay đổi roles của user.
+func (m *MockUserRepository) UpdateUserRoles(ctx context.Context, id string, roles []string) (*model.User, error) {
+	args := m.Called(ctx, id, roles)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+// UpdateUserPreferences giả lập việc lưu toàn bộ preferences của user.
+func (m *MockUserRepository) UpdateUserPreferences(ctx context.Context, id string, prefs model.UserPreferences) (*model.User, error) {
+	args := m.Called(ctx, id, prefs)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+// SoftDeleteUser giả lập việc đánh dấu user đã bị xoá (deleted_at).
+func (m *MockUserRepository) SoftDeleteUser(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+// AnonymizeDeletedUsers giả lập việc xoá thông tin cá nhân của các tài khoản đã xoá quá hạn.
+// Trả về các avatar key cần xoá file.
+func (m *MockUserRepository) AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]string, error) {
+	args := m.Called(ctx, deletedBefore)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockSessionRepository là bản giả của SessionRepository.
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+// CreateSession giả lập việc lưu refresh token mới.
+func (m *MockSessionRepository) CreateSession(ctx context.Context, session model.Session) (*model.Session, error) {
+	args := m.Called(ctx, session)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+// FindSessionByTokenHash giả lập việc tìm session theo hash của refresh token.
+func (m *MockSessionRepository) FindSessionByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error) {
+	args := m.Called(ctx, tokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+// RotateSession giả lập việc đổi refresh token cũ lấy token mới.
+func (m *MockSessionRepository) RotateSession(ctx context.Context, currentID string, next model.Session) (*model.Session, error) {
+	args := m.Called(ctx, currentID, next)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.Session), args.Error(1)
+}
+
+// ListActiveSessions giả lập việc liệt kê các session còn hiệu lực.
+func (m *MockSessionRepository) ListActiveSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.Session), args.Error(1)
+}
+
+// RevokeSessionFamily giả lập việc thu hồi toàn bộ family.
+func (m *MockSessionRepository) RevokeSessionFamily(ctx context.Context, familyID string) error {
+	args := m.Called(ctx, familyID)
+
+	return args.Error(0)
+}
+
+// RevokeUserSession giả lập việc thu hồi một session của user.
+func (m *MockSessionRepository) RevokeUserSession(ctx context.Context, userID, familyID string) error {
+	args := m.Called(ctx, userID, familyID)
+
+	return args.Error(0)
+}
+
+// RevokeUserSessions giả lập việc thu hồi mọi session của user.
+func (m *MockSessionRepository) RevokeUserSessions(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+
+	return args.Error(0)
+}
+
+// RevokeOtherUserSessions giả lập việc thu hồi các session khác của user.
+func (m *MockSessionRepository) RevokeOtherUserSessions(ctx context.Context, userID, currentFamilyID string) ([]string, error) {
+	args := m.Called(ctx, userID, currentFamilyID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockUserTokenRepository là bản giả của UserTokenRepository.
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+// CreateUserToken giả lập việc lưu token một lần dùng (chỉ lưu hash).
+func (m *MockUserTokenRepository) CreateUserToken(ctx context.Context, userToken model.UserToken) error {
+	args := m.Called(ctx, userToken)
+
+	return args.Error(0)
+}
+
+// ConsumeUserToken giả lập việc dùng token: chỉ thành công một lần và khi chưa hết hạn.
+func (m *MockUserTokenRepository) ConsumeUserToken(ctx context.Context, purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+// InvalidateUserTokens giả lập việc vô hiệu hoá các token cũ của user.
+func (m *MockUserTokenRepository) InvalidateUserTokens(ctx context.Context, userID string, purpose model.TokenPurpose) error {
+	args := m.Called(ctx, userID, purpose)
+
+	return args.Error(0)
+}
+
+// FindUserToken giả lập việc tìm token còn hiệu lực mà không dùng nó.
+func (m *MockUserTokenRepository) FindUserToken(ctx context.Context, purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+// FindOutstandingUserToken giả lập việc tìm token còn hiệu lực của user theo mục đích.
+func (m *MockUserTokenRepository) FindOutstandingUserToken(ctx context.Context, userID string, purpose model.TokenPurpose) (*model.UserToken, error) {
+	args := m.Called(ctx, userID, purpose)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+// ListUserTokenIssueTimes giả lập việc liệt kê thời điểm phát hành các token gần đây.
+func (m *MockUserTokenRepository) ListUserTokenIssueTimes(ctx context.Context, userID string, purpose model.TokenPurpose, since time.Time) ([]time.Time, error) {
+	args := m.Called(ctx, userID, purpose, since)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]time.Time), args.Error(1)
+}
+
+// ClaimUserTokenAttempt giả lập việc giành một lượt thử trên token trước khi kiểm tra.
+func (m *MockUserTokenRepository) ClaimUserTokenAttempt(ctx context.Context, id string, maxAttempts int) (*model.UserToken, error) {
+	args := m.Called(ctx, id, maxAttempts)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserToken), args.Error(1)
+}
+
+// MockTwoFactorRepository là bản giả của TwoFactorRepository.
+type MockTwoFactorRepository struct {
+	mock.Mock
+}
+
+// FindTOTP giả lập việc lấy authenticator của user (đã xác nhận hoặc chưa).
+func (m *MockTwoFactorRepository) FindTOTP(ctx context.Context, userID string) (*model.UserTOTP, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserTOTP), args.Error(1)
+}
+
+// SaveTOTPSecret giả lập việc lưu secret mới khi bắt đầu cài đặt 2FA.
+func (m *MockTwoFactorRepository) SaveTOTPSecret(ctx context.Context, userID, secret string) error {
+	args := m.Called(ctx, userID, secret)
+
+	return args.Error(0)
+}
+
+// EnableTOTP giả lập việc xác nhận authenticator và lưu recovery code.
+func (m *MockTwoFactorRepository) EnableTOTP(ctx context.Context, userID string, usedStep int64, codes []model.RecoveryCode) error {
+	args := m.Called(ctx, userID, usedStep, codes)
+
+	return args.Error(0)
+}
+
+// ReplaceRecoveryCodes giả lập việc thay toàn bộ recovery code.
+func (m *MockTwoFactorRepository) ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode) error {
+	args := m.Called(ctx, userID, codes)
+
+	return args.Error(0)
+}
+
+// MarkTOTPStepUsed giả lập việc đánh dấu time step đã dùng (chống dùng lại mã).
+func (m *MockTwoFactorRepository) MarkTOTPStepUsed(ctx context.Context, userID string, step int64) error {
+	args := m.Called(ctx, userID, step)
+
+	return args.Error(0)
+}
+
+// ConsumeRecoveryCode giả lập việc dùng một recovery code (chỉ dùng được một lần).
+func (m *MockTwoFactorRepository) ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) error {
+	args := m.Called(ctx, userID, codeHash)
+
+	return args.Error(0)
+}
+
+// CountUnusedRecoveryCodes giả lập việc đếm số recovery code còn lại.
+func (m *MockTwoFactorRepository) CountUnusedRecoveryCodes(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// DeleteTOTP giả lập việc tắt 2FA.
+func (m *MockTwoFactorRepository) DeleteTOTP(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+
+	return args.Error(0)
+}
+
+// MockIdentityRepository là bản giả của IdentityRepository.
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+// CreateAuthRequest giả lập việc lưu state/nonce/PKCE verifier của một lần đăng nhập OIDC.
+func (m *MockIdentityRepository) CreateAuthRequest(ctx context.Context, authRequest model.OIDCAuthRequest) error {
+	args := m.Called(ctx, authRequest)
+
+	return args.Error(0)
+}
+
+// ConsumeAuthRequest giả lập việc lấy và xoá auth request theo hash của state.
+func (m *MockIdentityRepository) ConsumeAuthRequest(ctx context.Context, stateHash string) (*model.OIDCAuthRequest, error) {
+	args := m.Called(ctx, stateHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.OIDCAuthRequest), args.Error(1)
+}
+
+// FindIdentity giả lập việc tìm liên kết giữa tài khoản provider và user.
+func (m *MockIdentityRepository) FindIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error) {
+	args := m.Called(ctx, provider, subject)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UserIdentity), args.Error(1)
+}
+
+// CreateUserWithIdentity giả lập việc tạo user mới (không có mật khẩu) cùng liên kết provider.
+func (m *MockIdentityRepository) CreateUserWithIdentity(ctx context.Context, user model.User, identity model.UserIdentity) (*model.User, error) {
+	args := m.Called(ctx, user, identity)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+// LinkIdentity giả lập việc liên kết provider với user đã có.
+func (m *MockIdentityRepository) LinkIdentity(ctx context.Context, identity model.UserIdentity, claimUnverified bool) error {
+	args := m.Called(ctx, identity, claimUnverified)
+
+	return args.Error(0)
+}
+
+// UpdateIdentityLastLogin giả lập việc cập nhật thời điểm đăng nhập qua provider.
+func (m *MockIdentityRepository) UpdateIdentityLastLogin(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+
+	return args.Error(0)
+}
+
+// MockLoginEventRepository là bản giả của LoginEventRepository.
+type MockLoginEventRepository struct {
+	mock.Mock
+}
+
+// CreateLoginEvent giả lập việc ghi một lần đăng nhập (thành công hoặc thất bại) vào lịch sử.
+func (m *MockLoginEventRepository) CreateLoginEvent(ctx context.Context, event model.LoginEvent) error {
+	args := m.Called(ctx, event)
+
+	return args.Error(0)
+}
+
+// HasSuccessfulLogin giả lập việc kiểm tra user đã từng đăng nhập từ thiết bị này chưa.
+// Fingerprint rỗng nghĩa là bất kỳ thiết bị nào.
+func (m *MockLoginEventRepository) HasSuccessfulLogin(ctx context.Context, userID, deviceFingerprint string) (bool, error) {
+	args := m.Called(ctx, userID, deviceFingerprint)
+
+	return args.Bool(0), args.Error(1)
+}
+
+// ListLoginEvents giả lập việc lấy lịch sử đăng nhập có phân trang.
+func (m *MockLoginEventRepository) ListLoginEvents(ctx context.Context, userID string, limit, offset int) ([]model.LoginEvent, error) {
+	args := m.Called(ctx, userID, limit, offset)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.LoginEvent), args.Error(1)
+}
+
+// CountLoginEvents giả lập việc đếm tổng số lần đăng nhập của user.
+func (m *MockLoginEventRepository) CountLoginEvents(ctx context.Context, userID string) (int64, error) {
+	args := m.Called(ctx, userID)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// MockEmailChangeRepository là bản giả của EmailChangeRepository.
+type MockEmailChangeRepository struct {
+	mock.Mock
+}
+
+// CreateEmailChange giả lập việc lưu yêu cầu đổi email (huỷ yêu cầu cũ đang chờ nếu có).
+func (m *MockEmailChangeRepository) CreateEmailChange(ctx context.Context, change model.EmailChange) error {
+	args := m.Called(ctx, change)
+
+	return args.Error(0)
+}
+
+// ConfirmEmailChange giả lập việc xác nhận đổi email bằng hash của token gửi tới email mới.
+func (m *MockEmailChangeRepository) ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (*model.EmailChange, error) {
+	args := m.Called(ctx, confirmTokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.EmailChange), args.Error(1)
+}
+
+// CancelEmailChange giả lập việc huỷ đổi email bằng hash của token gửi tới email cũ.
+// Trả về yêu cầu như trước khi huỷ (để biết đã được xác nhận hay chưa).
+func (m *MockEmailChangeRepository) CancelEmailChange(ctx context.Context, cancelTokenHash string) (*model.EmailChange, error) {
+	args := m.Called(ctx, cancelTokenHash)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.EmailChange), args.Error(1)
+}
+
+// MockDataExportRepository là bản giả của DataExportRepository.
+type MockDataExportRepository struct {
+	mock.Mock
+}
+
+// CreateDataExport giả lập việc tạo một yêu cầu xuất dữ liệu mới (trạng thái pending).
+func (m *MockDataExportRepository) CreateDataExport(ctx context.Context, export model.DataExport) (*model.DataExport, error) {
+	args := m.Called(ctx, export)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.DataExport), args.Error(1)
+}
+
+// FindLatestDataExport giả lập việc lấy yêu cầu xuất dữ liệu gần nhất của user.
+func (m *MockDataExportRepository) FindLatestDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.DataExport), args.Error(1)
+}
+
+// FindDownloadableDataExport giả lập việc lấy bản xuất đã hoàn tất và chưa hết hạn.
+func (m *MockDataExportRepository) FindDownloadableDataExport(ctx context.Context, userID string) (*model.DataExport, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.DataExport), args.Error(1)
+}
+
+// ClaimDataExport giả lập việc worker nhận một job đang chờ để xử lý.
+func (m *MockDataExportRepository) ClaimDataExport(ctx context.Context, staleBefore time.Time) (*model.DataExport, error) {
+	args := m.Called(ctx, staleBefore)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.DataExport), args.Error(1)
+}
+
+// CompleteDataExport giả lập việc đánh dấu job hoàn tất kèm file ZIP.
+func (m *MockDataExportRepository) CompleteDataExport(ctx context.Context, id, fileKey string, fileSize int64, expiresAt time.Time) error {
+	args := m.Called(ctx, id, fileKey, fileSize, expiresAt)
+
+	return args.Error(0)
+}
+
+// FailDataExport giả lập việc đánh dấu job thất bại.
+func (m *MockDataExportRepository) FailDataExport(ctx context.Context, id, reason string) error {
+	args := m.Called(ctx, id, reason)
+
+	return args.Error(0)
+}
+
+// ExpireDataExports giả lập việc đánh dấu các bản xuất đã hết hạn và trả về key file cần xoá.
+func (m *MockDataExportRepository) ExpireDataExports(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockEmailSender giả lập việc gửi email.
+// Test có thể kiểm tra nội dung email (ví dụ: link chứa token) qua mock.MatchedBy.
+type MockEmailSender struct {
+	mock.Mock
+}
+
+// Send giả lập việc gửi một email.
+func (m *MockEmailSender) Send(ctx context.Context, msg email.Message) error {
+	args := m.Called(ctx, msg)
+
+	return args.Error(0)
+}
+
+// MockSMSSender là bản giả của SMSSender.
+// Nó không gửi tin nhắn thật, chỉ ghi nhận tin nhắn được gửi.
+type MockSMSSender struct {
+	mock.Mock
+}
+
+// Send giả lập việc gửi một tin nhắn SMS.
+func (m *MockSMSSender) Send(ctx context.Context, msg sms.Message) error {
+	args := m.Called(ctx, msg)
+
+	return args.Error(0)
+}
+
+// MockBlobStore giả lập nơi lưu file (avatar...).
+// Nó không ghi file thật, chỉ ghi nhận key được Put/Delete.
+type MockBlobStore struct {
+	mock.Mock
+}
+
+// Put giả lập việc lưu file với key cho trước.
+func (m *MockBlobStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	args := m.Called(ctx, key, r, contentType)
+
+	return args.Error(0)
+}
+
+// Delete giả lập việc xoá file.
+func (m *MockBlobStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+
+	return args.Error(0)
+}
+
+// URL giả lập việc tạo URL public cho file.
+func (m *MockBlobStore) URL(key string) string {
+	args := m.Called(key)
+
+	return args.String(0)
+}
+
+// MockPrivateStore giả lập nơi lưu file không public (file ZIP xuất dữ liệu, giấy tờ tuỳ thân).
+type MockPrivateStore struct {
+	mock.Mock
+}
+
+// Put giả lập việc lưu file với key cho trước.
+func (m *MockPrivateStore) Put(ctx context.Context, key string, r io.Reader, contentType string) error {
+	args := m.Called(ctx, key, r, contentType)
+
+	return args.Error(0)
+}
+
+// Open giả lập việc mở file để đọc lại.
+func (m *MockPrivateStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	args := m.Called(ctx, key)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(io.ReadCloser), args.Error(1)
+}
+
+// Delete giả lập việc xoá file.
+func (m *MockPrivateStore) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+
+	return args.Error(0)
+}
+
+// MockHostVerificationRepository là bản giả của HostVerificationRepository.
+type MockHostVerificationRepository struct {
+	mock.Mock
+}
+
+// CreateHostVerification giả lập việc lưu hồ sơ xác minh mới và chuyển user sang trạng thái chờ duyệt.
+func (m *MockHostVerificationRepository) CreateHostVerification(ctx context.Context, v model.HostVerification) (*model.HostVerification, error) {
+	args := m.Called(ctx, v)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.HostVerification), args.Error(1)
+}
+
+// FindHostVerificationByID giả lập việc tìm hồ sơ xác minh theo ID.
+func (m *MockHostVerificationRepository) FindHostVerificationByID(ctx context.Context, id string) (*model.HostVerification, error) {
+	args := m.Called(ctx, id)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.HostVerification), args.Error(1)
+}
+
+// FindLatestHostVerification giả lập việc lấy hồ sơ xác minh gần nhất của user.
+func (m *MockHostVerificationRepository) FindLatestHostVerification(ctx context.Context, userID string) (*model.HostVerification, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.HostVerification), args.Error(1)
+}
+
+// ListHostVerificationsByUser giả lập việc lấy toàn bộ hồ sơ xác minh của user.
+func (m *MockHostVerificationRepository) ListHostVerificationsByUser(ctx context.Context, userID string) ([]model.HostVerification, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.HostVerification), args.Error(1)
+}
+
+// ListHostVerificationsByStatus giả lập việc lấy hàng đợi hồ sơ theo trạng thái.
+func (m *MockHostVerificationRepository) ListHostVerificationsByStatus(ctx context.Context, status model.HostVerificationStatus, limit, offset int) ([]model.HostVerification, error) {
+	args := m.Called(ctx, status, limit, offset)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]model.HostVerification), args.Error(1)
+}
+
+// CountHostVerificationsByStatus giả lập việc đếm số hồ sơ theo trạng thái.
+func (m *MockHostVerificationRepository) CountHostVerificationsByStatus(ctx context.Context, status model.HostVerificationStatus) (int64, error) {
+	args := m.Called(ctx, status)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// ReviewHostVerification giả lập việc admin duyệt hoặc từ chối hồ sơ đang chờ.
+func (m *MockHostVerificationRepository) ReviewHostVerification(ctx context.Context, arg model.ReviewHostVerificationParams) (*model.HostVerification, error) {
+	args := m.Called(ctx, arg)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.HostVerification), args.Error(1)
+}
+
+// DeleteAnonymizedHostVerifications giả lập việc xoá hồ sơ của các tài khoản đã ẩn danh.
+// Trả về các key giấy tờ cần xoá file.
+func (m *MockHostVerificationRepository) DeleteAnonymizedHostVerifications(ctx context.Context) ([]string, error) {
+	args := m.Called(ctx)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// MockListingClient giả lập HTTP client gọi sang Listing Service.
+type MockListingClient struct {
+	mock.Mock
+}
+
+// CountActiveListingsByHost giả lập việc đếm số listing đang active của host.
+func (m *MockListingClient) CountActiveListingsByHost(ctx context.Context, hostID string) (int64, error) {
+	args := m.Called(ctx, hostID)
+
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// DeactivateHostListings giả lập việc yêu cầu Listing Service ẩn toàn bộ listing của host.
+func (m *MockListingClient) DeactivateHostListings(ctx context.Context, hostID string) error {
+	args := m.Called(ctx, hostID)
+
+	return args.Error(0)
+}
+
+// ExportHostListings giả lập việc lấy toàn bộ listing của host (JSON thô) để xuất dữ liệu.
+func (m *MockListingClient) ExportHostListings(ctx context.Context, hostID string) (json.RawMessage, error) {
+	args := m.Called(ctx, hostID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(json.RawMessage), args.Error(1)
+}
+
+// MockBookingClient giả lập HTTP client gọi sang Booking Service.
+type MockBookingClient struct {
+	mock.Mock
+}
+
+// CountUpcomingBookings giả lập việc đếm các booking đã xác nhận sắp tới của user.
+func (m *MockBookingClient) CountUpcomingBookings(ctx context.Context, userID string) (*model.UpcomingBookingCounts, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(*model.UpcomingBookingCounts), args.Error(1)
+}
+
+// ExportUserBookings giả lập việc lấy toàn bộ booking của user (khách và chủ nhà) để xuất dữ liệu.
+func (m *MockBookingClient) ExportUserBookings(ctx context.Context, userID string) (json.RawMessage, error) {
+	args := m.Called(ctx, userID)
+
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+
+	return args.Get(0).(json.RawMessage), args.Error(1)
+}
+
 // MockTokenMaker giả lập pkg/token.TokenMaker interface.
 // Thay vì tạo JWT thật (cần secret key, expiry config...),
 // ta có thể bảo nó trả về bất kì token string nào ta muốn.
@@ -121,8 +872,8 @@ type MockTokenMaker struct {
 }
 
 // CreateToken giả lập việc tạo JWT token.
-func (m *MockTokenMaker) CreateToken(userID string) (string, time.Time, error) {
-	args := m.Called(userID)
+func (m *MockTokenMaker) CreateToken(arg token.CreateTokenParams) (string, time.Time, error) {
+	args := m.Called(arg)
 
 	return args.String(0), args.Get(1).(time.Time), args.Error(2)
 }
@@ -0,0 +1,190 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/oidc"
+)
+
+const (
+	// oidcAuthRequestExpiry is how long the user has to log in at the provider.
+	oidcAuthRequestExpiry = 10 * time.Minute
+
+	maxDisplayNameLength = 100
+)
+
+// StartOIDCLogin prepares a login with an identity provider and returns the
+// URL to send the user to.
+//
+// state ties the callback to this login, nonce ties the ID token to it, and
+// the PKCE verifier makes a stolen authorization code useless. All three stay
+// on our side until the callback comes back.
+func (s *UserService) StartOIDCLogin(ctx context.Context, providerName string) (string, error) {
+	provider, ok := s.oidcProviders[providerName]
+	if !ok {
+		return "", model.ErrIdentityProviderNotFound
+	}
+
+	state, stateHash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	nonce, _, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	codeVerifier, _, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, codeVerifier)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", model.ErrIdentityProviderUnavailable, err)
+	}
+
+	now := time.Now()
+	err = s.identityRepo.CreateAuthRequest(ctx, model.OIDCAuthRequest{
+		StateHash:    stateHash,
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: codeVerifier,
+		ExpiresAt:    now.Add(oidcAuthRequestExpiry),
+		CreatedAt:    now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return authURL, nil
+}
+
+// CompleteOIDCLogin finishes a login the provider redirected back to us.
+//
+// The provider account is matched to a user in this order: an existing link,
+// then an account with the same email if the provider verified it, and
+// otherwise a new account without a password. Users with 2FA still get a
+// challenge, exactly like after a password login.
+func (s *UserService) CompleteOIDCLogin(ctx context.Context, arg model.CompleteOIDCLoginParams) (*model.LoginUserResult, error) {
+	provider, ok := s.oidcProviders[arg.Provider]
+	if !ok {
+		return nil, model.ErrIdentityProviderNotFound
+	}
+
+	authRequest, err := s.identityRepo.ConsumeAuthRequest(ctx, hashOpaqueToken(arg.State))
+	if err != nil {
+		return nil, err
+	}
+	if authRequest.Provider != arg.Provider {
+		return nil, model.ErrOIDCStateInvalid
+	}
+
+	identity, err := provider.Exchange(ctx, arg.Code, authRequest.CodeVerifier, authRequest.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", model.ErrOIDCAuthenticationFailed, err)
+	}
+
+	user, err := s.findOrCreateOIDCUser(ctx, arg.Provider, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.continueLogin(ctx, user, arg.UserAgent, arg.ClientIP)
+}
+
+func (s *UserService) findOrCreateOIDCUser(ctx context.Context, providerName string, identity *oidc.Identity) (*model.User, error) {
+	linked, err := s.identityRepo.FindIdentity(ctx, providerName, identity.Subject)
+	if err == nil {
+		if err = s.identityRepo.UpdateIdentityLastLogin(ctx, linked.ID); err != nil {
+			log.Printf("[WARN] Failed to update last login for identity: %v", err)
+		}
+		return s.userRepo.FindUserByID(ctx, linked.UserID)
+	}
+	if !errors.Is(err, model.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	// Linking by email is only safe when the provider vouches for the address,
+	// otherwise anyone could take over an account by typing its email there
+	if identity.Email == "" || !identity.EmailVerified {
+		return nil, model.ErrOIDCEmailNotVerified
+	}
+
+	identityID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating identity ID: %w", err)
+	}
+	now := time.Now()
+	newIdentity := model.UserIdentity{
+		ID:          identityID.String(),
+		Provider:    providerName,
+		Subject:     identity.Subject,
+		Email:       identity.Email,
+		LastLoginAt: &now,
+		CreatedAt:   now,
+	}
+
+	user, err := s.userRepo.FindUserByEmail(ctx, identity.Email)
+	if err != nil && !errors.Is(err, model.ErrUserNotFound) {
+		return nil, err
+	}
+
+	if user == nil {
+		userID, err := uuid.NewV7()
+		if err != nil {
+			return nil, fmt.Errorf("unexpected error occur when generating user ID: %w", err)
+		}
+		newIdentity.UserID = userID.String()
+
+		return s.identityRepo.CreateUserWithIdentity(ctx, model.User{
+			ID:            userID.String(),
+			DisplayName:   oidcDisplayName(identity),
+			Email:         identity.Email,
+			EmailVerified: true,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}, newIdentity)
+	}
+
+	newIdentity.UserID = user.ID
+
+	// An unverified account may have been registered by someone who does not
+	// own the email, waiting for the real owner to show up. The owner takes it
+	// over: the registrant's password and sessions are dropped.
+	claimUnverified := !user.EmailVerified
+	if err = s.identityRepo.LinkIdentity(ctx, newIdentity, claimUnverified); err != nil {
+		return nil, err
+	}
+	if !claimUnverified {
+		return user, nil
+	}
+
+	if err = s.LogoutAll(ctx, user.ID); err != nil {
+		return nil, err
+	}
+
+	return s.userRepo.FindUserByID(ctx, user.ID)
+}
+
+// oidcDisplayName picks a display name for a new account: the provider's
+// name claim, or the local part of the email when there is none.
+func oidcDisplayName(identity *oidc.Identity) string {
+	name := strings.Join(strings.Fields(identity.Name), " ")
+	if utf8.RuneCountInString(name) < 2 {
+		name, _, _ = strings.Cut(identity.Email, "@")
+	}
+
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		name = string([]rune(name)[:maxDisplayNameLength])
+	}
+
+	return name
+}
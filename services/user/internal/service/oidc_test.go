@@ -0,0 +1,492 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/oidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testOIDCProvider = "test"
+	testOIDCClientID = "airbnb-clone"
+	testOIDCSubject  = "provider-user-1"
+)
+
+// fakeIssuer is a local OpenID provider: discovery, JWKS and a token endpoint
+// that checks PKCE and signs ID tokens with an Ed25519 key.
+type fakeIssuer struct {
+	server     *httptest.Server
+	privateKey ed25519.PrivateKey
+	keyID      string
+
+	mu sync.Mutex
+	// logins maps issued authorization codes to the login they belong to.
+	logins map[string]fakeLogin
+	// claims are put into every ID token, on top of the required ones.
+	claims jwt.MapClaims
+	// nonceOverride replaces the nonce of the login in ID tokens when set.
+	nonceOverride string
+}
+
+type fakeLogin struct {
+	codeChallenge string
+	nonce         string
+}
+
+func newFakeIssuer(t *testing.T) *fakeIssuer {
+	t.Helper()
+
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	issuer := &fakeIssuer{
+		privateKey: privateKey,
+		keyID:      "test-key",
+		logins:     make(map[string]fakeLogin),
+		claims: jwt.MapClaims{
+			"email":          "guest@example.com",
+			"email_verified": true,
+			"name":           "Guest User",
+		},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer.server.URL,
+			"authorization_endpoint": issuer.server.URL + "/authorize",
+			"token_endpoint":         issuer.server.URL + "/token",
+			"jwks_uri":               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(token.JWKSet{Keys: []token.JWK{{
+			KeyType:   "OKP",
+			KeyID:     issuer.keyID,
+			Use:       "sig",
+			Algorithm: jwt.SigningMethodEdDSA.Alg(),
+			Curve:     "Ed25519",
+			X:         base64.RawURLEncoding.EncodeToString(publicKey),
+		}}})
+	})
+	mux.HandleFunc("POST /token", issuer.handleToken)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (f *fakeIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	login, ok := f.logins[r.PostFormValue("code")]
+	delete(f.logins, r.PostFormValue("code"))
+	if !ok || oidc.CodeChallenge(r.PostFormValue("code_verifier")) != login.codeChallenge {
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := login.nonce
+	if f.nonceOverride != "" {
+		nonce = f.nonceOverride
+	}
+
+	claims := jwt.MapClaims{
+		"iss":   f.server.URL,
+		"aud":   testOIDCClientID,
+		"sub":   testOIDCSubject,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": nonce,
+	}
+	for k, v := range f.claims {
+		claims[k] = v
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	idToken.Header["kid"] = f.keyID
+	signed, err := idToken.SignedString(f.privateKey)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "token_type": "Bearer"})
+}
+
+// authorize plays the user logging in at the provider: it accepts the
+// authorization URL we redirected to and returns the code of the callback.
+func (f *fakeIssuer) authorize(t *testing.T, authURL string) (state, code string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	query := u.Query()
+	require.Equal(t, "S256", query.Get("code_challenge_method"))
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	code = "code-" + query.Get("state")
+	f.logins[code] = fakeLogin{codeChallenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+
+	return query.Get("state"), code
+}
+
+func newOIDCTestService(t *testing.T) (*serviceMocks, *UserService, *fakeIssuer) {
+	t.Helper()
+
+	issuer := newFakeIssuer(t)
+	m, service := newMocksAndService()
+	service.oidcProviders[testOIDCProvider] = oidc.NewProvider(oidc.Config{
+		Name:        testOIDCProvider,
+		IssuerURL:   issuer.server.URL,
+		ClientID:    testOIDCClientID,
+		RedirectURL: testAppBaseURL + "/auth/callback/" + testOIDCProvider,
+	})
+
+	return m, service, issuer
+}
+
+// startOIDCLogin runs StartOIDCLogin and the provider side of the login,
+// returning the callback parameters. The stored auth request is handed back
+// by ConsumeAuthRequest, as the database would.
+func startOIDCLogin(t *testing.T, m *serviceMocks, service *UserService, issuer *fakeIssuer) (state, code string) {
+	t.Helper()
+
+	var authRequest model.OIDCAuthRequest
+	m.identityRepo.On("CreateAuthRequest", mock.Anything, mock.AnythingOfType("model.OIDCAuthRequest")).
+		Run(func(args mock.Arguments) { authRequest = args.Get(1).(model.OIDCAuthRequest) }).
+		Return(nil).Once()
+
+	authURL, err := service.StartOIDCLogin(context.Background(), testOIDCProvider)
+	require.NoError(t, err)
+
+	state, code = issuer.authorize(t, authURL)
+	m.identityRepo.On("ConsumeAuthRequest", mock.Anything, hashOpaqueToken(state)).
+		Return(&authRequest, nil).Once()
+
+	return state, code
+}
+
+func TestStartOIDCLogin(t *testing.T) {
+	t.Run("success - stores the login and returns the provider URL", func(t *testing.T) {
+		m, service, issuer := newOIDCTestService(t)
+		m.identityRepo.On("CreateAuthRequest", mock.Anything, mock.MatchedBy(func(r model.OIDCAuthRequest) bool {
+			return r.Provider == testOIDCProvider && r.StateHash != "" && r.Nonce != "" && r.CodeVerifier != "" &&
+				r.ExpiresAt.After(time.Now())
+		})).Return(nil)
+
+		authURL, err := service.StartOIDCLogin(context.Background(), testOIDCProvider)
+
+		require.NoError(t, err)
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Equal(t, issuer.server.URL+"/authorize", u.Scheme+"://"+u.Host+u.Path)
+		assert.Equal(t, testOIDCClientID, u.Query().Get("client_id"))
+		assert.Equal(t, "code", u.Query().Get("response_type"))
+		assert.Equal(t, "S256", u.Query().Get("code_challenge_method"))
+		assert.NotEmpty(t, u.Query().Get("state"))
+
+		// Only the hash of the state is stored, and the verifier never leaves
+		stored := m.identityRepo.Calls[0].Arguments.Get(1).(model.OIDCAuthRequest)
+		assert.Equal(t, hashOpaqueToken(u.Query().Get("state")), stored.StateHash)
+		assert.Equal(t, oidc.CodeChallenge(stored.CodeVerifier), u.Query().Get("code_challenge"))
+		assert.NotContains(t, authURL, stored.CodeVerifier)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - unknown provider", func(t *testing.T) {
+		m, service, _ := newOIDCTestService(t)
+
+		_, err := service.StartOIDCLogin(context.Background(), "unknown")
+
+		require.ErrorIs(t, err, model.ErrIdentityProviderNotFound)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - provider unreachable", func(t *testing.T) {
+		m, service, issuer := newOIDCTestService(t)
+		issuer.server.Close()
+
+		_, err := service.StartOIDCLogin(context.Background(), testOIDCProvider)
+
+		require.ErrorIs(t, err, model.ErrIdentityProviderUnavailable)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestCompleteOIDCLogin(t *testing.T) {
+	const testUserID = "user-123"
+	const testEmail = "guest@example.com"
+
+	testSession := &model.Session{
+		ID:        "session-1",
+		FamilyID:  "session-1",
+		UserID:    testUserID,
+		ExpiresAt: time.Now().Add(testRefreshTokenExpiry),
+	}
+	expectSession := func(m *serviceMocks) {
+		m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+			Return(nil, model.ErrTwoFactorNotEnabled)
+		m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+			Return(testSession, nil)
+		m.tokenMaker.On("CreateToken", token.CreateTokenParams{UserID: testUserID, SessionID: testSession.FamilyID, EmailVerified: true}).
+			Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
+		m.userRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).
+			Return(nil)
+		m.expectLoginFromKnownDevice(testUserID)
+	}
+
+	testCases := []struct {
+		name        string
+		setupIssuer func(*fakeIssuer)
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+		validate    func(t *testing.T, result *model.LoginUserResult)
+	}{
+		{
+			name: "success - linked identity logs in",
+			setupMock: func(m *serviceMocks) {
+				m.identityRepo.On("FindIdentity", mock.Anything, testOIDCProvider, testOIDCSubject).
+					Return(&model.UserIdentity{ID: "identity-1", UserID: testUserID}, nil)
+				m.identityRepo.On("UpdateIdentityLastLogin", mock.Anything, "identity-1").Return(nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, Email: testEmail, EmailVerified: true}, nil)
+				expectSession(m)
+			},
+			validate: func(t *testing.T, result *model.LoginUserResult) {
+				assert.Equal(t, "jwt-token-xyz", result.AccessToken)
+				assert.NotEmpty(t, result.RefreshToken)
+			},
+		},
+		{
+			name: "success - new email creates an account without password",
+			setupMock: func(m *serviceMocks) {
+				m.identityRepo.On("FindIdentity", mock.Anything, testOIDCProvider, testOIDCSubject).
+					Return(nil, model.ErrIdentityNotFound)
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+					Return(nil, model.ErrUserNotFound)
+				m.identityRepo.On("CreateUserWithIdentity", mock.Anything,
+					mock.MatchedBy(func(u model.User) bool {
+						return u.Email == testEmail && u.DisplayName == "Guest User" && u.EmailVerified && u.PasswordHash == ""
+					}),
+					mock.MatchedBy(func(i model.UserIdentity) bool {
+						return i.Provider == testOIDCProvider && i.Subject == testOIDCSubject && i.UserID != ""
+					}),
+				).Return(&model.User{ID: testUserID, Email: testEmail, EmailVerified: true}, nil)
+				expectSession(m)
+			},
+			validate: func(t *testing.T, result *model.LoginUserResult) {
+				assert.Equal(t, testEmail, result.User.Email)
+				assert.Equal(t, "jwt-token-xyz", result.AccessToken)
+			},
+		},
+		{
+			name: "success - verified email links to the existing account",
+			setupMock: func(m *serviceMocks) {
+				m.identityRepo.On("FindIdentity", mock.Anything, testOIDCProvider, testOIDCSubject).
+					Return(nil, model.ErrIdentityNotFound)
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+					Return(&model.User{ID: testUserID, Email: testEmail, EmailVerified: true, PasswordHash: "hash"}, nil)
+				m.identityRepo.On("LinkIdentity", mock.Anything,
+					mock.MatchedBy(func(i model.UserIdentity) bool { return i.UserID == testUserID }), false).
+					Return(nil)
+				expectSession(m)
+			},
+			validate: func(t *testing.T, result *model.LoginUserResult) {
+				assert.Equal(t, testUserID, result.User.ID)
+			},
+		},
+		{
+			name: "success - unverified account is claimed and its sessions revoked",
+			setupMock: func(m *serviceMocks) {
+				m.identityRepo.On("FindIdentity", mock.Anything, testOIDCProvider, testOIDCSubject).
+					Return(nil, model.ErrIdentityNotFound)
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+					Return(&model.User{ID: testUserID, Email: testEmail, PasswordHash: "attacker-hash"}, nil)
+				m.identityRepo.On("LinkIdentity", mock.Anything,
+					mock.MatchedBy(func(i model.UserIdentity) bool { return i.UserID == testUserID }), true).
+					Return(nil)
+				m.sessionRepo.On("RevokeUserSessions", mock.Anything, testUserID).Return(nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, Email: testEmail, EmailVerified: true}, nil)
+				expectSession(m)
+			},
+			validate: func(t *testing.T, result *model.LoginUserResult) {
+				assert.True(t, result.User.EmailVerified)
+				assert.Empty(t, result.User.PasswordHash)
+			},
+		},
+		{
+			name: "success - 2FA enabled returns a challenge instead of tokens",
+			setupMock: func(m *serviceMocks) {
+				confirmedAt := time.Now()
+				m.identityRepo.On("FindIdentity", mock.Anything, testOIDCProvider, testOIDCSubject).
+					Return(&model.UserIdentity{ID: "identity-1", UserID: testUserID}, nil)
+				m.identityRepo.On("UpdateIdentityLastLogin", mock.Anything, "identity-1").Return(nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, Email: testEmail, EmailVerified: true}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(&model.UserTOTP{UserID: testUserID, ConfirmedAt: &confirmedAt}, nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeTwoFactorChallenge).
+					Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.AnythingOfType("model.UserToken")).
+					Return(nil)
+			},
+			validate: func(t *testing.T, result *model.LoginUserResult) {
+				require.NotNil(t, result.TwoFactorChallenge)
+				assert.Empty(t, result.AccessToken)
+			},
+		},
+		{
+			name: "error - provider did not verify the email",
+			setupIssuer: func(f *fakeIssuer) {
+				f.claims["email_verified"] = "false"
+			},
+			setupMock: func(m *serviceMocks) {
+				m.identityRepo.On("FindIdentity", mock.Anything, testOIDCProvider, testOIDCSubject).
+					Return(nil, model.ErrIdentityNotFound)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrOIDCEmailNotVerified,
+		},
+		{
+			name: "error - ID token with another nonce",
+			setupIssuer: func(f *fakeIssuer) {
+				f.nonceOverride = "replayed-nonce"
+			},
+			setupMock:   func(m *serviceMocks) {},
+			wantErr:     true,
+			expectedErr: model.ErrOIDCAuthenticationFailed,
+		},
+		{
+			name: "error - ID token for another client",
+			setupIssuer: func(f *fakeIssuer) {
+				f.claims["aud"] = "someone-else"
+			},
+			setupMock:   func(m *serviceMocks) {},
+			wantErr:     true,
+			expectedErr: model.ErrOIDCAuthenticationFailed,
+		},
+		{
+			name: "error - ID token with a forged signature",
+			setupIssuer: func(f *fakeIssuer) {
+				_, f.privateKey, _ = ed25519.GenerateKey(rand.Reader)
+			},
+			setupMock:   func(m *serviceMocks) {},
+			wantErr:     true,
+			expectedErr: model.ErrOIDCAuthenticationFailed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service, issuer := newOIDCTestService(t)
+			if tc.setupIssuer != nil {
+				tc.setupIssuer(issuer)
+			}
+			state, code := startOIDCLogin(t, m, service, issuer)
+			tc.setupMock(m)
+
+			result, err := service.CompleteOIDCLogin(context.Background(), model.CompleteOIDCLoginParams{
+				Provider: testOIDCProvider,
+				State:    state,
+				Code:     code,
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+				if tc.validate != nil {
+					tc.validate(t, result)
+				}
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCompleteOIDCLoginRejectsBadCallbacks(t *testing.T) {
+	t.Run("error - unknown state", func(t *testing.T) {
+		m, service, _ := newOIDCTestService(t)
+		m.identityRepo.On("ConsumeAuthRequest", mock.Anything, hashOpaqueToken("forged-state")).
+			Return(nil, model.ErrOIDCStateInvalid)
+
+		_, err := service.CompleteOIDCLogin(context.Background(), model.CompleteOIDCLoginParams{
+			Provider: testOIDCProvider,
+			State:    "forged-state",
+			Code:     "code",
+		})
+
+		require.ErrorIs(t, err, model.ErrOIDCStateInvalid)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - state started with another provider", func(t *testing.T) {
+		m, service, _ := newOIDCTestService(t)
+		m.identityRepo.On("ConsumeAuthRequest", mock.Anything, hashOpaqueToken("state")).
+			Return(&model.OIDCAuthRequest{Provider: "other"}, nil)
+
+		_, err := service.CompleteOIDCLogin(context.Background(), model.CompleteOIDCLoginParams{
+			Provider: testOIDCProvider,
+			State:    "state",
+			Code:     "code",
+		})
+
+		require.ErrorIs(t, err, model.ErrOIDCStateInvalid)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - code redeemed without the PKCE verifier", func(t *testing.T) {
+		m, service, issuer := newOIDCTestService(t)
+		m.identityRepo.On("CreateAuthRequest", mock.Anything, mock.AnythingOfType("model.OIDCAuthRequest")).
+			Return(nil)
+		authURL, err := service.StartOIDCLogin(context.Background(), testOIDCProvider)
+		require.NoError(t, err)
+		state, code := issuer.authorize(t, authURL)
+
+		// An attacker who intercepted the code has only their own login state
+		m.identityRepo.On("ConsumeAuthRequest", mock.Anything, hashOpaqueToken(state)).
+			Return(&model.OIDCAuthRequest{Provider: testOIDCProvider, Nonce: "n", CodeVerifier: "attacker-verifier"}, nil)
+
+		_, err = service.CompleteOIDCLogin(context.Background(), model.CompleteOIDCLoginParams{
+			Provider: testOIDCProvider,
+			State:    state,
+			Code:     code,
+		})
+
+		require.ErrorIs(t, err, model.ErrOIDCAuthenticationFailed)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestOIDCDisplayName(t *testing.T) {
+	assert.Equal(t, "Guest User", oidcDisplayName(&oidc.Identity{Name: "  Guest   User ", Email: "g@example.com"}))
+	assert.Equal(t, "guest.user", oidcDisplayName(&oidc.Identity{Email: "guest.user@example.com"}))
+}
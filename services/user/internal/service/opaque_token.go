@@ -0,0 +1,33 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// opaqueTokenBytes is the entropy of tokens handed to clients (256 bits).
+const opaqueTokenBytes = 32
+
+// newOpaqueToken generates a random token for the client and the hash we store.
+//
+// Only the hash is persisted, so a database leak doesn't expose usable tokens.
+// SHA-256 (rather than bcrypt) is fine here because the token is high-entropy
+// random data, not a user-chosen password.
+func newOpaqueToken() (token string, tokenHash string, err error) {
+	b := make([]byte, opaqueTokenBytes)
+	if _, err = rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("unexpected error occur when generating random token: %w", err)
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashOpaqueToken(token), nil
+}
+
+// hashOpaqueToken returns the hex-encoded SHA-256 of token.
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+)
+
+// ForgotPassword emails a password reset link if an account exists for the address.
+//
+// Unknown emails are not an error: the caller must answer the same way in both
+// cases so the endpoint cannot be used to find out who has an account. For the
+// same reason the link is issued and mailed in the background; waiting for the
+// mail server would make known addresses answer noticeably slower.
+func (s *UserService) ForgotPassword(ctx context.Context, emailAddress string) error {
+	user, err := s.userRepo.FindUserByEmail(ctx, emailAddress)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	s.runInBackground(ctx, func(ctx context.Context) {
+		if err := s.sendPasswordResetEmail(ctx, user); err != nil {
+			log.Printf("[ERROR] failed to send password reset email: %v", err)
+		}
+	})
+
+	return nil
+}
+
+func (s *UserService) sendPasswordResetEmail(ctx context.Context, user *model.User) error {
+	rawToken, err := s.issueUserToken(ctx, user.ID, model.TokenPurposePasswordReset, s.cfg.PasswordResetExpiry)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.cfg.AppBaseURL, url.QueryEscape(rawToken))
+
+	return s.emailSender.Send(ctx, email.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf(
+			"Hi %s,\n\nWe received a request to reset your password. Open the link below to choose a new one:\n\n%s\n\nThe link expires in %s and can only be used once. If you did not ask for this, you can ignore this email.\n",
+			user.DisplayName, link, formatExpiry(s.cfg.PasswordResetExpiry),
+		),
+	})
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword.
+// Every session of the user is ended, since whoever knew the old password
+// may still be logged in.
+func (s *UserService) ResetPassword(ctx context.Context, arg model.ResetPasswordParams) error {
+	// The password is checked before the token is spent, so a rejected
+	// password doesn't cost the user their reset link
+	userToken, err := s.userTokenRepo.FindUserToken(ctx, model.TokenPurposePasswordReset, hashOpaqueToken(arg.Token))
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, userToken.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err = s.checkPasswordPolicy(ctx, arg.NewPassword, user.Email, user.DisplayName); err != nil {
+		return err
+	}
+
+	userToken, err = s.consumeUserToken(ctx, model.TokenPurposePasswordReset, arg.Token)
+	if err != nil {
+		return err
+	}
+
+	if err = s.setPassword(ctx, userToken.UserID, arg.NewPassword); err != nil {
+		return err
+	}
+
+	return s.LogoutAll(ctx, userToken.UserID)
+}
+
+// ChangePassword replaces the password of a logged-in user after checking the
+// current one. The current session stays logged in; all other sessions are revoked.
+func (s *UserService) ChangePassword(ctx context.Context, arg model.ChangePasswordParams) error {
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = s.comparePassword(user, arg.CurrentPassword)
+	if err != nil {
+		if errors.Is(err, passwordhash.ErrMismatch) {
+			return model.ErrIncorrectPassword
+		}
+		return fmt.Errorf("unexpected error occur when comparing password: %w", err)
+	}
+
+	if err = s.checkPasswordPolicy(ctx, arg.NewPassword, user.Email, user.DisplayName); err != nil {
+		return err
+	}
+
+	if err = s.setPassword(ctx, user.ID, arg.NewPassword); err != nil {
+		return err
+	}
+
+	return s.RevokeOtherSessions(ctx, user.ID, arg.SessionID)
+}
+
+func (s *UserService) setPassword(ctx context.Context, userID, password string) error {
+	hashedPassword, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		return fmt.Errorf("unexpected error occur when generating hash password: %w", err)
+	}
+
+	return s.userRepo.UpdateUserPassword(ctx, userID, hashedPassword)
+}
+
+// checkPasswordPolicy rejects a new password that breaks the password policy
+// with a *model.PasswordRejectedError listing every broken rule. personalInfo
+// are values of the user the password must not contain.
+//
+// If the breached password list cannot be read, the other rules still apply:
+// an outage of the list should not stop people from registering.
+func (s *UserService) checkPasswordPolicy(ctx context.Context, password string, personalInfo ...string) error {
+	violations, err := s.passwordPolicy.Check(ctx, password, personalInfo...)
+	if err != nil {
+		log.Printf("[WARN] Password policy check incomplete: %v", err)
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+
+	rejected := &model.PasswordRejectedError{Violations: make([]model.PasswordViolation, len(violations))}
+	for i, v := range violations {
+		rejected.Violations[i] = model.PasswordViolation{Code: v.Code, Message: v.Message}
+	}
+
+	return rejected
+}
+
+// comparePassword checks password against the user's hash and returns
+// passwordhash.ErrMismatch if it is wrong.
+func (s *UserService) comparePassword(user *model.User, password string) error {
+	_, err := s.verifyPassword(user, password)
+	return err
+}
+
+// verifyPassword is comparePassword that also tells whether the hash should be
+// upgraded. Accounts created through an identity provider have no password,
+// which never matches.
+func (s *UserService) verifyPassword(user *model.User, password string) (needsRehash bool, err error) {
+	if user.PasswordHash == "" {
+		return false, passwordhash.ErrMismatch
+	}
+	return s.passwordHasher.Verify(user.PasswordHash, password)
+}
+
+// rehashPassword replaces the user's hash with one made with the current
+// settings. It only happens if the hash is still the one just verified, so a
+// password changed in the meantime is not overwritten. Failures are logged
+// only: the old hash keeps working and the next login tries again.
+func (s *UserService) rehashPassword(ctx context.Context, user *model.User, password string) {
+	newHash, err := s.passwordHasher.Hash(password)
+	if err != nil {
+		log.Printf("[WARN] Failed to rehash password: %v", err)
+		return
+	}
+
+	if err = s.userRepo.RehashUserPassword(ctx, user.ID, user.PasswordHash, newHash); err != nil {
+		log.Printf("[WARN] Failed to store rehashed password: %v", err)
+	}
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// matchPasswordHash matches a bcrypt hash of password.
+func matchPasswordHash(password string) any {
+	return mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+	})
+}
+
+func TestForgotPassword(t *testing.T) {
+	t.Run("success - emails a reset link", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userRepo.On("FindUserByEmail", mock.Anything, "user@example.com").
+			Return(&model.User{ID: "user-123", Email: "user@example.com"}, nil)
+		m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, "user-123", model.TokenPurposePasswordReset).
+			Return(nil)
+		m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(userToken model.UserToken) bool {
+			return userToken.Purpose == model.TokenPurposePasswordReset
+		})).
+			Return(nil)
+		m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+			return msg.To == "user@example.com" &&
+				strings.Contains(msg.Body, testAppBaseURL+"/reset-password?token=")
+		})).
+			Return(nil)
+
+		require.NoError(t, svc.ForgotPassword(context.Background(), "user@example.com"))
+		svc.background.Wait()
+		m.AssertExpectations(t)
+	})
+
+	t.Run("success - answers before the email is sent", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userRepo.On("FindUserByEmail", mock.Anything, "user@example.com").
+			Return(&model.User{ID: "user-123", Email: "user@example.com"}, nil)
+		m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, "user-123", model.TokenPurposePasswordReset).
+			Return(nil)
+		m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.AnythingOfType("model.UserToken")).
+			Return(nil)
+
+		// The mail server hangs until the request has been answered
+		answered := make(chan struct{})
+		m.emailSender.On("Send", mock.Anything, mock.AnythingOfType("email.Message")).
+			Run(func(mock.Arguments) { <-answered }).
+			Return(errors.New("smtp timeout"))
+
+		require.NoError(t, svc.ForgotPassword(context.Background(), "user@example.com"))
+		close(answered)
+
+		svc.background.Wait()
+		m.AssertExpectations(t)
+	})
+
+	t.Run("success - unknown email is silently ignored", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userRepo.On("FindUserByEmail", mock.Anything, "nobody@example.com").
+			Return(nil, model.ErrUserNotFound)
+
+		require.NoError(t, svc.ForgotPassword(context.Background(), "nobody@example.com"))
+		m.AssertExpectations(t)
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	const testUserID = "user-123"
+	const testRawToken = "reset-token-abc"
+
+	t.Run("success - sets the password and ends every session", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposePasswordReset, hashOpaqueToken(testRawToken)).
+			Return(&model.UserToken{UserID: testUserID}, nil)
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(createTestUser(testUserID, "user@example.com", "oldPassword123"), nil)
+		m.userTokenRepo.On("ConsumeUserToken", mock.Anything, model.TokenPurposePasswordReset, hashOpaqueToken(testRawToken)).
+			Return(&model.UserToken{UserID: testUserID}, nil)
+		m.userRepo.On("UpdateUserPassword", mock.Anything, testUserID, matchPasswordHash("newPassword123")).
+			Return(nil)
+		m.sessionRepo.On("RevokeUserSessions", mock.Anything, testUserID).
+			Return(nil)
+
+		err := svc.ResetPassword(context.Background(), model.ResetPasswordParams{
+			Token:       testRawToken,
+			NewPassword: "newPassword123",
+		})
+		require.NoError(t, err)
+
+		revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: testUserID, IssuedAt: time.Now().Add(-time.Minute)})
+		require.NoError(t, err)
+		assert.True(t, revoked)
+
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - invalid, expired or already used token", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposePasswordReset, hashOpaqueToken(testRawToken)).
+			Return(nil, model.ErrUserTokenInvalid)
+
+		err := svc.ResetPassword(context.Background(), model.ResetPasswordParams{
+			Token:       testRawToken,
+			NewPassword: "newPassword123",
+		})
+		assert.ErrorIs(t, err, model.ErrUserTokenInvalid)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - weak password keeps the reset link usable", func(t *testing.T) {
+		m, svc := newMocksAndService()
+		m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposePasswordReset, hashOpaqueToken(testRawToken)).
+			Return(&model.UserToken{UserID: testUserID}, nil)
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(createTestUser(testUserID, "user@example.com", "oldPassword123"), nil)
+
+		err := svc.ResetPassword(context.Background(), model.ResetPasswordParams{
+			Token:       testRawToken,
+			NewPassword: "aaaaaaaaaa",
+		})
+		assert.ErrorIs(t, err, model.ErrPasswordRejected)
+		m.userTokenRepo.AssertNotCalled(t, "ConsumeUserToken", mock.Anything, mock.Anything, mock.Anything)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestChangePassword(t *testing.T) {
+	const testUserID = "user-123"
+	const testPassword = "currentPassword123"
+
+	testCases := []struct {
+		name            string
+		currentPassword string
+		newPassword     string
+		setupMock       func(*serviceMocks)
+		wantErr         bool
+		expectedErr     error
+	}{
+		{
+			name:            "success - keeps the current session and revokes the others",
+			currentPassword: testPassword,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+				m.userRepo.On("UpdateUserPassword", mock.Anything, testUserID, matchPasswordHash("newPassword123")).
+					Return(nil)
+				m.sessionRepo.On("RevokeOtherUserSessions", mock.Anything, testUserID, "session-1").
+					Return([]string{"session-2"}, nil)
+			},
+		},
+		{
+			name:            "error - wrong current password",
+			currentPassword: "wrongPassword",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrIncorrectPassword,
+		},
+		{
+			name:            "error - new password breaks the policy",
+			currentPassword: testPassword,
+			newPassword:     "12345678",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPasswordRejected,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, svc := newMocksAndService()
+			tc.setupMock(m)
+
+			newPassword := tc.newPassword
+			if newPassword == "" {
+				newPassword = "newPassword123"
+			}
+
+			err := svc.ChangePassword(context.Background(), model.ChangePasswordParams{
+				UserID:          testUserID,
+				SessionID:       "session-1",
+				CurrentPassword: tc.currentPassword,
+				NewPassword:     newPassword,
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestChangePasswordRevokesOtherDevicesAccessTokens(t *testing.T) {
+	const testUserID = "user-123"
+	const testPassword = "currentPassword123"
+
+	m, svc := newMocksAndService()
+	m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+		Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+	m.userRepo.On("UpdateUserPassword", mock.Anything, testUserID, mock.AnythingOfType("string")).
+		Return(nil)
+	m.sessionRepo.On("RevokeOtherUserSessions", mock.Anything, testUserID, "session-1").
+		Return([]string{"session-2"}, nil)
+
+	issuedAt := time.Now().Add(-time.Minute)
+	err := svc.ChangePassword(context.Background(), model.ChangePasswordParams{
+		UserID:          testUserID,
+		SessionID:       "session-1",
+		CurrentPassword: testPassword,
+		NewPassword:     "newPassword123",
+	})
+	require.NoError(t, err)
+
+	// The access token another device already holds is rejected
+	revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-2", UserID: testUserID, SessionID: "session-2", IssuedAt: issuedAt})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// The device that changed the password stays logged in
+	revoked, err = m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: testUserID, SessionID: "session-1", IssuedAt: issuedAt})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	m.AssertExpectations(t)
+}
@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/sms"
+)
+
+const (
+	phoneCodeDigits = 6
+	phoneCodeExpiry = 10 * time.Minute
+
+	// maxPhoneCodeAttempts is how many guesses a code tolerates before it is
+	// burned and the user has to request a new one.
+	maxPhoneCodeAttempts = 5
+
+	// Every SMS costs money, so requests for codes are limited: one per
+	// phoneCodeResendInterval and maxPhoneCodesPerDay in any 24 hours.
+	phoneCodeResendInterval = time.Minute
+	maxPhoneCodesPerDay     = 5
+)
+
+// SendPhoneVerificationCode texts a code to the phone number on the user's
+// profile. Earlier codes stop working.
+func (s *UserService) SendPhoneVerificationCode(ctx context.Context, userID string) (*model.PhoneCodeSent, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Phone == nil {
+		return nil, model.ErrPhoneNumberMissing
+	}
+	if user.PhoneVerifiedAt != nil {
+		return nil, model.ErrPhoneAlreadyVerified
+	}
+
+	now := time.Now()
+	issued, err := s.userTokenRepo.ListUserTokenIssueTimes(ctx, user.ID, model.TokenPurposePhoneVerification, now.Add(-24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter := phoneCodeRetryAfter(issued, now); retryAfter > 0 {
+		return nil, &model.PhoneCodeThrottledError{RetryAfter: retryAfter}
+	}
+
+	if err = s.userTokenRepo.InvalidateUserTokens(ctx, user.ID, model.TokenPurposePhoneVerification); err != nil {
+		return nil, err
+	}
+
+	code, err := newPhoneCode()
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating token ID: %w", err)
+	}
+
+	err = s.userTokenRepo.CreateUserToken(ctx, model.UserToken{
+		ID:        tokenID.String(),
+		UserID:    user.ID,
+		Purpose:   model.TokenPurposePhoneVerification,
+		TokenHash: hashPhoneCode(tokenID.String(), *user.Phone, code),
+		ExpiresAt: now.Add(phoneCodeExpiry),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.smsSender.Send(ctx, sms.Message{
+		To: *user.Phone,
+		Body: fmt.Sprintf("%s is your verification code. It expires in %s. Never share this code with anyone.",
+			code, formatExpiry(phoneCodeExpiry)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to send phone verification code: %w", err)
+	}
+
+	return &model.PhoneCodeSent{
+		Phone:     *user.Phone,
+		ExpiresAt: now.Add(phoneCodeExpiry),
+		ResendAt:  now.Add(phoneCodeResendInterval),
+	}, nil
+}
+
+// VerifyPhone checks a code sent by SendPhoneVerificationCode and marks the
+// phone number as verified. The code only works for the number it was sent
+// to, so changing the number in between voids it.
+func (s *UserService) VerifyPhone(ctx context.Context, arg model.VerifyPhoneParams) error {
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+
+	if user.Phone == nil {
+		return model.ErrPhoneNumberMissing
+	}
+	if user.PhoneVerifiedAt != nil {
+		return model.ErrPhoneAlreadyVerified
+	}
+
+	userToken, err := s.userTokenRepo.FindOutstandingUserToken(ctx, user.ID, model.TokenPurposePhoneVerification)
+	if err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return model.ErrPhoneCodeExpired
+		}
+		return err
+	}
+
+	// The attempt is claimed before the code is compared, so parallel guesses
+	// cannot all get in while the counter still reads below the limit
+	userToken, err = s.userTokenRepo.ClaimUserTokenAttempt(ctx, userToken.ID, maxPhoneCodeAttempts)
+	if err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return model.ErrPhoneCodeExpired
+		}
+		return err
+	}
+
+	codeHash := hashPhoneCode(userToken.ID, *user.Phone, strings.TrimSpace(arg.Code))
+	if subtle.ConstantTimeCompare([]byte(codeHash), []byte(userToken.TokenHash)) != 1 {
+		return model.ErrPhoneCodeInvalid
+	}
+
+	// Consuming the code makes sure it verifies the number at most once
+	if _, err = s.userTokenRepo.ConsumeUserToken(ctx, model.TokenPurposePhoneVerification, codeHash); err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return model.ErrPhoneCodeExpired
+		}
+		return err
+	}
+
+	return s.userRepo.MarkPhoneVerified(ctx, user.ID, *user.Phone)
+}
+
+// phoneCodeRetryAfter returns how long the user must wait before another code
+// can be sent, given when the codes of the last 24 hours were issued (oldest
+// first). Zero means a code may be sent now.
+func phoneCodeRetryAfter(issued []time.Time, now time.Time) time.Duration {
+	if len(issued) == 0 {
+		return 0
+	}
+
+	var retryAfter time.Duration
+	if wait := issued[len(issued)-1].Add(phoneCodeResendInterval).Sub(now); wait > 0 {
+		retryAfter = wait
+	}
+	if len(issued) >= maxPhoneCodesPerDay {
+		// The oldest code that still counts has to leave the 24 hour window
+		oldest := issued[len(issued)-maxPhoneCodesPerDay]
+		if wait := oldest.Add(24 * time.Hour).Sub(now); wait > retryAfter {
+			retryAfter = wait
+		}
+	}
+
+	return retryAfter
+}
+
+// newPhoneCode returns a uniformly random numeric code of phoneCodeDigits.
+func newPhoneCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", fmt.Errorf("unexpected error occur when generating phone code: %w", err)
+	}
+
+	return fmt.Sprintf("%0*d", phoneCodeDigits, n.Int64()), nil
+}
+
+// hashPhoneCode binds a code to its token and phone number. Mixing in the
+// token ID keeps hashes unique even when two users get the same code, and the
+// phone number makes the code useless for any other number.
+func hashPhoneCode(tokenID, phone, code string) string {
+	return hashOpaqueToken(tokenID + ":" + phone + ":" + code)
+}
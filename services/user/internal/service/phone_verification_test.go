@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/sms"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testPhone = "+84901234567"
+
+func newTestUserWithPhone(userID string, phone *string, verified bool) *model.User {
+	user := createTestUser(userID, "guest@example.com", "password123")
+	user.Phone = phone
+	if verified {
+		verifiedAt := time.Now()
+		user.PhoneVerifiedAt = &verifiedAt
+	}
+	return user
+}
+
+func TestSendPhoneVerificationCode(t *testing.T) {
+	const testUserID = "user-123"
+	phone := testPhone
+
+	testCases := []struct {
+		name        string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - code is texted to the profile phone",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("ListUserTokenIssueTimes", mock.Anything, testUserID, model.TokenPurposePhoneVerification, mock.AnythingOfType("time.Time")).
+					Return([]time.Time{time.Now().Add(-time.Hour)}, nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(tok model.UserToken) bool {
+					return tok.UserID == testUserID && tok.Purpose == model.TokenPurposePhoneVerification
+				})).Return(nil)
+				m.smsSender.On("Send", mock.Anything, mock.MatchedBy(func(msg sms.Message) bool {
+					return msg.To == testPhone && strings.Contains(msg.Body, "verification code")
+				})).Return(nil)
+			},
+		},
+		{
+			name: "error - no phone number on the profile",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, nil, false), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPhoneNumberMissing,
+		},
+		{
+			name: "error - phone number is already verified",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, true), nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPhoneAlreadyVerified,
+		},
+		{
+			name: "error - previous code was sent a few seconds ago",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("ListUserTokenIssueTimes", mock.Anything, testUserID, model.TokenPurposePhoneVerification, mock.AnythingOfType("time.Time")).
+					Return([]time.Time{time.Now().Add(-10 * time.Second)}, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTooManyPhoneCodesRequested,
+		},
+		{
+			name: "error - daily limit of codes reached",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				issued := make([]time.Time, maxPhoneCodesPerDay)
+				for i := range issued {
+					issued[i] = time.Now().Add(-time.Duration(maxPhoneCodesPerDay-i) * time.Hour)
+				}
+				m.userTokenRepo.On("ListUserTokenIssueTimes", mock.Anything, testUserID, model.TokenPurposePhoneVerification, mock.AnythingOfType("time.Time")).
+					Return(issued, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTooManyPhoneCodesRequested,
+		},
+		{
+			name: "error - SMS provider fails",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("ListUserTokenIssueTimes", mock.Anything, testUserID, model.TokenPurposePhoneVerification, mock.AnythingOfType("time.Time")).
+					Return([]time.Time{}, nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.AnythingOfType("model.UserToken")).
+					Return(nil)
+				m.smsSender.On("Send", mock.Anything, mock.AnythingOfType("sms.Message")).
+					Return(errors.New("gateway timeout"))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			sent, err := service.SendPhoneVerificationCode(context.Background(), testUserID)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, sent)
+				assert.Equal(t, testPhone, sent.Phone)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyPhone(t *testing.T) {
+	const testUserID = "user-123"
+	const testTokenID = "token-1"
+	const testCode = "123456"
+	phone := testPhone
+
+	outstanding := &model.UserToken{
+		ID:        testTokenID,
+		UserID:    testUserID,
+		Purpose:   model.TokenPurposePhoneVerification,
+		TokenHash: hashPhoneCode(testTokenID, testPhone, testCode),
+		ExpiresAt: time.Now().Add(phoneCodeExpiry),
+	}
+
+	testCases := []struct {
+		name        string
+		code        string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - correct code verifies the phone",
+			code: testCode,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("FindOutstandingUserToken", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(outstanding, nil)
+				m.userTokenRepo.On("ClaimUserTokenAttempt", mock.Anything, testTokenID, maxPhoneCodeAttempts).
+					Return(outstanding, nil)
+				m.userTokenRepo.On("ConsumeUserToken", mock.Anything, model.TokenPurposePhoneVerification, outstanding.TokenHash).
+					Return(outstanding, nil)
+				m.userRepo.On("MarkPhoneVerified", mock.Anything, testUserID, testPhone).Return(nil)
+			},
+		},
+		{
+			name: "error - wrong code counts as a failed attempt",
+			code: "654321",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("FindOutstandingUserToken", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(outstanding, nil)
+				m.userTokenRepo.On("ClaimUserTokenAttempt", mock.Anything, testTokenID, maxPhoneCodeAttempts).
+					Return(outstanding, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPhoneCodeInvalid,
+		},
+		{
+			name: "error - code was sent to the number the user has since replaced",
+			code: testCode,
+			setupMock: func(m *serviceMocks) {
+				newPhone := "+84987654321"
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &newPhone, false), nil)
+				m.userTokenRepo.On("FindOutstandingUserToken", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(outstanding, nil)
+				m.userTokenRepo.On("ClaimUserTokenAttempt", mock.Anything, testTokenID, maxPhoneCodeAttempts).
+					Return(outstanding, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPhoneCodeInvalid,
+		},
+		{
+			name: "error - attempts used up by guesses made in parallel",
+			code: testCode,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("FindOutstandingUserToken", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(outstanding, nil)
+				m.userTokenRepo.On("ClaimUserTokenAttempt", mock.Anything, testTokenID, maxPhoneCodeAttempts).
+					Return(nil, model.ErrUserTokenInvalid)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPhoneCodeExpired,
+		},
+		{
+			name: "error - code expired or was burned by too many attempts",
+			code: testCode,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(newTestUserWithPhone(testUserID, &phone, false), nil)
+				m.userTokenRepo.On("FindOutstandingUserToken", mock.Anything, testUserID, model.TokenPurposePhoneVerification).
+					Return(nil, model.ErrUserTokenInvalid)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPhoneCodeExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			err := service.VerifyPhone(context.Background(), model.VerifyPhoneParams{UserID: testUserID, Code: tc.code})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPhoneCodeRetryAfter(t *testing.T) {
+	now := time.Now()
+
+	assert.Zero(t, phoneCodeRetryAfter(nil, now))
+	assert.Zero(t, phoneCodeRetryAfter([]time.Time{now.Add(-2 * time.Minute)}, now))
+	assert.Equal(t, 30*time.Second, phoneCodeRetryAfter([]time.Time{now.Add(-30 * time.Second)}, now))
+
+	// The oldest of the last five codes leaves the 24 hour window in an hour
+	issued := []time.Time{
+		now.Add(-23 * time.Hour), now.Add(-20 * time.Hour), now.Add(-10 * time.Hour),
+		now.Add(-5 * time.Hour), now.Add(-time.Hour),
+	}
+	assert.Equal(t, time.Hour, phoneCodeRetryAfter(issued, now))
+}
@@ -0,0 +1,14 @@
+package service
+
+import (
+	"context"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// UpdatePreferences replaces the user's preferences. Access tokens carry the
+// locale, timezone and currency, so other services only see the change once
+// the client refreshes its token.
+func (s *UserService) UpdatePreferences(ctx context.Context, userID string, prefs model.UserPreferences) (*model.User, error) {
+	return s.userRepo.UpdateUserPreferences(ctx, userID, prefs)
+}
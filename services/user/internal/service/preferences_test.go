@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePreferences(t *testing.T) {
+	const testUserID = "user-123"
+
+	prefs := model.UserPreferences{
+		Locale:   model.LocaleEnglish,
+		Currency: model.CurrencyUSD,
+		Timezone: "Europe/Berlin",
+		Notifications: model.NotificationPreferences{
+			BookingUpdates: model.NotificationChannels{Email: true, SMS: true},
+			HostingUpdates: model.NotificationChannels{Email: true},
+		},
+	}
+
+	testCases := []struct {
+		name        string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - preferences are replaced",
+			setupMock: func(m *serviceMocks) {
+				user := createTestUser(testUserID, "guest@example.com", "password123")
+				user.UserPreferences = prefs
+				m.userRepo.On("UpdateUserPreferences", mock.Anything, testUserID, prefs).Return(user, nil)
+			},
+		},
+		{
+			name: "error - user not found",
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("UpdateUserPreferences", mock.Anything, testUserID, prefs).
+					Return(nil, model.ErrUserNotFound)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			user, err := service.UpdatePreferences(context.Background(), testUserID, prefs)
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, prefs, user.UserPreferences)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAccessTokenParamsCarryPreferences(t *testing.T) {
+	user := createTestUser("user-123", "guest@example.com", "password123")
+	user.Roles = []string{"guest"}
+	user.UserPreferences = model.UserPreferences{
+		Locale:   model.LocaleEnglish,
+		Currency: model.CurrencyUSD,
+		Timezone: "Europe/Berlin",
+	}
+
+	assert.Equal(t, token.CreateTokenParams{
+		UserID:    "user-123",
+		SessionID: "session-1",
+		Roles:     []string{"guest"},
+		Locale:    model.LocaleEnglish,
+		Timezone:  "Europe/Berlin",
+		Currency:  model.CurrencyUSD,
+	}, accessTokenParams(user, "session-1"))
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// MaxAvatarSize is the largest avatar image accepted, in bytes.
+const MaxAvatarSize = 5 << 20
+
+// avatarExtensions maps the accepted (sniffed) image types to file extensions.
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+func (s *UserService) UpdateProfile(ctx context.Context, userID string, arg model.UpdateUserProfileParams) (*model.User, error) {
+	if arg.Languages != nil {
+		arg.Languages = normalizeLanguages(arg.Languages)
+	}
+
+	return s.userRepo.UpdateUserProfile(ctx, userID, arg)
+}
+
+// UploadAvatar stores a new avatar image and removes the previous one.
+//
+// The image type is sniffed from the content rather than trusted from the
+// client, so only real JPEG, PNG or WebP files end up being served.
+func (s *UserService) UploadAvatar(ctx context.Context, arg model.UploadAvatarParams) (*model.User, error) {
+	data, err := io.ReadAll(io.LimitReader(arg.File, MaxAvatarSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read avatar: %w", err)
+	}
+	if len(data) > MaxAvatarSize {
+		return nil, model.ErrAvatarTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := avatarExtensions[contentType]
+	if !ok {
+		return nil, model.ErrAvatarTypeUnsupported
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	fileID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating file ID: %w", err)
+	}
+
+	// A new key per upload, so browsers and CDNs never serve a stale cached image
+	key := fmt.Sprintf("avatars/%s/%s%s", user.ID, fileID, ext)
+	if err = s.avatarStore.Put(ctx, key, bytes.NewReader(data), contentType); err != nil {
+		return nil, err
+	}
+
+	url := s.avatarStore.URL(key)
+	updatedUser, err := s.userRepo.UpdateUserAvatar(ctx, user.ID, &key, &url)
+	if err != nil {
+		s.deleteAvatarFile(ctx, key)
+		return nil, err
+	}
+
+	if user.AvatarKey != nil {
+		s.deleteAvatarFile(ctx, *user.AvatarKey)
+	}
+
+	return updatedUser, nil
+}
+
+func (s *UserService) DeleteAvatar(ctx context.Context, userID string) (*model.User, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.AvatarKey == nil {
+		return user, nil
+	}
+
+	updatedUser, err := s.userRepo.UpdateUserAvatar(ctx, user.ID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteAvatarFile(ctx, *user.AvatarKey)
+
+	return updatedUser, nil
+}
+
+// GetPublicProfile returns what anyone may see about a user. The listing count
+// comes from the listing service; if it is down the profile is still returned
+// without it.
+func (s *UserService) GetPublicProfile(ctx context.Context, userID string) (*model.PublicProfile, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := &model.PublicProfile{User: user}
+
+	listingCount, err := s.listingClient.CountActiveListingsByHost(ctx, userID)
+	if err != nil {
+		log.Printf("[WARN] failed to count listings of user %s: %v", userID, err)
+	} else {
+		profile.ListingCount = &listingCount
+	}
+
+	return profile, nil
+}
+
+// deleteAvatarFile is best-effort: an orphaned file is harmless.
+func (s *UserService) deleteAvatarFile(ctx context.Context, key string) {
+	if err := s.avatarStore.Delete(ctx, key); err != nil {
+		log.Printf("[WARN] failed to delete avatar %s: %v", key, err)
+	}
+}
+
+// normalizeLanguages trims the language tags and drops duplicates, keeping order.
+func normalizeLanguages(languages []string) []string {
+	normalized := make([]string, 0, len(languages))
+	seen := make(map[string]bool, len(languages))
+
+	for _, language := range languages {
+		language = strings.TrimSpace(language)
+		key := strings.ToLower(language)
+		if language == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized = append(normalized, language)
+	}
+
+	return normalized
+}
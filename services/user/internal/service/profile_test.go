@@ -0,0 +1,163 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader là 8 byte đầu của mọi file PNG, đủ để http.DetectContentType nhận diện.
+var pngHeader = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+
+func TestUploadAvatar(t *testing.T) {
+	const testUserID = "user-123"
+	oldKey := "avatars/user-123/old.png"
+
+	testCases := []struct {
+		name        string
+		file        []byte
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - stores the new image and deletes the old one",
+			file: append(pngHeader, make([]byte, 64)...),
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, AvatarKey: &oldKey}, nil)
+
+				var newKey string
+				m.avatarStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+					newKey = key
+					return len(key) > len("avatars/user-123/") && key[len(key)-4:] == ".png"
+				}), mock.Anything, "image/png").
+					Return(nil)
+				m.avatarStore.On("URL", mock.Anything).
+					Return("https://cdn.example.com/avatar.png")
+				m.userRepo.On("UpdateUserAvatar", mock.Anything, testUserID,
+					mock.MatchedBy(func(key *string) bool { return key != nil && *key == newKey }),
+					mock.MatchedBy(func(url *string) bool { return url != nil && *url == "https://cdn.example.com/avatar.png" })).
+					Return(&model.User{ID: testUserID}, nil)
+				m.avatarStore.On("Delete", mock.Anything, oldKey).
+					Return(nil)
+			},
+		},
+		{
+			name:        "error - content is not an image",
+			file:        []byte("<html><body>not an image</body></html>"),
+			setupMock:   func(m *serviceMocks) {},
+			wantErr:     true,
+			expectedErr: model.ErrAvatarTypeUnsupported,
+		},
+		{
+			name:        "error - file is too large",
+			file:        append(pngHeader, make([]byte, MaxAvatarSize)...),
+			setupMock:   func(m *serviceMocks) {},
+			wantErr:     true,
+			expectedErr: model.ErrAvatarTooLarge,
+		},
+		{
+			name: "error - database fails, uploaded file is cleaned up",
+			file: pngHeader,
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID, AvatarKey: &oldKey}, nil)
+				m.avatarStore.On("Put", mock.Anything, mock.AnythingOfType("string"), mock.Anything, "image/png").
+					Return(nil)
+				m.avatarStore.On("URL", mock.Anything).
+					Return("https://cdn.example.com/avatar.png")
+				m.userRepo.On("UpdateUserAvatar", mock.Anything, testUserID, mock.Anything, mock.Anything).
+					Return(nil, errors.New("db error"))
+				// Xoá file vừa upload, KHÔNG xoá avatar cũ
+				m.avatarStore.On("Delete", mock.Anything, mock.MatchedBy(func(key string) bool {
+					return key != oldKey
+				})).
+					Return(nil)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			user, err := service.UploadAvatar(context.Background(), model.UploadAvatarParams{
+				UserID: testUserID,
+				File:   bytes.NewReader(tc.file),
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				if tc.expectedErr != nil {
+					assert.ErrorIs(t, err, tc.expectedErr)
+				}
+				assert.Nil(t, user)
+			} else {
+				require.NoError(t, err)
+				assert.NotNil(t, user)
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetPublicProfile(t *testing.T) {
+	const testUserID = "user-123"
+
+	t.Run("success - includes listing count", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(&model.User{ID: testUserID}, nil)
+		m.listingClient.On("CountActiveListingsByHost", mock.Anything, testUserID).
+			Return(int64(3), nil)
+
+		profile, err := service.GetPublicProfile(context.Background(), testUserID)
+
+		require.NoError(t, err)
+		require.NotNil(t, profile.ListingCount)
+		assert.Equal(t, int64(3), *profile.ListingCount)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("success - listing service down, profile without count", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(&model.User{ID: testUserID}, nil)
+		m.listingClient.On("CountActiveListingsByHost", mock.Anything, testUserID).
+			Return(int64(0), model.ErrListingServiceUnavailable)
+
+		profile, err := service.GetPublicProfile(context.Background(), testUserID)
+
+		require.NoError(t, err)
+		assert.Nil(t, profile.ListingCount)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - user not found", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(nil, model.ErrUserNotFound)
+
+		profile, err := service.GetPublicProfile(context.Background(), testUserID)
+
+		assert.ErrorIs(t, err, model.ErrUserNotFound)
+		assert.Nil(t, profile)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestNormalizeLanguages(t *testing.T) {
+	got := normalizeLanguages([]string{" vi ", "en", "", "EN", "fr"})
+
+	assert.Equal(t, []string{"vi", "en", "fr"}, got)
+}
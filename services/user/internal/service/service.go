@@ -2,15 +2,22 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
 	"github.com/katatrina/airbnb-clone/services/user/internal/model"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/katatrina/airbnb-clone/services/user/internal/oidc"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordpolicy"
+	"github.com/katatrina/airbnb-clone/services/user/internal/sms"
 )
 
 type UserRepository interface {
@@ -19,20 +26,287 @@ type UserRepository interface {
 	FindUserByID(ctx context.Context, id string) (*model.User, error)
 	UpdateUserLastLogin(ctx context.Context, id string, lastLoginAt time.Time) error
 	CheckEmailExists(ctx context.Context, email string) (bool, error)
+	MarkEmailVerified(ctx context.Context, id string) error
+	MarkPhoneVerified(ctx context.Context, id, phone string) error
+	UpdateUserPassword(ctx context.Context, id, passwordHash string) error
+	RehashUserPassword(ctx context.Context, id, currentHash, newHash string) error
+	UpdateUserProfile(ctx context.Context, id string, params model.UpdateUserProfileParams) (*model.User, error)
+	UpdateUserAvatar(ctx context.Context, id string, avatarKey, avatarURL *string) (*model.User, error)
+	UpdateUserRoles(ctx context.Context, id string, roles []string) (*model.User, error)
+	UpdateUserPreferences(ctx context.Context, id string, prefs model.UserPreferences) (*model.User, error)
+	SoftDeleteUser(ctx context.Context, id string) error
+	AnonymizeDeletedUsers(ctx context.Context, deletedBefore time.Time) ([]string, error)
+}
+
+type SessionRepository interface {
+	CreateSession(ctx context.Context, session model.Session) (*model.Session, error)
+	FindSessionByTokenHash(ctx context.Context, tokenHash string) (*model.Session, error)
+	RotateSession(ctx context.Context, currentID string, next model.Session) (*model.Session, error)
+	ListActiveSessions(ctx context.Context, userID string) ([]model.Session, error)
+	RevokeSessionFamily(ctx context.Context, familyID string) error
+	RevokeUserSession(ctx context.Context, userID, familyID string) error
+	RevokeUserSessions(ctx context.Context, userID string) error
+	RevokeOtherUserSessions(ctx context.Context, userID, currentFamilyID string) ([]string, error)
+}
+
+type UserTokenRepository interface {
+	CreateUserToken(ctx context.Context, userToken model.UserToken) error
+	ConsumeUserToken(ctx context.Context, purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error)
+	InvalidateUserTokens(ctx context.Context, userID string, purpose model.TokenPurpose) error
+	FindUserToken(ctx context.Context, purpose model.TokenPurpose, tokenHash string) (*model.UserToken, error)
+	FindOutstandingUserToken(ctx context.Context, userID string, purpose model.TokenPurpose) (*model.UserToken, error)
+	ListUserTokenIssueTimes(ctx context.Context, userID string, purpose model.TokenPurpose, since time.Time) ([]time.Time, error)
+	ClaimUserTokenAttempt(ctx context.Context, id string, maxAttempts int) (*model.UserToken, error)
+}
+
+type TwoFactorRepository interface {
+	FindTOTP(ctx context.Context, userID string) (*model.UserTOTP, error)
+	SaveTOTPSecret(ctx context.Context, userID, secret string) error
+	EnableTOTP(ctx context.Context, userID string, usedStep int64, codes []model.RecoveryCode) error
+	ReplaceRecoveryCodes(ctx context.Context, userID string, codes []model.RecoveryCode) error
+	MarkTOTPStepUsed(ctx context.Context, userID string, step int64) error
+	ConsumeRecoveryCode(ctx context.Context, userID, codeHash string) error
+	CountUnusedRecoveryCodes(ctx context.Context, userID string) (int64, error)
+	DeleteTOTP(ctx context.Context, userID string) error
+}
+
+type IdentityRepository interface {
+	CreateAuthRequest(ctx context.Context, authRequest model.OIDCAuthRequest) error
+	ConsumeAuthRequest(ctx context.Context, stateHash string) (*model.OIDCAuthRequest, error)
+	FindIdentity(ctx context.Context, provider, subject string) (*model.UserIdentity, error)
+	CreateUserWithIdentity(ctx context.Context, user model.User, identity model.UserIdentity) (*model.User, error)
+	LinkIdentity(ctx context.Context, identity model.UserIdentity, claimUnverified bool) error
+	UpdateIdentityLastLogin(ctx context.Context, id string) error
+}
+
+type LoginEventRepository interface {
+	CreateLoginEvent(ctx context.Context, event model.LoginEvent) error
+	HasSuccessfulLogin(ctx context.Context, userID, deviceFingerprint string) (bool, error)
+	ListLoginEvents(ctx context.Context, userID string, limit, offset int) ([]model.LoginEvent, error)
+	CountLoginEvents(ctx context.Context, userID string) (int64, error)
+}
+
+type EmailChangeRepository interface {
+	CreateEmailChange(ctx context.Context, change model.EmailChange) error
+	ConfirmEmailChange(ctx context.Context, confirmTokenHash string) (*model.EmailChange, error)
+	CancelEmailChange(ctx context.Context, cancelTokenHash string) (*model.EmailChange, error)
+}
+
+type DataExportRepository interface {
+	CreateDataExport(ctx context.Context, export model.DataExport) (*model.DataExport, error)
+	FindLatestDataExport(ctx context.Context, userID string) (*model.DataExport, error)
+	FindDownloadableDataExport(ctx context.Context, userID string) (*model.DataExport, error)
+	ClaimDataExport(ctx context.Context, staleBefore time.Time) (*model.DataExport, error)
+	CompleteDataExport(ctx context.Context, id, fileKey string, fileSize int64, expiresAt time.Time) error
+	FailDataExport(ctx context.Context, id, reason string) error
+	ExpireDataExports(ctx context.Context) ([]string, error)
+}
+
+type HostVerificationRepository interface {
+	CreateHostVerification(ctx context.Context, v model.HostVerification) (*model.HostVerification, error)
+	FindHostVerificationByID(ctx context.Context, id string) (*model.HostVerification, error)
+	FindLatestHostVerification(ctx context.Context, userID string) (*model.HostVerification, error)
+	ListHostVerificationsByUser(ctx context.Context, userID string) ([]model.HostVerification, error)
+	ListHostVerificationsByStatus(ctx context.Context, status model.HostVerificationStatus, limit, offset int) ([]model.HostVerification, error)
+	CountHostVerificationsByStatus(ctx context.Context, status model.HostVerificationStatus) (int64, error)
+	ReviewHostVerification(ctx context.Context, arg model.ReviewHostVerificationParams) (*model.HostVerification, error)
+	DeleteAnonymizedHostVerifications(ctx context.Context) ([]string, error)
+}
+
+type EmailSender interface {
+	Send(ctx context.Context, msg email.Message) error
+}
+
+type SMSSender interface {
+	Send(ctx context.Context, msg sms.Message) error
+}
+
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// PrivateStore keeps files that are never served publicly, such as data export
+// archives and ID documents. They are only read back through Open.
+type PrivateStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type ListingClient interface {
+	CountActiveListingsByHost(ctx context.Context, hostID string) (int64, error)
+	DeactivateHostListings(ctx context.Context, hostID string) error
+	ExportHostListings(ctx context.Context, hostID string) (json.RawMessage, error)
+}
+
+type BookingClient interface {
+	CountUpcomingBookings(ctx context.Context, userID string) (*model.UpcomingBookingCounts, error)
+	ExportUserBookings(ctx context.Context, userID string) (json.RawMessage, error)
+}
+
+// LoginGuard throttles password guessing. Check returns how long the caller
+// must wait before trying again, zero when the attempt may proceed.
+type LoginGuard interface {
+	Check(ctx context.Context, email, clientIP string) (time.Duration, error)
+	RecordFailure(ctx context.Context, email, clientIP string) error
+	RecordSuccess(ctx context.Context, email string) error
+}
+
+// PasswordPolicy returns the rules a new password breaks. An error means some
+// rules could not be checked; the violations found are still returned.
+type PasswordPolicy interface {
+	Check(ctx context.Context, password string, personalInfo ...string) ([]passwordpolicy.Violation, error)
+}
+
+// PasswordHasher hashes new passwords and verifies passwords against stored
+// hashes. needsRehash reports that a verified hash is weaker than what Hash
+// produces now.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (needsRehash bool, err error)
+}
+
+// OIDCProvider is an external OpenID provider users can log in with.
+type OIDCProvider interface {
+	AuthCodeURL(ctx context.Context, state, nonce, codeVerifier string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Identity, error)
+}
+
+// Config holds the settings of UserService.
+type Config struct {
+	RefreshTokenExpiry      time.Duration
+	EmailVerificationExpiry time.Duration
+	PasswordResetExpiry     time.Duration
+
+	// AccountDeletionGracePeriod is how long a deleted account keeps its
+	// personal data before it is anonymized.
+	AccountDeletionGracePeriod time.Duration
+
+	// AppBaseURL is the frontend URL that links in emails point to.
+	AppBaseURL string
+
+	// TOTPIssuer is the name authenticator apps show next to the account.
+	TOTPIssuer string
+
+	// DataExportExpiry is how long a finished data export can be downloaded.
+	DataExportExpiry time.Duration
 }
 
 type UserService struct {
-	userRepo   UserRepository
-	tokenMaker token.TokenMaker
+	userRepo        UserRepository
+	sessionRepo     SessionRepository
+	userTokenRepo   UserTokenRepository
+	twoFactorRepo   TwoFactorRepository
+	identityRepo    IdentityRepository
+	loginEventRepo  LoginEventRepository
+	emailChangeRepo EmailChangeRepository
+	dataExportRepo  DataExportRepository
+	hostVerifyRepo  HostVerificationRepository
+	tokenMaker      token.TokenMaker
+	revocations     token.RevocationStore
+	emailSender     EmailSender
+	smsSender       SMSSender
+	avatarStore     BlobStore
+	exportStore     PrivateStore
+	documentStore   PrivateStore
+	listingClient   ListingClient
+	bookingClient   BookingClient
+	loginGuard      LoginGuard
+	passwordPolicy  PasswordPolicy
+	passwordHasher  PasswordHasher
+	oidcProviders   map[string]OIDCProvider
+	cfg             Config
+
+	// background tracks work started by runInBackground.
+	background sync.WaitGroup
 }
 
-func NewUserService(userRepo UserRepository, tokenMaker token.TokenMaker) *UserService {
+// Deps are the collaborators a UserService is built from. Every field is
+// required.
+type Deps struct {
+	// Repositories
+	UserRepo        UserRepository
+	SessionRepo     SessionRepository
+	UserTokenRepo   UserTokenRepository
+	TwoFactorRepo   TwoFactorRepository
+	IdentityRepo    IdentityRepository
+	LoginEventRepo  LoginEventRepository
+	EmailChangeRepo EmailChangeRepository
+	DataExportRepo  DataExportRepository
+	HostVerifyRepo  HostVerificationRepository
+
+	// Tokens
+	TokenMaker  token.TokenMaker
+	Revocations token.RevocationStore
+
+	// Notifications
+	EmailSender EmailSender
+	SMSSender   SMSSender
+
+	// File storage
+	AvatarStore   BlobStore
+	ExportStore   PrivateStore
+	DocumentStore PrivateStore
+
+	// Other services
+	ListingClient ListingClient
+	BookingClient BookingClient
+
+	// Sign-in and password security
+	LoginGuard     LoginGuard
+	PasswordPolicy PasswordPolicy
+	PasswordHasher PasswordHasher
+
+	// OIDCProviders are the configured social login providers by name.
+	OIDCProviders map[string]OIDCProvider
+}
+
+func NewUserService(deps Deps, cfg Config) *UserService {
 	return &UserService{
-		userRepo:   userRepo,
-		tokenMaker: tokenMaker,
+		userRepo:        deps.UserRepo,
+		sessionRepo:     deps.SessionRepo,
+		userTokenRepo:   deps.UserTokenRepo,
+		twoFactorRepo:   deps.TwoFactorRepo,
+		identityRepo:    deps.IdentityRepo,
+		loginEventRepo:  deps.LoginEventRepo,
+		emailChangeRepo: deps.EmailChangeRepo,
+		dataExportRepo:  deps.DataExportRepo,
+		hostVerifyRepo:  deps.HostVerifyRepo,
+		tokenMaker:      deps.TokenMaker,
+		revocations:     deps.Revocations,
+		emailSender:     deps.EmailSender,
+		smsSender:       deps.SMSSender,
+		avatarStore:     deps.AvatarStore,
+		exportStore:     deps.ExportStore,
+		documentStore:   deps.DocumentStore,
+		listingClient:   deps.ListingClient,
+		bookingClient:   deps.BookingClient,
+		loginGuard:      deps.LoginGuard,
+		passwordPolicy:  deps.PasswordPolicy,
+		passwordHasher:  deps.PasswordHasher,
+		oidcProviders:   deps.OIDCProviders,
+		cfg:             cfg,
 	}
 }
 
+// backgroundTimeout bounds work that outlives the request that started it.
+const backgroundTimeout = time.Minute
+
+// runInBackground runs fn after the request has been answered. fn keeps the
+// values of ctx but not its cancellation, and gets backgroundTimeout to finish.
+func (s *UserService) runInBackground(ctx context.Context, fn func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), backgroundTimeout)
+
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
 func (s *UserService) CreateUser(ctx context.Context, arg model.CreateUserParams) (*model.User, error) {
 	exists, err := s.userRepo.CheckEmailExists(ctx, arg.Email)
 	if err != nil {
@@ -42,7 +316,11 @@ func (s *UserService) CreateUser(ctx context.Context, arg model.CreateUserParams
 		return nil, model.ErrEmailAlreadyExists
 	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(arg.Password), bcrypt.DefaultCost)
+	if err = s.checkPasswordPolicy(ctx, arg.Password, arg.Email, arg.DisplayName); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := s.passwordHasher.Hash(arg.Password)
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error occur when generating hash password: %w", err)
 	}
@@ -57,7 +335,7 @@ func (s *UserService) CreateUser(ctx context.Context, arg model.CreateUserParams
 		ID:            userID.String(),
 		DisplayName:   arg.DisplayName,
 		Email:         arg.Email,
-		PasswordHash:  string(hashedPassword),
+		PasswordHash:  hashedPassword,
 		EmailVerified: false,
 		CreatedAt:     now,
 		UpdatedAt:     now,
@@ -68,31 +346,122 @@ func (s *UserService) CreateUser(ctx context.Context, arg model.CreateUserParams
 		return nil, err
 	}
 
+	// The account is usable without verification, so a mail failure must not fail
+	// the registration. The user can ask for a new email later.
+	if err = s.sendVerificationEmail(ctx, createdUser); err != nil {
+		log.Printf("[WARN] Failed to send verification email: %v", err)
+	}
+
 	return createdUser, nil
 }
 
 func (s *UserService) LoginUser(ctx context.Context, arg model.LoginUserParams) (*model.LoginUserResult, error) {
+	// Checked before touching the password, so throttled attempts cost no hashing work
+	retryAfter, err := s.loginGuard.Check(ctx, arg.Email, arg.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, &model.LoginThrottledError{RetryAfter: retryAfter}
+	}
+
 	user, err := s.userRepo.FindUserByEmail(ctx, arg.Email)
 	if err != nil {
 		if errors.Is(err, model.ErrUserNotFound) {
-			return nil, model.ErrIncorrectCredentials
+			// Unknown emails count too, otherwise lockouts would reveal which accounts exist
+			return nil, s.loginFailed(ctx, arg)
 		}
 		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(arg.Password))
+	needsRehash, err := s.verifyPassword(user, arg.Password)
 	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
-			return nil, model.ErrIncorrectCredentials
+		if errors.Is(err, passwordhash.ErrMismatch) {
+			s.recordLoginFailure(ctx, user.ID, arg.UserAgent, arg.ClientIP, model.LoginFailureIncorrectPassword)
+			return nil, s.loginFailed(ctx, arg)
 		}
 		return nil, fmt.Errorf("unexpected error occur when comparing password: %w", err)
 	}
 
-	accessToken, accessTokenExpiresAt, err := s.tokenMaker.CreateToken(user.ID)
+	// Login is the only moment the plain password is known, so it is when
+	// hashes made with older, weaker settings get upgraded
+	if needsRehash {
+		s.rehashPassword(ctx, user, arg.Password)
+	}
+
+	result, err := s.continueLogin(ctx, user, arg.UserAgent, arg.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	// With 2FA the counter is reset once the code is accepted too, otherwise
+	// every correct password would wipe out the wrong codes guessed so far
+	if result.TwoFactorChallenge == nil {
+		if err = s.loginGuard.RecordSuccess(ctx, arg.Email); err != nil {
+			log.Printf("[WARN] Failed to reset failed login attempts: %v", err)
+		}
+	}
+
+	return result, nil
+}
+
+// continueLogin runs the steps after the first factor, whether that was a
+// password or an identity provider: a 2FA challenge if the user has an
+// authenticator, otherwise a new session.
+func (s *UserService) continueLogin(ctx context.Context, user *model.User, userAgent, clientIP string) (*model.LoginUserResult, error) {
+	// The first factor alone is not enough when the user has an authenticator
+	userTOTP, err := s.twoFactorRepo.FindTOTP(ctx, user.ID)
+	if err != nil && !errors.Is(err, model.ErrTwoFactorNotEnabled) {
+		return nil, err
+	}
+	if userTOTP != nil && userTOTP.Enabled() {
+		challenge, err := s.issueTwoFactorChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &model.LoginUserResult{TwoFactorChallenge: challenge}, nil
+	}
+
+	return s.completeLogin(ctx, user, userAgent, clientIP)
+}
+
+// loginFailed records a failed password attempt and returns the error for the caller.
+func (s *UserService) loginFailed(ctx context.Context, arg model.LoginUserParams) error {
+	if err := s.loginGuard.RecordFailure(ctx, arg.Email, arg.ClientIP); err != nil {
+		log.Printf("[WARN] Failed to record failed login attempt: %v", err)
+	}
+
+	return model.ErrIncorrectCredentials
+}
+
+// accessTokenParams builds the claims of an access token for the user, so a
+// login and a refresh hand out the same picture of the account.
+func accessTokenParams(user *model.User, sessionID string) token.CreateTokenParams {
+	return token.CreateTokenParams{
+		UserID:        user.ID,
+		SessionID:     sessionID,
+		EmailVerified: user.EmailVerified,
+		Roles:         user.Roles,
+		Locale:        user.Locale,
+		Timezone:      user.Timezone,
+		Currency:      user.Currency,
+	}
+}
+
+// completeLogin starts a session for a user who passed every login step.
+func (s *UserService) completeLogin(ctx context.Context, user *model.User, userAgent, clientIP string) (*model.LoginUserResult, error) {
+	refreshToken, session, err := s.startSession(ctx, user.ID, userAgent, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, accessTokenExpiresAt, err := s.tokenMaker.CreateToken(accessTokenParams(user, session.FamilyID))
 	if err != nil {
 		return nil, fmt.Errorf("unexpected error occur when generating token: %w", err)
 	}
 
+	s.recordLoginSuccess(ctx, user, userAgent, clientIP)
+
 	now := time.Now()
 	err = s.userRepo.UpdateUserLastLogin(ctx, user.ID, now)
 	if err != nil {
@@ -102,9 +471,11 @@ func (s *UserService) LoginUser(ctx context.Context, arg model.LoginUserParams)
 	}
 
 	return &model.LoginUserResult{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessTokenExpiresAt,
-		User:                 user,
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: session.ExpiresAt,
+		User:                  user,
 	}, nil
 }
 
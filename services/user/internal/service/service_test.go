@@ -3,10 +3,16 @@ package service
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/email"
+	"github.com/katatrina/airbnb-clone/services/user/internal/loginguard"
 	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordpolicy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -24,19 +30,173 @@ func createTestUser(id, email, password string) *model.User {
 	}
 }
 
-func newMocksAndService() (*MockUserRepository, *MockTokenMaker, *UserService) {
-	mockRepo := new(MockUserRepository)
-	mockToken := new(MockTokenMaker)
-	service := NewUserService(mockRepo, mockToken)
+const (
+	testRefreshTokenExpiry      = 30 * 24 * time.Hour
+	testEmailVerificationExpiry = 24 * time.Hour
+	testAppBaseURL              = "https://app.example.com"
 
-	return mockRepo, mockToken, service
+	testAccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+	testTOTPIssuer = "Airbnb Clone"
+
+	testDataExportExpiry = 72 * time.Hour
+)
+
+// testLoginPolicy throttles after 2 free failures and locks at 5.
+var testLoginPolicy = loginguard.Policy{
+	FreeAttempts:    2,
+	BaseDelay:       time.Minute,
+	MaxDelay:        10 * time.Minute,
+	MaxAttempts:     5,
+	LockoutDuration: 15 * time.Minute,
+	Window:          15 * time.Minute,
+}
+
+// testBreachedPassword is on the fake breached password list of every test service.
+const testBreachedPassword = "Summer2024!"
+
+// fakeBreachedSet is an in-memory breached password list.
+type fakeBreachedSet map[string]bool
+
+func (f fakeBreachedSet) Contains(_ context.Context, password string) (bool, error) {
+	return f[password], nil
+}
+
+// serviceMocks groups every mocked dependency of UserService.
+type serviceMocks struct {
+	userRepo      *MockUserRepository
+	sessionRepo   *MockSessionRepository
+	userTokenRepo *MockUserTokenRepository
+	twoFactorRepo *MockTwoFactorRepository
+	identityRepo  *MockIdentityRepository
+	loginEvents   *MockLoginEventRepository
+	emailChanges  *MockEmailChangeRepository
+	dataExports   *MockDataExportRepository
+	hostVerifies  *MockHostVerificationRepository
+	tokenMaker    *MockTokenMaker
+	revocations   *token.MemoryRevocationStore
+	emailSender   *MockEmailSender
+	smsSender     *MockSMSSender
+	avatarStore   *MockBlobStore
+	exportStore   *MockPrivateStore
+	documentStore *MockPrivateStore
+	listingClient *MockListingClient
+	bookingClient *MockBookingClient
+	loginGuard    *loginguard.Guard
+}
+
+func (m *serviceMocks) AssertExpectations(t *testing.T) {
+	m.userRepo.AssertExpectations(t)
+	m.sessionRepo.AssertExpectations(t)
+	m.userTokenRepo.AssertExpectations(t)
+	m.twoFactorRepo.AssertExpectations(t)
+	m.identityRepo.AssertExpectations(t)
+	m.loginEvents.AssertExpectations(t)
+	m.emailChanges.AssertExpectations(t)
+	m.dataExports.AssertExpectations(t)
+	m.hostVerifies.AssertExpectations(t)
+	m.tokenMaker.AssertExpectations(t)
+	m.emailSender.AssertExpectations(t)
+	m.smsSender.AssertExpectations(t)
+	m.avatarStore.AssertExpectations(t)
+	m.exportStore.AssertExpectations(t)
+	m.documentStore.AssertExpectations(t)
+	m.listingClient.AssertExpectations(t)
+	m.bookingClient.AssertExpectations(t)
+}
+
+// testPasswordPolicy mirrors the defaults of .env.example.
+var testPasswordPolicy = passwordpolicy.NewChecker(
+	passwordpolicy.Config{MinLength: 8, MinScore: 2},
+	fakeBreachedSet{testBreachedPassword: true},
+)
+
+// testPasswordHasher uses the cheapest bcrypt cost, the one createTestUser
+// hashes with, so test users never need a rehash.
+var testPasswordHasher = passwordhash.NewHasher(passwordhash.Config{
+	Algorithm:  passwordhash.AlgorithmBcrypt,
+	BcryptCost: bcrypt.MinCost,
+})
+
+func newMocksAndService() (*serviceMocks, *UserService) {
+	m := &serviceMocks{
+		userRepo:      new(MockUserRepository),
+		sessionRepo:   new(MockSessionRepository),
+		userTokenRepo: new(MockUserTokenRepository),
+		twoFactorRepo: new(MockTwoFactorRepository),
+		identityRepo:  new(MockIdentityRepository),
+		loginEvents:   new(MockLoginEventRepository),
+		emailChanges:  new(MockEmailChangeRepository),
+		dataExports:   new(MockDataExportRepository),
+		hostVerifies:  new(MockHostVerificationRepository),
+		tokenMaker:    new(MockTokenMaker),
+		revocations:   token.NewMemoryRevocationStore(time.Hour),
+		emailSender:   new(MockEmailSender),
+		smsSender:     new(MockSMSSender),
+		avatarStore:   new(MockBlobStore),
+		exportStore:   new(MockPrivateStore),
+		documentStore: new(MockPrivateStore),
+		listingClient: new(MockListingClient),
+		bookingClient: new(MockBookingClient),
+		loginGuard:    loginguard.NewGuard(loginguard.NewMemoryStore(), testLoginPolicy, testLoginPolicy),
+	}
+	service := NewUserService(Deps{
+		UserRepo:        m.userRepo,
+		SessionRepo:     m.sessionRepo,
+		UserTokenRepo:   m.userTokenRepo,
+		TwoFactorRepo:   m.twoFactorRepo,
+		IdentityRepo:    m.identityRepo,
+		LoginEventRepo:  m.loginEvents,
+		EmailChangeRepo: m.emailChanges,
+		DataExportRepo:  m.dataExports,
+		HostVerifyRepo:  m.hostVerifies,
+		TokenMaker:      m.tokenMaker,
+		Revocations:     m.revocations,
+		EmailSender:     m.emailSender,
+		SMSSender:       m.smsSender,
+		AvatarStore:     m.avatarStore,
+		ExportStore:     m.exportStore,
+		DocumentStore:   m.documentStore,
+		ListingClient:   m.listingClient,
+		BookingClient:   m.bookingClient,
+		LoginGuard:      m.loginGuard,
+		PasswordPolicy:  testPasswordPolicy,
+		PasswordHasher:  testPasswordHasher,
+		OIDCProviders:   map[string]OIDCProvider{},
+	}, Config{
+		RefreshTokenExpiry:         testRefreshTokenExpiry,
+		EmailVerificationExpiry:    testEmailVerificationExpiry,
+		AccountDeletionGracePeriod: testAccountDeletionGracePeriod,
+		AppBaseURL:                 testAppBaseURL,
+		TOTPIssuer:                 testTOTPIssuer,
+		DataExportExpiry:           testDataExportExpiry,
+	})
+
+	return m, service
+}
+
+// expectLoginFromKnownDevice expects a successful login of userID to be
+// recorded, from a device the user logged in with before.
+func (m *serviceMocks) expectLoginFromKnownDevice(userID string) {
+	m.loginEvents.On("HasSuccessfulLogin", mock.Anything, userID, mock.AnythingOfType("string")).
+		Return(true, nil)
+	m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.MatchedBy(func(e model.LoginEvent) bool {
+		return e.UserID == userID && e.Success && !e.NewDevice
+	})).Return(nil)
+}
+
+// expectLoginFailure expects a failed login of userID to be recorded with reason.
+func (m *serviceMocks) expectLoginFailure(userID, reason string) {
+	m.loginEvents.On("CreateLoginEvent", mock.Anything, mock.MatchedBy(func(e model.LoginEvent) bool {
+		return e.UserID == userID && !e.Success && e.Reason != nil && *e.Reason == reason
+	})).Return(nil)
 }
 
 func TestCreateUser(t *testing.T) {
 	testCases := []struct {
 		name        string
 		input       model.CreateUserParams
-		setupMock   func(*MockUserRepository, *MockTokenMaker)
+		setupMock   func(*serviceMocks)
 		wantErr     bool
 		expectedErr error
 		validate    func(t *testing.T, input model.CreateUserParams, user *model.User)
@@ -48,18 +208,27 @@ func TestCreateUser(t *testing.T) {
 				Email:       "newuser@example.com",
 				Password:    "strongPassword123",
 			},
-			setupMock: func(mockRepo *MockUserRepository, _ *MockTokenMaker) {
-				mockRepo.On("CheckEmailExists", mock.Anything, "newuser@example.com").
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("CheckEmailExists", mock.Anything, "newuser@example.com").
 					Return(false, nil)
 
 				hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("strongPassword123"), bcrypt.MinCost)
-				mockRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("model.User")).
+				m.userRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("model.User")).
 					Return(&model.User{
 						ID:           "mock-user-id",
 						Email:        "newuser@example.com",
 						DisplayName:  "New User",
 						PasswordHash: string(hashedPassword),
 					}, nil)
+
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, "mock-user-id", model.TokenPurposeEmailVerification).
+					Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.AnythingOfType("model.UserToken")).
+					Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.MatchedBy(func(msg email.Message) bool {
+					return msg.To == "newuser@example.com"
+				})).
+					Return(nil)
 			},
 			wantErr:     false,
 			expectedErr: nil,
@@ -70,6 +239,27 @@ func TestCreateUser(t *testing.T) {
 				assert.NotEqual(t, input.Password, user.PasswordHash)
 			},
 		},
+		{
+			name: "success - registration succeeds even when verification email fails",
+			input: model.CreateUserParams{
+				DisplayName: "New User",
+				Email:       "newuser@example.com",
+				Password:    "strongPassword123",
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("CheckEmailExists", mock.Anything, "newuser@example.com").
+					Return(false, nil)
+				m.userRepo.On("CreateUser", mock.Anything, mock.AnythingOfType("model.User")).
+					Return(&model.User{ID: "mock-user-id", Email: "newuser@example.com", DisplayName: "New User"}, nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, "mock-user-id", model.TokenPurposeEmailVerification).
+					Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.AnythingOfType("model.UserToken")).
+					Return(nil)
+				m.emailSender.On("Send", mock.Anything, mock.AnythingOfType("email.Message")).
+					Return(errors.New("smtp unavailable"))
+			},
+			wantErr: false,
+		},
 		{
 			name: "error - email already exists",
 			input: model.CreateUserParams{
@@ -77,19 +267,61 @@ func TestCreateUser(t *testing.T) {
 				Email:       "existing@example.com",
 				Password:    "strongPassword123",
 			},
-			setupMock: func(mockRepo *MockUserRepository, _ *MockTokenMaker) {
-				mockRepo.On("CheckEmailExists", mock.Anything, "existing@example.com").
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("CheckEmailExists", mock.Anything, "existing@example.com").
 					Return(true, nil)
 			},
 			wantErr:     true,
 			expectedErr: model.ErrEmailAlreadyExists,
 		},
+		{
+			name: "error - password too easy to guess",
+			input: model.CreateUserParams{
+				DisplayName: "New User",
+				Email:       "newuser@example.com",
+				Password:    "aaaaaaaa",
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("CheckEmailExists", mock.Anything, "newuser@example.com").
+					Return(false, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPasswordRejected,
+		},
+		{
+			name: "error - password contains the email",
+			input: model.CreateUserParams{
+				DisplayName: "New User",
+				Email:       "newuser@example.com",
+				Password:    "newuser2024!",
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("CheckEmailExists", mock.Anything, "newuser@example.com").
+					Return(false, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPasswordRejected,
+		},
+		{
+			name: "error - password appears in a data breach",
+			input: model.CreateUserParams{
+				DisplayName: "New User",
+				Email:       "newuser@example.com",
+				Password:    testBreachedPassword,
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("CheckEmailExists", mock.Anything, "newuser@example.com").
+					Return(false, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrPasswordRejected,
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo, mockToken, svc := newMocksAndService()
-			tc.setupMock(mockRepo, mockToken)
+			m, svc := newMocksAndService()
+			tc.setupMock(m)
 
 			ctx := context.Background()
 			user, err := svc.CreateUser(ctx, tc.input)
@@ -108,7 +340,7 @@ func TestCreateUser(t *testing.T) {
 				}
 			}
 
-			mockRepo.AssertExpectations(t)
+			m.AssertExpectations(t)
 		})
 	}
 }
@@ -119,11 +351,17 @@ func TestLoginUser(t *testing.T) {
 	const testPassword = "correctPassword123"
 
 	existingUser := createTestUser(testUserID, testEmail, testPassword)
+	testSession := &model.Session{
+		ID:        "session-1",
+		FamilyID:  "session-1",
+		UserID:    testUserID,
+		ExpiresAt: time.Now().Add(testRefreshTokenExpiry),
+	}
 
 	testCases := []struct {
 		name        string
 		input       model.LoginUserParams
-		setupMock   func(*MockUserRepository, *MockTokenMaker)
+		setupMock   func(*serviceMocks)
 		wantErr     bool
 		expectedErr error
 		validate    func(t *testing.T, result *model.LoginUserResult)
@@ -134,19 +372,53 @@ func TestLoginUser(t *testing.T) {
 				Email:    testEmail,
 				Password: testPassword,
 			},
-			setupMock: func(mockRepo *MockUserRepository, mockToken *MockTokenMaker) {
-				mockRepo.On("FindUserByEmail", mock.Anything, testEmail).
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
 					Return(existingUser, nil)
-				mockToken.On("CreateToken", testUserID).
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(nil, model.ErrTwoFactorNotEnabled)
+				m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+					Return(testSession, nil)
+				m.tokenMaker.On("CreateToken", token.CreateTokenParams{UserID: testUserID, SessionID: testSession.FamilyID}).
 					Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
-				mockRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).
+				m.userRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).
 					Return(nil)
+				m.expectLoginFromKnownDevice(testUserID)
 			},
 			wantErr:     false,
 			expectedErr: nil,
 			validate: func(t *testing.T, result *model.LoginUserResult) {
 				assert.Equal(t, "jwt-token-xyz", result.AccessToken)
 				assert.NotZero(t, result.AccessTokenExpiresAt)
+				assert.NotEmpty(t, result.RefreshToken)
+				assert.Equal(t, testSession.ExpiresAt, result.RefreshTokenExpiresAt)
+			},
+		},
+		{
+			name: "success - 2FA enabled returns a challenge instead of tokens",
+			input: model.LoginUserParams{
+				Email:    testEmail,
+				Password: testPassword,
+			},
+			setupMock: func(m *serviceMocks) {
+				confirmedAt := time.Now()
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+					Return(existingUser, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(&model.UserTOTP{UserID: testUserID, ConfirmedAt: &confirmedAt}, nil)
+				m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeTwoFactorChallenge).
+					Return(nil)
+				m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(ut model.UserToken) bool {
+					return ut.UserID == testUserID && ut.Purpose == model.TokenPurposeTwoFactorChallenge
+				})).Return(nil)
+			},
+			wantErr:     false,
+			expectedErr: nil,
+			validate: func(t *testing.T, result *model.LoginUserResult) {
+				require.NotNil(t, result.TwoFactorChallenge)
+				assert.NotEmpty(t, result.TwoFactorChallenge.Token)
+				assert.Empty(t, result.AccessToken)
+				assert.Empty(t, result.RefreshToken)
 			},
 		},
 		{
@@ -155,8 +427,8 @@ func TestLoginUser(t *testing.T) {
 				Email:    "nonexistent@example.com",
 				Password: "anyPassword",
 			},
-			setupMock: func(mockRepo *MockUserRepository, _ *MockTokenMaker) {
-				mockRepo.On("FindUserByEmail", mock.Anything, "nonexistent@example.com").
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByEmail", mock.Anything, "nonexistent@example.com").
 					Return(nil, model.ErrUserNotFound)
 			},
 			wantErr:     true,
@@ -168,9 +440,24 @@ func TestLoginUser(t *testing.T) {
 				Email:    testEmail,
 				Password: "wrongPassword",
 			},
-			setupMock: func(mockRepo *MockUserRepository, _ *MockTokenMaker) {
-				mockRepo.On("FindUserByEmail", mock.Anything, testEmail).
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
 					Return(existingUser, nil)
+				m.expectLoginFailure(testUserID, model.LoginFailureIncorrectPassword)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrIncorrectCredentials,
+		},
+		{
+			name: "error - account created through an identity provider has no password",
+			input: model.LoginUserParams{
+				Email:    testEmail,
+				Password: "",
+			},
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+					Return(&model.User{ID: testUserID, Email: testEmail, EmailVerified: true}, nil)
+				m.expectLoginFailure(testUserID, model.LoginFailureIncorrectPassword)
 			},
 			wantErr:     true,
 			expectedErr: model.ErrIncorrectCredentials,
@@ -181,10 +468,14 @@ func TestLoginUser(t *testing.T) {
 				Email:    testEmail,
 				Password: testPassword,
 			},
-			setupMock: func(mockRepo *MockUserRepository, mockToken *MockTokenMaker) {
-				mockRepo.On("FindUserByEmail", mock.Anything, testEmail).
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
 					Return(existingUser, nil)
-				mockToken.On("CreateToken", testUserID).
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(nil, model.ErrTwoFactorNotEnabled)
+				m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+					Return(testSession, nil)
+				m.tokenMaker.On("CreateToken", token.CreateTokenParams{UserID: testUserID, SessionID: testSession.FamilyID}).
 					Return("", time.Time{}, errors.New("token creation failed"))
 			},
 			wantErr: true,
@@ -195,13 +486,18 @@ func TestLoginUser(t *testing.T) {
 				Email:    testEmail,
 				Password: testPassword,
 			},
-			setupMock: func(mockRepo *MockUserRepository, mockToken *MockTokenMaker) {
-				mockRepo.On("FindUserByEmail", mock.Anything, testEmail).
+			setupMock: func(m *serviceMocks) {
+				m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
 					Return(existingUser, nil)
-				mockToken.On("CreateToken", testUserID).
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(nil, model.ErrTwoFactorNotEnabled)
+				m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+					Return(testSession, nil)
+				m.tokenMaker.On("CreateToken", token.CreateTokenParams{UserID: testUserID, SessionID: testSession.FamilyID}).
 					Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
-				mockRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).
+				m.userRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).
 					Return(errors.New("update last login failed"))
+				m.expectLoginFromKnownDevice(testUserID)
 			},
 			wantErr:     false,
 			expectedErr: nil,
@@ -214,8 +510,8 @@ func TestLoginUser(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			mockRepo, mockToken, svc := newMocksAndService()
-			tc.setupMock(mockRepo, mockToken)
+			m, svc := newMocksAndService()
+			tc.setupMock(m)
 
 			ctx := context.Background()
 			result, err := svc.LoginUser(ctx, tc.input)
@@ -234,8 +530,174 @@ func TestLoginUser(t *testing.T) {
 				}
 			}
 
-			mockRepo.AssertExpectations(t)
-			mockToken.AssertExpectations(t)
+			m.AssertExpectations(t)
 		})
 	}
 }
+
+func TestLoginUserRehash(t *testing.T) {
+	const testUserID = "user-123"
+	const testEmail = "user@example.com"
+	const testPassword = "correctPassword123"
+
+	// Cheap parameters, the tests only need a stronger algorithm than bcrypt cost 4
+	argon2idHasher := passwordhash.NewHasher(passwordhash.Config{
+		Algorithm: passwordhash.AlgorithmArgon2id,
+		Argon2:    passwordhash.Argon2Params{Memory: 1024, Iterations: 1, Parallelism: 1},
+	})
+
+	expectLogin := func(m *serviceMocks, user *model.User) {
+		m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).Return(user, nil)
+		m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).Return(nil, model.ErrTwoFactorNotEnabled)
+		m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+			Return(&model.Session{ID: "session-1", FamilyID: "session-1", UserID: testUserID}, nil)
+		m.tokenMaker.On("CreateToken", mock.Anything).Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
+		m.userRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).Return(nil)
+		m.expectLoginFromKnownDevice(testUserID)
+	}
+
+	t.Run("success - old bcrypt hash is upgraded to argon2id", func(t *testing.T) {
+		m, service := newMocksAndService()
+		service.passwordHasher = argon2idHasher
+		user := createTestUser(testUserID, testEmail, testPassword)
+
+		var newHash string
+		expectLogin(m, user)
+		m.userRepo.On("RehashUserPassword", mock.Anything, testUserID, user.PasswordHash, mock.MatchedBy(func(hash string) bool {
+			newHash = hash
+			return strings.HasPrefix(hash, "$argon2id$")
+		})).Return(nil)
+
+		_, err := service.LoginUser(context.Background(), model.LoginUserParams{Email: testEmail, Password: testPassword})
+		require.NoError(t, err)
+		m.AssertExpectations(t)
+
+		// The new hash verifies and is already as strong as configured
+		needsRehash, err := argon2idHasher.Verify(newHash, testPassword)
+		require.NoError(t, err)
+		assert.False(t, needsRehash)
+	})
+
+	t.Run("success - hash with current settings is left alone", func(t *testing.T) {
+		m, service := newMocksAndService()
+		service.passwordHasher = argon2idHasher
+		hash, err := argon2idHasher.Hash(testPassword)
+		require.NoError(t, err)
+		expectLogin(m, &model.User{ID: testUserID, Email: testEmail, PasswordHash: hash})
+
+		_, err = service.LoginUser(context.Background(), model.LoginUserParams{Email: testEmail, Password: testPassword})
+		require.NoError(t, err)
+		m.userRepo.AssertNotCalled(t, "RehashUserPassword", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("success - login goes through when storing the new hash fails", func(t *testing.T) {
+		m, service := newMocksAndService()
+		service.passwordHasher = passwordhash.NewHasher(passwordhash.Config{
+			Algorithm:  passwordhash.AlgorithmBcrypt,
+			BcryptCost: bcrypt.MinCost + 1,
+		})
+		user := createTestUser(testUserID, testEmail, testPassword)
+		expectLogin(m, user)
+		m.userRepo.On("RehashUserPassword", mock.Anything, testUserID, user.PasswordHash, mock.AnythingOfType("string")).
+			Return(errors.New("connection reset"))
+
+		_, err := service.LoginUser(context.Background(), model.LoginUserParams{Email: testEmail, Password: testPassword})
+		require.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - wrong password against an argon2id hash", func(t *testing.T) {
+		m, service := newMocksAndService()
+		service.passwordHasher = argon2idHasher
+		hash, err := argon2idHasher.Hash(testPassword)
+		require.NoError(t, err)
+		m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+			Return(&model.User{ID: testUserID, Email: testEmail, PasswordHash: hash}, nil)
+		m.expectLoginFailure(testUserID, model.LoginFailureIncorrectPassword)
+
+		_, err = service.LoginUser(context.Background(), model.LoginUserParams{Email: testEmail, Password: "wrongPassword"})
+		require.ErrorIs(t, err, model.ErrIncorrectCredentials)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestLoginUserThrottling(t *testing.T) {
+	const testUserID = "user-123"
+	const testEmail = "user@example.com"
+	const testPassword = "correctPassword123"
+	const testIP = "203.0.113.7"
+
+	existingUser := createTestUser(testUserID, testEmail, testPassword)
+	wrongLogin := model.LoginUserParams{Email: testEmail, Password: "wrongPassword", ClientIP: testIP}
+
+	t.Run("error - throttled after free attempts without checking the password", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+			Return(existingUser, nil).Times(testLoginPolicy.FreeAttempts + 1)
+		m.expectLoginFailure(testUserID, model.LoginFailureIncorrectPassword)
+
+		ctx := context.Background()
+		for range testLoginPolicy.FreeAttempts + 1 {
+			_, err := service.LoginUser(ctx, wrongLogin)
+			require.ErrorIs(t, err, model.ErrIncorrectCredentials)
+		}
+
+		// Even the right password is rejected until the delay has passed
+		_, err := service.LoginUser(ctx, model.LoginUserParams{Email: testEmail, Password: testPassword, ClientIP: testIP})
+		require.ErrorIs(t, err, model.ErrTooManyLoginAttempts)
+
+		var throttled *model.LoginThrottledError
+		require.ErrorAs(t, err, &throttled)
+		assert.InDelta(t, testLoginPolicy.BaseDelay.Seconds(), throttled.RetryAfter.Seconds(), 1)
+
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - unknown emails count as failures", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByEmail", mock.Anything, "ghost@example.com").
+			Return(nil, model.ErrUserNotFound).Times(testLoginPolicy.FreeAttempts + 1)
+
+		ctx := context.Background()
+		ghostLogin := model.LoginUserParams{Email: "ghost@example.com", Password: "anyPassword", ClientIP: testIP}
+		for range testLoginPolicy.FreeAttempts + 1 {
+			_, err := service.LoginUser(ctx, ghostLogin)
+			require.ErrorIs(t, err, model.ErrIncorrectCredentials)
+		}
+
+		_, err := service.LoginUser(ctx, ghostLogin)
+		require.ErrorIs(t, err, model.ErrTooManyLoginAttempts)
+
+		m.AssertExpectations(t)
+	})
+
+	t.Run("success - successful login clears the failures of the email", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).Return(existingUser, nil)
+		m.expectLoginFailure(testUserID, model.LoginFailureIncorrectPassword)
+		m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).Return(nil, model.ErrTwoFactorNotEnabled)
+		m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+			Return(&model.Session{ID: "session-1", FamilyID: "session-1", UserID: testUserID}, nil)
+		m.tokenMaker.On("CreateToken", mock.Anything).Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
+		m.userRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).Return(nil)
+		m.expectLoginFromKnownDevice(testUserID)
+
+		ctx := context.Background()
+		for range testLoginPolicy.FreeAttempts {
+			_, err := service.LoginUser(ctx, wrongLogin)
+			require.ErrorIs(t, err, model.ErrIncorrectCredentials)
+		}
+
+		_, err := service.LoginUser(ctx, model.LoginUserParams{Email: testEmail, Password: testPassword, ClientIP: "198.51.100.1"})
+		require.NoError(t, err)
+
+		// The counter starts over, so the free attempts are available again
+		for range testLoginPolicy.FreeAttempts {
+			_, err = service.LoginUser(ctx, model.LoginUserParams{Email: testEmail, Password: "wrongPassword", ClientIP: "198.51.100.1"})
+			require.ErrorIs(t, err, model.ErrIncorrectCredentials)
+		}
+
+		m.AssertExpectations(t)
+	})
+}
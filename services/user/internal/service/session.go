@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// startSession creates a new session family for the user and returns its first refresh token.
+func (s *UserService) startSession(ctx context.Context, userID, userAgent, clientIP string) (string, *model.Session, error) {
+	refreshToken, refreshTokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	sessionID, err := uuid.NewV7()
+	if err != nil {
+		return "", nil, fmt.Errorf("unexpected error occur when generating session ID: %w", err)
+	}
+
+	now := time.Now()
+	session, err := s.sessionRepo.CreateSession(ctx, model.Session{
+		ID:               sessionID.String(),
+		FamilyID:         sessionID.String(), // The first token of a family names the family
+		UserID:           userID,
+		RefreshTokenHash: refreshTokenHash,
+		UserAgent:        userAgent,
+		ClientIP:         clientIP,
+		ExpiresAt:        now.Add(s.cfg.RefreshTokenExpiry),
+		CreatedAt:        now,
+	})
+	if err != nil {
+		return "", nil, err
+	}
+
+	return refreshToken, session, nil
+}
+
+// RefreshSession exchanges a refresh token for a new access token and a new refresh token.
+//
+// Refresh tokens are single-use. If a token that was already exchanged shows up
+// again, someone is holding a copy of it: either the legitimate client or an
+// attacker, and we can't tell which. We revoke the whole family so both are
+// forced to log in again.
+func (s *UserService) RefreshSession(ctx context.Context, arg model.RefreshSessionParams) (*model.RefreshSessionResult, error) {
+	current, err := s.sessionRepo.FindSessionByTokenHash(ctx, hashOpaqueToken(arg.RefreshToken))
+	if err != nil {
+		if errors.Is(err, model.ErrSessionNotFound) {
+			return nil, model.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	if current.RevokedAt != nil {
+		return nil, model.ErrRefreshTokenInvalid
+	}
+
+	if current.RotatedAt != nil {
+		s.revokeSessionFamily(ctx, current.FamilyID)
+		return nil, model.ErrRefreshTokenReused
+	}
+
+	now := time.Now()
+	if now.After(current.ExpiresAt) {
+		return nil, model.ErrRefreshTokenExpired
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			s.revokeSessionFamily(ctx, current.FamilyID)
+			return nil, model.ErrRefreshTokenInvalid
+		}
+		return nil, err
+	}
+
+	refreshToken, refreshTokenHash, err := newOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	nextID, err := uuid.NewV7()
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating session ID: %w", err)
+	}
+
+	next := model.Session{
+		ID:               nextID.String(),
+		FamilyID:         current.FamilyID,
+		UserID:           current.UserID,
+		RefreshTokenHash: refreshTokenHash,
+		UserAgent:        current.UserAgent,
+		ClientIP:         current.ClientIP,
+		ExpiresAt:        now.Add(s.cfg.RefreshTokenExpiry),
+		CreatedAt:        now,
+	}
+	if arg.UserAgent != "" {
+		next.UserAgent = arg.UserAgent
+	}
+	if arg.ClientIP != "" {
+		next.ClientIP = arg.ClientIP
+	}
+
+	rotated, err := s.sessionRepo.RotateSession(ctx, current.ID, next)
+	if err != nil {
+		if errors.Is(err, model.ErrSessionAlreadyRotated) {
+			// Lost the race against another request presenting the same token
+			s.revokeSessionFamily(ctx, current.FamilyID)
+			return nil, model.ErrRefreshTokenReused
+		}
+		return nil, err
+	}
+
+	accessToken, accessTokenExpiresAt, err := s.tokenMaker.CreateToken(accessTokenParams(user, rotated.FamilyID))
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error occur when generating token: %w", err)
+	}
+
+	return &model.RefreshSessionResult{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  accessTokenExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: rotated.ExpiresAt,
+	}, nil
+}
+
+func (s *UserService) ListSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	return s.sessionRepo.ListActiveSessions(ctx, userID)
+}
+
+// RevokeSession logs one device out, including the access token it holds.
+func (s *UserService) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := s.sessionRepo.RevokeUserSession(ctx, userID, sessionID); err != nil {
+		return err
+	}
+
+	if err := s.revocations.RevokeSessionTokens(ctx, sessionID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeOtherSessions logs the user out of every device except the current
+// one. The access tokens of exactly the sessions that were revoked are
+// revoked too, session by session, so the current one keeps working.
+func (s *UserService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID string) error {
+	families, err := s.sessionRepo.RevokeOtherUserSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, familyID := range families {
+		if err = s.revocations.RevokeSessionTokens(ctx, familyID, now); err != nil {
+			return fmt.Errorf("failed to revoke access tokens: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Logout ends the current session: the presented access token is revoked right
+// away and the session's refresh token can no longer be exchanged.
+func (s *UserService) Logout(ctx context.Context, arg model.LogoutParams) error {
+	if err := s.revocations.RevokeToken(ctx, arg.TokenID, arg.TokenExpiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if arg.SessionID == "" {
+		return nil
+	}
+
+	err := s.sessionRepo.RevokeUserSession(ctx, arg.UserID, arg.SessionID)
+	if err != nil && !errors.Is(err, model.ErrSessionNotFound) {
+		return err
+	}
+
+	return nil
+}
+
+// LogoutAll ends every session of the user, including access tokens that were
+// already handed out to other devices.
+func (s *UserService) LogoutAll(ctx context.Context, userID string) error {
+	if err := s.sessionRepo.RevokeUserSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.revocations.RevokeUserTokens(ctx, userID, time.Now()); err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+
+	return nil
+}
+
+// revokeSessionFamily is best-effort: the caller is already rejecting the request.
+func (s *UserService) revokeSessionFamily(ctx context.Context, familyID string) {
+	if err := s.sessionRepo.RevokeSessionFamily(ctx, familyID); err != nil {
+		log.Printf("[ERROR] failed to revoke session family %s: %v", familyID, err)
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRefreshSession(t *testing.T) {
+	const testUserID = "user-123"
+	const testRefreshToken = "refresh-token-abc"
+	testTokenHash := hashOpaqueToken(testRefreshToken)
+
+	newCurrentSession := func() *model.Session {
+		return &model.Session{
+			ID:               "session-2",
+			FamilyID:         "session-1",
+			UserID:           testUserID,
+			RefreshTokenHash: testTokenHash,
+			UserAgent:        "old-agent",
+			ExpiresAt:        time.Now().Add(time.Hour),
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+		validate    func(t *testing.T, result *model.RefreshSessionResult)
+	}{
+		{
+			name: "success - rotates refresh token within the same family",
+			setupMock: func(m *serviceMocks) {
+				m.sessionRepo.On("FindSessionByTokenHash", mock.Anything, testTokenHash).
+					Return(newCurrentSession(), nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.sessionRepo.On("RotateSession", mock.Anything, "session-2", mock.MatchedBy(func(next model.Session) bool {
+					return next.FamilyID == "session-1" &&
+						next.UserID == testUserID &&
+						next.RefreshTokenHash != testTokenHash &&
+						next.UserAgent == "new-agent"
+				})).
+					Return(&model.Session{ID: "session-3", FamilyID: "session-1", ExpiresAt: time.Now().Add(testRefreshTokenExpiry)}, nil)
+				m.tokenMaker.On("CreateToken", token.CreateTokenParams{UserID: testUserID, SessionID: "session-1"}).
+					Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
+			},
+			validate: func(t *testing.T, result *model.RefreshSessionResult) {
+				assert.Equal(t, "jwt-token-xyz", result.AccessToken)
+				assert.NotEmpty(t, result.RefreshToken)
+				assert.NotEqual(t, testRefreshToken, result.RefreshToken)
+			},
+		},
+		{
+			name: "error - unknown refresh token",
+			setupMock: func(m *serviceMocks) {
+				m.sessionRepo.On("FindSessionByTokenHash", mock.Anything, testTokenHash).
+					Return(nil, model.ErrSessionNotFound)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "error - reused refresh token revokes the whole family",
+			setupMock: func(m *serviceMocks) {
+				rotated := newCurrentSession()
+				rotatedAt := time.Now().Add(-time.Minute)
+				rotated.RotatedAt = &rotatedAt
+
+				m.sessionRepo.On("FindSessionByTokenHash", mock.Anything, testTokenHash).
+					Return(rotated, nil)
+				m.sessionRepo.On("RevokeSessionFamily", mock.Anything, "session-1").
+					Return(nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrRefreshTokenReused,
+		},
+		{
+			name: "error - concurrent rotation is treated as reuse",
+			setupMock: func(m *serviceMocks) {
+				m.sessionRepo.On("FindSessionByTokenHash", mock.Anything, testTokenHash).
+					Return(newCurrentSession(), nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.sessionRepo.On("RotateSession", mock.Anything, "session-2", mock.AnythingOfType("model.Session")).
+					Return(nil, model.ErrSessionAlreadyRotated)
+				m.sessionRepo.On("RevokeSessionFamily", mock.Anything, "session-1").
+					Return(nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrRefreshTokenReused,
+		},
+		{
+			name: "error - revoked session",
+			setupMock: func(m *serviceMocks) {
+				revoked := newCurrentSession()
+				revokedAt := time.Now().Add(-time.Minute)
+				revoked.RevokedAt = &revokedAt
+
+				m.sessionRepo.On("FindSessionByTokenHash", mock.Anything, testTokenHash).
+					Return(revoked, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrRefreshTokenInvalid,
+		},
+		{
+			name: "error - expired refresh token",
+			setupMock: func(m *serviceMocks) {
+				expired := newCurrentSession()
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+				m.sessionRepo.On("FindSessionByTokenHash", mock.Anything, testTokenHash).
+					Return(expired, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrRefreshTokenExpired,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, svc := newMocksAndService()
+			tc.setupMock(m)
+
+			result, err := svc.RefreshSession(context.Background(), model.RefreshSessionParams{
+				RefreshToken: testRefreshToken,
+				UserAgent:    "new-agent",
+			})
+
+			if tc.wantErr {
+				require.Error(t, err)
+				require.Nil(t, result)
+				if tc.expectedErr != nil {
+					require.ErrorIs(t, err, tc.expectedErr)
+				}
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, result)
+				if tc.validate != nil {
+					tc.validate(t, result)
+				}
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	const testUserID = "user-123"
+
+	m, svc := newMocksAndService()
+	m.sessionRepo.On("RevokeUserSession", mock.Anything, testUserID, "session-1").
+		Return(nil)
+
+	issuedAt := time.Now()
+	err := svc.Logout(context.Background(), model.LogoutParams{
+		UserID:         testUserID,
+		SessionID:      "session-1",
+		TokenID:        "jti-1",
+		TokenExpiresAt: issuedAt.Add(15 * time.Minute),
+	})
+	require.NoError(t, err)
+
+	revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: testUserID, IssuedAt: issuedAt})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Other tokens of the same user remain valid
+	revoked, err = m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-2", UserID: testUserID, IssuedAt: issuedAt})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	m.AssertExpectations(t)
+}
+
+func TestLogoutAll(t *testing.T) {
+	const testUserID = "user-123"
+
+	m, svc := newMocksAndService()
+	m.sessionRepo.On("RevokeUserSessions", mock.Anything, testUserID).
+		Return(nil)
+
+	issuedBefore := time.Now().Add(-time.Minute)
+	require.NoError(t, svc.LogoutAll(context.Background(), testUserID))
+
+	revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: testUserID, IssuedAt: issuedBefore})
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	// Tokens issued after logging out everywhere are accepted
+	revoked, err = m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-2", UserID: testUserID, IssuedAt: time.Now().Add(2 * time.Second)})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	m.AssertExpectations(t)
+}
+
+func TestRevokeOtherSessionsRevokesTheReturnedFamilies(t *testing.T) {
+	const testUserID = "user-123"
+
+	m, svc := newMocksAndService()
+	// session-3 started after the user listed their sessions; it was still
+	// revoked by the same statement, so its tokens must be revoked too
+	m.sessionRepo.On("RevokeOtherUserSessions", mock.Anything, testUserID, "session-1").
+		Return([]string{"session-2", "session-3"}, nil)
+
+	issuedAt := time.Now().Add(-time.Minute)
+	require.NoError(t, svc.RevokeOtherSessions(context.Background(), testUserID, "session-1"))
+
+	for _, sessionID := range []string{"session-2", "session-3"} {
+		revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-" + sessionID, UserID: testUserID, SessionID: sessionID, IssuedAt: issuedAt})
+		require.NoError(t, err)
+		assert.True(t, revoked, sessionID)
+	}
+
+	revoked, err := m.revocations.IsRevoked(context.Background(), &token.Claims{ID: "jti-1", UserID: testUserID, SessionID: "session-1", IssuedAt: issuedAt})
+	require.NoError(t, err)
+	assert.False(t, revoked)
+
+	m.AssertExpectations(t)
+}
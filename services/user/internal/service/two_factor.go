@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/passwordhash"
+	"github.com/katatrina/airbnb-clone/services/user/internal/totp"
+)
+
+const (
+	// twoFactorChallengeExpiry is how long the user has to enter their code
+	// after typing the correct password.
+	twoFactorChallengeExpiry = 5 * time.Minute
+
+	// maxTwoFactorAttempts is how many codes can be tried against a challenge
+	// before it is burned and the user has to log in with the password again.
+	maxTwoFactorAttempts = 5
+
+	recoveryCodeCount = 10
+
+	// recoveryCodeLength is in characters of recoveryCodeAlphabet (5 bits each),
+	// displayed as two groups of five.
+	recoveryCodeLength = 10
+)
+
+// recoveryCodeAlphabet has 32 characters, so each random byte maps to one
+// without bias. It leaves out the easily confused 0/o and i/l.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz123456789"
+
+// SetupTOTP generates a new authenticator secret for the user. Two-factor
+// login only turns on after EnableTOTP confirms a code generated from it.
+// Calling it again before that replaces the pending secret.
+func (s *UserService) SetupTOTP(ctx context.Context, userID string) (*model.TOTPSetup, error) {
+	user, err := s.userRepo.FindUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.twoFactorRepo.SaveTOTPSecret(ctx, user.ID, secret); err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPSetup{
+		Secret: secret,
+		URI:    totp.URI(s.cfg.TOTPIssuer, user.Email, secret),
+	}, nil
+}
+
+// EnableTOTP turns on two-factor login once the user proves their authenticator
+// works. It returns the recovery codes, which are shown to the user only this once.
+func (s *UserService) EnableTOTP(ctx context.Context, arg model.EnableTOTPParams) ([]string, error) {
+	userTOTP, err := s.twoFactorRepo.FindTOTP(ctx, arg.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrTwoFactorNotEnabled) {
+			return nil, model.ErrTwoFactorSetupNotStarted
+		}
+		return nil, err
+	}
+	if userTOTP.Enabled() {
+		return nil, model.ErrTwoFactorAlreadyEnabled
+	}
+
+	step, ok := totp.Validate(userTOTP.Secret, arg.Code, time.Now())
+	if !ok {
+		return nil, model.ErrTwoFactorCodeInvalid
+	}
+
+	codes, records, err := newRecoveryCodes(arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.twoFactorRepo.EnableTOTP(ctx, arg.UserID, step, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (s *UserService) GetTwoFactorStatus(ctx context.Context, userID string) (*model.TwoFactorStatus, error) {
+	userTOTP, err := s.twoFactorRepo.FindTOTP(ctx, userID)
+	if err != nil {
+		if errors.Is(err, model.ErrTwoFactorNotEnabled) {
+			return &model.TwoFactorStatus{}, nil
+		}
+		return nil, err
+	}
+	if !userTOTP.Enabled() {
+		return &model.TwoFactorStatus{}, nil
+	}
+
+	remaining, err := s.twoFactorRepo.CountUnusedRecoveryCodes(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorStatus{
+		Enabled:                true,
+		EnabledAt:              userTOTP.ConfirmedAt,
+		RecoveryCodesRemaining: remaining,
+	}, nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes of the user, e.g. after
+// they used most of them. A current TOTP code is required.
+func (s *UserService) RegenerateRecoveryCodes(ctx context.Context, arg model.RegenerateRecoveryCodesParams) ([]string, error) {
+	userTOTP, err := s.findEnabledTOTP(ctx, arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.verifyTOTPCode(ctx, userTOTP, arg.Code); err != nil {
+		return nil, err
+	}
+
+	codes, records, err := newRecoveryCodes(arg.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = s.twoFactorRepo.ReplaceRecoveryCodes(ctx, arg.UserID, records); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor removes the authenticator and recovery codes after checking
+// the password. A pending setup is discarded as well.
+func (s *UserService) DisableTwoFactor(ctx context.Context, arg model.DisableTwoFactorParams) error {
+	user, err := s.userRepo.FindUserByID(ctx, arg.UserID)
+	if err != nil {
+		return err
+	}
+
+	err = s.comparePassword(user, arg.Password)
+	if err != nil {
+		if errors.Is(err, passwordhash.ErrMismatch) {
+			return model.ErrIncorrectPassword
+		}
+		return fmt.Errorf("unexpected error occur when comparing password: %w", err)
+	}
+
+	return s.twoFactorRepo.DeleteTOTP(ctx, user.ID)
+}
+
+// VerifyTwoFactorLogin finishes a login that LoginUser answered with a
+// challenge. The code may be a TOTP code or an unused recovery code.
+func (s *UserService) VerifyTwoFactorLogin(ctx context.Context, arg model.VerifyTwoFactorLoginParams) (*model.LoginUserResult, error) {
+	challengeHash := hashOpaqueToken(arg.ChallengeToken)
+
+	challenge, err := s.userTokenRepo.FindUserToken(ctx, model.TokenPurposeTwoFactorChallenge, challengeHash)
+	if err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return nil, model.ErrTwoFactorChallengeInvalid
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindUserByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			return nil, model.ErrTwoFactorChallengeInvalid
+		}
+		return nil, err
+	}
+
+	// 2FA may have been turned off since the password step; the challenge is
+	// then meaningless and the user simply logs in again.
+	userTOTP, err := s.findEnabledTOTP(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, model.ErrTwoFactorNotEnabled) {
+			return nil, model.ErrTwoFactorChallengeInvalid
+		}
+		return nil, err
+	}
+
+	// A new challenge only costs a correct password, so wrong codes count
+	// towards the same lockout as wrong passwords
+	retryAfter, err := s.loginGuard.Check(ctx, user.Email, arg.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+	if retryAfter > 0 {
+		return nil, &model.LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	// The attempt is claimed before the code is checked, so parallel guesses
+	// cannot all get in while the counter still reads below the limit
+	if _, err = s.userTokenRepo.ClaimUserTokenAttempt(ctx, challenge.ID, maxTwoFactorAttempts); err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return nil, model.ErrTwoFactorChallengeInvalid
+		}
+		return nil, err
+	}
+
+	if err = s.verifySecondFactor(ctx, userTOTP, arg.Code); err != nil {
+		if errors.Is(err, model.ErrTwoFactorCodeInvalid) {
+			if recordErr := s.loginGuard.RecordFailure(ctx, user.Email, arg.ClientIP); recordErr != nil {
+				log.Printf("[WARN] Failed to record failed login attempt: %v", recordErr)
+			}
+			s.recordLoginFailure(ctx, user.ID, arg.UserAgent, arg.ClientIP, model.LoginFailureIncorrectTwoFactorCode)
+		}
+		return nil, err
+	}
+
+	// Consuming the challenge makes sure it finishes at most one login
+	if _, err = s.userTokenRepo.ConsumeUserToken(ctx, model.TokenPurposeTwoFactorChallenge, challengeHash); err != nil {
+		if errors.Is(err, model.ErrUserTokenInvalid) {
+			return nil, model.ErrTwoFactorChallengeInvalid
+		}
+		return nil, err
+	}
+
+	if err = s.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		log.Printf("[WARN] Failed to reset failed login attempts: %v", err)
+	}
+
+	return s.completeLogin(ctx, user, arg.UserAgent, arg.ClientIP)
+}
+
+func (s *UserService) issueTwoFactorChallenge(ctx context.Context, userID string) (*model.TwoFactorChallenge, error) {
+	expiresAt := time.Now().Add(twoFactorChallengeExpiry)
+
+	rawToken, err := s.issueUserToken(ctx, userID, model.TokenPurposeTwoFactorChallenge, twoFactorChallengeExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TwoFactorChallenge{Token: rawToken, ExpiresAt: expiresAt}, nil
+}
+
+// findEnabledTOTP returns model.ErrTwoFactorNotEnabled for pending setups too.
+func (s *UserService) findEnabledTOTP(ctx context.Context, userID string) (*model.UserTOTP, error) {
+	userTOTP, err := s.twoFactorRepo.FindTOTP(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !userTOTP.Enabled() {
+		return nil, model.ErrTwoFactorNotEnabled
+	}
+
+	return userTOTP, nil
+}
+
+// verifySecondFactor accepts either a TOTP code or a recovery code.
+func (s *UserService) verifySecondFactor(ctx context.Context, userTOTP *model.UserTOTP, code string) error {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		return s.verifyTOTPCode(ctx, userTOTP, code)
+	}
+
+	return s.twoFactorRepo.ConsumeRecoveryCode(ctx, userTOTP.UserID, hashOpaqueToken(normalizeRecoveryCode(code)))
+}
+
+// verifyTOTPCode checks a TOTP code and burns its time step, so the same code
+// cannot be used again.
+func (s *UserService) verifyTOTPCode(ctx context.Context, userTOTP *model.UserTOTP, code string) error {
+	step, ok := totp.Validate(userTOTP.Secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return model.ErrTwoFactorCodeInvalid
+	}
+	if userTOTP.LastUsedStep != nil && step <= *userTOTP.LastUsedStep {
+		return model.ErrTwoFactorCodeInvalid
+	}
+
+	return s.twoFactorRepo.MarkTOTPStepUsed(ctx, userTOTP.UserID, step)
+}
+
+// newRecoveryCodes generates a fresh set of recovery codes. It returns the codes
+// to show to the user and the records (hashes only) to store.
+func newRecoveryCodes(userID string) ([]string, []model.RecoveryCode, error) {
+	codes := make([]string, recoveryCodeCount)
+	records := make([]model.RecoveryCode, recoveryCodeCount)
+	now := time.Now()
+
+	for i := range codes {
+		raw := make([]byte, recoveryCodeLength)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("unexpected error occur when generating recovery code: %w", err)
+		}
+
+		var b strings.Builder
+		for _, v := range raw {
+			b.WriteByte(recoveryCodeAlphabet[v&31])
+		}
+		code := b.String()
+
+		id, err := uuid.NewV7()
+		if err != nil {
+			return nil, nil, fmt.Errorf("unexpected error occur when generating recovery code ID: %w", err)
+		}
+
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		records[i] = model.RecoveryCode{
+			ID:        id.String(),
+			UserID:    userID,
+			CodeHash:  hashOpaqueToken(code),
+			CreatedAt: now,
+		}
+	}
+
+	return codes, records, nil
+}
+
+// normalizeRecoveryCode makes "ABCDE-FGHJK", "abcde fghjk" and "abcdefghjk" equal.
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/katatrina/airbnb-clone/pkg/token"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+	"github.com/katatrina/airbnb-clone/services/user/internal/totp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testTOTPSecret is the RFC 6238 test key ("12345678901234567890") in base32.
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func currentTOTPCode(t *testing.T) string {
+	t.Helper()
+	code, err := totp.Code(testTOTPSecret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	return code
+}
+
+// expectAttemptClaimed expects one attempt to be claimed on challenge, with attempts to spare.
+func (m *serviceMocks) expectAttemptClaimed(challenge *model.UserToken) {
+	m.userTokenRepo.On("ClaimUserTokenAttempt", mock.Anything, challenge.ID, maxTwoFactorAttempts).
+		Return(challenge, nil)
+}
+
+func TestSetupTOTP(t *testing.T) {
+	const testUserID = "user-123"
+
+	t.Run("success - returns secret and otpauth URI", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(&model.User{ID: testUserID, Email: "user@example.com"}, nil)
+		m.twoFactorRepo.On("SaveTOTPSecret", mock.Anything, testUserID, mock.AnythingOfType("string")).
+			Return(nil)
+
+		setup, err := service.SetupTOTP(context.Background(), testUserID)
+
+		require.NoError(t, err)
+		assert.NotEmpty(t, setup.Secret)
+		assert.True(t, strings.HasPrefix(setup.URI, "otpauth://totp/"))
+		assert.Contains(t, setup.URI, "secret="+setup.Secret)
+		assert.Contains(t, setup.URI, "user@example.com")
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - already enabled", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(&model.User{ID: testUserID, Email: "user@example.com"}, nil)
+		m.twoFactorRepo.On("SaveTOTPSecret", mock.Anything, testUserID, mock.AnythingOfType("string")).
+			Return(model.ErrTwoFactorAlreadyEnabled)
+
+		_, err := service.SetupTOTP(context.Background(), testUserID)
+
+		require.ErrorIs(t, err, model.ErrTwoFactorAlreadyEnabled)
+		m.AssertExpectations(t)
+	})
+}
+
+func TestEnableTOTP(t *testing.T) {
+	const testUserID = "user-123"
+	confirmedAt := time.Now()
+
+	testCases := []struct {
+		name        string
+		code        func(t *testing.T) string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - returns recovery codes",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(&model.UserTOTP{UserID: testUserID, Secret: testTOTPSecret}, nil)
+				m.twoFactorRepo.On("EnableTOTP", mock.Anything, testUserID, mock.AnythingOfType("int64"),
+					mock.MatchedBy(func(codes []model.RecoveryCode) bool {
+						return len(codes) == recoveryCodeCount
+					})).Return(nil)
+			},
+		},
+		{
+			name: "error - setup not started",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(nil, model.ErrTwoFactorNotEnabled)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorSetupNotStarted,
+		},
+		{
+			name: "error - already enabled",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(&model.UserTOTP{UserID: testUserID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt}, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorAlreadyEnabled,
+		},
+		{
+			name: "error - wrong code",
+			// Not numeric, so it can never collide with the current code
+			code: func(t *testing.T) string { return "abcdef" },
+			setupMock: func(m *serviceMocks) {
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(&model.UserTOTP{UserID: testUserID, Secret: testTOTPSecret}, nil)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorCodeInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			codes, err := service.EnableTOTP(context.Background(), model.EnableTOTPParams{
+				UserID: testUserID,
+				Code:   tc.code(t),
+			})
+
+			if tc.wantErr {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				require.Len(t, codes, recoveryCodeCount)
+				for _, c := range codes {
+					assert.Len(t, c, recoveryCodeLength+1)
+				}
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyTwoFactorLogin(t *testing.T) {
+	const testUserID = "user-123"
+	const challengeToken = "challenge-token"
+	challengeHash := hashOpaqueToken(challengeToken)
+	confirmedAt := time.Now()
+
+	challenge := &model.UserToken{
+		ID:      "challenge-1",
+		UserID:  testUserID,
+		Purpose: model.TokenPurposeTwoFactorChallenge,
+	}
+	enabledTOTP := func() *model.UserTOTP {
+		return &model.UserTOTP{UserID: testUserID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt}
+	}
+	testSession := &model.Session{
+		ID:        "session-1",
+		FamilyID:  "session-1",
+		UserID:    testUserID,
+		ExpiresAt: time.Now().Add(testRefreshTokenExpiry),
+	}
+	expectLogin := func(m *serviceMocks) {
+		m.userTokenRepo.On("ConsumeUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+			Return(challenge, nil)
+		m.sessionRepo.On("CreateSession", mock.Anything, mock.AnythingOfType("model.Session")).
+			Return(testSession, nil)
+		m.tokenMaker.On("CreateToken", token.CreateTokenParams{UserID: testUserID, SessionID: testSession.FamilyID}).
+			Return("jwt-token-xyz", time.Now().Add(15*time.Minute), nil)
+		m.userRepo.On("UpdateUserLastLogin", mock.Anything, testUserID, mock.AnythingOfType("time.Time")).
+			Return(nil)
+		m.expectLoginFromKnownDevice(testUserID)
+	}
+
+	testCases := []struct {
+		name        string
+		code        func(t *testing.T) string
+		setupMock   func(*serviceMocks)
+		wantErr     bool
+		expectedErr error
+	}{
+		{
+			name: "success - TOTP code",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(challenge, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).Return(enabledTOTP(), nil)
+				m.expectAttemptClaimed(challenge)
+				m.twoFactorRepo.On("MarkTOTPStepUsed", mock.Anything, testUserID, mock.AnythingOfType("int64")).
+					Return(nil)
+				expectLogin(m)
+			},
+		},
+		{
+			name: "success - recovery code, case and dash insensitive",
+			code: func(t *testing.T) string { return "ABCDE-FGHJK" },
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(challenge, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).Return(enabledTOTP(), nil)
+				m.expectAttemptClaimed(challenge)
+				m.twoFactorRepo.On("ConsumeRecoveryCode", mock.Anything, testUserID, hashOpaqueToken("abcdefghjk")).
+					Return(nil)
+				expectLogin(m)
+			},
+		},
+		{
+			name: "error - challenge invalid or expired",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(nil, model.ErrUserTokenInvalid)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorChallengeInvalid,
+		},
+		{
+			name: "error - wrong code counts a failed attempt",
+			code: func(t *testing.T) string { return "abcde-zzzzz" },
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(challenge, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).Return(enabledTOTP(), nil)
+				m.expectAttemptClaimed(challenge)
+				m.twoFactorRepo.On("ConsumeRecoveryCode", mock.Anything, testUserID, mock.AnythingOfType("string")).
+					Return(model.ErrTwoFactorCodeInvalid)
+				m.expectLoginFailure(testUserID, model.LoginFailureIncorrectTwoFactorCode)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorCodeInvalid,
+		},
+		{
+			name: "error - TOTP code from an already used step",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				usedStep := totp.Step(time.Now()) + 1
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(challenge, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(&model.UserTOTP{UserID: testUserID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt, LastUsedStep: &usedStep}, nil)
+				m.expectAttemptClaimed(challenge)
+				m.expectLoginFailure(testUserID, model.LoginFailureIncorrectTwoFactorCode)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorCodeInvalid,
+		},
+		{
+			name: "error - attempts used up by codes tried in parallel",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(challenge, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).Return(enabledTOTP(), nil)
+				m.userTokenRepo.On("ClaimUserTokenAttempt", mock.Anything, challenge.ID, maxTwoFactorAttempts).
+					Return(nil, model.ErrUserTokenInvalid)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorChallengeInvalid,
+		},
+		{
+			name: "error - 2FA was disabled after the password step",
+			code: currentTOTPCode,
+			setupMock: func(m *serviceMocks) {
+				m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, challengeHash).
+					Return(challenge, nil)
+				m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+					Return(&model.User{ID: testUserID}, nil)
+				m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+					Return(nil, model.ErrTwoFactorNotEnabled)
+			},
+			wantErr:     true,
+			expectedErr: model.ErrTwoFactorChallengeInvalid,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, service := newMocksAndService()
+			tc.setupMock(m)
+
+			result, err := service.VerifyTwoFactorLogin(context.Background(), model.VerifyTwoFactorLoginParams{
+				ChallengeToken: challengeToken,
+				Code:           tc.code(t),
+			})
+
+			if tc.wantErr {
+				require.ErrorIs(t, err, tc.expectedErr)
+				require.Nil(t, result)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "jwt-token-xyz", result.AccessToken)
+				assert.NotEmpty(t, result.RefreshToken)
+				assert.Nil(t, result.TwoFactorChallenge)
+			}
+
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVerifyTwoFactorLoginThrottling(t *testing.T) {
+	const testUserID = "user-123"
+	const testEmail = "user@example.com"
+	const testPassword = "correctPassword123"
+	const testIP = "203.0.113.7"
+	confirmedAt := time.Now()
+
+	challenge := &model.UserToken{
+		ID:      "challenge-1",
+		UserID:  testUserID,
+		Purpose: model.TokenPurposeTwoFactorChallenge,
+	}
+	wrongCode := model.VerifyTwoFactorLoginParams{ChallengeToken: "challenge-token", Code: "abcde-zzzzz", ClientIP: testIP}
+
+	m, service := newMocksAndService()
+	m.userRepo.On("FindUserByEmail", mock.Anything, testEmail).
+		Return(createTestUser(testUserID, testEmail, testPassword), nil)
+	m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+		Return(&model.User{ID: testUserID, Email: testEmail}, nil)
+	m.twoFactorRepo.On("FindTOTP", mock.Anything, testUserID).
+		Return(&model.UserTOTP{UserID: testUserID, Secret: testTOTPSecret, ConfirmedAt: &confirmedAt}, nil)
+	m.userTokenRepo.On("InvalidateUserTokens", mock.Anything, testUserID, model.TokenPurposeTwoFactorChallenge).
+		Return(nil)
+	m.userTokenRepo.On("CreateUserToken", mock.Anything, mock.AnythingOfType("model.UserToken")).Return(nil)
+	m.userTokenRepo.On("FindUserToken", mock.Anything, model.TokenPurposeTwoFactorChallenge, mock.AnythingOfType("string")).
+		Return(challenge, nil)
+	m.expectAttemptClaimed(challenge)
+	m.twoFactorRepo.On("ConsumeRecoveryCode", mock.Anything, testUserID, mock.AnythingOfType("string")).
+		Return(model.ErrTwoFactorCodeInvalid)
+	m.expectLoginFailure(testUserID, model.LoginFailureIncorrectTwoFactorCode)
+
+	ctx := context.Background()
+	for range testLoginPolicy.FreeAttempts + 1 {
+		// Every fresh challenge gets its own attempts, but the correct password
+		// that earned it does not clear the wrong codes counted so far
+		result, err := service.LoginUser(ctx, model.LoginUserParams{Email: testEmail, Password: testPassword, ClientIP: testIP})
+		require.NoError(t, err)
+		require.NotNil(t, result.TwoFactorChallenge)
+
+		_, err = service.VerifyTwoFactorLogin(ctx, wrongCode)
+		require.ErrorIs(t, err, model.ErrTwoFactorCodeInvalid)
+	}
+
+	// Rejected before the code is even looked at
+	_, err := service.VerifyTwoFactorLogin(ctx, wrongCode)
+	require.ErrorIs(t, err, model.ErrTooManyLoginAttempts)
+	m.userTokenRepo.AssertNumberOfCalls(t, "ClaimUserTokenAttempt", testLoginPolicy.FreeAttempts+1)
+
+	_, err = service.LoginUser(ctx, model.LoginUserParams{Email: testEmail, Password: testPassword, ClientIP: testIP})
+	require.ErrorIs(t, err, model.ErrTooManyLoginAttempts)
+
+	m.AssertExpectations(t)
+}
+
+func TestDisableTwoFactor(t *testing.T) {
+	const testUserID = "user-123"
+	const testPassword = "correctPassword123"
+
+	t.Run("success", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+		m.twoFactorRepo.On("DeleteTOTP", mock.Anything, testUserID).Return(nil)
+
+		err := service.DisableTwoFactor(context.Background(), model.DisableTwoFactorParams{
+			UserID:   testUserID,
+			Password: testPassword,
+		})
+
+		require.NoError(t, err)
+		m.AssertExpectations(t)
+	})
+
+	t.Run("error - wrong password", func(t *testing.T) {
+		m, service := newMocksAndService()
+		m.userRepo.On("FindUserByID", mock.Anything, testUserID).
+			Return(createTestUser(testUserID, "user@example.com", testPassword), nil)
+
+		err := service.DisableTwoFactor(context.Background(), model.DisableTwoFactorParams{
+			UserID:   testUserID,
+			Password: "wrongPassword",
+		})
+
+		require.ErrorIs(t, err, model.ErrIncorrectPassword)
+		m.AssertExpectations(t)
+	})
+}
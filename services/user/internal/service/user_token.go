@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/katatrina/airbnb-clone/services/user/internal/model"
+)
+
+// issueUserToken invalidates the user's outstanding tokens for purpose and
+// creates a new one, so only the most recently sent link works.
+// It returns the raw token to deliver to the user.
+func (s *UserService) issueUserToken(ctx context.Context, userID string, purpose model.TokenPurpose, expiry time.Duration) (string, error) {
+	if err := s.userTokenRepo.InvalidateUserTokens(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	rawToken, tokenHash, err := newOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	tokenID, err := uuid.NewV7()
+	if err != nil {
+		return "", fmt.Errorf("unexpected error occur when generating token ID: %w", err)
+	}
+
+	now := time.Now()
+	err = s.userTokenRepo.CreateUserToken(ctx, model.UserToken{
+		ID:        tokenID.String(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(expiry),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// consumeUserToken redeems a raw token received from the user.
+func (s *UserService) consumeUserToken(ctx context.Context, purpose model.TokenPurpose, rawToken string) (*model.UserToken, error) {
+	return s.userTokenRepo.ConsumeUserToken(ctx, purpose, hashOpaqueToken(rawToken))
+}
+
+// formatExpiry renders a token lifetime for humans, e.g. "24 hours" or "30 minutes".
+func formatExpiry(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d hours", int(d.Hours()))
+	}
+	return fmt.Sprintf("%d minutes", int(d.Minutes()))
+}
@@ -0,0 +1,17 @@
+package sms
+
+import (
+	"context"
+	"log"
+)
+
+// ConsoleSender prints text messages to the application log instead of
+// sending them, so codes can be read during local development.
+type ConsoleSender struct{}
+
+var _ Sender = ConsoleSender{}
+
+func (ConsoleSender) Send(_ context.Context, msg Message) error {
+	log.Printf("[SMS] to=%s\n%s", msg.To, msg.Body)
+	return nil
+}
@@ -0,0 +1,15 @@
+package sms
+
+import "context"
+
+// Message is a plain-text SMS. To is a phone number in E.164 format.
+type Message struct {
+	To   string
+	Body string
+}
+
+// Sender delivers text messages. Only ConsoleSender exists for now; a real
+// provider (e.g. an SMS gateway's HTTP API) plugs in by implementing Send.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
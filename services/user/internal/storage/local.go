@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalDiskStore keeps blobs as files under a root directory. The directory is
+// expected to be served over HTTP at baseURL (see cmd/api), unless the blobs
+// are private and only read back through Open.
+type LocalDiskStore struct {
+	rootDir string
+	baseURL string
+}
+
+var _ BlobStore = (*LocalDiskStore)(nil)
+
+func NewLocalDiskStore(rootDir, baseURL string) (*LocalDiskStore, error) {
+	if err := os.MkdirAll(rootDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &LocalDiskStore{
+		rootDir: rootDir,
+		baseURL: strings.TrimRight(baseURL, "/"),
+	}, nil
+}
+
+// Put writes the blob to a temporary file first and renames it into place, so
+// readers never see a partially written file.
+func (s *LocalDiskStore) Put(_ context.Context, key string, r io.Reader, _ string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(filePath), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create blob file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err = io.Copy(tmp, r); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write blob: %w", err)
+	}
+
+	if err = os.Rename(tmp.Name(), filePath); err != nil {
+		return fmt.Errorf("failed to store blob: %w", err)
+	}
+
+	return nil
+}
+
+// Open returns a reader for the blob. The caller must close it.
+func (s *LocalDiskStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open blob: %w", err)
+	}
+
+	return f, nil
+}
+
+// Delete removes the blob. Deleting a missing blob is not an error.
+func (s *LocalDiskStore) Delete(_ context.Context, key string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete blob: %w", err)
+	}
+
+	return nil
+}
+
+func (s *LocalDiskStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// filePath maps a key to a path inside rootDir, rejecting keys like "../x".
+func (s *LocalDiskStore) filePath(key string) (string, error) {
+	cleaned := path.Clean("/" + key)[1:]
+	if key == "" || cleaned != key {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(s.rootDir, filepath.FromSlash(cleaned)), nil
+}
@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrInvalidKey is returned for keys that are empty or try to escape the store.
+var ErrInvalidKey = errors.New("invalid blob key")
+
+// BlobStore stores files (avatars, documents) under slash-separated keys such as
+// "avatars/{userID}/{fileID}.png". LocalDiskStore is the only driver for now;
+// an object storage driver only needs to implement the same methods.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, contentType string) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+
+	// URL returns the address the blob is publicly served from.
+	URL(key string) string
+}
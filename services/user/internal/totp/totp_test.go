@@ -0,0 +1,89 @@
+package totp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors ("12345678901234567890") in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA1. The RFC lists 8-digit codes; with 6 digits
+	// they are the last six.
+	testCases := []struct {
+		unix int64
+		code string
+	}{
+		{unix: 59, code: "287082"},
+		{unix: 1111111109, code: "081804"},
+		{unix: 1111111111, code: "050471"},
+		{unix: 1234567890, code: "005924"},
+		{unix: 2000000000, code: "279037"},
+		{unix: 20000000000, code: "353130"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(time.Unix(tc.unix, 0).UTC().Format(time.RFC3339), func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(tc.unix, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, tc.code, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	testCases := []struct {
+		name     string
+		step     int64
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "current step", step: current, wantOK: true, wantStep: current},
+		{name: "previous step, clock drift", step: current - 1, wantOK: true, wantStep: current - 1},
+		{name: "next step, clock drift", step: current + 1, wantOK: true, wantStep: current + 1},
+		{name: "two steps old", step: current - 2},
+		{name: "two steps ahead", step: current + 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, tc.step)
+			require.NoError(t, err)
+
+			step, ok := Validate(rfcSecret, code, now)
+			assert.Equal(t, tc.wantOK, ok)
+			assert.Equal(t, tc.wantStep, step)
+		})
+	}
+
+	_, ok := Validate(rfcSecret, "50471", now)
+	assert.False(t, ok, "codes of the wrong length are rejected")
+	_, ok = Validate("not base32!", "050471", now)
+	assert.False(t, ok, "invalid secrets never validate")
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	key, err := encoding.DecodeString(secret)
+	require.NoError(t, err)
+	assert.Len(t, key, secretSize)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Airbnb Clone", "user@example.com", rfcSecret)
+
+	assert.Equal(t, "otpauth://totp/Airbnb%20Clone:user@example.com?algorithm=SHA1&digits=6&issuer=Airbnb+Clone&period=30&secret="+rfcSecret, uri)
+}
@@ -0,0 +1,25 @@
+-- Each row is one refresh token. Rotating a refresh token marks the current row
+-- as rotated and inserts a new row in the same family, so a family represents
+-- a single logged-in device.
+CREATE TABLE sessions
+(
+    id                 UUID PRIMARY KEY,
+    family_id          UUID        NOT NULL,
+    user_id            UUID        NOT NULL,
+    refresh_token_hash TEXT        NOT NULL UNIQUE,
+    user_agent         TEXT        NOT NULL DEFAULT '',
+    client_ip          TEXT        NOT NULL DEFAULT '',
+    expires_at         TIMESTAMPTZ NOT NULL,
+    rotated_at         TIMESTAMPTZ,
+    revoked_at         TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- List user's active sessions
+CREATE INDEX idx_sessions_user
+    ON sessions (user_id, created_at)
+    WHERE rotated_at IS NULL AND revoked_at IS NULL;
+
+-- Revoke a whole family on reuse detection
+CREATE INDEX idx_sessions_family
+    ON sessions (family_id);
@@ -0,0 +1,17 @@
+-- Single-use tokens sent to the user out of band (email links, etc.).
+-- Only the SHA-256 hash of a token is stored; purpose tells flows apart.
+CREATE TABLE user_tokens
+(
+    id          UUID PRIMARY KEY,
+    user_id     UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    purpose     TEXT        NOT NULL,
+    token_hash  TEXT        NOT NULL UNIQUE,
+    expires_at  TIMESTAMPTZ NOT NULL,
+    consumed_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Invalidate outstanding tokens of a user for one purpose
+CREATE INDEX idx_user_tokens_user_purpose
+    ON user_tokens (user_id, purpose)
+    WHERE consumed_at IS NULL;
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    DROP COLUMN bio,
+    DROP COLUMN phone,
+    DROP COLUMN languages,
+    DROP COLUMN avatar_key,
+    DROP COLUMN avatar_url;
@@ -0,0 +1,6 @@
+ALTER TABLE users
+    ADD COLUMN bio        TEXT   NOT NULL DEFAULT '',
+    ADD COLUMN phone      TEXT,
+    ADD COLUMN languages  TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN avatar_key TEXT, -- Blob store key, kept to delete the file when replaced
+    ADD COLUMN avatar_url TEXT;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_users_pending_anonymization;
+
+ALTER TABLE users
+    DROP COLUMN anonymized_at;
@@ -0,0 +1,9 @@
+-- Deleted accounts keep their row (bookings and listings still reference the ID),
+-- but personal data is scrubbed once the grace period is over.
+ALTER TABLE users
+    ADD COLUMN anonymized_at TIMESTAMPTZ;
+
+-- Find deleted accounts that still need to be anonymized
+CREATE INDEX idx_users_pending_anonymization
+    ON users (deleted_at)
+    WHERE deleted_at IS NOT NULL AND anonymized_at IS NULL;
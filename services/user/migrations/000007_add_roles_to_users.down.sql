@@ -0,0 +1,3 @@
+ALTER TABLE users
+    DROP CONSTRAINT check_roles,
+    DROP COLUMN roles;
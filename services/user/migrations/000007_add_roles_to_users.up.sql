@@ -0,0 +1,6 @@
+-- Every account may book and host by default; admin is granted explicitly.
+ALTER TABLE users
+    ADD COLUMN roles TEXT[] NOT NULL DEFAULT '{guest,host}',
+    ADD CONSTRAINT check_roles CHECK (
+        cardinality(roles) > 0 AND roles <@ ARRAY ['guest', 'host', 'admin']
+    );
@@ -0,0 +1,5 @@
+ALTER TABLE user_tokens
+    DROP COLUMN attempts;
+
+DROP TABLE recovery_codes;
+DROP TABLE user_totp;
@@ -0,0 +1,30 @@
+-- TOTP authenticator of a user. The row is created at setup and only counts as
+-- enabled once confirmed_at is set, i.e. after the user proved they can
+-- generate codes. last_used_step stops a code from being used twice.
+CREATE TABLE user_totp
+(
+    user_id        UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    secret         TEXT        NOT NULL,
+    last_used_step BIGINT,
+    confirmed_at   TIMESTAMPTZ,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Single-use recovery codes for when the authenticator is lost.
+-- Only the SHA-256 hash of a code is stored.
+CREATE TABLE recovery_codes
+(
+    id         UUID PRIMARY KEY,
+    user_id    UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    code_hash  TEXT        NOT NULL,
+    used_at    TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_recovery_codes_user_id
+    ON recovery_codes (user_id)
+    WHERE used_at IS NULL;
+
+-- Failed attempts on a token, so a 2FA login challenge can be locked after a few wrong codes
+ALTER TABLE user_tokens
+    ADD COLUMN attempts INT NOT NULL DEFAULT 0;
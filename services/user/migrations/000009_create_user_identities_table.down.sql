@@ -0,0 +1,10 @@
+DROP TABLE oidc_auth_requests;
+DROP TABLE user_identities;
+
+-- Accounts without a password get an unusable empty hash
+UPDATE users
+SET password_hash = ''
+WHERE password_hash IS NULL;
+
+ALTER TABLE users
+    ALTER COLUMN password_hash SET NOT NULL;
@@ -0,0 +1,36 @@
+-- Accounts created through an external provider have no password
+ALTER TABLE users
+    ALTER COLUMN password_hash DROP NOT NULL;
+
+UPDATE users
+SET password_hash = NULL
+WHERE password_hash = '';
+
+-- Links an account at an OpenID provider (provider + subject) to a user.
+-- A user can link several providers, a provider account only one user.
+CREATE TABLE user_identities
+(
+    id            UUID PRIMARY KEY,
+    user_id       UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    provider      TEXT        NOT NULL,
+    subject       TEXT        NOT NULL,
+    email         TEXT        NOT NULL DEFAULT '',
+    last_login_at TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT user_identities_provider_subject_key UNIQUE (provider, subject)
+);
+
+CREATE INDEX idx_user_identities_user_id ON user_identities (user_id);
+
+-- In-flight logins: what we sent to the provider and must check on the way back.
+-- Only the SHA-256 hash of the state is stored.
+CREATE TABLE oidc_auth_requests
+(
+    state_hash    TEXT PRIMARY KEY,
+    provider      TEXT        NOT NULL,
+    nonce         TEXT        NOT NULL,
+    code_verifier TEXT        NOT NULL,
+    expires_at    TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,21 @@
+-- Audit trail of logins: every successful login and every wrong password or
+-- 2FA code for an existing account. Attempts for unknown emails are not stored.
+CREATE TABLE login_events
+(
+    id                 UUID PRIMARY KEY,
+    user_id            UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    success            BOOLEAN     NOT NULL,
+    reason             TEXT,
+    client_ip          TEXT        NOT NULL DEFAULT '',
+    user_agent         TEXT        NOT NULL DEFAULT '',
+    device_fingerprint TEXT        NOT NULL,
+    new_device         BOOLEAN     NOT NULL DEFAULT FALSE,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_login_events_user_id_created_at ON login_events (user_id, created_at DESC);
+
+-- Answers "has this user logged in from this device before?"
+CREATE INDEX idx_login_events_known_devices
+    ON login_events (user_id, device_fingerprint)
+    WHERE success;
@@ -0,0 +1,25 @@
+-- Personal data export jobs. A background worker builds the ZIP archive and
+-- stores it under file_key until expires_at; the download link is a user token.
+CREATE TABLE data_exports
+(
+    id           UUID PRIMARY KEY,
+    user_id      UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    status       TEXT        NOT NULL DEFAULT 'pending',
+    file_key     TEXT,
+    file_size    BIGINT,
+    error        TEXT,
+    started_at   TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_data_exports_user_id_created_at ON data_exports (user_id, created_at DESC);
+
+-- At most one export per user is queued or being built at a time
+CREATE UNIQUE INDEX idx_data_exports_user_id_in_progress
+    ON data_exports (user_id)
+    WHERE status IN ('pending', 'processing');
+
+-- The worker's queue
+CREATE INDEX idx_data_exports_status_created_at ON data_exports (status, created_at);
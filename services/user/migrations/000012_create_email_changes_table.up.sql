@@ -0,0 +1,23 @@
+-- Pending and past email address changes. The confirm token goes to the new
+-- address, the cancel token to the old one; only their SHA-256 hashes are stored.
+CREATE TABLE email_changes
+(
+    id                 UUID PRIMARY KEY,
+    user_id            UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    old_email          TEXT        NOT NULL,
+    old_email_verified BOOLEAN     NOT NULL,
+    new_email          TEXT        NOT NULL,
+    status             TEXT        NOT NULL DEFAULT 'pending',
+    confirm_token_hash TEXT        NOT NULL UNIQUE,
+    cancel_token_hash  TEXT        NOT NULL UNIQUE,
+    expires_at         TIMESTAMPTZ NOT NULL,
+    cancel_expires_at  TIMESTAMPTZ NOT NULL,
+    confirmed_at       TIMESTAMPTZ,
+    cancelled_at       TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- A new request replaces the pending one, so there is at most one per user
+CREATE UNIQUE INDEX idx_email_changes_user_id_pending
+    ON email_changes (user_id)
+    WHERE status = 'pending';
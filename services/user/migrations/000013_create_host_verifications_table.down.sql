@@ -0,0 +1,5 @@
+DROP TABLE host_verifications;
+
+ALTER TABLE users
+    DROP CONSTRAINT check_host_verification_status,
+    DROP COLUMN host_verification_status;
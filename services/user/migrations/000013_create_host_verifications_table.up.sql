@@ -0,0 +1,33 @@
+-- Identity verification of hosts. The outcome of the latest review lives on
+-- the user, so other services can check it with a single lookup.
+ALTER TABLE users
+    ADD COLUMN host_verification_status TEXT NOT NULL DEFAULT 'unverified',
+    ADD CONSTRAINT check_host_verification_status CHECK (
+        host_verification_status IN ('unverified', 'pending', 'verified', 'rejected')
+    );
+
+-- Each submission of ID documents. The files are kept in a private store
+-- under front_key and back_key.
+CREATE TABLE host_verifications
+(
+    id               UUID PRIMARY KEY,
+    user_id          UUID        NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    document_type    TEXT        NOT NULL,
+    front_key        TEXT        NOT NULL,
+    back_key         TEXT,
+    status           TEXT        NOT NULL DEFAULT 'pending',
+    rejection_reason TEXT,
+    reviewed_by      UUID REFERENCES users (id) ON DELETE SET NULL,
+    reviewed_at      TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_host_verifications_user_id_created_at ON host_verifications (user_id, created_at DESC);
+
+-- At most one submission per user waits for review
+CREATE UNIQUE INDEX idx_host_verifications_user_id_pending
+    ON host_verifications (user_id)
+    WHERE status = 'pending';
+
+-- The review queue
+CREATE INDEX idx_host_verifications_status_created_at ON host_verifications (status, created_at);
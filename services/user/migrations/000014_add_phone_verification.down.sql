@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_user_tokens_user_purpose_created_at;
+
+ALTER TABLE users
+    DROP COLUMN phone_verified_at;
@@ -0,0 +1,8 @@
+-- Set once the user proved they receive SMS at their phone number by entering
+-- a code sent there. Changing the number clears it again.
+ALTER TABLE users
+    ADD COLUMN phone_verified_at TIMESTAMPTZ;
+
+-- Count the codes a user requested recently, used or not, to limit SMS sends
+CREATE INDEX idx_user_tokens_user_purpose_created_at
+    ON user_tokens (user_id, purpose, created_at);
@@ -0,0 +1,5 @@
+ALTER TABLE users
+    DROP COLUMN notification_preferences,
+    DROP COLUMN timezone,
+    DROP COLUMN currency,
+    DROP COLUMN locale;
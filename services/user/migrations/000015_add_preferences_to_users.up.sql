@@ -0,0 +1,18 @@
+-- Display preferences default to what a Vietnamese guest expects. They travel
+-- in access token claims so other services can localize without a lookup.
+ALTER TABLE users
+    ADD COLUMN locale   TEXT NOT NULL DEFAULT 'vi',
+    ADD COLUMN currency TEXT NOT NULL DEFAULT 'VND',
+    ADD COLUMN timezone TEXT NOT NULL DEFAULT 'Asia/Ho_Chi_Minh',
+    ADD CONSTRAINT check_locale CHECK (locale IN ('vi', 'en')),
+    ADD CONSTRAINT check_currency CHECK (currency IN ('VND', 'USD'));
+
+-- Which channels each kind of notification may use. Security alerts are not
+-- listed because they are always sent by email.
+ALTER TABLE users
+    ADD COLUMN notification_preferences JSONB NOT NULL DEFAULT '{
+        "booking_updates": {"email": true, "sms": false},
+        "hosting_updates": {"email": true, "sms": false},
+        "reminders": {"email": true, "sms": false},
+        "promotions": {"email": false, "sms": false}
+    }';